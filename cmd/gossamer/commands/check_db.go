@@ -0,0 +1,96 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	CheckDBCmd.Flags().Bool("repair", false, "Repair the missing or wrong block number indices")
+	CheckDBCmd.Flags().Bool("json", false, "Print the check report as JSON")
+}
+
+// CheckDBCmd is the command to check the consistency of the database
+var CheckDBCmd = &cobra.Command{
+	Use:   "check-db",
+	Short: "Check the consistency of the database",
+	Long: `The check-db command verifies the database of the node from its finalised
+block down to the genesis block: the continuity of the canonical chain, the block
+number index, the block bodies, the justifications of the GRANDPA set change blocks,
+the state tries of the retained blocks, and the BABE epoch data and GRANDPA authority
+sets against the block digests. The missing or wrong block number indices are
+repaired if --repair is set, and the report is printed as JSON if --json is set.
+The command fails if some of the inconsistencies found are not repaired.
+The node must be initialised and must not be running while the database is checked.
+Example:
+	gossamer check-db --base-path ~/.gossamer/westend --repair --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return execCheckDB(cmd)
+	},
+}
+
+// execCheckDB executes the check-db command
+func execCheckDB(cmd *cobra.Command) error {
+	repair, err := cmd.Flags().GetBool("repair")
+	if err != nil {
+		return fmt.Errorf("failed to get repair: %s", err)
+	}
+
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to get json: %s", err)
+	}
+
+	isInitialised, err := dot.IsNodeInitialised(config.BasePath)
+	if err != nil {
+		return fmt.Errorf("failed to check if node is initialised: %w", err)
+	}
+	if !isInitialised {
+		return fmt.Errorf("node must be initialised before checking the database")
+	}
+
+	report, err := dot.CheckDatabase(config, repair)
+	if err != nil {
+		return fmt.Errorf("failed to check database: %w", err)
+	}
+
+	if asJSON {
+		out, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		fmt.Println(string(out))
+	} else {
+		for _, issue := range report.Issues {
+			hash := ""
+			if issue.Hash != nil {
+				hash = " (" + issue.Hash.String() + ")"
+			}
+			repaired := ""
+			if issue.Repaired {
+				repaired = ", repaired"
+			}
+			logger.Warnf("%s: block #%d%s: %s%s", issue.Check, issue.Number, hash, issue.Message, repaired)
+		}
+	}
+
+	var unrepaired int
+	for _, issue := range report.Issues {
+		if !issue.Repaired {
+			unrepaired++
+		}
+	}
+	if unrepaired > 0 {
+		return fmt.Errorf("database check found %d issues not repaired", unrepaired)
+	}
+
+	logger.Infof("database checked up to finalised block #%d (%s), %d issues repaired",
+		report.FinalisedNumber, report.FinalisedHash, len(report.Issues))
+	return nil
+}
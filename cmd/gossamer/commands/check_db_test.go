@@ -0,0 +1,26 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDBNodeNotInitialised(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(CheckDBCmd)
+
+	rootCmd.SetArgs([]string{CheckDBCmd.Name(),
+		"--chain", testChainSpec,
+		"--base-path", t.TempDir(),
+		"--repair",
+		"--json",
+	})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "node must be initialised before checking the database")
+}
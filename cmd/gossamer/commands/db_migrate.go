@@ -0,0 +1,71 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	DBMigrateCmd.Flags().Bool("dry-run", false, "List the migrations pending without running them")
+}
+
+// DBMigrateCmd is the command to migrate the database to the latest schema version
+var DBMigrateCmd = &cobra.Command{
+	Use:   "db-migrate",
+	Short: "Migrate the database to the latest schema version",
+	Long: `The db-migrate command runs the migrations of the database schema pending,
+from the schema version of the database to the latest schema version of the node.
+The migrations are also run when the node starts, the command allows running them
+ahead, or listing them with --dry-run.
+The node must be initialised and must not be running while the database is migrated.
+Example:
+	gossamer db-migrate --base-path ~/.gossamer/westend --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return execDBMigrate(cmd)
+	},
+}
+
+// execDBMigrate executes the db-migrate command
+func execDBMigrate(cmd *cobra.Command) error {
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("failed to get dry-run: %s", err)
+	}
+
+	isInitialised, err := dot.IsNodeInitialised(config.BasePath)
+	if err != nil {
+		return fmt.Errorf("failed to check if node is initialised: %w", err)
+	}
+	if !isInitialised {
+		return fmt.Errorf("node must be initialised before migrating the database")
+	}
+
+	version, pending, err := dot.MigrateDatabase(config.BasePath, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	if len(pending) == 0 {
+		logger.Infof("database schema version %d is the latest, no migration pending", version)
+		return nil
+	}
+
+	for _, migration := range pending {
+		logger.Infof("migration to schema version %d: %s", migration.Version, migration.Description)
+	}
+
+	if dryRun {
+		logger.Infof("dry run, %d migrations pending from schema version %d to version %d",
+			len(pending), version, state.SchemaVersion)
+		return nil
+	}
+
+	logger.Infof("migrated database schema from version %d to version %d", version, state.SchemaVersion)
+	return nil
+}
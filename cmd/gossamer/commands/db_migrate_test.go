@@ -0,0 +1,25 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBMigrateNodeNotInitialised(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(DBMigrateCmd)
+
+	rootCmd.SetArgs([]string{DBMigrateCmd.Name(),
+		"--chain", testChainSpec,
+		"--base-path", t.TempDir(),
+		"--dry-run",
+	})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "node must be initialised before migrating the database")
+}
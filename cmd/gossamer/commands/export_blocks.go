@@ -0,0 +1,81 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	ExportBlocksCmd.Flags().Uint("from", 1, "Number of the first block to export")
+	ExportBlocksCmd.Flags().Uint("to", 0, "Number of the last block to export, defaults to the best block")
+	ExportBlocksCmd.Flags().String("out", "", "Path of the block archive file to write")
+	ExportBlocksCmd.Flags().Bool("compress", false, "Compress the block archive using zstd")
+}
+
+// ExportBlocksCmd is the command to export blocks to a block archive file
+var ExportBlocksCmd = &cobra.Command{
+	Use:   "export-blocks",
+	Short: "Export blocks of the best chain to a block archive file",
+	Long: `The export-blocks command writes the headers, bodies and justifications
+of the blocks of the best chain to a portable block archive file,
+which can be imported by another node using the import-blocks command.
+The node must not be running while the blocks are exported.
+Example:
+	gossamer export-blocks --base-path ~/.gossamer/westend --from 1 --to 100000 --out blocks.bin --compress`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return execExportBlocks(cmd)
+	},
+}
+
+// execExportBlocks executes the export-blocks command
+func execExportBlocks(cmd *cobra.Command) error {
+	if basePath == "" {
+		basePath = config.BasePath
+	}
+
+	if basePath == "" {
+		return fmt.Errorf("basepath must be specified")
+	}
+
+	from, err := cmd.Flags().GetUint("from")
+	if err != nil {
+		return fmt.Errorf("failed to get from: %s", err)
+	}
+
+	to, err := cmd.Flags().GetUint("to")
+	if err != nil {
+		return fmt.Errorf("failed to get to: %s", err)
+	}
+	if to != 0 && to < from {
+		return fmt.Errorf("to must be greater than or equal to from")
+	}
+
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("failed to get out: %s", err)
+	}
+	if out == "" {
+		return fmt.Errorf("out must be specified")
+	}
+
+	compress, err := cmd.Flags().GetBool("compress")
+	if err != nil {
+		return fmt.Errorf("failed to get compress: %s", err)
+	}
+
+	basePath = utils.ExpandDir(basePath)
+
+	exported, err := dot.ExportBlocks(basePath, from, to, out, compress)
+	if err != nil {
+		return fmt.Errorf("failed to export blocks: %w", err)
+	}
+
+	logger.Infof("exported %d blocks to %s", exported, out)
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportBlocksMissingOut(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(ExportBlocksCmd)
+
+	rootCmd.SetArgs([]string{ExportBlocksCmd.Name(), "--base-path", t.TempDir()})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "out must be specified")
+}
+
+func TestExportBlocksInvalidRange(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(ExportBlocksCmd)
+
+	rootCmd.SetArgs([]string{ExportBlocksCmd.Name(),
+		"--base-path", t.TempDir(),
+		"--from", "10",
+		"--to", "5",
+		"--out", "blocks.bin",
+	})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "to must be greater than or equal to from")
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	ExportSnapshotCmd.Flags().String("block", "", "Hash of the finalised block to export the snapshot of")
+	ExportSnapshotCmd.Flags().String("out", "", "Path of the snapshot file to write")
+}
+
+// ExportSnapshotCmd is the command to export the state snapshot of a finalised block
+var ExportSnapshotCmd = &cobra.Command{
+	Use:   "export-snapshot",
+	Short: "Export the state snapshot of a finalised block to a snapshot file",
+	Long: `The export-snapshot command writes the header of a finalised block, its state
+including the child tries, and the BABE epoch and GRANDPA authority set data at the block
+to a compressed snapshot file with checksums, which can be imported by another node
+using the import-snapshot command to continue syncing from the block.
+The export fails if a GRANDPA authority set change is announced before the block
+and applied after it, in which case a later block must be exported.
+The node must be initialised and must not be running while the snapshot is exported.
+Example:
+	gossamer export-snapshot --base-path ~/.gossamer/westend --block <block hash> --out snapshot.bin`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return execExportSnapshot(cmd)
+	},
+}
+
+// execExportSnapshot executes the export-snapshot command
+func execExportSnapshot(cmd *cobra.Command) error {
+	block, err := cmd.Flags().GetString("block")
+	if err != nil {
+		return fmt.Errorf("failed to get block: %s", err)
+	}
+	if block == "" {
+		return fmt.Errorf("block must be specified")
+	}
+	if !strings.HasPrefix(block, "0x") {
+		return fmt.Errorf("invalid block hash: %s must be 0x prefixed", block)
+	}
+	blockHash, err := common.HexToHash(block)
+	if err != nil {
+		return fmt.Errorf("invalid block hash: %w", err)
+	}
+
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("failed to get out: %s", err)
+	}
+	if out == "" {
+		return fmt.Errorf("out must be specified")
+	}
+
+	isInitialised, err := dot.IsNodeInitialised(config.BasePath)
+	if err != nil {
+		return fmt.Errorf("failed to check if node is initialised: %w", err)
+	}
+	if !isInitialised {
+		return fmt.Errorf("node must be initialised before exporting a snapshot")
+	}
+
+	err = dot.ExportSnapshot(config, blockHash, out)
+	if err != nil {
+		return fmt.Errorf("failed to export snapshot: %w", err)
+	}
+
+	logger.Infof("exported snapshot of block %s to %s", blockHash, out)
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBlockHash = "0x276bfa91f70859348285599321ea96afd3ae681f0be47d36196bac8075ea32e8"
+
+func TestExportSnapshotMissingBlock(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(ExportSnapshotCmd)
+
+	rootCmd.SetArgs([]string{ExportSnapshotCmd.Name(), "--chain", testChainSpec, "--base-path", t.TempDir()})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "block must be specified")
+}
+
+func TestExportSnapshotInvalidBlock(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(ExportSnapshotCmd)
+
+	rootCmd.SetArgs([]string{ExportSnapshotCmd.Name(),
+		"--chain", testChainSpec,
+		"--base-path", t.TempDir(),
+		"--block", "1",
+	})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "invalid block hash")
+}
+
+func TestExportSnapshotMissingOut(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(ExportSnapshotCmd)
+
+	rootCmd.SetArgs([]string{ExportSnapshotCmd.Name(),
+		"--chain", testChainSpec,
+		"--base-path", t.TempDir(),
+		"--block", testBlockHash,
+	})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "out must be specified")
+}
+
+func TestExportSnapshotNodeNotInitialised(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(ExportSnapshotCmd)
+
+	rootCmd.SetArgs([]string{ExportSnapshotCmd.Name(),
+		"--chain", testChainSpec,
+		"--base-path", t.TempDir(),
+		"--block", testBlockHash,
+		"--out", "snapshot.bin",
+	})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "node must be initialised before exporting a snapshot")
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	ImportBlocksCmd.Flags().String("in", "", "Path of the block archive file to import")
+	ImportBlocksCmd.Flags().Bool("skip-verify", false,
+		"Do not verify the headers and bodies of the blocks, only use with a trusted block archive")
+}
+
+// ImportBlocksCmd is the command to import blocks from a block archive file
+var ImportBlocksCmd = &cobra.Command{
+	Use:   "import-blocks",
+	Short: "Import blocks from a block archive file",
+	Long: `The import-blocks command imports the blocks of a block archive file
+written by the export-blocks command. The blocks are verified and executed
+the way they are during the initial sync, the headers and bodies of the blocks
+are not verified if --skip-verify is set. The blocks already imported are skipped,
+so an interrupted import can be resumed by running the command again.
+The node must be initialised and must not be running while the blocks are imported.
+Example:
+	gossamer import-blocks --base-path ~/.gossamer/westend --in blocks.bin`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return execImportBlocks(cmd)
+	},
+}
+
+// execImportBlocks executes the import-blocks command
+func execImportBlocks(cmd *cobra.Command) error {
+	in, err := cmd.Flags().GetString("in")
+	if err != nil {
+		return fmt.Errorf("failed to get in: %s", err)
+	}
+	if in == "" {
+		return fmt.Errorf("in must be specified")
+	}
+
+	skipVerify, err := cmd.Flags().GetBool("skip-verify")
+	if err != nil {
+		return fmt.Errorf("failed to get skip-verify: %s", err)
+	}
+
+	isInitialised, err := dot.IsNodeInitialised(config.BasePath)
+	if err != nil {
+		return fmt.Errorf("failed to check if node is initialised: %w", err)
+	}
+	if !isInitialised {
+		return fmt.Errorf("node must be initialised before importing blocks")
+	}
+
+	imported, err := dot.ImportBlocks(config, in, skipVerify)
+	if err != nil {
+		return fmt.Errorf("failed to import blocks after importing %d blocks: %w", imported, err)
+	}
+
+	logger.Infof("imported %d blocks from %s", imported, in)
+	return nil
+}
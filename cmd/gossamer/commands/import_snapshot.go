@@ -0,0 +1,60 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	ImportSnapshotCmd.Flags().String("in", "", "Path of the snapshot file to import")
+}
+
+// ImportSnapshotCmd is the command to bootstrap a node from a state snapshot file
+var ImportSnapshotCmd = &cobra.Command{
+	Use:   "import-snapshot",
+	Short: "Bootstrap the node from a state snapshot file",
+	Long: `The import-snapshot command imports a snapshot file written by the export-snapshot
+command. The checksums of the snapshot and the state root of its block are verified,
+then the block is set as the finalised head of the chain along with its state and
+the BABE epoch and GRANDPA authority set data of the snapshot, so that the node
+continues syncing from the block once started.
+The node must be initialised with the chain of the snapshot, must not have imported
+any block and must not be running while the snapshot is imported.
+Example:
+	gossamer import-snapshot --base-path ~/.gossamer/westend --in snapshot.bin`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return execImportSnapshot(cmd)
+	},
+}
+
+// execImportSnapshot executes the import-snapshot command
+func execImportSnapshot(cmd *cobra.Command) error {
+	in, err := cmd.Flags().GetString("in")
+	if err != nil {
+		return fmt.Errorf("failed to get in: %s", err)
+	}
+	if in == "" {
+		return fmt.Errorf("in must be specified")
+	}
+
+	isInitialised, err := dot.IsNodeInitialised(config.BasePath)
+	if err != nil {
+		return fmt.Errorf("failed to check if node is initialised: %w", err)
+	}
+	if !isInitialised {
+		return fmt.Errorf("node must be initialised before importing a snapshot")
+	}
+
+	number, err := dot.ImportSnapshot(config, in)
+	if err != nil {
+		return fmt.Errorf("failed to import snapshot: %w", err)
+	}
+
+	logger.Infof("imported snapshot of block #%d from %s", number, in)
+	return nil
+}
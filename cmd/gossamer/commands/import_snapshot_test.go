@@ -0,0 +1,35 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportSnapshotMissingIn(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(ImportSnapshotCmd)
+
+	rootCmd.SetArgs([]string{ImportSnapshotCmd.Name(), "--chain", testChainSpec, "--base-path", t.TempDir()})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "in must be specified")
+}
+
+func TestImportSnapshotNodeNotInitialised(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(ImportSnapshotCmd)
+
+	rootCmd.SetArgs([]string{ImportSnapshotCmd.Name(),
+		"--chain", testChainSpec,
+		"--base-path", t.TempDir(),
+		"--in", "snapshot.bin",
+	})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "node must be initialised before importing a snapshot")
+}
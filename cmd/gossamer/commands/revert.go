@@ -0,0 +1,68 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RevertCmd.Flags().Uint("blocks", 0, "Number of blocks to revert from the best block")
+	RevertCmd.Flags().Bool("revert-finalised", false,
+		"Allow reverting finalised blocks, the new best block becomes the finalised block")
+}
+
+// RevertCmd is the command to revert the chain by a number of blocks
+var RevertCmd = &cobra.Command{
+	Use:   "revert",
+	Short: "Revert the chain by a number of blocks",
+	Long: `The revert command reverts the chain of the node by the given number
+of blocks from its best block. The reverted blocks are removed from the database,
+and the GRANDPA authority set, the BABE epoch data and the finalised block are
+restored as they were at the new best block.
+Reverting finalised blocks fails unless --revert-finalised is set, in which case
+the new best block becomes the finalised block. Since the blocks not finalised are
+not kept by a stopped node, reverting any block of a stopped node requires it.
+The node must be initialised and must not be running while the chain is reverted.
+Example:
+	gossamer revert --base-path ~/.gossamer/westend --blocks 100 --revert-finalised`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return execRevert(cmd)
+	},
+}
+
+// execRevert executes the revert command
+func execRevert(cmd *cobra.Command) error {
+	blocks, err := cmd.Flags().GetUint("blocks")
+	if err != nil {
+		return fmt.Errorf("failed to get blocks: %s", err)
+	}
+	if blocks == 0 {
+		return fmt.Errorf("blocks must be greater than 0")
+	}
+
+	revertFinalised, err := cmd.Flags().GetBool("revert-finalised")
+	if err != nil {
+		return fmt.Errorf("failed to get revert-finalised: %s", err)
+	}
+
+	isInitialised, err := dot.IsNodeInitialised(config.BasePath)
+	if err != nil {
+		return fmt.Errorf("failed to check if node is initialised: %w", err)
+	}
+	if !isInitialised {
+		return fmt.Errorf("node must be initialised before reverting blocks")
+	}
+
+	best, err := dot.RevertBlocks(config, blocks, revertFinalised)
+	if err != nil {
+		return fmt.Errorf("failed to revert blocks: %w", err)
+	}
+
+	logger.Infof("reverted chain to best block #%d (%s)", best.Number, best.Hash())
+	return nil
+}
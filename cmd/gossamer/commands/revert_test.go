@@ -0,0 +1,35 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertMissingBlocks(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(RevertCmd)
+
+	rootCmd.SetArgs([]string{RevertCmd.Name(), "--chain", testChainSpec, "--base-path", t.TempDir()})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "blocks must be greater than 0")
+}
+
+func TestRevertNodeNotInitialised(t *testing.T) {
+	rootCmd, err := NewRootCommand()
+	require.NoError(t, err)
+	rootCmd.AddCommand(RevertCmd)
+
+	rootCmd.SetArgs([]string{RevertCmd.Name(),
+		"--chain", testChainSpec,
+		"--base-path", t.TempDir(),
+		"--blocks", "10",
+	})
+	err = rootCmd.Execute()
+	assert.ErrorContains(t, err, "node must be initialised before reverting blocks")
+}
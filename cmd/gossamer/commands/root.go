@@ -84,7 +84,7 @@ func NewRootCommand() (*cobra.Command, error) {
 			return execRoot(cmd)
 		},
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
-			if !(cmd.Name() == "gossamer" || cmd.Name() == "init") {
+			if !(cmd.Name() == "gossamer" || cmd.Name() == "init" || cmd.Name() == "import-blocks") {
 				return nil
 			}
 
@@ -106,6 +106,13 @@ func NewRootCommand() (*cobra.Command, error) {
 				return fmt.Errorf("failed to parse telemetry-url: %s", err.Error())
 			}
 
+			// the flag default must not override the pruning mode of the configuration file
+			if cmd.Flags().Changed("state-pruning") {
+				if err := parsePruning(); err != nil {
+					return fmt.Errorf("failed to parse state-pruning: %s", err)
+				}
+			}
+
 			parseRPC()
 
 			// If no chain-spec is provided, it should already exist in the base-path
@@ -125,7 +132,7 @@ func NewRootCommand() (*cobra.Command, error) {
 				return fmt.Errorf("failed to parse log level: %s", err)
 			}
 
-			if cmd.Name() == "gossamer" {
+			if cmd.Name() == "gossamer" || cmd.Name() == "import-blocks" {
 				if err := configureViper(config.BasePath); err != nil {
 					return fmt.Errorf("failed to configure viper: %s", err)
 				}
@@ -241,7 +248,7 @@ func addBaseConfigFlags(cmd *cobra.Command) error {
 	cmd.Flags().StringVar(&pruning,
 		"state-pruning",
 		string(config.BaseConfig.Pruning),
-		"State trie online pruning")
+		"State trie online pruning mode, one of archive or full")
 	if err := addBoolFlagBindViper(cmd,
 		"prometheus-external",
 		config.BaseConfig.PrometheusExternal,
@@ -363,6 +370,14 @@ func addNetworkFlags(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to add --persistent-peers flag: %s", err)
 	}
 
+	if err := addBoolFlagBindViper(cmd,
+		"reserved-only",
+		config.Network.ReservedOnly,
+		"Only connect to and accept connections from the persistent (reserved) peers",
+		"network.reserved-only"); err != nil {
+		return fmt.Errorf("failed to add --reserved-only flag: %s", err)
+	}
+
 	if err := addDurationFlagBindViper(cmd,
 		"discovery-interval",
 		config.Network.DiscoveryInterval,
@@ -403,6 +418,39 @@ func addNetworkFlags(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to add --listen-addr flag: %s", err)
 	}
 
+	if err := addStringSliceFlagBindViper(cmd,
+		"listen-addrs",
+		config.Network.ListenAddresses,
+		"Comma separated list of additional multiaddresses to listen on",
+		"network.listen-addrs"); err != nil {
+		return fmt.Errorf("failed to add --listen-addrs flag: %s", err)
+	}
+
+	if err := addStringSliceFlagBindViper(cmd,
+		"public-addrs",
+		config.Network.PublicAddresses,
+		"Comma separated list of multiaddresses advertised to other peers",
+		"network.public-addrs"); err != nil {
+		return fmt.Errorf("failed to add --public-addrs flag: %s", err)
+	}
+
+	if err := addStringSliceFlagBindViper(cmd,
+		"transports",
+		config.Network.Transports,
+		"Comma separated list of transports to listen on (tcp, quic, ws), peers are dialed with any of them",
+		"network.transports"); err != nil {
+		return fmt.Errorf("failed to add --transports flag: %s", err)
+	}
+
+	if err := addStringSliceFlagBindViper(cmd,
+		"outbound-rate-limits",
+		config.Network.OutboundRateLimits,
+		"Comma separated list of outbound rate limits formatted as protocol=bytes per second "+
+			"(sync, light, warp, block-announces, transactions, grandpa)",
+		"network.outbound-rate-limits"); err != nil {
+		return fmt.Errorf("failed to add --outbound-rate-limits flag: %s", err)
+	}
+
 	return nil
 }
 
@@ -529,6 +577,22 @@ func addCoreFlags(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to add --grandpa-interval flag: %s", err)
 	}
 
+	if err := addStringSliceFlagBindViper(cmd,
+		"bad-blocks",
+		config.Core.BadBlocks,
+		"Comma separated list of bad block hashes, the bad blocks and their descendants are not imported",
+		"core.bad-blocks"); err != nil {
+		return fmt.Errorf("failed to add --bad-blocks flag: %s", err)
+	}
+
+	if err := addStringSliceFlagBindViper(cmd,
+		"fork-blocks",
+		config.Core.ForkBlocks,
+		"Comma separated list of fork blocks formatted as number:hash, only the given block is imported at the number",
+		"core.fork-blocks"); err != nil {
+		return fmt.Errorf("failed to add --fork-blocks flag: %s", err)
+	}
+
 	return nil
 }
 
@@ -541,6 +605,13 @@ func addStateFlags(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to add --rewind flag: %s", err)
 	}
 
+	if err := addStringFlagBindViper(cmd,
+		"state-backend", config.State.Backend,
+		"Storage backend of the state tries, one of inmemory or triedb",
+		"state.backend"); err != nil {
+		return fmt.Errorf("failed to add --state-backend flag: %s", err)
+	}
+
 	return nil
 }
 
@@ -30,6 +30,7 @@
 	terminal "golang.org/x/term"
 
 	cfg "github.com/ChainSafe/gossamer/config"
+	"github.com/ChainSafe/gossamer/dot/state/pruner"
 
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/keystore"
@@ -407,6 +408,18 @@ func parseRole() error {
 	return nil
 }
 
+// parsePruning parses the state pruning mode from the command line flag
+func parsePruning() error {
+	mode := pruner.Mode(pruning)
+	if !mode.IsValid() {
+		return fmt.Errorf("invalid state pruning mode: %s", pruning)
+	}
+
+	config.Pruning = mode
+	viper.Set("pruning", config.Pruning)
+	return nil
+}
+
 // parseTelemetryURL parses the telemetry-url from the command line flag
 func parseTelemetryURL() error {
 	if telemetryURLs == "" {
@@ -25,6 +25,13 @@ func main() {
 		commands.BuildSpecCmd,
 		commands.PruneStateCmd,
 		commands.ImportStateCmd,
+		commands.ExportBlocksCmd,
+		commands.ImportBlocksCmd,
+		commands.RevertCmd,
+		commands.CheckDBCmd,
+		commands.DBMigrateCmd,
+		commands.ExportSnapshotCmd,
+		commands.ImportSnapshotCmd,
 		commands.VersionCmd,
 	)
 	configureCobraCmd("GSSMR")
@@ -29,6 +29,8 @@
 	DefaultRetainBlocks = uint32(512)
 	// DefaultPruning is the default pruning strategy
 	DefaultPruning = pruner.Archive
+	// DefaultStateBackend is the default storage backend of the state tries
+	DefaultStateBackend = "inmemory"
 
 	// defaultAccount is the default account key
 	defaultAccount = "alice"
@@ -77,6 +79,9 @@
 	"payment",
 }
 
+// DefaultTransports the default transports listened on
+var DefaultTransports = []string{"tcp"}
+
 // Config defines the configuration for the gossamer node
 type Config struct {
 	BaseConfig `mapstructure:",squash"`
@@ -166,19 +171,24 @@ type AccountConfig struct {
 
 // NetworkConfig is to marshal/unmarshal toml network config vars
 type NetworkConfig struct {
-	Port              uint16        `mapstructure:"port"`
-	Bootnodes         []string      `mapstructure:"bootnodes"`
-	ProtocolID        string        `mapstructure:"protocol"`
-	NoBootstrap       bool          `mapstructure:"no-bootstrap"`
-	NoMDNS            bool          `mapstructure:"no-mdns"`
-	MinPeers          int           `mapstructure:"min-peers"`
-	MaxPeers          int           `mapstructure:"max-peers"`
-	PersistentPeers   []string      `mapstructure:"persistent-peers"`
-	DiscoveryInterval time.Duration `mapstructure:"discovery-interval"`
-	PublicIP          string        `mapstructure:"public-ip"`
-	PublicDNS         string        `mapstructure:"public-dns"`
-	NodeKey           string        `mapstructure:"node-key"`
-	ListenAddress     string        `mapstructure:"listen-addr"`
+	Port               uint16        `mapstructure:"port"`
+	Bootnodes          []string      `mapstructure:"bootnodes"`
+	ProtocolID         string        `mapstructure:"protocol"`
+	NoBootstrap        bool          `mapstructure:"no-bootstrap"`
+	NoMDNS             bool          `mapstructure:"no-mdns"`
+	MinPeers           int           `mapstructure:"min-peers"`
+	MaxPeers           int           `mapstructure:"max-peers"`
+	PersistentPeers    []string      `mapstructure:"persistent-peers"`
+	ReservedOnly       bool          `mapstructure:"reserved-only"`
+	DiscoveryInterval  time.Duration `mapstructure:"discovery-interval"`
+	PublicIP           string        `mapstructure:"public-ip"`
+	PublicDNS          string        `mapstructure:"public-dns"`
+	NodeKey            string        `mapstructure:"node-key"`
+	ListenAddress      string        `mapstructure:"listen-addr"`
+	ListenAddresses    []string      `mapstructure:"listen-addrs"`
+	PublicAddresses    []string      `mapstructure:"public-addrs"`
+	Transports         []string      `mapstructure:"transports"`
+	OutboundRateLimits []string      `mapstructure:"outbound-rate-limits,omitempty"`
 }
 
 // CoreConfig is to marshal/unmarshal toml core config vars
@@ -188,11 +198,14 @@ type CoreConfig struct {
 	GrandpaAuthority bool               `mapstructure:"grandpa-authority"`
 	WasmInterpreter  string             `mapstructure:"wasm-interpreter,omitempty"`
 	GrandpaInterval  time.Duration      `mapstructure:"grandpa-interval,omitempty"`
+	BadBlocks        []string           `mapstructure:"bad-blocks,omitempty"`
+	ForkBlocks       []string           `mapstructure:"fork-blocks,omitempty"`
 }
 
 // StateConfig contains the configuration for the state.
 type StateConfig struct {
-	Rewind uint `mapstructure:"rewind,omitempty"`
+	Rewind  uint   `mapstructure:"rewind,omitempty"`
+	Backend string `mapstructure:"backend,omitempty"`
 }
 
 // RPCConfig is to marshal/unmarshal toml RPC config vars
@@ -233,6 +246,9 @@ func (b *BaseConfig) ValidateBasic() error {
 	if b.PrometheusPort == 0 {
 		return fmt.Errorf("prometheus port cannot be empty")
 	}
+	if !b.Pruning.IsValid() {
+		return fmt.Errorf("invalid pruning mode: %s", b.Pruning)
+	}
 	if uint32Max < b.RetainBlocks {
 		return fmt.Errorf(
 			"retain-blocks value overflows uint32 boundaries, must be less than or equal to: %d",
@@ -280,12 +296,28 @@ func (c *CoreConfig) ValidateBasic() error {
 	if c.WasmInterpreter != wazero.Name {
 		return fmt.Errorf("wasm-interpreter is invalid")
 	}
+	for _, badBlock := range c.BadBlocks {
+		if _, err := genesis.ParseBlockHash(badBlock); err != nil {
+			return fmt.Errorf("bad-blocks is invalid: %w", err)
+		}
+	}
+	for _, forkBlock := range c.ForkBlocks {
+		if _, err := genesis.ParseForkBlock(forkBlock); err != nil {
+			return fmt.Errorf("fork-blocks is invalid: %w", err)
+		}
+	}
 
 	return nil
 }
 
 // ValidateBasic does the basic validation on StateConfig
 func (s *StateConfig) ValidateBasic() error {
+	switch s.Backend {
+	case "", "inmemory", "triedb":
+	default:
+		return fmt.Errorf("invalid state backend: %q, must be one of inmemory or triedb", s.Backend)
+	}
+
 	return nil
 }
 
@@ -373,14 +405,19 @@ func DefaultConfig() *Config {
 			MinPeers:          DefaultMinPeers,
 			MaxPeers:          DefaultMaxPeers,
 			PersistentPeers:   nil,
+			ReservedOnly:      false,
 			DiscoveryInterval: DefaultDiscoveryInterval,
 			PublicIP:          "",
 			PublicDNS:         "",
 			NodeKey:           "",
 			ListenAddress:     "",
+			ListenAddresses:   nil,
+			PublicAddresses:   nil,
+			Transports:        DefaultTransports,
 		},
 		State: &StateConfig{
-			Rewind: 0,
+			Rewind:  0,
+			Backend: DefaultStateBackend,
 		},
 		RPC: &RPCConfig{
 			RPCExternal:       false,
@@ -454,14 +491,19 @@ func DefaultConfigFromSpec(nodeSpec *genesis.Genesis) *Config {
 			MinPeers:          DefaultMinPeers,
 			MaxPeers:          DefaultMaxPeers,
 			PersistentPeers:   nil,
+			ReservedOnly:      false,
 			DiscoveryInterval: DefaultDiscoveryInterval,
 			PublicIP:          "",
 			PublicDNS:         "",
 			NodeKey:           "",
 			ListenAddress:     "",
+			ListenAddresses:   nil,
+			PublicAddresses:   nil,
+			Transports:        DefaultTransports,
 		},
 		State: &StateConfig{
-			Rewind: 0,
+			Rewind:  0,
+			Backend: DefaultStateBackend,
 		},
 		RPC: &RPCConfig{
 			RPCExternal:       false,
@@ -525,24 +567,32 @@ func Copy(c *Config) Config {
 			GrandpaAuthority: c.Core.GrandpaAuthority,
 			WasmInterpreter:  c.Core.WasmInterpreter,
 			GrandpaInterval:  c.Core.GrandpaInterval,
+			BadBlocks:        c.Core.BadBlocks,
+			ForkBlocks:       c.Core.ForkBlocks,
 		},
 		Network: &NetworkConfig{
-			Port:              c.Network.Port,
-			Bootnodes:         c.Network.Bootnodes,
-			ProtocolID:        c.Network.ProtocolID,
-			NoBootstrap:       c.Network.NoBootstrap,
-			NoMDNS:            c.Network.NoMDNS,
-			MinPeers:          c.Network.MinPeers,
-			MaxPeers:          c.Network.MaxPeers,
-			PersistentPeers:   c.Network.PersistentPeers,
-			DiscoveryInterval: c.Network.DiscoveryInterval,
-			PublicIP:          c.Network.PublicIP,
-			PublicDNS:         c.Network.PublicDNS,
-			NodeKey:           c.Network.NodeKey,
-			ListenAddress:     c.Network.ListenAddress,
+			Port:               c.Network.Port,
+			Bootnodes:          c.Network.Bootnodes,
+			ProtocolID:         c.Network.ProtocolID,
+			NoBootstrap:        c.Network.NoBootstrap,
+			NoMDNS:             c.Network.NoMDNS,
+			MinPeers:           c.Network.MinPeers,
+			MaxPeers:           c.Network.MaxPeers,
+			PersistentPeers:    c.Network.PersistentPeers,
+			ReservedOnly:       c.Network.ReservedOnly,
+			DiscoveryInterval:  c.Network.DiscoveryInterval,
+			PublicIP:           c.Network.PublicIP,
+			PublicDNS:          c.Network.PublicDNS,
+			NodeKey:            c.Network.NodeKey,
+			ListenAddress:      c.Network.ListenAddress,
+			ListenAddresses:    c.Network.ListenAddresses,
+			PublicAddresses:    c.Network.PublicAddresses,
+			Transports:         c.Network.Transports,
+			OutboundRateLimits: c.Network.OutboundRateLimits,
 		},
 		State: &StateConfig{
-			Rewind: c.State.Rewind,
+			Rewind:  c.State.Rewind,
+			Backend: c.State.Backend,
 		},
 		RPC: &RPCConfig{
 			UnsafeRPC:         c.RPC.UnsafeRPC,
@@ -91,6 +91,7 @@ func WriteConfigFile(basePath string, config *Config) error {
 retain-blocks = {{ .BaseConfig.RetainBlocks }}
 
 # State trie online pruning mode
+# One of: archive, full
 # Defaults to "archive"
 pruning = "{{ .BaseConfig.Pruning }}"
 
@@ -201,6 +202,10 @@ func WriteConfigFile(basePath string, config *Config) error {
 # Comma separated list of peers to always keep connected to
 persistent-peers = "{{ StringsJoin .Network.PersistentPeers ", " }}"
 
+# Only connect to and accept connections from the persistent (reserved) peers
+# Defaults to false
+reserved-only = {{ .Network.ReservedOnly }}
+
 # Interval to perform peer discovery in duration
 # Format: "10s", "1m", "1h"
 discovery-interval = "{{ .Network.DiscoveryInterval }}"
@@ -217,6 +222,26 @@ func WriteConfigFile(basePath string, config *Config) error {
 # Multiaddress to listen on
 listen-addr = "{{ .Network.ListenAddress }}"
 
+# Comma separated list of additional multiaddresses to listen on
+# eg. "/ip4/0.0.0.0/tcp/7002/ws, /ip4/0.0.0.0/udp/7001/quic-v1"
+listen-addrs = "{{ StringsJoin .Network.ListenAddresses ", " }}"
+
+# Comma separated list of multiaddresses advertised to other peers,
+# overrides the listen addresses with the public IP or DNS
+# eg. "/dns/example.com/tcp/443/wss" behind a TLS proxy
+public-addrs = "{{ StringsJoin .Network.PublicAddresses ", " }}"
+
+# Comma separated list of transports to listen on, the peers
+# are dialed with any of them
+# One or more of: tcp, quic, ws
+# Defaults to "tcp"
+transports = "{{ StringsJoin .Network.Transports ", " }}"
+
+# Comma separated list of outbound rate limits formatted as protocol=bytes per second
+# The protocol is one of: sync, light, warp, block-announces, transactions, grandpa
+# eg. "sync=1048576, transactions=65536"
+outbound-rate-limits = "{{ StringsJoin .Network.OutboundRateLimits ", " }}"
+
 #######################################################
 ###             Core Configuration Options          ###
 #######################################################
@@ -242,6 +267,13 @@ func WriteConfigFile(basePath string, config *Config) error {
 # Grandpa interval
 grandpa-interval = "{{ .Core.GrandpaInterval }}"
 
+# Comma separated list of bad block hashes, the bad blocks and their descendants are not imported
+bad-blocks = "{{ StringsJoin .Core.BadBlocks ", " }}"
+
+# Comma separated list of fork blocks formatted as number:hash,
+# only the given block can be imported at the given number
+fork-blocks = "{{ StringsJoin .Core.ForkBlocks ", " }}"
+
 #######################################################
 ###            State Configuration Options          ###
 #######################################################
@@ -250,6 +282,11 @@ func WriteConfigFile(basePath string, config *Config) error {
 # Defaults to 0
 rewind = {{ .State.Rewind }}
 
+# Storage backend of the state tries
+# One of: inmemory, triedb
+# Defaults to "inmemory"
+backend = "{{ .State.Backend }}"
+
 #######################################################
 ###              RPC Configuration Options          ###
 #######################################################
@@ -0,0 +1,277 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	cfg "github.com/ChainSafe/gossamer/config"
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/sync"
+	"github.com/ChainSafe/gossamer/dot/telemetry"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/blockarchive"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/grandpa"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+)
+
+// importBatchSize is the number of blocks read from the archive before being imported
+const importBatchSize = 512
+
+// ExportBlocks writes the blocks of the best chain from the block number from to the block
+// number to, both included, from the database with the given base path to the archive file out.
+// If to is 0, the blocks are exported up to the best block. It returns the number of blocks exported.
+func ExportBlocks(basepath string, from, to uint, out string, compress bool) (exported uint, err error) {
+	db, err := database.LoadDatabase(basepath, false)
+	if err != nil {
+		return 0, fmt.Errorf("loading database: %w", err)
+	}
+	defer func() {
+		closeErr := db.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("closing database: %w", closeErr)
+		}
+	}()
+
+	tries := state.NewTries()
+	tries.SetEmptyTrie()
+
+	// NewBlockState on export does not use telemetry
+	blockState, err := state.NewBlockState(db, tries, nil)
+	if err != nil {
+		return 0, fmt.Errorf("creating block state: %w", err)
+	}
+
+	best, err := blockState.BestBlockNumber()
+	if err != nil {
+		return 0, fmt.Errorf("getting best block number: %w", err)
+	}
+	if to == 0 || to > best {
+		to = best
+	}
+	if from > to {
+		return 0, fmt.Errorf("%w: from #%d to #%d with best block #%d", ErrInvalidBlockRange, from, to, best)
+	}
+
+	file, err := os.Create(filepath.Clean(out))
+	if err != nil {
+		return 0, fmt.Errorf("creating archive file: %w", err)
+	}
+	defer func() {
+		closeErr := file.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("closing archive file: %w", closeErr)
+		}
+	}()
+
+	writer, err := blockarchive.NewWriter(file, blockState.GenesisHash(), compress)
+	if err != nil {
+		return 0, err
+	}
+
+	for number := from; number <= to; number++ {
+		bd, err := getBlockData(blockState, number)
+		if err != nil {
+			return exported, err
+		}
+
+		err = writer.Write(bd)
+		if err != nil {
+			return exported, err
+		}
+		exported++
+
+		if exported%10000 == 0 {
+			logger.Infof("exported %d blocks, up to block #%d", exported, number)
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return exported, fmt.Errorf("closing archive writer: %w", err)
+	}
+
+	return exported, nil
+}
+
+// getBlockData returns the header, the body and the justification of the block
+// of the best chain with the given number.
+func getBlockData(blockState *state.BlockState, number uint) (*types.BlockData, error) {
+	hash, err := blockState.GetHashByNumber(number)
+	if err != nil {
+		return nil, fmt.Errorf("getting hash of block #%d: %w", number, err)
+	}
+
+	header, err := blockState.GetHeader(hash)
+	if err != nil {
+		return nil, fmt.Errorf("getting header of block #%d: %w", number, err)
+	}
+
+	body, err := blockState.GetBlockBody(hash)
+	if err != nil {
+		return nil, fmt.Errorf("getting body of block #%d: %w", number, err)
+	}
+
+	bd := &types.BlockData{
+		Hash:   hash,
+		Header: header,
+		Body:   body,
+	}
+
+	justification, err := blockState.GetJustification(hash)
+	if err == nil {
+		bd.Justification = &justification
+	} else if !errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("getting justification of block #%d: %w", number, err)
+	}
+
+	return bd, nil
+}
+
+// ImportBlocks imports the blocks of the archive file into the node with the given configuration.
+// The blocks are verified and executed the way they are during the initial sync, unless skipVerify
+// is set, in which case the headers and bodies of the trusted blocks are not verified. The blocks
+// already imported are skipped, so an interrupted import can be resumed with the same archive.
+// It returns the number of blocks imported.
+func ImportBlocks(config *cfg.Config, archivePath string, skipVerify bool) (imported uint, err error) {
+	file, err := os.Open(filepath.Clean(archivePath))
+	if err != nil {
+		return 0, fmt.Errorf("opening archive file: %w", err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	reader, err := blockarchive.NewReader(file)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	builder := nodeBuilder{}
+	stateSrvc, err := builder.createStateService(config)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create state service: %s", err)
+	}
+
+	// the blocks are not announced, there is no telemetry to send
+	stateSrvc.Telemetry = telemetry.NewNoopMailer()
+
+	err = startStateService(*config.State, stateSrvc)
+	if err != nil {
+		return 0, fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		stopErr := stateSrvc.Stop()
+		if err == nil && stopErr != nil {
+			err = fmt.Errorf("stopping state service: %w", stopErr)
+		}
+	}()
+
+	genesisHash := stateSrvc.Block.GenesisHash()
+	if reader.GenesisHash != genesisHash {
+		return 0, fmt.Errorf("%w: archive genesis hash %s, node genesis hash %s",
+			ErrArchiveGenesisMismatch, reader.GenesisHash, genesisHash)
+	}
+
+	ns, err := builder.createRuntimeStorage(stateSrvc)
+	if err != nil {
+		return 0, err
+	}
+
+	ks := keystore.NewGlobalKeystore()
+	err = builder.loadRuntime(config, ns, stateSrvc, ks, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	dh, err := builder.createDigestHandler(stateSrvc)
+	if err != nil {
+		return 0, err
+	}
+	err = dh.Start()
+	if err != nil {
+		return 0, fmt.Errorf("starting digest handler: %w", err)
+	}
+	defer dh.Stop() //nolint:errcheck
+
+	coreSrvc, err := builder.createCoreService(config, ks, stateSrvc, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create core service: %s", err)
+	}
+	err = coreSrvc.Start()
+	if err != nil {
+		return 0, fmt.Errorf("starting core service: %w", err)
+	}
+	defer coreSrvc.Stop() //nolint:errcheck
+
+	genesisData, err := stateSrvc.Base.LoadGenesisData()
+	if err != nil {
+		return 0, fmt.Errorf("cannot load genesis data: %w", err)
+	}
+
+	blockRules, err := createBlockRules(config, genesisData)
+	if err != nil {
+		return 0, err
+	}
+
+	syncCfg := &sync.FullSyncConfig{
+		BlockState:         stateSrvc.Block,
+		StorageState:       stateSrvc.Storage,
+		TransactionState:   stateSrvc.Transaction,
+		FinalityGadget:     grandpa.NewJustificationVerifier(stateSrvc.Grandpa),
+		BabeVerifier:       builder.createBlockVerifier(stateSrvc),
+		BlockImportHandler: coreSrvc,
+		Telemetry:          stateSrvc.Telemetry,
+		BlockRules:         blockRules,
+	}
+
+	batch := make([]*types.BlockData, 0, importBatchSize)
+	var skipped uint
+	for {
+		bd, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return imported, err
+		}
+
+		has, err := stateSrvc.Block.HasHeader(bd.Hash)
+		if err != nil {
+			return imported, fmt.Errorf("checking block #%d was imported: %w", bd.Header.Number, err)
+		}
+		if has {
+			skipped++
+			continue
+		}
+
+		batch = append(batch, bd)
+		if len(batch) < importBatchSize {
+			continue
+		}
+
+		count, err := sync.ImportBlocks(syncCfg, batch, skipVerify)
+		imported += uint(count)
+		if err != nil {
+			return imported, fmt.Errorf("importing blocks: %w", err)
+		}
+		logger.Infof("imported %d blocks, up to block #%d", imported, batch[len(batch)-1].Header.Number)
+		batch = batch[:0]
+	}
+
+	count, err := sync.ImportBlocks(syncCfg, batch, skipVerify)
+	imported += uint(count)
+	if err != nil {
+		return imported, fmt.Errorf("importing blocks: %w", err)
+	}
+
+	if skipped > 0 {
+		logger.Infof("skipped %d blocks already imported", skipped)
+	}
+
+	return imported, nil
+}
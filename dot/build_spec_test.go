@@ -10,6 +10,7 @@
 	"strings"
 	"testing"
 
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/genesis"
 	"github.com/ChainSafe/gossamer/lib/utils"
 	"github.com/stretchr/testify/assert"
@@ -83,7 +84,7 @@ func TestBuildSpec_ToJSON(t *testing.T) {
 					ProtocolID:         "protocol",
 					Genesis:            genesis.Fields{},
 					Properties:         map[string]interface{}{"key": "value"},
-					ForkBlocks:         []string{"1", "2"},
+					ForkBlocks:         []genesis.ForkBlock{{Number: 1, Hash: common.Hash{2}}},
 					BadBlocks:          []string{"3", "4"},
 					ConsensusEngine:    "babe",
 					CodeSubstitutes:    map[string]string{"key": "value"},
@@ -0,0 +1,38 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"fmt"
+
+	cfg "github.com/ChainSafe/gossamer/config"
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/telemetry"
+)
+
+// CheckDatabase checks the consistency of the database of the node with the given configuration,
+// from its finalised block down to the genesis block, and repairs the block number index if repair
+// is set. It returns the report of the inconsistencies found.
+func CheckDatabase(config *cfg.Config, repair bool) (report *state.CheckReport, err error) {
+	builder := nodeBuilder{}
+	stateSrvc, err := builder.createStateService(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state service: %s", err)
+	}
+
+	stateSrvc.Telemetry = telemetry.NewNoopMailer()
+
+	err = stateSrvc.Start()
+	if err != nil {
+		return nil, fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		stopErr := stateSrvc.Stop()
+		if err == nil && stopErr != nil {
+			err = fmt.Errorf("stopping state service: %w", stopErr)
+		}
+	}()
+
+	return stateSrvc.Check(repair)
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+//go:build integration
+
+package dot
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/telemetry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDatabase(t *testing.T) {
+	config := DefaultTestWestendDevConfig(t)
+	config.ChainSpec = NewTestGenesisRawFile(t, config)
+
+	err := InitNode(config)
+	require.NoError(t, err)
+
+	stateSrvc, err := nodeBuilder{}.createStateService(config)
+	require.NoError(t, err)
+	stateSrvc.Telemetry = telemetry.NewNoopMailer()
+	err = startStateService(*config.State, stateSrvc)
+	require.NoError(t, err)
+
+	chain, _ := state.AddBlocksToState(t, stateSrvc.Block, 6, false)
+	err = stateSrvc.Block.SetFinalisedHash(chain[3].Hash(), 1, 0)
+	require.NoError(t, err)
+	err = stateSrvc.Stop()
+	require.NoError(t, err)
+
+	report, err := CheckDatabase(config, false)
+	require.NoError(t, err)
+	require.Equal(t, uint(4), report.FinalisedNumber)
+	require.Equal(t, chain[3].Hash(), report.FinalisedHash)
+	require.Equal(t, uint(5), report.BlocksChecked)
+	require.Empty(t, report.Issues)
+	require.True(t, report.OK())
+}
@@ -14,3 +14,9 @@
 var ErrInvalidKeystoreType = errors.New("invalid keystore type")
 
 var ErrWasmInterpreterName = errors.New("unknown wasm interpreter name")
+
+// ErrInvalidBlockRange is returned when the range of blocks to export is not part of the best chain
+var ErrInvalidBlockRange = errors.New("invalid block range")
+
+// ErrArchiveGenesisMismatch is returned when importing blocks of another chain
+var ErrArchiveGenesisMismatch = errors.New("block archive genesis hash does not match")
@@ -0,0 +1,29 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/internal/database"
+)
+
+// MigrateDatabase runs the migrations of the database schema pending for the database with the
+// given base path, or only lists them if dryRun is set. It returns the schema version of the
+// database before the migration and the migrations pending.
+func MigrateDatabase(basepath string, dryRun bool) (version uint32, pending []state.Migration, err error) {
+	db, err := database.LoadDatabase(basepath, false)
+	if err != nil {
+		return 0, nil, fmt.Errorf("loading database: %w", err)
+	}
+	defer func() {
+		closeErr := db.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("closing database: %w", closeErr)
+		}
+	}()
+
+	return state.Migrate(db, dryRun)
+}
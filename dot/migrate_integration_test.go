@@ -0,0 +1,55 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+//go:build integration
+
+package dot
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateDatabase(t *testing.T) {
+	config := DefaultTestWestendDevConfig(t)
+	config.ChainSpec = NewTestGenesisRawFile(t, config)
+
+	err := InitNode(config)
+	require.NoError(t, err)
+
+	// a new database has the latest schema
+	version, pending, err := MigrateDatabase(config.BasePath, false)
+	require.NoError(t, err)
+	require.Equal(t, state.SchemaVersion, version)
+	require.Empty(t, pending)
+
+	db, err := database.LoadDatabase(config.BasePath, false)
+	require.NoError(t, err)
+	err = db.Del(common.SchemaVersionKey)
+	require.NoError(t, err)
+	err = db.Close()
+	require.NoError(t, err)
+
+	// the migrations are listed without being run on a dry run
+	version, pending, err = MigrateDatabase(config.BasePath, true)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), version)
+	require.Len(t, pending, int(state.SchemaVersion))
+
+	version, pending, err = MigrateDatabase(config.BasePath, true)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), version)
+	require.Len(t, pending, int(state.SchemaVersion))
+
+	_, _, err = MigrateDatabase(config.BasePath, false)
+	require.NoError(t, err)
+
+	version, pending, err = MigrateDatabase(config.BasePath, true)
+	require.NoError(t, err)
+	require.Equal(t, state.SchemaVersion, version)
+	require.Empty(t, pending)
+}
@@ -0,0 +1,344 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/libp2p/go-flow-metrics"
+	"github.com/libp2p/go-libp2p/core/metrics"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+)
+
+// the names the traffic of the protocols is accounted under
+const (
+	syncProtocolName          = "sync"
+	lightProtocolName         = "light"
+	warpProtocolName          = "warp"
+	blockAnnounceProtocolName = "block-announces"
+	transactionsProtocolName  = "transactions"
+	grandpaProtocolName       = "grandpa"
+	otherProtocolName         = "other"
+)
+
+const (
+	// grandpaProtocolIdentifier is part of the GRANDPA protocol ID, registered by the grandpa package
+	grandpaProtocolIdentifier = "/grandpa/"
+
+	inboundDirection  = "in"
+	outboundDirection = "out"
+
+	// bandwidthIdleTimeout is the time after which the traffic of a peer or a protocol
+	// without any message is forgotten
+	bandwidthIdleTimeout = 10 * time.Minute
+	// bandwidthTrimInterval is the interval between two trims of the idle traffic
+	bandwidthTrimInterval = time.Minute
+)
+
+var protocolNames = []string{
+	syncProtocolName,
+	lightProtocolName,
+	warpProtocolName,
+	blockAnnounceProtocolName,
+	transactionsProtocolName,
+	grandpaProtocolName,
+	otherProtocolName,
+}
+
+var (
+	errInvalidOutboundRateLimit = errors.New("invalid outbound rate limit")
+
+	protocolBytesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gossamer_network_bandwidth",
+		Name:      "bytes_total",
+		Help:      "total number of bytes sent and received per protocol",
+	}, []string{"protocol", "direction"})
+	protocolMessagesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gossamer_network_bandwidth",
+		Name:      "messages_total",
+		Help:      "total number of messages sent and received per protocol",
+	}, []string{"protocol", "direction"})
+	protocolStreamsGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gossamer_network_bandwidth",
+		Name:      "streams",
+		Help:      "number of open inbound and outbound streams per protocol",
+	}, []string{"protocol", "direction"})
+)
+
+// protocolName returns the name the traffic of the protocol is accounted under
+func protocolName(id protocol.ID) string {
+	s := string(id)
+	switch {
+	case strings.HasSuffix(s, SyncID):
+		return syncProtocolName
+	case strings.HasSuffix(s, WarpSyncID):
+		return warpProtocolName
+	case strings.HasSuffix(s, lightID):
+		return lightProtocolName
+	case strings.HasSuffix(s, blockAnnounceID):
+		return blockAnnounceProtocolName
+	case strings.HasSuffix(s, transactionsID):
+		return transactionsProtocolName
+	case strings.Contains(s, grandpaProtocolIdentifier):
+		return grandpaProtocolName
+	default:
+		return otherProtocolName
+	}
+}
+
+type protocolMessages struct {
+	in  atomic.Uint64
+	out atomic.Uint64
+}
+
+// trafficMeters accounts the inbound and outbound traffic per key
+type trafficMeters struct {
+	in  flow.MeterRegistry
+	out flow.MeterRegistry
+}
+
+// stats returns the traffic of each key
+func (t *trafficMeters) stats() map[string]metrics.Stats {
+	stats := make(map[string]metrics.Stats)
+	t.in.ForEach(func(key string, meter *flow.Meter) {
+		snapshot := meter.Snapshot()
+		stat := stats[key]
+		stat.TotalIn = int64(snapshot.Total) //nolint:gosec
+		stat.RateIn = snapshot.Rate
+		stats[key] = stat
+	})
+	t.out.ForEach(func(key string, meter *flow.Meter) {
+		snapshot := meter.Snapshot()
+		stat := stats[key]
+		stat.TotalOut = int64(snapshot.Total) //nolint:gosec
+		stat.RateOut = snapshot.Rate
+		stats[key] = stat
+	})
+	return stats
+}
+
+// trimIdle removes the meters not updated since the given time. The meters are removed
+// one by one since flow.MeterRegistry.TrimIdle does not remove any meter.
+func (t *trafficMeters) trimIdle(since time.Time) {
+	for _, registry := range []*flow.MeterRegistry{&t.in, &t.out} {
+		registry.ForEach(func(key string, meter *flow.Meter) {
+			if meter.Snapshot().LastUpdate.Before(since) {
+				registry.Remove(key)
+			}
+		})
+	}
+}
+
+// bandwidthTracker accounts the traffic of the streams in total, per protocol and per peer,
+// and caps the outbound traffic of the protocols with an outbound rate limit.
+type bandwidthTracker struct {
+	// counter accounts the total traffic only
+	counter *metrics.BandwidthCounter
+
+	// byProtocol is keyed by protocol ID and byPeer by peer ID, the traffic of the
+	// idle protocols and peers is trimmed
+	byProtocol trafficMeters
+	byPeer     trafficMeters
+
+	// messages and outboundLimits are keyed by protocol name, they are not modified once created
+	messages       map[string]*protocolMessages
+	outboundLimits map[string]*rate.Limiter
+}
+
+// newBandwidthTracker creates a bandwidth tracker with the given outbound rate limits,
+// formatted as protocol=bytes per second.
+func newBandwidthTracker(outboundRateLimits []string) (*bandwidthTracker, error) {
+	outboundLimits, err := parseOutboundRateLimits(outboundRateLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(map[string]*protocolMessages, len(protocolNames))
+	for _, name := range protocolNames {
+		messages[name] = &protocolMessages{}
+	}
+
+	return &bandwidthTracker{
+		counter:        metrics.NewBandwidthCounter(),
+		messages:       messages,
+		outboundLimits: outboundLimits,
+	}, nil
+}
+
+func parseOutboundRateLimits(outboundRateLimits []string) (map[string]*rate.Limiter, error) {
+	limiters := make(map[string]*rate.Limiter, len(outboundRateLimits))
+	for _, limit := range outboundRateLimits {
+		name, value, ok := strings.Cut(limit, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == otherProtocolName || !slices.Contains(protocolNames, name) {
+			return nil, fmt.Errorf("%w: %q must be formatted as protocol=bytes with protocol one of %s",
+				errInvalidOutboundRateLimit, limit, strings.Join(protocolNames[:len(protocolNames)-1], ", "))
+		}
+
+		bytesPerSecond, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+		if err != nil || bytesPerSecond == 0 {
+			return nil, fmt.Errorf("%w: %q must have a positive number of bytes per second",
+				errInvalidOutboundRateLimit, limit)
+		}
+
+		limiters[name] = rate.NewLimiter(rate.Limit(bytesPerSecond), int(bytesPerSecond))
+	}
+	return limiters, nil
+}
+
+// waitOutbound blocks until the message of the given size can be sent
+// on the stream without exceeding the outbound rate limit of its protocol.
+func (b *bandwidthTracker) waitOutbound(ctx context.Context, stream libp2pnetwork.Stream, size int) error {
+	limiter, ok := b.outboundLimits[protocolName(stream.Protocol())]
+	if !ok {
+		return nil
+	}
+
+	// messages larger than the burst are sent once enough tokens were accumulated for each part
+	for size > 0 {
+		n := min(size, limiter.Burst())
+		err := limiter.WaitN(ctx, n)
+		if err != nil {
+			return fmt.Errorf("waiting for outbound rate limit: %w", err)
+		}
+		size -= n
+	}
+	return nil
+}
+
+// sent accounts the message of the given size sent on the stream
+func (b *bandwidthTracker) sent(stream libp2pnetwork.Stream, size int) {
+	name := protocolName(stream.Protocol())
+	b.counter.LogSentMessage(int64(size))
+	b.byProtocol.out.Get(string(stream.Protocol())).Mark(uint64(size))      //nolint:gosec
+	b.byPeer.out.Get(string(stream.Conn().RemotePeer())).Mark(uint64(size)) //nolint:gosec
+	b.messages[name].out.Add(1)
+
+	protocolBytesCounter.WithLabelValues(name, outboundDirection).Add(float64(size))
+	protocolMessagesCounter.WithLabelValues(name, outboundDirection).Inc()
+}
+
+// received accounts the message of the given size received on the stream
+func (b *bandwidthTracker) received(stream libp2pnetwork.Stream, size int) {
+	name := protocolName(stream.Protocol())
+	b.counter.LogRecvMessage(int64(size))
+	b.byProtocol.in.Get(string(stream.Protocol())).Mark(uint64(size))      //nolint:gosec
+	b.byPeer.in.Get(string(stream.Conn().RemotePeer())).Mark(uint64(size)) //nolint:gosec
+	b.messages[name].in.Add(1)
+
+	protocolBytesCounter.WithLabelValues(name, inboundDirection).Add(float64(size))
+	protocolMessagesCounter.WithLabelValues(name, inboundDirection).Inc()
+}
+
+// trimIdle forgets the traffic of the peers and protocols idle for bandwidthIdleTimeout
+func (b *bandwidthTracker) trimIdle(now time.Time) {
+	since := now.Add(-bandwidthIdleTimeout)
+	b.byProtocol.trimIdle(since)
+	b.byPeer.trimIdle(since)
+}
+
+// totals returns the traffic of all the streams
+func (b *bandwidthTracker) totals() metrics.Stats {
+	return b.counter.GetBandwidthTotals()
+}
+
+// streamCount is the number of open streams of a protocol
+type streamCount struct {
+	inbound  uint64
+	outbound uint64
+}
+
+// countStreams returns the number of open streams per protocol name
+func countStreams(conns []libp2pnetwork.Conn) map[string]streamCount {
+	counts := make(map[string]streamCount, len(protocolNames))
+	for _, conn := range conns {
+		for _, stream := range conn.GetStreams() {
+			name := protocolName(stream.Protocol())
+			count := counts[name]
+			if isInbound(stream) {
+				count.inbound++
+			} else {
+				count.outbound++
+			}
+			counts[name] = count
+		}
+	}
+	return counts
+}
+
+// updateStreamsMetrics sets the number of open streams of each protocol
+func updateStreamsMetrics(counts map[string]streamCount) {
+	for _, name := range protocolNames {
+		count := counts[name]
+		protocolStreamsGauge.WithLabelValues(name, inboundDirection).Set(float64(count.inbound))
+		protocolStreamsGauge.WithLabelValues(name, outboundDirection).Set(float64(count.outbound))
+	}
+}
+
+// snapshot returns the traffic in total, per protocol and per connected peer, with the number of
+// messages and open streams of each protocol. The peers are sorted by peer ID.
+func (b *bandwidthTracker) snapshot(streams map[string]streamCount, connected []peer.ID) common.NetworkBandwidth {
+	totals := b.counter.GetBandwidthTotals()
+	bandwidth := common.NetworkBandwidth{
+		TotalIn:   uint64(totals.TotalIn),  //nolint:gosec
+		TotalOut:  uint64(totals.TotalOut), //nolint:gosec
+		RateIn:    totals.RateIn,
+		RateOut:   totals.RateOut,
+		Protocols: make([]common.ProtocolBandwidth, len(protocolNames)),
+	}
+
+	byName := make(map[string]*common.ProtocolBandwidth, len(protocolNames))
+	for i, name := range protocolNames {
+		messages := b.messages[name]
+		bandwidth.Protocols[i] = common.ProtocolBandwidth{
+			Protocol:        name,
+			MessagesIn:      messages.in.Load(),
+			MessagesOut:     messages.out.Load(),
+			InboundStreams:  streams[name].inbound,
+			OutboundStreams: streams[name].outbound,
+		}
+		byName[name] = &bandwidth.Protocols[i]
+	}
+
+	for id, stats := range b.byProtocol.stats() {
+		protocolBandwidth := byName[protocolName(protocol.ID(id))]
+		protocolBandwidth.TotalIn += uint64(stats.TotalIn)   //nolint:gosec
+		protocolBandwidth.TotalOut += uint64(stats.TotalOut) //nolint:gosec
+		protocolBandwidth.RateIn += stats.RateIn
+		protocolBandwidth.RateOut += stats.RateOut
+	}
+
+	byPeer := b.byPeer.stats()
+	peers := slices.Clone(connected)
+	slices.Sort(peers)
+	bandwidth.Peers = make([]common.PeerBandwidth, 0, len(peers))
+	for _, who := range peers {
+		stats, ok := byPeer[string(who)]
+		if !ok {
+			continue
+		}
+		bandwidth.Peers = append(bandwidth.Peers, common.PeerBandwidth{
+			PeerID:   who.String(),
+			TotalIn:  uint64(stats.TotalIn),  //nolint:gosec
+			TotalOut: uint64(stats.TotalOut), //nolint:gosec
+			RateIn:   stats.RateIn,
+			RateOut:  stats.RateOut,
+		})
+	}
+
+	return bandwidth
+}
@@ -0,0 +1,174 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+type testConn struct {
+	libp2pnetwork.Conn
+	remotePeer peer.ID
+}
+
+func (c *testConn) RemotePeer() peer.ID {
+	return c.remotePeer
+}
+
+type testStream struct {
+	libp2pnetwork.Stream
+	protocolID protocol.ID
+	conn       *testConn
+}
+
+func (s *testStream) Protocol() protocol.ID {
+	return s.protocolID
+}
+
+func (s *testStream) Conn() libp2pnetwork.Conn {
+	return s.conn
+}
+
+type writtenStream struct {
+	*testStream
+	written []byte
+}
+
+func (s *writtenStream) Write(p []byte) (int, error) {
+	s.written = append(s.written, p...)
+	return len(p), nil
+}
+
+func TestHost_writeLimited(t *testing.T) {
+	t.Parallel()
+
+	tracker, err := newBandwidthTracker([]string{"warp=100"})
+	require.NoError(t, err)
+
+	// the cancelled context does not allow to wait for the rate limit
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h := &host{ctx: ctx, bandwidth: tracker}
+
+	warpStream := &writtenStream{testStream: &testStream{protocolID: "/0x91b1/sync/warp", conn: &testConn{}}}
+	err = h.writeLimited(warpStream, make([]byte, 250))
+	require.Error(t, err)
+	require.Empty(t, warpStream.written)
+
+	// the protocols without limit are not waited for
+	syncStream := &writtenStream{testStream: &testStream{protocolID: "/dot/sync/2", conn: &testConn{}}}
+	err = h.writeLimited(syncStream, []byte{1, 2})
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, syncStream.written)
+}
+
+func TestProtocolName(t *testing.T) {
+	t.Parallel()
+
+	cases := map[protocol.ID]string{
+		"/dot/sync/2":              syncProtocolName,
+		"/0x91b1/sync/warp":        warpProtocolName,
+		"/dot/light/2":             lightProtocolName,
+		"/dot/block-announces/1":   blockAnnounceProtocolName,
+		"/dot/transactions/1":      transactionsProtocolName,
+		"/0x91b1/grandpa/1":        grandpaProtocolName,
+		"/ipfs/kad/1.0.0":          otherProtocolName,
+		"/dot/sync/2/unrecognised": otherProtocolName,
+	}
+
+	for id, expected := range cases {
+		require.Equal(t, expected, protocolName(id), id)
+	}
+}
+
+func TestParseOutboundRateLimits(t *testing.T) {
+	t.Parallel()
+
+	limiters, err := parseOutboundRateLimits([]string{"sync=1024", " grandpa = 2048 "})
+	require.NoError(t, err)
+	require.Len(t, limiters, 2)
+	require.Equal(t, 1024, limiters[syncProtocolName].Burst())
+	require.Equal(t, 2048, limiters[grandpaProtocolName].Burst())
+
+	for _, limit := range []string{"sync", "other=1024", "unknown=1024", "sync=0", "sync=fast"} {
+		_, err := parseOutboundRateLimits([]string{limit})
+		require.ErrorIs(t, err, errInvalidOutboundRateLimit, limit)
+	}
+}
+
+func TestBandwidthTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker, err := newBandwidthTracker([]string{"transactions=100"})
+	require.NoError(t, err)
+
+	alice := &testConn{remotePeer: "alice"}
+	bob := &testConn{remotePeer: "bob"}
+	syncStream := &testStream{protocolID: "/dot/sync/2", conn: alice}
+	transactionsStream := &testStream{protocolID: "/dot/transactions/1", conn: bob}
+
+	tracker.sent(syncStream, 10)
+	tracker.received(syncStream, 1000)
+	tracker.sent(transactionsStream, 50)
+
+	// the message larger than the burst waits for the tokens of several seconds,
+	// which the cancelled context does not allow
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(t, tracker.waitOutbound(ctx, transactionsStream, 250))
+	require.NoError(t, tracker.waitOutbound(ctx, syncStream, 250))
+
+	// the libp2p meters account the traffic on their next sweep
+	var bandwidth common.NetworkBandwidth
+	require.Eventually(t, func() bool {
+		bandwidth = tracker.snapshot(map[string]streamCount{syncProtocolName: {inbound: 1, outbound: 2}},
+			[]peer.ID{"bob", "alice"})
+		return bandwidth.TotalIn == 1000 && bandwidth.TotalOut == 60 && len(bandwidth.Peers) == 2 &&
+			bandwidth.Protocols[0].TotalIn == 1000
+	}, 5*time.Second, 100*time.Millisecond)
+
+	require.Equal(t, uint64(1000), bandwidth.TotalIn)
+	require.Equal(t, uint64(60), bandwidth.TotalOut)
+	require.Len(t, bandwidth.Protocols, len(protocolNames))
+
+	require.Equal(t, common.ProtocolBandwidth{
+		Protocol:        syncProtocolName,
+		TotalIn:         1000,
+		TotalOut:        10,
+		RateIn:          bandwidth.Protocols[0].RateIn,
+		RateOut:         bandwidth.Protocols[0].RateOut,
+		MessagesIn:      1,
+		MessagesOut:     1,
+		InboundStreams:  1,
+		OutboundStreams: 2,
+	}, bandwidth.Protocols[0])
+	require.Equal(t, transactionsProtocolName, bandwidth.Protocols[4].Protocol)
+	require.Equal(t, uint64(50), bandwidth.Protocols[4].TotalOut)
+	require.Equal(t, uint64(1), bandwidth.Protocols[4].MessagesOut)
+
+	require.Len(t, bandwidth.Peers, 2)
+	require.Equal(t, peer.ID("alice").String(), bandwidth.Peers[0].PeerID)
+	require.Equal(t, uint64(1000), bandwidth.Peers[0].TotalIn)
+	require.Equal(t, uint64(50), bandwidth.Peers[1].TotalOut)
+
+	// only the connected peers are listed
+	bandwidth = tracker.snapshot(nil, []peer.ID{"bob"})
+	require.Len(t, bandwidth.Peers, 1)
+	require.Equal(t, peer.ID("bob").String(), bandwidth.Peers[0].PeerID)
+
+	// the traffic of the idle peers and protocols is forgotten, not the totals
+	tracker.trimIdle(time.Now().Add(bandwidthIdleTimeout + time.Minute))
+	require.Empty(t, tracker.byPeer.stats())
+	require.Empty(t, tracker.byProtocol.stats())
+	require.Empty(t, tracker.snapshot(nil, []peer.ID{"alice", "bob"}).Peers)
+	require.Equal(t, int64(1000), tracker.totals().TotalIn)
+}
@@ -171,6 +171,10 @@ func (s *Service) validateBlockAnnounceHandshake(from peer.ID, hs Handshake) err
 		return errors.New("genesis hash mismatch")
 	}
 
+	if bhs.Roles == common.AuthorityRole {
+		s.host.cm.TagPeer(from, validatorTag, validatorTagValue)
+	}
+
 	np, ok := s.notificationsProtocols[blockAnnounceMsgType]
 	if !ok {
 		// this should never happen.
@@ -201,3 +205,27 @@ func (s *Service) handleBlockAnnounceMessage(from peer.ID, msg NotificationsMess
 	shouldPropagate := err == nil
 	return shouldPropagate, err
 }
+
+// peerRole returns the role the peer sent in its block announce handshake, if any.
+func (s *Service) peerRole(p peer.ID) (common.NetworkRole, bool) {
+	s.notificationsMu.RLock()
+	np, ok := s.notificationsProtocols[blockAnnounceMsgType]
+	s.notificationsMu.RUnlock()
+	if !ok {
+		return 0, false
+	}
+
+	for _, data := range []*handshakeData{
+		np.peersData.getInboundHandshakeData(p),
+		np.peersData.getOutboundHandshakeData(p),
+	} {
+		if data == nil {
+			continue
+		}
+		hs, ok := data.handshake.(*BlockAnnounceHandshake)
+		if ok {
+			return hs.Roles, true
+		}
+	}
+	return 0, false
+}
@@ -12,6 +12,8 @@
 
 	"github.com/adrg/xdg"
 	"github.com/libp2p/go-libp2p/core/crypto"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peerstore"
 
 	"github.com/ChainSafe/gossamer/dot/network/ratelimiters"
 	"github.com/ChainSafe/gossamer/internal/log"
@@ -53,6 +55,9 @@
 // DefaultBootnodes the default value for Config.Bootnodes
 var DefaultBootnodes = []string(nil)
 
+// Libp2pHostFunc creates a libp2p host with the given identity, using the given peer store.
+type Libp2pHostFunc func(privateKey crypto.PrivKey, ps peerstore.Peerstore) (libp2phost.Host, error)
+
 // Config is used to configure a network service
 type Config struct {
 	LogLvl  log.Level
@@ -89,6 +94,18 @@ type Config struct {
 	NoMDNS bool
 	// ListenAddress is the multiaddress to listen on
 	ListenAddress string
+	// ListenAddresses are additional multiaddresses to listen on, for example
+	// /ip4/0.0.0.0/tcp/7001/ws or /ip4/0.0.0.0/udp/7000/quic-v1
+	ListenAddresses []string
+	// PublicAddresses are the multiaddresses advertised to other peers, instead
+	// of the listen addresses with the public IP or DNS
+	PublicAddresses []string
+	// Transports are the transports listened on, among tcp, quic and ws.
+	// The peers are dialed with any of them.
+	Transports []string
+	// OutboundRateLimits caps the outbound traffic of protocols, formatted as protocol=bytes per second
+	// with protocol among sync, light, warp, block-announces, transactions and grandpa
+	OutboundRateLimits []string
 
 	MinPeers int
 	MaxPeers int
@@ -97,10 +114,16 @@ type Config struct {
 
 	// PersistentPeers is a list of multiaddrs which the node should remain connected to
 	PersistentPeers []string
+	// ReservedOnly only connects to and accepts connections from reserved peers
+	ReservedOnly bool
 
 	// NodeKey is the private hex encoded Ed25519 key to build the p2p identity
 	NodeKey string
 
+	// Libp2pHost, if set, creates the libp2p host instead of a host listening on the
+	// configured addresses and transports, for example to run the node on a simulated network
+	Libp2pHost Libp2pHostFunc
+
 	// privateKey the private key for the network p2p identity
 	privateKey crypto.PrivKey
 
@@ -136,6 +159,10 @@ func (c *Config) build() error {
 		c.Roles = DefaultRoles
 	}
 
+	if len(c.Transports) == 0 {
+		c.Transports = DefaultTransports
+	}
+
 	// build identity configuration
 	err = c.buildIdentity()
 	if err != nil {
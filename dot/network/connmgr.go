@@ -5,17 +5,56 @@
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/connmgr"
+	"github.com/libp2p/go-libp2p/core/control"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	ma "github.com/multiformats/go-multiaddr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/ChainSafe/gossamer/dot/peerset"
 )
 
-// ConnManager implements connmgr.ConnManager
+const (
+	// reservedPeerTag is the tag given to reserved and persistent peers
+	reservedPeerTag      = "reserved"
+	reservedPeerTagValue = 100
+	// validatorTag is the tag given to peers announcing the authority role
+	validatorTag      = "validator"
+	validatorTagValue = 50
+	// syncWorkerTag is the tag given to peers while we are making requests to them
+	syncWorkerTag      = "sync-worker"
+	syncWorkerTagValue = 20
+
+	// trimGracePeriod is the time a new connection is kept before it can be trimmed,
+	// so that the peer has time to perform the handshakes and earn its tags
+	trimGracePeriod = 20 * time.Second
+	// trimInterval is the interval between two checks of the number of connected peers
+	trimInterval = 10 * time.Second
+	// minTrimInterval is the minimum interval between two trims
+	minTrimInterval = time.Second
+)
+
+var (
+	trimsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gossamer_network_connmgr",
+		Name:      "trims_total",
+		Help:      "total number of times the open connections were trimmed",
+	})
+	trimmedPeersCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gossamer_network_connmgr",
+		Name:      "trimmed_peers_total",
+		Help:      "total number of peers disconnected while trimming the open connections",
+	})
+)
+
+// ConnManager implements connmgr.ConnManager and connmgr.ConnectionGater
 type ConnManager struct {
 	sync.Mutex
 	host              *host
@@ -23,19 +62,30 @@ type ConnManager struct {
 	connectHandler    func(peer.ID)
 	disconnectHandler func(peer.ID)
 
+	// peerTags contains the tags of the connected peers, used to select
+	// the peers to disconnect when we are above the maximum number of peers.
+	peerTags map[peer.ID]*connmgr.TagInfo
+
 	// protectedPeers contains a list of peers that are protected from pruning
 	// when we reach the maximum numbers of peers.
-	protectedPeers *sync.Map // map[peer.ID]struct{}
+	protectedPeers *sync.Map // map[peer.ID]map[string]struct{}
 
 	// persistentPeers contains peers we should remain connected to.
 	persistentPeers *sync.Map // map[peer.ID]struct{}
 
 	peerSetHandler PeerSetHandler
+
+	// peerBook persists the known peers, their reputation and the banned peers.
+	peerBook *peerBook
+
+	lastTrim  time.Time
+	trimCh    chan struct{}
+	closeCh   chan struct{}
+	closeOnce sync.Once
 }
 
 func newConnManager(max int, peerSetCfg *peerset.ConfigSet) (*ConnManager, error) {
-	// TODO: peerSetHandler never used from within connection manager and also referred outside through cm,
-	// so this should be refactored
+	// TODO: peerSetHandler is also referred outside through cm, so this should be refactored
 	psh, err := peerset.NewPeerSetHandler(peerSetCfg)
 	if err != nil {
 		return nil, err
@@ -43,9 +93,12 @@ func newConnManager(max int, peerSetCfg *peerset.ConfigSet) (*ConnManager, error
 
 	return &ConnManager{
 		maxPeers:        max,
+		peerTags:        make(map[peer.ID]*connmgr.TagInfo),
 		protectedPeers:  new(sync.Map),
 		persistentPeers: new(sync.Map),
 		peerSetHandler:  psh,
+		trimCh:          make(chan struct{}, 1),
+		closeCh:         make(chan struct{}),
 	}, nil
 }
 
@@ -61,47 +114,322 @@ func (cm *ConnManager) Notifee() network.Notifiee {
 	return nb
 }
 
-// TagPeer is unimplemented
-func (*ConnManager) TagPeer(peer.ID, string, int) {}
+// tagInfo returns the tag info of the given peer, creating it if needed when
+// the peer connects. The caller must hold the lock.
+func (cm *ConnManager) tagInfo(p peer.ID) *connmgr.TagInfo {
+	info, ok := cm.peerTags[p]
+	if !ok {
+		info = &connmgr.TagInfo{
+			FirstSeen: time.Now(),
+			Tags:      make(map[string]int),
+			Conns:     make(map[string]time.Time),
+		}
+		cm.peerTags[p] = info
+	}
+	return info
+}
 
-// UntagPeer is unimplemented
-func (*ConnManager) UntagPeer(peer.ID, string) {}
+// TagPeer tags a peer with a string, associating a weight with the tag.
+// Only connected peers are tagged, their tags being dropped once disconnected.
+func (cm *ConnManager) TagPeer(p peer.ID, tag string, val int) {
+	cm.Lock()
+	defer cm.Unlock()
 
-// UpsertTag is unimplemented
-func (*ConnManager) UpsertTag(peer.ID, string, func(int) int) {}
+	info, ok := cm.peerTags[p]
+	if !ok {
+		return
+	}
 
-// GetTagInfo is unimplemented
-func (*ConnManager) GetTagInfo(peer.ID) *connmgr.TagInfo { return &connmgr.TagInfo{} }
+	info.Value += val - info.Tags[tag]
+	info.Tags[tag] = val
+}
+
+// UntagPeer removes the tagged value from the peer.
+func (cm *ConnManager) UntagPeer(p peer.ID, tag string) {
+	cm.Lock()
+	defer cm.Unlock()
 
-// TrimOpenConns is unimplemented
-func (*ConnManager) TrimOpenConns(context.Context) {}
+	info, ok := cm.peerTags[p]
+	if !ok {
+		return
+	}
+
+	info.Value -= info.Tags[tag]
+	delete(info.Tags, tag)
+}
+
+// UpsertTag updates an existing tag or inserts a new one, for connected peers only.
+func (cm *ConnManager) UpsertTag(p peer.ID, tag string, upsert func(int) int) {
+	cm.Lock()
+	defer cm.Unlock()
+
+	info, ok := cm.peerTags[p]
+	if !ok {
+		return
+	}
+
+	oldValue := info.Tags[tag]
+	newValue := upsert(oldValue)
+	info.Value += newValue - oldValue
+	info.Tags[tag] = newValue
+}
+
+// GetTagInfo returns a copy of the metadata associated with the peer,
+// or nil if no metadata has been recorded for the peer.
+func (cm *ConnManager) GetTagInfo(p peer.ID) *connmgr.TagInfo {
+	cm.Lock()
+	defer cm.Unlock()
+
+	info, ok := cm.peerTags[p]
+	if !ok {
+		return nil
+	}
+
+	tagInfo := &connmgr.TagInfo{
+		FirstSeen: info.FirstSeen,
+		Value:     info.Value,
+		Tags:      make(map[string]int, len(info.Tags)),
+		Conns:     make(map[string]time.Time, len(info.Conns)),
+	}
+	for tag, value := range info.Tags {
+		tagInfo.Tags[tag] = value
+	}
+	for conn, connectedAt := range info.Conns {
+		tagInfo.Conns[conn] = connectedAt
+	}
+	return tagInfo
+}
+
+// TrimOpenConns disconnects the lowest valued peers until we are back to the
+// maximum number of peers. Protected, persistent and reserved peers as well as
+// peers connected during the grace period are never disconnected.
+func (cm *ConnManager) TrimOpenConns(ctx context.Context) {
+	if cm.host == nil || ctx.Err() != nil {
+		return
+	}
+
+	connected := cm.host.peers()
+	if len(connected) <= cm.maxPeers {
+		return
+	}
+
+	cm.Lock()
+	if time.Since(cm.lastTrim) < minTrimInterval {
+		cm.Unlock()
+		return
+	}
+
+	type candidate struct {
+		id        peer.ID
+		value     int
+		firstSeen time.Time
+	}
+
+	candidates := make([]candidate, 0, len(connected))
+	for _, p := range connected {
+		info, ok := cm.peerTags[p]
+		if !ok || time.Since(info.FirstSeen) < trimGracePeriod {
+			continue
+		}
+
+		candidates = append(candidates, candidate{id: p, value: info.Value, firstSeen: info.FirstSeen})
+	}
+	cm.Unlock()
+
+	// lowest valued and then most recently connected peers are trimmed first
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].value == candidates[j].value {
+			return candidates[i].firstSeen.After(candidates[j].firstSeen)
+		}
+		return candidates[i].value < candidates[j].value
+	})
+
+	const setID = 0
+	inPeerSet := make(map[peer.ID]struct{})
+	for _, p := range <-cm.peerSetHandler.SortedPeers(setID) {
+		inPeerSet[p] = struct{}{}
+	}
+
+	toTrim := len(connected) - cm.maxPeers
+	trimmed := 0
+	for _, c := range candidates {
+		if trimmed == toTrim {
+			break
+		}
+
+		if cm.isKept(c.id) {
+			continue
+		}
+
+		logger.Debugf("trimming connection with peer %s (value %d)", c.id, c.value)
+		trimmed++
+
+		// peers known by the peerSet are removed from it, so that the slot is
+		// released and the peer is not dialled again right away.
+		if _, ok := inPeerSet[c.id]; ok {
+			cm.peerSetHandler.RemovePeer(setID, c.id)
+			continue
+		}
+
+		err := cm.host.closePeer(c.id)
+		if err != nil {
+			logger.Warnf("failed to close connection with peer %s: %s", c.id, err)
+		}
+	}
+
+	if trimmed > 0 {
+		cm.Lock()
+		cm.lastTrim = time.Now()
+		cm.Unlock()
+
+		trimsCounter.Inc()
+		trimmedPeersCounter.Add(float64(trimmed))
+	}
+}
 
-// CheckLimit is unimplemented
-func (*ConnManager) CheckLimit(connmgr.GetConnLimiter) error {
+// isKept returns true if the peer must never be trimmed.
+func (cm *ConnManager) isKept(p peer.ID) bool {
+	if cm.IsProtected(p, "") {
+		return true
+	}
+
+	if _, ok := cm.persistentPeers.Load(p); ok {
+		return true
+	}
+
+	return cm.peerSetHandler.IsReserved(p)
+}
+
+// trimLoop periodically trims the open connections, and as soon as requested
+// by a new connection going above the maximum number of peers.
+func (cm *ConnManager) trimLoop(ctx context.Context) {
+	ticker := time.NewTicker(trimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-cm.closeCh:
+			return
+		case <-ticker.C:
+		case <-cm.trimCh:
+		}
+
+		cm.TrimOpenConns(ctx)
+	}
+}
+
+// CheckLimit checks that the resource manager allows at least the maximum number of peers.
+func (cm *ConnManager) CheckLimit(l connmgr.GetConnLimiter) error {
+	if cm.maxPeers > l.GetConnLimit() {
+		return fmt.Errorf("max peers %d exceeds the system connection limit of %d",
+			cm.maxPeers, l.GetConnLimit())
+	}
 	return nil
 }
 
-// Protect peer will add the given peer to the protectedPeerMap which will
-// protect the peer from pruning.
-func (cm *ConnManager) Protect(id peer.ID, _ string) {
-	cm.protectedPeers.Store(id, struct{}{})
+// Protect peer will protect the given peer from pruning under the given tag.
+func (cm *ConnManager) Protect(id peer.ID, tag string) {
+	cm.Lock()
+	defer cm.Unlock()
+
+	tags, ok := cm.protectedPeers.Load(id)
+	if !ok {
+		tags = make(map[string]struct{}, 1)
+		cm.protectedPeers.Store(id, tags)
+	}
+	tags.(map[string]struct{})[tag] = struct{}{}
 }
 
-// Unprotect peer will remove the given peer from prune protection.
-// returns true if we have successfully removed the peer from the
-// protectedPeerMap. False otherwise.
-func (cm *ConnManager) Unprotect(id peer.ID, _ string) bool {
-	_, wasDeleted := cm.protectedPeers.LoadAndDelete(id)
-	return wasDeleted
+// Unprotect removes the protection of the given peer under the given tag.
+// It returns true if the peer is still protected under a different tag.
+func (cm *ConnManager) Unprotect(id peer.ID, tag string) (protected bool) {
+	cm.Lock()
+	defer cm.Unlock()
+
+	tags, ok := cm.protectedPeers.Load(id)
+	if !ok {
+		return false
+	}
+
+	protectedTags := tags.(map[string]struct{})
+	delete(protectedTags, tag)
+	if len(protectedTags) == 0 {
+		cm.protectedPeers.Delete(id)
+		return false
+	}
+	return true
 }
 
-// Close is unimplemented
-func (*ConnManager) Close() error { return nil }
+// Close stops the trimming of open connections.
+func (cm *ConnManager) Close() error {
+	cm.closeOnce.Do(func() {
+		close(cm.closeCh)
+	})
+	return nil
+}
 
 // IsProtected returns whether the given peer is protected from pruning or not.
-func (cm *ConnManager) IsProtected(id peer.ID, _ string) (protected bool) {
-	_, ok := cm.protectedPeers.Load(id)
-	return ok
+// If tag is empty, returns whether the peer is protected under any tag.
+func (cm *ConnManager) IsProtected(id peer.ID, tag string) (protected bool) {
+	tags, ok := cm.protectedPeers.Load(id)
+	if !ok {
+		return false
+	}
+
+	if tag == "" {
+		return true
+	}
+
+	cm.Lock()
+	defer cm.Unlock()
+	_, protected = tags.(map[string]struct{})[tag]
+	return protected
+}
+
+// InterceptPeerDial is called before dialling a peer. In reserved-only mode
+// only reserved and persistent peers are dialled.
+func (cm *ConnManager) InterceptPeerDial(p peer.ID) (allow bool) {
+	return cm.isAllowed(p)
+}
+
+// InterceptAddrDial allows dialling any address of an allowed peer.
+func (*ConnManager) InterceptAddrDial(peer.ID, ma.Multiaddr) (allow bool) {
+	return true
+}
+
+// InterceptAccept allows any inbound connection, since the remote peer is not known yet.
+func (*ConnManager) InterceptAccept(network.ConnMultiaddrs) (allow bool) {
+	return true
+}
+
+// InterceptSecured is called once the remote peer is authenticated. In reserved-only
+// mode connections with peers which are not reserved or persistent are refused.
+func (cm *ConnManager) InterceptSecured(_ network.Direction, p peer.ID, _ network.ConnMultiaddrs) (allow bool) {
+	return cm.isAllowed(p)
+}
+
+// InterceptUpgraded allows any fully upgraded connection.
+func (*ConnManager) InterceptUpgraded(network.Conn) (allow bool, reason control.DisconnectReason) {
+	return true, 0
+}
+
+// isAllowed returns whether a connection with the given peer is allowed
+func (cm *ConnManager) isAllowed(p peer.ID) bool {
+	if cm.peerBook != nil && cm.peerBook.isBanned(p) {
+		return false
+	}
+
+	if !cm.peerSetHandler.IsReservedOnly() {
+		return true
+	}
+
+	if _, ok := cm.persistentPeers.Load(p); ok {
+		return true
+	}
+
+	return cm.peerSetHandler.IsReserved(p)
 }
 
 // Listen is called when network starts listening on an address
@@ -121,17 +449,50 @@ func (cm *ConnManager) Connected(n network.Network, c network.Conn) {
 	logger.Tracef(
 		"Host %s connected to peer %s", n.LocalPeer(), c.RemotePeer())
 
+	p := c.RemotePeer()
+	cm.Lock()
+	info := cm.tagInfo(p)
+	firstConn := len(info.Conns) == 0
+	info.Conns[c.RemoteMultiaddr().String()] = time.Now()
+	cm.Unlock()
+
+	if firstConn && cm.peerBook != nil {
+		cm.peerBook.connected(p, n.Peerstore().Addrs(p))
+	}
+
+	_, isPersistent := cm.persistentPeers.Load(p)
+	if isPersistent || cm.peerSetHandler.IsReserved(p) {
+		cm.TagPeer(p, reservedPeerTag, reservedPeerTagValue)
+	}
+
+	if len(n.Peers()) > cm.maxPeers {
+		select {
+		case cm.trimCh <- struct{}{}:
+		default:
+		}
+	}
+
 	if cm.connectHandler != nil {
-		cm.connectHandler(c.RemotePeer())
+		cm.connectHandler(p)
 	}
 }
 
 // Disconnected is called when a connection closed
-func (cm *ConnManager) Disconnected(_ network.Network, c network.Conn) {
+func (cm *ConnManager) Disconnected(n network.Network, c network.Conn) {
 	logger.Tracef("Host %s disconnected from peer %s", c.LocalPeer(), c.RemotePeer())
 
-	cm.Unprotect(c.RemotePeer(), "")
+	p := c.RemotePeer()
+	cm.Lock()
+	if info, ok := cm.peerTags[p]; ok {
+		delete(info.Conns, c.RemoteMultiaddr().String())
+	}
+	if n.Connectedness(p) != network.Connected {
+		delete(cm.peerTags, p)
+		cm.protectedPeers.Delete(p)
+	}
+	cm.Unlock()
+
 	if cm.disconnectHandler != nil {
-		cm.disconnectHandler(c.RemotePeer())
+		cm.disconnectHandler(p)
 	}
 }
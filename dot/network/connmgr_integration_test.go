@@ -127,6 +127,118 @@ func TestProtectUnprotectPeer(t *testing.T) {
 	require.Equal(t, unprot, []peer.ID{p1, p2, p3, p4})
 }
 
+func TestTagUntagPeer(t *testing.T) {
+	t.Parallel()
+
+	peerCfgSet := peerset.NewConfigSet(1, 2, false, time.Second*2)
+	cm, err := newConnManager(2, peerCfgSet)
+	require.NoError(t, err)
+
+	p1 := peer.ID("a")
+	require.Nil(t, cm.GetTagInfo(p1))
+
+	// peers which are not connected are not tagged
+	cm.TagPeer(p1, validatorTag, validatorTagValue)
+	cm.UpsertTag(p1, syncWorkerTag, func(v int) int { return v + 1 })
+	require.Nil(t, cm.GetTagInfo(p1))
+
+	// the peer connects
+	cm.Lock()
+	cm.tagInfo(p1)
+	cm.Unlock()
+
+	cm.TagPeer(p1, validatorTag, validatorTagValue)
+	cm.TagPeer(p1, syncWorkerTag, syncWorkerTagValue)
+	cm.UpsertTag(p1, syncWorkerTag, func(v int) int { return v + 1 })
+
+	info := cm.GetTagInfo(p1)
+	require.Equal(t, validatorTagValue+syncWorkerTagValue+1, info.Value)
+	require.Equal(t, map[string]int{
+		validatorTag:  validatorTagValue,
+		syncWorkerTag: syncWorkerTagValue + 1,
+	}, info.Tags)
+
+	// the returned tag info is a copy
+	info.Tags[reservedPeerTag] = reservedPeerTagValue
+	require.Len(t, cm.GetTagInfo(p1).Tags, 2)
+
+	cm.UntagPeer(p1, validatorTag)
+	require.Equal(t, syncWorkerTagValue+1, cm.GetTagInfo(p1).Value)
+}
+
+func TestProtectUnprotectPeerWithTags(t *testing.T) {
+	t.Parallel()
+
+	peerCfgSet := peerset.NewConfigSet(1, 2, false, time.Second*2)
+	cm, err := newConnManager(2, peerCfgSet)
+	require.NoError(t, err)
+
+	p1 := peer.ID("a")
+	cm.Protect(p1, "tag1")
+	cm.Protect(p1, "tag2")
+
+	require.True(t, cm.IsProtected(p1, ""))
+	require.True(t, cm.IsProtected(p1, "tag1"))
+	require.False(t, cm.IsProtected(p1, "tag3"))
+
+	require.True(t, cm.Unprotect(p1, "tag1"))
+	require.False(t, cm.IsProtected(p1, "tag1"))
+	require.False(t, cm.Unprotect(p1, "tag2"))
+	require.False(t, cm.IsProtected(p1, ""))
+}
+
+func TestTrimOpenConns(t *testing.T) {
+	t.Parallel()
+
+	const max = 2
+
+	configA := &Config{
+		BasePath:    t.TempDir(),
+		Port:        availablePort(t),
+		NoBootstrap: true,
+		NoMDNS:      true,
+	}
+	nodeA := createTestService(t, configA)
+	nodeA.noGossip = true
+	// only lower the connection manager limit, so the peer set keeps accepting
+	// the inbound peers and trimming is what brings the count back down.
+	nodeA.host.cm.maxPeers = max
+
+	nodes := make([]*Service, max+1)
+	for i := range nodes {
+		config := &Config{
+			BasePath:    t.TempDir(),
+			Port:        availablePort(t),
+			NoBootstrap: true,
+			NoMDNS:      true,
+		}
+		nodes[i] = createTestService(t, config)
+
+		err := nodes[i].host.connect(addrInfo(nodeA.host))
+		require.NoError(t, err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, max+1, nodeA.host.peerCount())
+
+	cm := nodeA.host.cm
+	cm.TagPeer(nodes[0].host.id(), validatorTag, validatorTagValue)
+	cm.TagPeer(nodes[2].host.id(), syncWorkerTag, syncWorkerTagValue)
+
+	// leave the grace period
+	cm.Lock()
+	for _, info := range cm.peerTags {
+		info.FirstSeen = info.FirstSeen.Add(-trimGracePeriod)
+	}
+	cm.Unlock()
+
+	cm.TrimOpenConns(nodeA.ctx)
+	time.Sleep(200 * time.Millisecond)
+
+	require.Equal(t, max, nodeA.host.peerCount())
+	require.Empty(t, nodeA.host.p2pHost.Network().ConnsToPeer(nodes[1].host.id()))
+}
+
 func TestPersistentPeers(t *testing.T) {
 	t.Parallel()
 
@@ -233,7 +345,130 @@ func TestSetReservedPeer(t *testing.T) {
 	node3.host.cm.peerSetHandler.(*peerset.Handler).SetReservedPeer(0, addrC.ID)
 	time.Sleep(200 * time.Millisecond)
 
-	// reservedOnly mode is not yet implemented, so nodeA and nodeB won't be disconnected (#1888).
-	// TODO: once reservedOnly mode is implemented and reservedOnly is set to true, change expected value to 1 (nodeC)
+	// nodeA and nodeB are no longer reserved but are kept since reserved-only mode is disabled.
 	require.Equal(t, 3, node3.host.peerCount())
 }
+
+func TestReservedOnly(t *testing.T) {
+	t.Parallel()
+
+	nodes := make([]*Service, 2)
+	for i := range nodes {
+		config := &Config{
+			BasePath:    t.TempDir(),
+			Port:        availablePort(t),
+			NoBootstrap: true,
+			NoMDNS:      true,
+		}
+		node := createTestService(t, config)
+		nodes[i] = node
+	}
+
+	addrA := nodes[0].host.multiaddrs()[0]
+	addrB := nodes[1].host.multiaddrs()[0]
+
+	config := &Config{
+		BasePath:        t.TempDir(),
+		Port:            availablePort(t),
+		NoMDNS:          true,
+		ReservedOnly:    true,
+		PersistentPeers: []string{addrA.String()},
+		Bootnodes:       []string{addrB.String()},
+	}
+
+	node3 := createTestService(t, config)
+	node3.noGossip = true
+	time.Sleep(time.Millisecond * 600)
+
+	// the bootnode is not reserved, so it is not dialled
+	require.Equal(t, 1, node3.host.peerCount())
+	require.Equal(t, []string{nodes[0].host.id().String()}, node3.ReservedPeers())
+
+	// inbound connections from non-reserved peers are refused
+	_ = nodes[1].host.connect(addrInfo(node3.host))
+	time.Sleep(time.Millisecond * 200)
+	require.Equal(t, 1, node3.host.peerCount())
+
+	node3.SetReservedOnly(false)
+	time.Sleep(time.Millisecond * 200)
+
+	err := nodes[1].host.connect(addrInfo(node3.host))
+	require.NoError(t, err)
+	require.Equal(t, 2, node3.host.peerCount())
+
+	// switching back drops the non-reserved peer
+	node3.SetReservedOnly(true)
+	time.Sleep(time.Millisecond * 200)
+	require.Equal(t, 1, node3.host.peerCount())
+}
+
+func TestBanPeer(t *testing.T) {
+	t.Parallel()
+
+	basePath := t.TempDir()
+	configA := &Config{
+		BasePath:    basePath,
+		Port:        availablePort(t),
+		NoBootstrap: true,
+		NoMDNS:      true,
+	}
+	nodeA := createTestService(t, configA)
+	nodeA.noGossip = true
+
+	configB := &Config{
+		BasePath:    t.TempDir(),
+		Port:        availablePort(t),
+		NoBootstrap: true,
+		NoMDNS:      true,
+	}
+	nodeB := createTestService(t, configB)
+	nodeB.noGossip = true
+
+	err := nodeB.host.connect(addrInfo(nodeA.host))
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, 1, nodeA.host.peerCount())
+
+	idB := nodeB.host.id().String()
+	err = nodeA.BanPeer(idB, time.Hour)
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, 0, nodeA.host.peerCount())
+
+	// connections with the banned peer are refused
+	_ = nodeB.host.connect(addrInfo(nodeA.host))
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, 0, nodeA.host.peerCount())
+
+	// the ban survives a restart
+	err = nodeA.Stop()
+	require.NoError(t, err)
+
+	configA = &Config{
+		BasePath:    basePath,
+		Port:        availablePort(t),
+		NoBootstrap: true,
+		NoMDNS:      true,
+	}
+	nodeA = createTestService(t, configA)
+	nodeA.noGossip = true
+
+	knownPeers := nodeA.KnownPeers()
+	require.Len(t, knownPeers, 1)
+	require.Equal(t, idB, knownPeers[0].PeerID)
+	require.Equal(t, uint32(1), knownPeers[0].Successes)
+	require.False(t, knownPeers[0].BannedUntil.IsZero())
+
+	_ = nodeB.host.connect(addrInfo(nodeA.host))
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, 0, nodeA.host.peerCount())
+
+	err = nodeA.UnbanPeer(idB)
+	require.NoError(t, err)
+	require.ErrorIs(t, nodeA.UnbanPeer(idB), errPeerNotBanned)
+
+	err = nodeB.host.connect(addrInfo(nodeA.host))
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, 1, nodeA.host.peerCount())
+}
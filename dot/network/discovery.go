@@ -211,7 +211,11 @@ func (d *discovery) findPeers() {
 		select {
 		case <-timer.C:
 			return
-		case peer := <-peerCh:
+		case peer, ok := <-peerCh:
+			if !ok {
+				// the DHT query is over, receiving from the closed channel would spin until the timeout
+				return
+			}
 			if peer.ID == d.h.ID() || peer.ID == "" {
 				continue
 			}
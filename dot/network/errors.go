@@ -17,6 +17,13 @@
 	errHandshakeTimeout          = errors.New("handshake timeout reached")
 	errInboundHanshakeExists     = errors.New("an inbound handshake already exists for given peer")
 	errInvalidRole               = errors.New("invalid role")
+	errInvalidBanDuration        = errors.New("invalid ban duration")
+	errCannotBanReservedPeer     = errors.New("cannot ban a reserved peer")
+	errPeerNotBanned             = errors.New("peer is not banned")
+	errUnknownTransport          = errors.New("unknown transport")
+	errNoTransport               = errors.New("no transport enabled")
+	errUnsupportedListenAddress  = errors.New("unsupported listen address")
+	errTransportNotEnabled       = errors.New("transport not enabled")
 	ErrFailedToReadEntireMessage = errors.New("failed to read entire message")
 	ErrNilStream                 = errors.New("nil stream")
 	ErrInvalidLEB128EncodedData  = errors.New("invalid LEB128 encoded data")
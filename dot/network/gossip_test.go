@@ -0,0 +1,45 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_gossip_hasSeen(t *testing.T) {
+	t.Parallel()
+
+	g := newGossip()
+	msg := &BlockAnnounceMessage{
+		Number: 1,
+		Digest: types.NewDigest(),
+	}
+
+	// concurrent duplicates are only handled once
+	const receivers = 10
+	var unseen atomic.Int32
+	var wg sync.WaitGroup
+	for range receivers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seen, err := g.hasSeen(msg)
+			require.NoError(t, err)
+			if !seen {
+				unseen.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), unseen.Load())
+
+	seen, err := g.hasSeen(msg)
+	require.NoError(t, err)
+	require.True(t, seen)
+}
@@ -9,19 +9,15 @@
 	"log"
 	"net"
 	"path"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/ChainSafe/gossamer/dot/network/messages"
 	"github.com/ChainSafe/gossamer/dot/peerset"
-	"github.com/ChainSafe/gossamer/internal/pubip"
 	"github.com/dgraph-io/ristretto"
 	badger "github.com/ipfs/go-ds-badger4"
 	"github.com/libp2p/go-libp2p"
 	libp2phost "github.com/libp2p/go-libp2p/core/host"
-	"github.com/libp2p/go-libp2p/core/metrics"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/peerstore"
@@ -80,60 +76,31 @@ type host struct {
 	cm              *ConnManager
 	ds              *badger.Datastore
 	messageCache    *messageCache
-	bwc             *metrics.BandwidthCounter
+	bandwidth       *bandwidthTracker
 	closeSync       sync.Once
-	externalAddr    ma.Multiaddr
+	externalAddrs   []ma.Multiaddr
 }
 
 func newHost(ctx context.Context, cfg *Config) (*host, error) {
-	// create multiaddress (without p2p identity)
-	listenAddress := fmt.Sprintf("/ip4/0.0.0.0/tcp/%d", cfg.Port)
-	if cfg.ListenAddress != "" {
-		listenAddress = cfg.ListenAddress
-	}
-	addr, err := ma.NewMultiaddr(listenAddress)
-	if err != nil {
-		return nil, err
-	}
-
-	portString, err := addr.ValueForProtocol(ma.P_TCP)
-	if err != nil {
-		return nil, err
-	}
-
-	port, err := strconv.ParseUint(portString, 10, 64)
-	if err != nil {
-		return nil, err
-	}
-	var externalAddr ma.Multiaddr
-
-	switch {
-	case strings.TrimSpace(cfg.PublicIP) != "":
-		ip := net.ParseIP(cfg.PublicIP)
-		if ip == nil {
-			return nil, fmt.Errorf("invalid public ip: %s", cfg.PublicIP)
-		}
-		logger.Debugf("using config PublicIP: %s", ip)
-		externalAddr, err = ma.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", ip, port))
+	var (
+		listenAddrs, externalAddrs []ma.Multiaddr
+		err                        error
+	)
+	if cfg.Libp2pHost == nil {
+		// create listen multiaddresses (without p2p identity)
+		listenAddrs, err = listenAddresses(cfg)
 		if err != nil {
 			return nil, err
 		}
-	case strings.TrimSpace(cfg.PublicDNS) != "":
-		logger.Debugf("using config PublicDNS: %s", cfg.PublicDNS)
-		externalAddr, err = ma.NewMultiaddr(fmt.Sprintf("/dns/%s/tcp/%d", cfg.PublicDNS, port))
+
+		err = checkTransports(cfg.Transports)
 		if err != nil {
 			return nil, err
 		}
-	default:
-		ip, err := pubip.Get()
+
+		externalAddrs, err = externalAddresses(cfg, listenAddrs)
 		if err != nil {
-			logger.Errorf("failed to get public IP error: %v", err)
-		} else {
-			logger.Debugf("got public IP address %s", ip)
-			externalAddr, err = ma.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", ip, port))
-			if err != nil {
-				return nil, err
-			}
+			return nil, err
 		}
 	}
 
@@ -151,7 +118,6 @@ func newHost(ctx context.Context, cfg *Config) (*host, error) {
 
 	// We have tried to set maxInPeers and maxOutPeers such that number of peer
 	// connections remain between min peers and max peers
-	const reservedOnly = false
 	peerCfgSet := peerset.NewConfigSet(
 		//TODO: there is no any understanding of maxOutPeers and maxInPirs calculations.
 		// This needs to be explicitly mentioned
@@ -160,7 +126,7 @@ func newHost(ctx context.Context, cfg *Config) (*host, error) {
 		uint32(cfg.MaxPeers-cfg.MinPeers), //nolint:gosec
 		// maxOutPeers is later used in peerstate only and defines available Outgoing connection slots
 		uint32(cfg.MaxPeers/2), //nolint:gosec
-		reservedOnly,
+		cfg.ReservedOnly,
 		peerSetSlotAllocTime,
 	)
 
@@ -182,56 +148,31 @@ func newHost(ctx context.Context, cfg *Config) (*host, error) {
 		return nil, fmt.Errorf("failed to create libp2p datastore: %w", err)
 	}
 
+	cm.peerBook = newPeerBook(ds)
+	err = cm.peerBook.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load peer book: %w", err)
+	}
+
 	ps, err := mempstore.NewPeerstore()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create peerstore: %w", err)
 	}
 
-	limiter := rm.NewFixedLimiter(rm.DefaultLimits.AutoScale())
-	var managerOptions []rm.Option
-
-	if cfg.Metrics.Publish {
-		rm.MustRegisterWith(prometheus.DefaultRegisterer)
-		reporter, err := rm.NewStatsTraceReporter()
+	var h libp2phost.Host
+	if cfg.Libp2pHost != nil {
+		h, err = cfg.Libp2pHost(cfg.privateKey, ps)
 		if err != nil {
-			return nil, fmt.Errorf("while creating resource manager stats trace reporter: %w", err)
+			return nil, fmt.Errorf("creating libp2p host: %w", err)
 		}
 
-		managerOptions = append(managerOptions, rm.WithTraceReporter(reporter))
-	}
-
-	manager, err := rm.NewResourceManager(limiter, managerOptions...)
-	if err != nil {
-		return nil, fmt.Errorf("while creating the resource manager: %w", err)
-	}
-
-	// set libp2p host options
-	opts := []libp2p.Option{
-		libp2p.ResourceManager(manager),
-		libp2p.ListenAddrs(addr),
-		libp2p.DisableRelay(),
-		libp2p.Identity(cfg.privateKey),
-		libp2p.NATPortMap(),
-		libp2p.Peerstore(ps),
-		libp2p.ConnectionManager(cm),
-		libp2p.AddrsFactory(func(as []ma.Multiaddr) []ma.Multiaddr {
-			var addrs []ma.Multiaddr
-			for _, addr := range as {
-				if !privateIPs.AddrBlocked(addr) {
-					addrs = append(addrs, addr)
-				}
-			}
-			if externalAddr == nil {
-				return addrs
-			}
-			return append(addrs, externalAddr)
-		}),
-	}
-
-	// create libp2p host instance
-	h, err := libp2p.New(opts...)
-	if err != nil {
-		return nil, err
+		// the connection manager is not set as an option of the host, so it is notified of the connections here
+		h.Network().Notify(cm.Notifee())
+	} else {
+		h, err = newLibp2pHost(cfg, cm, ps, listenAddrs, externalAddrs)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	cacheSize := 64 << 20 // 64 MB
@@ -248,7 +189,11 @@ func newHost(ctx context.Context, cfg *Config) (*host, error) {
 		return nil, err
 	}
 
-	bwc := metrics.NewBandwidthCounter()
+	bandwidth, err := newBandwidthTracker(cfg.OutboundRateLimits)
+	if err != nil {
+		return nil, err
+	}
+
 	discovery := newDiscovery(ctx, h, bns, ds, pid, cfg.MaxPeers, cm.peerSetHandler)
 
 	host := &host{
@@ -261,14 +206,71 @@ func newHost(ctx context.Context, cfg *Config) (*host, error) {
 		ds:              ds,
 		persistentPeers: pps,
 		messageCache:    msgCache,
-		bwc:             bwc,
-		externalAddr:    externalAddr,
+		bandwidth:       bandwidth,
+		externalAddrs:   externalAddrs,
 	}
 
 	cm.host = host
 	return host, nil
 }
 
+// newLibp2pHost creates the libp2p host listening on the given addresses, and advertising the given
+// external addresses.
+func newLibp2pHost(cfg *Config, cm *ConnManager, ps peerstore.Peerstore,
+	listenAddrs, externalAddrs []ma.Multiaddr) (libp2phost.Host, error) {
+	// make sure the resource manager allows at least the maximum number of peers
+	limits := rm.DefaultLimits.AutoScale()
+	if systemConns := limits.ToPartialLimitConfig().System.Conns; int(systemConns) < cfg.MaxPeers {
+		partialLimits := rm.PartialLimitConfig{
+			System: rm.ResourceLimits{Conns: rm.LimitVal(cfg.MaxPeers)},
+		}
+		limits = partialLimits.Build(limits)
+	}
+	limiter := rm.NewFixedLimiter(limits)
+	var managerOptions []rm.Option
+
+	if cfg.Metrics.Publish {
+		rm.MustRegisterWith(prometheus.DefaultRegisterer)
+		reporter, err := rm.NewStatsTraceReporter()
+		if err != nil {
+			return nil, fmt.Errorf("while creating resource manager stats trace reporter: %w", err)
+		}
+
+		managerOptions = append(managerOptions, rm.WithTraceReporter(reporter))
+	}
+
+	manager, err := rm.NewResourceManager(limiter, managerOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("while creating the resource manager: %w", err)
+	}
+
+	// set libp2p host options, the default transports are kept to dial the peers
+	// listening on any of them
+	opts := []libp2p.Option{
+		libp2p.DefaultTransports,
+		libp2p.ResourceManager(manager),
+		libp2p.ListenAddrs(listenAddrs...),
+		libp2p.DisableRelay(),
+		libp2p.Identity(cfg.privateKey),
+		libp2p.NATPortMap(),
+		libp2p.Peerstore(ps),
+		libp2p.ConnectionManager(cm),
+		libp2p.ConnectionGater(cm),
+		libp2p.AddrsFactory(func(as []ma.Multiaddr) []ma.Multiaddr {
+			var addrs []ma.Multiaddr
+			for _, addr := range as {
+				if !privateIPs.AddrBlocked(addr) {
+					addrs = append(addrs, addr)
+				}
+			}
+			return append(addrs, externalAddrs...)
+		}),
+	}
+
+	// create libp2p host instance
+	return libp2p.New(opts...)
+}
+
 // close closes host services and the libp2p host (host services first)
 func (h *host) close() error {
 	// close DHT service
@@ -286,6 +288,9 @@ func (h *host) close() error {
 	}
 
 	h.closeSync.Do(func() {
+		h.refreshPeerBook()
+		h.cm.peerBook.close()
+
 		err = h.p2pHost.Peerstore().Close()
 		if err != nil {
 			logger.Errorf("Failed to close libp2p peerstore: %s", err)
@@ -315,6 +320,36 @@ func (h *host) connect(p peer.AddrInfo) (err error) {
 	return err
 }
 
+// refreshPeerBook updates the addresses and the reputations of the peers of the peer book.
+func (h *host) refreshPeerBook() {
+	h.cm.peerBook.update(h.p2pHost.Peerstore().Addrs, func(p peer.ID) (int32, bool) {
+		rep, err := h.cm.peerSetHandler.PeerReputation(p)
+		if err != nil {
+			return 0, false
+		}
+		return int32(rep), true
+	})
+}
+
+// loadKnownPeers adds the peers of the peer book to the peerSet, restoring
+// their last known reputation.
+func (h *host) loadKnownPeers() {
+	infos, reputations := h.cm.peerBook.knownPeers()
+	for i, info := range infos {
+		h.p2pHost.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.PermanentAddrTTL)
+		h.cm.peerSetHandler.AddPeer(0, info.ID)
+
+		if reputations[i] != 0 {
+			h.cm.peerSetHandler.ReportPeer(peerset.ReputationChange{
+				Value:  peerset.Reputation(reputations[i]),
+				Reason: peerset.RestoredReputationReason,
+			}, info.ID)
+		}
+	}
+
+	logger.Debugf("loaded %d known peers from the peer book", len(infos))
+}
+
 // bootstrap connects the host to the configured bootnodes
 func (h *host) bootstrap() {
 	for _, info := range h.persistentPeers {
@@ -365,16 +400,27 @@ func (h *host) writeToStream(s network.Stream, msg messages.P2PMessage) error {
 	lenBytes := Uint64ToLEB128(msgLen)
 	encMsg = append(lenBytes, encMsg...)
 
-	sent, err := s.Write(encMsg)
+	return h.writeLimited(s, encMsg)
+}
+
+// writeLimited writes the data to the stream once the outbound rate limit of its
+// protocol allows it, and accounts it. All the writes to the streams go through it.
+func (h *host) writeLimited(s network.Stream, data []byte) error {
+	err := h.bandwidth.waitOutbound(h.ctx, s, len(data))
+	if err != nil {
+		return err
+	}
+
+	sent, err := s.Write(data)
 	if err != nil {
 		return err
 	}
 
-	if len(encMsg) != sent {
-		logger.Errorf("full message not sent: sent %d, message size %d", sent, len(encMsg))
+	if len(data) != sent {
+		logger.Errorf("full message not sent: sent %d, message size %d", sent, len(data))
 	}
 
-	h.bwc.LogSentMessage(int64(sent))
+	h.bandwidth.sent(s, sent)
 
 	return nil
 }
@@ -403,6 +449,7 @@ func (h *host) addReservedPeers(addrs ...string) error {
 		}
 		h.p2pHost.Peerstore().AddAddrs(addrInfo.ID, addrInfo.Addrs, peerstore.PermanentAddrTTL)
 		h.cm.peerSetHandler.AddReservedPeer(0, addrInfo.ID)
+		h.cm.TagPeer(addrInfo.ID, reservedPeerTag, reservedPeerTagValue)
 	}
 
 	return nil
@@ -416,7 +463,7 @@ func (h *host) removeReservedPeers(ids ...string) error {
 			return err
 		}
 		h.cm.peerSetHandler.RemoveReservedPeer(0, peerID)
-		h.p2pHost.ConnManager().Unprotect(peerID, "")
+		h.cm.UntagPeer(peerID, reservedPeerTag)
 	}
 
 	return nil
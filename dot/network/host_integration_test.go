@@ -41,14 +41,6 @@ func TestExternalAddrs(t *testing.T) {
 	}
 }
 
-func mustNewMultiAddr(s string) (a ma.Multiaddr) {
-	a, err := ma.NewMultiaddr(s)
-	if err != nil {
-		panic(err)
-	}
-	return a
-}
-
 func TestExternalAddrsPublicIP(t *testing.T) {
 	t.Parallel()
 
@@ -49,7 +49,7 @@ func (s *Service) readStream(stream libp2pnetwork.Stream, decoder messageDecoder
 			return
 		}
 
-		s.host.bwc.LogRecvMessage(int64(n))
+		s.host.bandwidth.received(stream, n)
 	}
 }
 
@@ -438,6 +438,7 @@ func (s *Service) readHandshake(stream network.Stream, decoder HandshakeDecoder,
 			hsC <- &handshakeReader{hs: nil, err: err}
 			return
 		}
+		s.host.bandwidth.received(stream, tot)
 
 		msgBytes := *buffer
 		hs, err := decoder(msgBytes[:tot])
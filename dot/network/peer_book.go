@@ -0,0 +1,438 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	peerBookPrefix = "/gossamer/peerbook"
+
+	// peerBookExpiry is the time after which a peer that was not seen anymore
+	// is forgotten, unless it is still banned.
+	peerBookExpiry = 30 * 24 * time.Hour
+
+	// peerBookMaxRecords is the maximum number of peers kept in the peer book.
+	// The least recently seen peers which are not banned are forgotten first.
+	peerBookMaxRecords = 10_000
+
+	// peerBookFlushDelay is the time the changes of the peer book are gathered
+	// before being written to the datastore.
+	peerBookFlushDelay = 5 * time.Second
+
+	// peerBookRefreshInterval is the interval at which the addresses and the
+	// reputations of the known peers are refreshed.
+	peerBookRefreshInterval = time.Minute
+)
+
+// peerRecord is the persisted information about a known peer.
+type peerRecord struct {
+	Addrs       [][]byte
+	LastSeen    int64
+	Successes   uint32
+	Failures    uint32
+	Reputation  int32
+	BannedUntil int64
+}
+
+// addrs returns the decoded multiaddresses of the peer record, skipping the invalid ones.
+func (r *peerRecord) addrs() []ma.Multiaddr {
+	addrs := make([]ma.Multiaddr, 0, len(r.Addrs))
+	for _, b := range r.Addrs {
+		addr, err := ma.NewMultiaddrBytes(b)
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// isBanned returns true if the peer is banned at the given time.
+func (r *peerRecord) isBanned(now time.Time) bool {
+	return r.BannedUntil > now.Unix()
+}
+
+// isExpired returns true if the peer was not seen for too long at the given time,
+// and is not banned anymore.
+func (r *peerRecord) isExpired(now time.Time) bool {
+	return !r.isBanned(now) && now.Sub(time.Unix(r.LastSeen, 0)) > peerBookExpiry
+}
+
+// peerBook keeps track of the addresses, connection statistics, reputations and
+// bans of the peers we know, and persists them in the node datastore so they
+// survive restarts. The changed records are written in the background, so the
+// callers never wait for the datastore.
+type peerBook struct {
+	sync.RWMutex
+	ds      datastore.Datastore
+	records map[peer.ID]*peerRecord
+	// dirty are the peers whose record changed since the last flush
+	dirty map[peer.ID]struct{}
+	// forgotten are the peers whose record was removed since the last flush
+	forgotten map[peer.ID]struct{}
+
+	flushDelay time.Duration
+	flushCh    chan struct{}
+	closeCh    chan struct{}
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
+}
+
+func newPeerBook(ds datastore.Datastore) *peerBook {
+	return &peerBook{
+		ds:         ds,
+		records:    make(map[peer.ID]*peerRecord),
+		dirty:      make(map[peer.ID]struct{}),
+		forgotten:  make(map[peer.ID]struct{}),
+		flushDelay: peerBookFlushDelay,
+		flushCh:    make(chan struct{}, 1),
+		closeCh:    make(chan struct{}),
+	}
+}
+
+func peerBookKey(p peer.ID) datastore.Key {
+	return datastore.NewKey(peerBookPrefix).ChildString(p.String())
+}
+
+// load reads the persisted peer records, forgetting the ones that expired.
+func (pb *peerBook) load(ctx context.Context) error {
+	results, err := pb.ds.Query(ctx, query.Query{Prefix: peerBookPrefix})
+	if err != nil {
+		return fmt.Errorf("querying peer book: %w", err)
+	}
+	defer results.Close()
+
+	pb.Lock()
+	defer pb.Unlock()
+
+	now := time.Now()
+	var expired []datastore.Key
+	for result := range results.Next() {
+		if result.Error != nil {
+			return fmt.Errorf("reading peer book entry: %w", result.Error)
+		}
+
+		key := datastore.NewKey(result.Key)
+		p, err := peer.Decode(key.BaseNamespace())
+		if err != nil {
+			logger.Debugf("skipping peer book entry with invalid peer id %s: %s", key, err)
+			continue
+		}
+
+		record := &peerRecord{}
+		err = scale.Unmarshal(result.Value, record)
+		if err != nil {
+			logger.Debugf("skipping invalid peer book entry for peer %s: %s", p, err)
+			continue
+		}
+
+		if record.isExpired(now) {
+			expired = append(expired, key)
+			continue
+		}
+
+		pb.records[p] = record
+	}
+
+	for _, key := range expired {
+		err = pb.ds.Delete(ctx, key)
+		if err != nil {
+			return fmt.Errorf("deleting expired peer book entry %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// start writes the changed records in the background, and periodically forgets
+// the expired records and calls refresh so it can update the records of the known peers.
+func (pb *peerBook) start(refresh func()) {
+	pb.wg.Add(1)
+	go pb.run(refresh)
+}
+
+func (pb *peerBook) run(refresh func()) {
+	defer pb.wg.Done()
+
+	ticker := time.NewTicker(peerBookRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pb.closeCh:
+			return
+		case <-ticker.C:
+			pb.expire(time.Now())
+			refresh()
+		case <-pb.flushCh:
+			// gather the changes happening in the meantime in a single flush.
+			timer := time.NewTimer(pb.flushDelay)
+			select {
+			case <-pb.closeCh:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			pb.flush()
+		}
+	}
+}
+
+// close stops the background writes and writes the records changed since the
+// last flush. The datastore must only be closed afterwards.
+func (pb *peerBook) close() {
+	pb.closeOnce.Do(func() {
+		close(pb.closeCh)
+	})
+	pb.wg.Wait()
+	pb.flush()
+}
+
+// flush writes the changed records to the datastore, and deletes the forgotten ones.
+func (pb *peerBook) flush() {
+	pb.Lock()
+	encoded := make(map[peer.ID][]byte, len(pb.dirty))
+	for p := range pb.dirty {
+		enc, err := scale.Marshal(*pb.records[p])
+		if err != nil {
+			logger.Warnf("failed to encode peer book entry for peer %s: %s", p, err)
+			continue
+		}
+		encoded[p] = enc
+	}
+	pb.dirty = make(map[peer.ID]struct{})
+	forgotten := pb.forgotten
+	pb.forgotten = make(map[peer.ID]struct{})
+	pb.Unlock()
+
+	for p := range forgotten {
+		err := pb.ds.Delete(context.Background(), peerBookKey(p))
+		if err != nil {
+			logger.Warnf("failed to delete peer book entry for peer %s: %s", p, err)
+		}
+	}
+
+	for p, enc := range encoded {
+		err := pb.ds.Put(context.Background(), peerBookKey(p), enc)
+		if err != nil {
+			logger.Warnf("failed to store peer book entry for peer %s: %s", p, err)
+		}
+	}
+}
+
+// put schedules the peer record to be written. The caller must hold the lock.
+func (pb *peerBook) put(p peer.ID) {
+	pb.dirty[p] = struct{}{}
+	delete(pb.forgotten, p)
+	pb.scheduleFlush()
+}
+
+// forget removes the peer record and schedules it to be deleted. The caller must hold the lock.
+func (pb *peerBook) forget(p peer.ID) {
+	delete(pb.records, p)
+	delete(pb.dirty, p)
+	pb.forgotten[p] = struct{}{}
+	pb.scheduleFlush()
+}
+
+func (pb *peerBook) scheduleFlush() {
+	select {
+	case pb.flushCh <- struct{}{}:
+	default:
+	}
+}
+
+// expire forgets the records of the peers which expired at the given time.
+func (pb *peerBook) expire(now time.Time) {
+	pb.Lock()
+	defer pb.Unlock()
+
+	for p, record := range pb.records {
+		if record.isExpired(now) {
+			pb.forget(p)
+		}
+	}
+}
+
+// record returns the record of the given peer, creating it if needed. If the
+// peer book is full, the least recently seen peer which is not banned is forgotten
+// to make room for the new record. The caller must hold the lock.
+func (pb *peerBook) record(p peer.ID) *peerRecord {
+	record, ok := pb.records[p]
+	if ok {
+		return record
+	}
+
+	if len(pb.records) >= peerBookMaxRecords {
+		pb.evictLeastRecentlySeen(time.Now())
+	}
+
+	record = &peerRecord{}
+	pb.records[p] = record
+	return record
+}
+
+// evictLeastRecentlySeen forgets the least recently seen peer which is not banned.
+// The caller must hold the lock.
+func (pb *peerBook) evictLeastRecentlySeen(now time.Time) {
+	var (
+		oldest   peer.ID
+		lastSeen int64
+	)
+	for p, record := range pb.records {
+		if record.isBanned(now) {
+			continue
+		}
+		if oldest == "" || record.LastSeen < lastSeen {
+			oldest, lastSeen = p, record.LastSeen
+		}
+	}
+
+	if oldest != "" {
+		pb.forget(oldest)
+	}
+}
+
+func encodeAddrs(addrs []ma.Multiaddr) [][]byte {
+	encoded := make([][]byte, len(addrs))
+	for i, addr := range addrs {
+		encoded[i] = addr.Bytes()
+	}
+	return encoded
+}
+
+// connected records a successful connection with the peer, and its addresses if any.
+func (pb *peerBook) connected(p peer.ID, addrs []ma.Multiaddr) {
+	pb.Lock()
+	defer pb.Unlock()
+
+	record := pb.record(p)
+	record.LastSeen = time.Now().Unix()
+	record.Successes++
+	if len(addrs) > 0 {
+		record.Addrs = encodeAddrs(addrs)
+	}
+	pb.put(p)
+}
+
+// dialFailed records a failed attempt to connect to the peer.
+func (pb *peerBook) dialFailed(p peer.ID) {
+	pb.Lock()
+	defer pb.Unlock()
+
+	record, ok := pb.records[p]
+	if !ok {
+		// only keep statistics of peers we already managed to connect to.
+		return
+	}
+	record.Failures++
+	pb.put(p)
+}
+
+// update refreshes the addresses and the reputation of the known peers, and
+// schedules the changed records to be written.
+func (pb *peerBook) update(addrs func(peer.ID) []ma.Multiaddr, reputation func(peer.ID) (int32, bool)) {
+	pb.Lock()
+	defer pb.Unlock()
+
+	for p, record := range pb.records {
+		changed := false
+		if peerAddrs := addrs(p); len(peerAddrs) > 0 {
+			encoded := encodeAddrs(peerAddrs)
+			if !slices.EqualFunc(record.Addrs, encoded, bytes.Equal) {
+				record.Addrs = encoded
+				changed = true
+			}
+		}
+		if rep, ok := reputation(p); ok && rep != record.Reputation {
+			record.Reputation = rep
+			changed = true
+		}
+		if changed {
+			pb.put(p)
+		}
+	}
+}
+
+// ban bans the peer until the given time.
+func (pb *peerBook) ban(p peer.ID, until time.Time) {
+	pb.Lock()
+	defer pb.Unlock()
+
+	record := pb.record(p)
+	record.BannedUntil = until.Unix()
+	pb.put(p)
+}
+
+// unban lifts the ban of the peer, and returns its addresses.
+func (pb *peerBook) unban(p peer.ID) (addrs []ma.Multiaddr, banned bool) {
+	pb.Lock()
+	defer pb.Unlock()
+
+	record, ok := pb.records[p]
+	if !ok || !record.isBanned(time.Now()) {
+		return nil, false
+	}
+
+	record.BannedUntil = 0
+	pb.put(p)
+	return record.addrs(), true
+}
+
+// isBanned returns true if the peer is currently banned.
+func (pb *peerBook) isBanned(p peer.ID) bool {
+	pb.RLock()
+	defer pb.RUnlock()
+
+	record, ok := pb.records[p]
+	return ok && record.isBanned(time.Now())
+}
+
+// knownPeers returns the address information and the last known reputation of
+// the peers that are not banned and have at least one address.
+func (pb *peerBook) knownPeers() (infos []peer.AddrInfo, reputations []int32) {
+	pb.RLock()
+	defer pb.RUnlock()
+
+	now := time.Now()
+	for p, record := range pb.records {
+		if record.isBanned(now) {
+			continue
+		}
+
+		addrs := record.addrs()
+		if len(addrs) == 0 {
+			continue
+		}
+
+		infos = append(infos, peer.AddrInfo{ID: p, Addrs: addrs})
+		reputations = append(reputations, record.Reputation)
+	}
+	return infos, reputations
+}
+
+// entries returns a copy of all the peer records.
+func (pb *peerBook) entries() map[peer.ID]peerRecord {
+	pb.RLock()
+	defer pb.RUnlock()
+
+	entries := make(map[peer.ID]peerRecord, len(pb.records))
+	for p, record := range pb.records {
+		entries[p] = *record
+	}
+	return entries
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"context"
+	"crypto/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPeerID(t *testing.T) peer.ID {
+	t.Helper()
+
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+	id, err := peer.IDFromPrivateKey(key)
+	require.NoError(t, err)
+	return id
+}
+
+func Test_peerBook_persistence(t *testing.T) {
+	t.Parallel()
+
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	book := newPeerBook(ds)
+
+	knownPeer := newTestPeerID(t)
+	bannedPeer := newTestPeerID(t)
+	addr, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/7001")
+	require.NoError(t, err)
+
+	book.connected(knownPeer, []ma.Multiaddr{addr})
+	book.connected(knownPeer, nil)
+	book.dialFailed(knownPeer)
+	book.connected(bannedPeer, []ma.Multiaddr{addr})
+	book.ban(bannedPeer, time.Now().Add(time.Hour))
+	// dial failures of unknown peers are not recorded
+	book.dialFailed(newTestPeerID(t))
+
+	book.update(func(p peer.ID) []ma.Multiaddr {
+		return nil
+	}, func(p peer.ID) (int32, bool) {
+		return 42, p == knownPeer
+	})
+	book.close()
+
+	reloaded := newPeerBook(ds)
+	err = reloaded.load(context.Background())
+	require.NoError(t, err)
+
+	entries := reloaded.entries()
+	require.Len(t, entries, 2)
+
+	known := entries[knownPeer]
+	require.Equal(t, uint32(2), known.Successes)
+	require.Equal(t, uint32(1), known.Failures)
+	require.Equal(t, int32(42), known.Reputation)
+	require.Equal(t, []ma.Multiaddr{addr}, known.addrs())
+
+	require.True(t, reloaded.isBanned(bannedPeer))
+	require.False(t, reloaded.isBanned(knownPeer))
+
+	infos, reputations := reloaded.knownPeers()
+	require.Equal(t, []peer.AddrInfo{{ID: knownPeer, Addrs: []ma.Multiaddr{addr}}}, infos)
+	require.Equal(t, []int32{42}, reputations)
+
+	addrs, banned := reloaded.unban(bannedPeer)
+	require.True(t, banned)
+	require.Equal(t, []ma.Multiaddr{addr}, addrs)
+	require.False(t, reloaded.isBanned(bannedPeer))
+
+	_, banned = reloaded.unban(bannedPeer)
+	require.False(t, banned)
+}
+
+func Test_peerBook_backgroundWrites(t *testing.T) {
+	t.Parallel()
+
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	book := newPeerBook(ds)
+	book.flushDelay = 100 * time.Millisecond
+	book.start(func() {})
+	t.Cleanup(book.close)
+
+	ctx := context.Background()
+	p := newTestPeerID(t)
+	addr, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/7001")
+	require.NoError(t, err)
+
+	// the record is not written by the caller
+	book.connected(p, []ma.Multiaddr{addr})
+	has, err := ds.Has(ctx, peerBookKey(p))
+	require.NoError(t, err)
+	require.False(t, has)
+
+	require.Eventually(t, func() bool {
+		has, err := ds.Has(ctx, peerBookKey(p))
+		return err == nil && has
+	}, time.Second, 10*time.Millisecond)
+
+	// records left unchanged by an update are not written again
+	require.NoError(t, ds.Delete(ctx, peerBookKey(p)))
+	book.update(func(peer.ID) []ma.Multiaddr {
+		return []ma.Multiaddr{addr}
+	}, func(peer.ID) (int32, bool) {
+		return 0, true
+	})
+	book.flush()
+	has, err = ds.Has(ctx, peerBookKey(p))
+	require.NoError(t, err)
+	require.False(t, has)
+}
+
+func Test_peerBook_load_expired(t *testing.T) {
+	t.Parallel()
+
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	ctx := context.Background()
+
+	expiredPeer := newTestPeerID(t)
+	expiredBannedPeer := newTestPeerID(t)
+	recentPeer := newTestPeerID(t)
+	lastSeen := time.Now().Add(-2 * peerBookExpiry).Unix()
+	records := map[peer.ID]peerRecord{
+		expiredPeer:       {LastSeen: lastSeen},
+		expiredBannedPeer: {LastSeen: lastSeen, BannedUntil: time.Now().Add(time.Hour).Unix()},
+		recentPeer:        {LastSeen: time.Now().Unix()},
+	}
+	for p, record := range records {
+		enc, err := scale.Marshal(record)
+		require.NoError(t, err)
+		err = ds.Put(ctx, peerBookKey(p), enc)
+		require.NoError(t, err)
+	}
+
+	book := newPeerBook(ds)
+	err := book.load(ctx)
+	require.NoError(t, err)
+
+	entries := book.entries()
+	require.Len(t, entries, 2)
+	require.Contains(t, entries, expiredBannedPeer)
+	require.Contains(t, entries, recentPeer)
+
+	has, err := ds.Has(ctx, peerBookKey(expiredPeer))
+	require.NoError(t, err)
+	require.False(t, has)
+}
+
+func Test_peerBook_expire(t *testing.T) {
+	t.Parallel()
+
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	ctx := context.Background()
+	book := newPeerBook(ds)
+
+	stalePeer := newTestPeerID(t)
+	recentPeer := newTestPeerID(t)
+	book.connected(stalePeer, nil)
+	book.connected(recentPeer, nil)
+	book.flush()
+
+	book.Lock()
+	book.records[stalePeer].LastSeen = time.Now().Add(-2 * peerBookExpiry).Unix()
+	book.Unlock()
+
+	book.expire(time.Now())
+	entries := book.entries()
+	require.Len(t, entries, 1)
+	require.Contains(t, entries, recentPeer)
+
+	book.flush()
+	has, err := ds.Has(ctx, peerBookKey(stalePeer))
+	require.NoError(t, err)
+	require.False(t, has)
+	has, err = ds.Has(ctx, peerBookKey(recentPeer))
+	require.NoError(t, err)
+	require.True(t, has)
+}
+
+func Test_peerBook_evictLeastRecentlySeen(t *testing.T) {
+	t.Parallel()
+
+	book := newPeerBook(dssync.MutexWrap(datastore.NewMapDatastore()))
+	now := time.Now()
+
+	oldestPeer := newTestPeerID(t)
+	bannedPeer := newTestPeerID(t)
+	book.records[oldestPeer] = &peerRecord{LastSeen: now.Add(-time.Hour).Unix()}
+	book.records[bannedPeer] = &peerRecord{BannedUntil: now.Add(time.Hour).Unix()}
+	for i := 2; i < peerBookMaxRecords; i++ {
+		book.records[peer.ID(strconv.Itoa(i))] = &peerRecord{LastSeen: now.Unix()}
+	}
+
+	newPeer := newTestPeerID(t)
+	book.connected(newPeer, nil)
+
+	entries := book.entries()
+	require.Len(t, entries, peerBookMaxRecords)
+	require.Contains(t, entries, newPeer)
+	require.Contains(t, entries, bannedPeer)
+	require.NotContains(t, entries, oldestPeer)
+}
@@ -31,10 +31,21 @@ type RequestResponseProtocol struct {
 }
 
 func (rrp *RequestResponseProtocol) Do(to peer.ID, req, res messages.P2PMessage) error {
-	rrp.host.p2pHost.ConnManager().Protect(to, "")
-	defer rrp.host.p2pHost.ConnManager().Unprotect(to, "")
+	return rrp.DoWithTimeout(to, req, res, rrp.requestTimeout)
+}
+
+// DoWithTimeout sends the request to the peer and waits for its response at most the
+// given timeout, which cannot exceed the request timeout of the protocol.
+func (rrp *RequestResponseProtocol) DoWithTimeout(to peer.ID, req, res messages.P2PMessage,
+	timeout time.Duration) error {
+	rrp.host.p2pHost.ConnManager().Protect(to, string(rrp.protocolID))
+	defer rrp.host.p2pHost.ConnManager().Unprotect(to, string(rrp.protocolID))
+
+	if timeout <= 0 || timeout > rrp.requestTimeout {
+		timeout = rrp.requestTimeout
+	}
 
-	ctx, cancel := context.WithTimeout(rrp.ctx, rrp.requestTimeout)
+	ctx, cancel := context.WithTimeout(rrp.ctx, timeout)
 	defer cancel()
 
 	stream, err := rrp.host.p2pHost.NewStream(ctx, to, rrp.protocolID)
@@ -42,6 +53,10 @@ func (rrp *RequestResponseProtocol) Do(to peer.ID, req, res messages.P2PMessage)
 		return err
 	}
 
+	// the peer is connected once the stream is opened, it is valued while the request is in progress
+	rrp.host.p2pHost.ConnManager().TagPeer(to, syncWorkerTag, syncWorkerTagValue)
+	defer rrp.host.p2pHost.ConnManager().UntagPeer(to, syncWorkerTag)
+
 	defer func() {
 		err := stream.Close()
 		if err != nil && err.Error() != ErrStreamReset.Error() {
@@ -49,6 +64,12 @@ func (rrp *RequestResponseProtocol) Do(to peer.ID, req, res messages.P2PMessage)
 		}
 	}()
 
+	// the timeout also applies to writing the request and reading the response
+	deadline, _ := ctx.Deadline()
+	if err = stream.SetDeadline(deadline); err != nil {
+		logger.Debugf("failed to set stream deadline: %s", err)
+	}
+
 	if err = rrp.host.writeToStream(stream, req); err != nil {
 		return err
 	}
@@ -69,6 +90,7 @@ func (rrp *RequestResponseProtocol) receiveResponse(stream libp2pnetwork.Stream,
 	if err != nil {
 		return fmt.Errorf("read stream error: %w", err)
 	}
+	rrp.host.bandwidth.received(stream, n)
 
 	if n == 0 {
 		return ErrReceivedEmptyMessage
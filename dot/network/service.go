@@ -8,6 +8,7 @@
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +22,7 @@
 	"github.com/ChainSafe/gossamer/lib/common"
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
 	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
 	"github.com/prometheus/client_golang/prometheus"
@@ -117,6 +119,7 @@ type Service struct {
 	host          *host
 	mdns          MDNS
 	gossip        *gossip
+	txnPropagator *transactionPropagator
 	bufPool       *sync.Pool
 	streamManager *streamManager
 
@@ -215,6 +218,7 @@ func NewService(cfg *Config) (*Service, error) {
 		host:                   host,
 		mdns:                   mdnsService,
 		gossip:                 newGossip(),
+		txnPropagator:          newTransactionPropagator(),
 		blockState:             cfg.BlockState,
 		transactionHandler:     cfg.TransactionHandler,
 		noBootstrap:            cfg.NoBootstrap,
@@ -320,6 +324,7 @@ func (s *Service) Start() error {
 			prtl.peersData.deleteInboundHandshakeData(peerID)
 			prtl.peersData.deleteOutboundHandshakeData(peerID)
 		}
+		s.txnPropagator.removePeer(peerID)
 	}
 
 	// log listening addresses to console
@@ -356,6 +361,8 @@ func (s *Service) Start() error {
 	}
 
 	go s.logPeerCount()
+	go s.trimIdleBandwidth()
+	go s.startTxnPropagation()
 	go s.publishNetworkTelemetry(s.closeCh)
 	go s.sentBlockIntervalTelemetry()
 	s.streamManager.start()
@@ -383,6 +390,7 @@ func (s *Service) updateMetrics() {
 			outboundGrandpaStreamsGauge.Set(float64(s.getNumStreams(ConsensusMsgType, false)))
 			inboundStreamsGauge.Set(float64(s.getTotalStreams(true)))
 			outboundStreamsGauge.Set(float64(s.getTotalStreams(false)))
+			updateStreamsMetrics(countStreams(s.host.p2pHost.Network().Conns()))
 		}
 	}
 }
@@ -425,6 +433,22 @@ func (s *Service) logPeerCount() {
 	}
 }
 
+// trimIdleBandwidth periodically forgets the traffic of the idle peers and protocols,
+// so the traffic of the peers no longer connected is not kept forever
+func (s *Service) trimIdleBandwidth() {
+	ticker := time.NewTicker(bandwidthTrimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			s.host.bandwidth.trimIdle(now)
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
 func (s *Service) publishNetworkTelemetry(done <-chan struct{}) {
 	ticker := time.NewTicker(s.telemetryInterval)
 	defer ticker.Stop()
@@ -435,7 +459,7 @@ func (s *Service) publishNetworkTelemetry(done <-chan struct{}) {
 			return
 
 		case <-ticker.C:
-			o := s.host.bwc.GetBandwidthTotals()
+			o := s.host.bandwidth.totals()
 			s.telemetry.SendMessage(telemetry.NewBandwidth(o.RateIn, o.RateOut, s.host.peerCount()))
 		}
 	}
@@ -556,6 +580,12 @@ func (s *Service) GossipMessage(msg NotificationsMessage) {
 	logger.Debugf("gossiping from host %s message of type %d: %s",
 		s.host.id(), msg.Type(), msg)
 
+	// transactions are batched and sent on the next propagation tick
+	if txMsg, ok := msg.(*TransactionMessage); ok {
+		s.txnPropagator.queue(txMsg.Extrinsics...)
+		return
+	}
+
 	// check if the message is part of a notifications protocol
 	s.notificationsMu.Lock()
 	defer s.notificationsMu.Unlock()
@@ -643,6 +673,12 @@ func (s *Service) Health() common.Health {
 	}
 }
 
+// Bandwidth returns the traffic of the node in total, per protocol and per peer,
+// with the number of messages and open streams of each protocol
+func (s *Service) Bandwidth() common.NetworkBandwidth {
+	return s.host.bandwidth.snapshot(countStreams(s.host.p2pHost.Network().Conns()), s.host.peers())
+}
+
 // NetworkState returns information about host needed for the rpc server and the runtime
 func (s *Service) NetworkState() common.NetworkState {
 	return common.NetworkState{
@@ -696,6 +732,104 @@ func (s *Service) RemoveReservedPeers(addrs ...string) error {
 	return s.host.removeReservedPeers(addrs...)
 }
 
+// ReservedPeers returns the peer ids of the reserved peers
+func (s *Service) ReservedPeers() []string {
+	reserved := s.host.cm.peerSetHandler.ReservedPeers()
+	peers := make([]string, len(reserved))
+	for i, p := range reserved {
+		peers[i] = p.String()
+	}
+	return peers
+}
+
+// IsReservedOnly returns true if the node only connects to reserved peers
+func (s *Service) IsReservedOnly() bool {
+	return s.host.cm.peerSetHandler.IsReservedOnly()
+}
+
+// SetReservedOnly switches the reserved-only mode, when enabled all the
+// connected peers which are not reserved are dropped
+func (s *Service) SetReservedOnly(reservedOnly bool) {
+	const setID = 0
+	s.host.cm.peerSetHandler.SetReservedOnly(setID, reservedOnly)
+}
+
+// KnownPeers returns the peers of the peer book, including the banned ones
+func (s *Service) KnownPeers() []common.KnownPeerInfo {
+	entries := s.host.cm.peerBook.entries()
+	peers := make([]common.KnownPeerInfo, 0, len(entries))
+	for p, record := range entries {
+		info := common.KnownPeerInfo{
+			PeerID:     p.String(),
+			LastSeen:   time.Unix(record.LastSeen, 0),
+			Successes:  record.Successes,
+			Failures:   record.Failures,
+			Reputation: record.Reputation,
+		}
+
+		// prefer the live reputation of the peers known by the peerSet
+		if rep, err := s.host.cm.peerSetHandler.PeerReputation(p); err == nil {
+			info.Reputation = int32(rep)
+		}
+
+		if record.isBanned(time.Now()) {
+			info.BannedUntil = time.Unix(record.BannedUntil, 0)
+		}
+
+		for _, addr := range record.addrs() {
+			info.Addrs = append(info.Addrs, addr.String())
+		}
+		peers = append(peers, info)
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].PeerID < peers[j].PeerID
+	})
+	return peers
+}
+
+// BanPeer disconnects the peer and refuses any connection with it for the given duration
+func (s *Service) BanPeer(id string, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("%w: %s", errInvalidBanDuration, duration)
+	}
+
+	p, err := peer.Decode(id)
+	if err != nil {
+		return fmt.Errorf("decoding peer id: %w", err)
+	}
+
+	if s.host.cm.peerSetHandler.IsReserved(p) {
+		return fmt.Errorf("%w: %s", errCannotBanReservedPeer, p)
+	}
+
+	s.host.cm.peerBook.ban(p, time.Now().Add(duration))
+
+	const setID = 0
+	s.host.cm.peerSetHandler.RemovePeer(setID, p)
+	return s.host.closePeer(p)
+}
+
+// UnbanPeer lifts the ban of the peer, allowing connections with it again
+func (s *Service) UnbanPeer(id string) error {
+	p, err := peer.Decode(id)
+	if err != nil {
+		return fmt.Errorf("decoding peer id: %w", err)
+	}
+
+	addrs, banned := s.host.cm.peerBook.unban(p)
+	if !banned {
+		return fmt.Errorf("%w: %s", errPeerNotBanned, p)
+	}
+
+	if len(addrs) > 0 {
+		const setID = 0
+		s.host.p2pHost.Peerstore().AddAddrs(p, addrs, peerstore.PermanentAddrTTL)
+		s.host.cm.peerSetHandler.AddPeer(setID, p)
+	}
+	return nil
+}
+
 // NodeRoles Returns the roles the node is running as.
 func (s *Service) NodeRoles() common.NetworkRole {
 	return s.cfg.Roles
@@ -725,11 +859,16 @@ func (s *Service) ReportPeer(change peerset.ReputationChange, p peer.ID) {
 
 func (s *Service) startPeerSetHandler() {
 	s.host.cm.peerSetHandler.Start(s.ctx)
+	// restore the peers we knew before dialling the bootnodes.
+	s.host.loadKnownPeers()
 	// wait for peerSetHandler to start.
 	if !s.noBootstrap {
 		s.host.bootstrap()
 	}
 
+	go s.host.cm.trimLoop(s.ctx)
+	s.host.cm.peerBook.start(s.host.refreshPeerBook)
+
 	go s.startProcessingMsg()
 }
 
@@ -758,6 +897,7 @@ func (s *Service) processMessage(msg peerset.Message) {
 
 		err := s.host.connect(addrInfo)
 		if err != nil {
+			s.host.cm.peerBook.dialFailed(peerID)
 			// TODO: if error happens here outgoing (?) slot is occupied but no peer is really connected
 			logger.Warnf("failed to open connection for peer %s: %s", peerID, err)
 			return
@@ -52,6 +52,7 @@ type PeerSetHandler interface {
 	ReportPeer(peerset.ReputationChange, ...peer.ID)
 	PeerAdd
 	PeerRemove
+	PeerReserved
 	Peer
 }
 
@@ -65,10 +66,20 @@ type PeerAdd interface {
 // PeerRemove is the interface used by the PeerSetHandler to remove peers from peerSet.
 type PeerRemove interface {
 	RemoveReservedPeer(int, ...peer.ID)
+	RemovePeer(int, ...peer.ID)
+}
+
+// PeerReserved is the interface used by the PeerSetHandler to manage reserved peers and the reserved-only mode.
+type PeerReserved interface {
+	SetReservedOnly(int, bool)
+	IsReservedOnly() bool
+	IsReserved(peer.ID) bool
+	ReservedPeers() peer.IDSlice
 }
 
 // Peer is the interface used by the PeerSetHandler to get the peer data from peerSet.
 type Peer interface {
 	SortedPeers(idx int) chan peer.IDSlice
+	PeerReputation(peer.ID) (peerset.Reputation, error)
 	Messages() chan peerset.Message
 }
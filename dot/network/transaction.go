@@ -9,6 +9,8 @@
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
@@ -20,8 +22,31 @@
 	_ Handshake            = (*transactionHandshake)(nil)
 )
 
-// txnBatchChTimeout is the timeout for adding a transaction to the batch processing channel
-const txnBatchChTimeout = time.Millisecond * 200
+const (
+	// txnBatchChTimeout is the timeout for adding a transaction to the batch processing channel
+	txnBatchChTimeout = time.Millisecond * 200
+
+	// txnPropagationInterval is the interval at which the pending transactions are sent to our peers
+	txnPropagationInterval = time.Millisecond * 2900
+)
+
+var (
+	transactionsReceivedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gossamer_network_transactions",
+		Name:      "received_total",
+		Help:      "total number of transactions received from peers",
+	})
+	transactionsDuplicateCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gossamer_network_transactions",
+		Name:      "duplicate_total",
+		Help:      "total number of transactions received from peers that were already seen",
+	})
+	transactionsSentCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gossamer_network_transactions",
+		Name:      "sent_total",
+		Help:      "total number of transactions sent to peers",
+	})
+)
 
 // TransactionMessage is a network message that is sent to notify of new transactions entering the network
 type TransactionMessage struct {
@@ -104,35 +129,91 @@ func (s *Service) startTxnBatchProcessing(txnBatchCh chan *batchMessage, slotDur
 				case <-timer.C:
 					timedOut = true
 				case txnMsg := <-txnBatchCh:
-					propagate, err := s.handleTransactionMessage(txnMsg.peer, txnMsg.msg)
-					if err != nil {
-						logger.Warnf("could not handle transaction message: %s", err)
+					txMsg, ok := txnMsg.msg.(*TransactionMessage)
+					if !ok {
+						logger.Warnf("could not handle transaction message: invalid transaction type %T", txnMsg.msg)
 						s.host.closeProtocolStream(protocolID, txnMsg.peer)
 						continue
 					}
 
-					if s.noGossip || !propagate {
+					propagate, err := s.handleTransactionMessage(txnMsg.peer, txMsg)
+					if err != nil {
+						logger.Warnf("could not handle transaction message: %s", err)
+						s.host.closeProtocolStream(protocolID, txnMsg.peer)
 						continue
 					}
 
-					// TODO: Check if s.gossip.hasSeen should be moved before handleTransactionMessage. #2445
-					// That we could avoid handling the transactions again, which we would have already seen.
+					// the sender knows the transactions it sent us once they are validated,
+					// they are never sent back to it.
+					fresh := s.txnPropagator.received(txnMsg.peer, txMsg.Extrinsics)
 
-					hasSeen, err := s.gossip.hasSeen(txnMsg.msg)
-					if err != nil {
-						s.host.closeProtocolStream(protocolID, txnMsg.peer)
-						logger.Debugf("could not check if message was seen before: %s", err)
+					if s.noGossip || !propagate {
 						continue
 					}
-					if !hasSeen {
-						s.broadcastExcluding(s.notificationsProtocols[transactionMsgType], txnMsg.peer, txnMsg.msg)
-					}
+
+					s.txnPropagator.queue(fresh...)
 				}
 			}
 		}
 	}
 }
 
+// startTxnPropagation periodically sends the pending transactions to the peers
+// that do not know them yet, batched in a single message per peer.
+func (s *Service) startTxnPropagation() {
+	ticker := time.NewTicker(txnPropagationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.propagateTransactions()
+		}
+	}
+}
+
+func (s *Service) propagateTransactions() {
+	s.notificationsMu.RLock()
+	info, ok := s.notificationsProtocols[transactionMsgType]
+	s.notificationsMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	hs, err := info.getHandshake()
+	if err != nil {
+		logger.Errorf("failed to get handshake using protocol %s: %s", info.protocolID, err)
+		return
+	}
+
+	// light clients do not take part in the transactions gossip
+	var peers []peer.ID
+	for _, p := range s.host.peers() {
+		role, ok := s.peerRole(p)
+		if ok && role == common.LightClientRole {
+			continue
+		}
+		peers = append(peers, p)
+	}
+
+	batches := s.txnPropagator.takeBatches(peers)
+	for p, exts := range batches {
+		if len(exts) == 0 {
+			continue
+		}
+
+		logger.Tracef("propagating %d transactions to peer %s", len(exts), p)
+		transactionsSentCounter.Add(float64(len(exts)))
+
+		info.peersData.setMutex(p)
+		for _, batch := range splitTransactions(exts, maxTransactionsNotificationSize) {
+			go s.sendData(p, hs, info, &TransactionMessage{Extrinsics: batch})
+		}
+	}
+}
+
 func (s *Service) createBatchMessageHandler(txnBatchCh chan *batchMessage) NotificationsMessageBatchHandler {
 	go s.startTxnBatchProcessing(txnBatchCh, s.cfg.SlotDuration)
 
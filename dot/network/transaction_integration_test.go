@@ -10,6 +10,7 @@
 	"time"
 
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -61,3 +62,87 @@ func TestHandleTransactionMessage(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, ret)
 }
+
+func TestTransactionPropagation(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	newTransactionHandler := func(received chan<- *TransactionMessage) TransactionHandler {
+		handler := NewMockTransactionHandler(ctrl)
+		handler.EXPECT().
+			HandleTransactionMessage(gomock.AssignableToTypeOf(peer.ID("")), gomock.Any()).
+			DoAndReturn(func(_ peer.ID, msg *TransactionMessage) (bool, error) {
+				received <- msg
+				return false, nil
+			}).AnyTimes()
+		handler.EXPECT().TransactionsCount().Return(0).AnyTimes()
+		return handler
+	}
+
+	nodeA := createTestService(t, &Config{
+		BasePath:    t.TempDir(),
+		Port:        availablePort(t),
+		NoBootstrap: true,
+		NoMDNS:      true,
+	})
+
+	receivedB := make(chan *TransactionMessage, 2)
+	nodeB := createTestService(t, &Config{
+		BasePath:           t.TempDir(),
+		Port:               availablePort(t),
+		NoBootstrap:        true,
+		NoMDNS:             true,
+		TransactionHandler: newTransactionHandler(receivedB),
+	})
+
+	receivedLight := make(chan *TransactionMessage, 2)
+	lightNode := createTestService(t, &Config{
+		BasePath:           t.TempDir(),
+		Port:               availablePort(t),
+		NoBootstrap:        true,
+		NoMDNS:             true,
+		TransactionHandler: newTransactionHandler(receivedLight),
+	})
+
+	for _, node := range []*Service{nodeB, lightNode} {
+		addrInfo := addrInfo(node.host)
+		err := nodeA.host.connect(addrInfo)
+		if failedToDial(err) {
+			time.Sleep(TestBackoffTimeout)
+			err = nodeA.host.connect(addrInfo)
+		}
+		require.NoError(t, err)
+	}
+
+	// record the role the light node would send in its block announce handshake
+	nodeA.notificationsProtocols[blockAnnounceMsgType].peersData.setInboundHandshakeData(
+		lightNode.host.id(), &handshakeData{
+			received:  true,
+			validated: true,
+			handshake: &BlockAnnounceHandshake{Roles: common.LightClientRole},
+		})
+
+	exts := []types.Extrinsic{{1, 1}, {2, 2}}
+	nodeA.GossipMessage(&TransactionMessage{Extrinsics: exts[:1]})
+	nodeA.GossipMessage(&TransactionMessage{Extrinsics: exts[1:]})
+
+	// the transactions are sent in a single batch
+	select {
+	case msg := <-receivedB:
+		require.Equal(t, exts, msg.Extrinsics)
+	case <-time.After(4 * txnPropagationInterval):
+		t.Fatal("timed out waiting for the transactions")
+	}
+
+	// transactions known by the peer are not sent again
+	nodeA.GossipMessage(&TransactionMessage{Extrinsics: exts})
+
+	select {
+	case msg := <-receivedB:
+		t.Fatalf("unexpected transactions received: %s", msg)
+	case msg := <-receivedLight:
+		t.Fatalf("unexpected transactions received by light node: %s", msg)
+	case <-time.After(2 * txnPropagationInterval):
+	}
+}
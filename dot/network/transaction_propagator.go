@@ -0,0 +1,154 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"sync"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	lrucache "github.com/ChainSafe/gossamer/lib/utils/lru-cache"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// maxKnownTransactionsPerPeer is the number of transaction hashes remembered for each peer
+	maxKnownTransactionsPerPeer = 10240
+	// maxSeenTransactions is the number of transaction hashes remembered to detect duplicates
+	maxSeenTransactions = 4 * maxKnownTransactionsPerPeer
+)
+
+// transactionPropagator keeps track of the transactions known by each peer, and
+// of the transactions waiting to be propagated on the next tick.
+type transactionPropagator struct {
+	sync.Mutex
+	// known are the hashes of the transactions each peer sent us or we sent to it
+	known map[peer.ID]*lrucache.LRUCache[common.Hash, bool]
+	// seen are the hashes of the transactions we received from any peer
+	seen *lrucache.LRUCache[common.Hash, bool]
+
+	pending       []pendingTransaction
+	pendingHashes map[common.Hash]struct{}
+}
+
+// pendingTransaction is a transaction waiting to be propagated, along with its hash
+type pendingTransaction struct {
+	ext  types.Extrinsic
+	hash common.Hash
+}
+
+func newTransactionPropagator() *transactionPropagator {
+	return &transactionPropagator{
+		known:         make(map[peer.ID]*lrucache.LRUCache[common.Hash, bool]),
+		seen:          lrucache.NewLRUCache[common.Hash, bool](maxSeenTransactions),
+		pendingHashes: make(map[common.Hash]struct{}),
+	}
+}
+
+// knownBy returns the transactions known by the peer. The caller must hold the lock.
+func (tp *transactionPropagator) knownBy(p peer.ID) *lrucache.LRUCache[common.Hash, bool] {
+	known, ok := tp.known[p]
+	if !ok {
+		known = lrucache.NewLRUCache[common.Hash, bool](maxKnownTransactionsPerPeer)
+		tp.known[p] = known
+	}
+	return known
+}
+
+// received records the transactions received from the peer as known by it, and
+// returns the ones we had not seen before.
+func (tp *transactionPropagator) received(from peer.ID, exts []types.Extrinsic) (fresh []types.Extrinsic) {
+	tp.Lock()
+	defer tp.Unlock()
+
+	known := tp.knownBy(from)
+	for _, ext := range exts {
+		hash := ext.Hash()
+		known.Put(hash, true)
+
+		if tp.seen.Get(hash) {
+			transactionsDuplicateCounter.Inc()
+			continue
+		}
+		tp.seen.Put(hash, true)
+		fresh = append(fresh, ext)
+	}
+
+	transactionsReceivedCounter.Add(float64(len(exts)))
+	return fresh
+}
+
+// queue adds the transactions to the ones propagated on the next tick.
+func (tp *transactionPropagator) queue(exts ...types.Extrinsic) {
+	tp.Lock()
+	defer tp.Unlock()
+
+	for _, ext := range exts {
+		hash := ext.Hash()
+		if _, ok := tp.pendingHashes[hash]; ok {
+			continue
+		}
+		tp.pendingHashes[hash] = struct{}{}
+		tp.pending = append(tp.pending, pendingTransaction{ext: ext, hash: hash})
+	}
+}
+
+// takeBatches removes the pending transactions, and returns for each of the given peers
+// the ones it does not know yet. The returned transactions are recorded as known by the peers.
+func (tp *transactionPropagator) takeBatches(peers []peer.ID) map[peer.ID][]types.Extrinsic {
+	tp.Lock()
+	defer tp.Unlock()
+
+	if len(tp.pending) == 0 {
+		return nil
+	}
+
+	pending := tp.pending
+	tp.pending = nil
+	tp.pendingHashes = make(map[common.Hash]struct{})
+
+	batches := make(map[peer.ID][]types.Extrinsic, len(peers))
+	for _, p := range peers {
+		known := tp.knownBy(p)
+		for _, txn := range pending {
+			if known.Get(txn.hash) {
+				continue
+			}
+			known.Put(txn.hash, true)
+			batches[p] = append(batches[p], txn.ext)
+		}
+	}
+	return batches
+}
+
+// removePeer forgets the transactions known by the peer.
+func (tp *transactionPropagator) removePeer(p peer.ID) {
+	tp.Lock()
+	defer tp.Unlock()
+
+	delete(tp.known, p)
+}
+
+// splitTransactions splits the transactions in batches whose encoded size do not
+// exceed the given maximum size.
+func splitTransactions(exts []types.Extrinsic, maxSize uint64) (batches [][]types.Extrinsic) {
+	var batch []types.Extrinsic
+	var size uint64
+	for _, ext := range exts {
+		// keep some room for the compact encoded lengths
+		extSize := uint64(len(ext)) + 8
+		if len(batch) > 0 && size+extSize > maxSize {
+			batches = append(batches, batch)
+			batch = nil
+			size = 0
+		}
+		batch = append(batch, ext)
+		size += extSize
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_transactionPropagator(t *testing.T) {
+	t.Parallel()
+
+	const peerA, peerB = peer.ID("a"), peer.ID("b")
+	ext1, ext2, ext3 := types.Extrinsic{1, 1}, types.Extrinsic{2, 2}, types.Extrinsic{3, 3}
+
+	tp := newTransactionPropagator()
+
+	fresh := tp.received(peerA, []types.Extrinsic{ext1, ext2})
+	require.Equal(t, []types.Extrinsic{ext1, ext2}, fresh)
+
+	// transactions already received from another peer are not fresh
+	fresh = tp.received(peerB, []types.Extrinsic{ext2, ext3})
+	require.Equal(t, []types.Extrinsic{ext3}, fresh)
+
+	// nothing to propagate
+	require.Nil(t, tp.takeBatches([]peer.ID{peerA, peerB}))
+
+	tp.queue(ext1, ext2, ext3, ext1)
+	batches := tp.takeBatches([]peer.ID{peerA, peerB})
+	require.Equal(t, map[peer.ID][]types.Extrinsic{
+		peerA: {ext3},
+		peerB: {ext1},
+	}, batches)
+
+	// peers now know all the transactions
+	tp.queue(ext1, ext2, ext3)
+	batches = tp.takeBatches([]peer.ID{peerA, peerB})
+	require.Empty(t, batches)
+
+	// removed peers are forgotten
+	tp.removePeer(peerA)
+	tp.queue(ext1)
+	batches = tp.takeBatches([]peer.ID{peerA, peerB})
+	require.Equal(t, map[peer.ID][]types.Extrinsic{
+		peerA: {ext1},
+	}, batches)
+}
+
+func Test_splitTransactions(t *testing.T) {
+	t.Parallel()
+
+	exts := []types.Extrinsic{make([]byte, 10), make([]byte, 10), make([]byte, 30), make([]byte, 5)}
+
+	batches := splitTransactions(exts, 40)
+	require.Equal(t, [][]types.Extrinsic{exts[:2], exts[2:3], exts[3:]}, batches)
+
+	batches = splitTransactions(exts, 1024)
+	require.Equal(t, [][]types.Extrinsic{exts}, batches)
+
+	require.Nil(t, splitTransactions(nil, 1024))
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/ChainSafe/gossamer/internal/pubip"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// TCPTransport is the name of the TCP transport
+	TCPTransport = "tcp"
+	// QUICTransport is the name of the QUIC transport
+	QUICTransport = "quic"
+	// WebSocketTransport is the name of the WebSocket transport, used for both /ws and /wss addresses
+	WebSocketTransport = "ws"
+)
+
+// DefaultTransports the default value for Config.Transports
+var DefaultTransports = []string{TCPTransport}
+
+// checkTransports checks the transports to listen on are known.
+// The peers are dialed with all the default libp2p transports whatever the transports
+// listened on, since an explicit list of libp2p transports replaces its default ones.
+func checkTransports(transports []string) error {
+	if len(transports) == 0 {
+		return errNoTransport
+	}
+
+	for _, transport := range transports {
+		switch strings.TrimSpace(transport) {
+		case TCPTransport, QUICTransport, WebSocketTransport:
+		default:
+			return fmt.Errorf("%w: %s", errUnknownTransport, transport)
+		}
+	}
+	return nil
+}
+
+// transportOf returns the name of the transport the multiaddress is using.
+func transportOf(addr ma.Multiaddr) (string, error) {
+	var transport string
+	ma.ForEach(addr, func(c ma.Component) bool {
+		switch c.Protocol().Code {
+		case ma.P_QUIC_V1:
+			transport = QUICTransport
+		case ma.P_WS, ma.P_WSS:
+			transport = WebSocketTransport
+		case ma.P_TCP:
+			// a /ws component may still follow
+			transport = TCPTransport
+			return true
+		default:
+			return true
+		}
+		return false
+	})
+
+	if transport == "" {
+		return "", fmt.Errorf("%w: %s", errUnsupportedListenAddress, addr)
+	}
+	return transport, nil
+}
+
+// defaultListenAddresses returns the addresses to listen on when none are configured,
+// TCP and QUIC listen on the configured port, WebSocket is only listened on when
+// its address is configured.
+func defaultListenAddresses(transports []string, port uint16) []string {
+	var addrs []string
+	for _, transport := range transports {
+		switch strings.TrimSpace(transport) {
+		case TCPTransport:
+			addrs = append(addrs, fmt.Sprintf("/ip4/0.0.0.0/tcp/%d", port))
+		case QUICTransport:
+			addrs = append(addrs, fmt.Sprintf("/ip4/0.0.0.0/udp/%d/quic-v1", port))
+		}
+	}
+	return addrs
+}
+
+// listenAddresses parses the listen addresses of the configuration, and checks
+// that the transport each of them is using is enabled.
+func listenAddresses(cfg *Config) ([]ma.Multiaddr, error) {
+	var listen []string
+	if cfg.ListenAddress != "" {
+		listen = append(listen, cfg.ListenAddress)
+	}
+	listen = append(listen, cfg.ListenAddresses...)
+	if len(listen) == 0 {
+		listen = defaultListenAddresses(cfg.Transports, cfg.Port)
+	}
+
+	enabled := make(map[string]struct{}, len(cfg.Transports))
+	for _, transport := range cfg.Transports {
+		enabled[strings.TrimSpace(transport)] = struct{}{}
+	}
+
+	addrs := make([]ma.Multiaddr, 0, len(listen))
+	for _, s := range listen {
+		addr, err := ma.NewMultiaddr(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("parsing listen address %s: %w", s, err)
+		}
+
+		transport, err := transportOf(addr)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := enabled[transport]; !ok {
+			return nil, fmt.Errorf("%w: %s transport of listen address %s", errTransportNotEnabled, transport, addr)
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, nil
+}
+
+// externalAddresses returns the addresses advertised to other peers through
+// identify and the DHT, on top of the public listen addresses.
+// If public addresses are configured, they are advertised as they are, this is
+// useful to advertise a /wss address terminated by a proxy for example.
+// Otherwise the public IP or DNS replaces the IP of each listen address.
+func externalAddresses(cfg *Config, listenAddrs []ma.Multiaddr) ([]ma.Multiaddr, error) {
+	if len(cfg.PublicAddresses) > 0 {
+		addrs := make([]ma.Multiaddr, len(cfg.PublicAddresses))
+		for i, s := range cfg.PublicAddresses {
+			addr, err := ma.NewMultiaddr(strings.TrimSpace(s))
+			if err != nil {
+				return nil, fmt.Errorf("parsing public address %s: %w", s, err)
+			}
+			addrs[i] = addr
+		}
+		return addrs, nil
+	}
+
+	var public ma.Multiaddr
+	var err error
+	switch {
+	case strings.TrimSpace(cfg.PublicIP) != "":
+		ip := net.ParseIP(cfg.PublicIP)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid public ip: %s", cfg.PublicIP)
+		}
+		logger.Debugf("using config PublicIP: %s", ip)
+		public, err = ma.NewMultiaddr(fmt.Sprintf("/ip4/%s", ip))
+		if err != nil {
+			return nil, err
+		}
+	case strings.TrimSpace(cfg.PublicDNS) != "":
+		logger.Debugf("using config PublicDNS: %s", cfg.PublicDNS)
+		public, err = ma.NewMultiaddr(fmt.Sprintf("/dns/%s", cfg.PublicDNS))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		ip, err := pubip.Get()
+		if err != nil {
+			logger.Errorf("failed to get public IP error: %v", err)
+			return nil, nil
+		}
+		logger.Debugf("got public IP address %s", ip)
+		public, err = ma.NewMultiaddr(fmt.Sprintf("/ip4/%s", ip))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	addrs := make([]ma.Multiaddr, 0, len(listenAddrs))
+	for _, addr := range listenAddrs {
+		// replace the /ip4, /ip6 or /dns component of the listen address
+		_, rest := ma.SplitFirst(addr)
+		if rest == nil {
+			continue
+		}
+		addrs = append(addrs, public.Encapsulate(rest))
+	}
+	return addrs, nil
+}
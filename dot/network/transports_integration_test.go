@@ -0,0 +1,90 @@
+//go:build integration
+
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectTransports(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		transport  string
+		listenAddr string
+		publicAddr string
+	}{
+		"tcp": {
+			transport:  TCPTransport,
+			listenAddr: "/ip4/127.0.0.1/tcp/%d",
+			publicAddr: "/ip4/10.0.5.2/tcp/%d",
+		},
+		"quic": {
+			transport:  QUICTransport,
+			listenAddr: "/ip4/127.0.0.1/udp/%d/quic-v1",
+			publicAddr: "/ip4/10.0.5.2/udp/%d/quic-v1",
+		},
+		"websocket": {
+			transport:  WebSocketTransport,
+			listenAddr: "/ip4/127.0.0.1/tcp/%d/ws",
+			publicAddr: "/ip4/10.0.5.2/tcp/%d/ws",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			newConfig := func(transport, listenAddr string) *Config {
+				port := availablePort(t)
+				return &Config{
+					BasePath:        t.TempDir(),
+					Port:            port,
+					ListenAddresses: []string{fmt.Sprintf(listenAddr, port)},
+					PublicIP:        "10.0.5.2",
+					Transports:      []string{transport},
+					NoBootstrap:     true,
+					NoMDNS:          true,
+				}
+			}
+
+			// the node only listening on TCP dials the peer with the transport it listens on
+			nodeA := createTestService(t, newConfig(TCPTransport, "/ip4/127.0.0.1/tcp/%d"))
+			nodeA.noGossip = true
+			nodeB := createTestService(t, newConfig(testCase.transport, testCase.listenAddr))
+			nodeB.noGossip = true
+
+			// the listen address is advertised with the public IP
+			addrInfoB := addrInfo(nodeB.host)
+			require.Contains(t, addrInfoB.Addrs, mustNewMultiAddr(
+				fmt.Sprintf(testCase.listenAddr, nodeB.cfg.Port)))
+			require.Contains(t, addrInfoB.Addrs, mustNewMultiAddr(
+				fmt.Sprintf(testCase.publicAddr, nodeB.cfg.Port)))
+
+			err := nodeA.host.connect(addrInfoB)
+			// retry connect if "failed to dial" error
+			if failedToDial(err) {
+				time.Sleep(TestBackoffTimeout)
+				err = nodeA.host.connect(addrInfoB)
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, 1, nodeA.host.peerCount())
+			require.Equal(t, 1, nodeB.host.peerCount())
+
+			conns := nodeA.host.p2pHost.Network().ConnsToPeer(nodeB.host.id())
+			require.Len(t, conns, 1)
+			transport, err := transportOf(conns[0].RemoteMultiaddr())
+			require.NoError(t, err)
+			require.Equal(t, testCase.transport, transport)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkTransports(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		transports []string
+		errWrapped error
+	}{
+		"all_transports": {
+			transports: []string{TCPTransport, QUICTransport, " ws"},
+		},
+		"unknown_transport": {
+			transports: []string{TCPTransport, "webrtc"},
+			errWrapped: errUnknownTransport,
+		},
+		"no_transport": {
+			errWrapped: errNoTransport,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkTransports(testCase.transports)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+		})
+	}
+}
+
+func Test_listenAddresses(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		cfg        *Config
+		addrs      []string
+		errWrapped error
+	}{
+		"default_addresses": {
+			cfg: &Config{
+				Port:       7001,
+				Transports: []string{TCPTransport, QUICTransport, WebSocketTransport},
+			},
+			addrs: []string{"/ip4/0.0.0.0/tcp/7001", "/ip4/0.0.0.0/udp/7001/quic-v1"},
+		},
+		"configured_addresses": {
+			cfg: &Config{
+				Port:            7001,
+				ListenAddress:   "/ip4/127.0.0.1/tcp/7002",
+				ListenAddresses: []string{"/ip4/0.0.0.0/tcp/7003/ws", " /ip6/::/udp/7001/quic-v1"},
+				Transports:      []string{TCPTransport, QUICTransport, WebSocketTransport},
+			},
+			addrs: []string{"/ip4/127.0.0.1/tcp/7002", "/ip4/0.0.0.0/tcp/7003/ws", "/ip6/::/udp/7001/quic-v1"},
+		},
+		"transport_not_enabled": {
+			cfg: &Config{
+				ListenAddresses: []string{"/ip4/0.0.0.0/tcp/7003/ws"},
+				Transports:      []string{TCPTransport},
+			},
+			errWrapped: errTransportNotEnabled,
+		},
+		"unsupported_address": {
+			cfg: &Config{
+				ListenAddresses: []string{"/ip4/0.0.0.0/udp/7001"},
+				Transports:      []string{TCPTransport, QUICTransport},
+			},
+			errWrapped: errUnsupportedListenAddress,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			addrs, err := listenAddresses(testCase.cfg)
+			require.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				return
+			}
+
+			expected := make([]ma.Multiaddr, len(testCase.addrs))
+			for i, addr := range testCase.addrs {
+				expected[i] = mustNewMultiAddr(addr)
+			}
+			assert.Equal(t, expected, addrs)
+		})
+	}
+}
+
+func Test_externalAddresses(t *testing.T) {
+	t.Parallel()
+
+	listenAddrs := []ma.Multiaddr{
+		mustNewMultiAddr("/ip4/0.0.0.0/tcp/7001"),
+		mustNewMultiAddr("/ip4/0.0.0.0/udp/7001/quic-v1"),
+		mustNewMultiAddr("/ip4/0.0.0.0/tcp/7002/ws"),
+	}
+
+	testCases := map[string]struct {
+		cfg   *Config
+		addrs []string
+	}{
+		"public_ip": {
+			cfg: &Config{PublicIP: "10.0.5.2"},
+			addrs: []string{
+				"/ip4/10.0.5.2/tcp/7001",
+				"/ip4/10.0.5.2/udp/7001/quic-v1",
+				"/ip4/10.0.5.2/tcp/7002/ws",
+			},
+		},
+		"public_dns": {
+			cfg: &Config{PublicDNS: "alice"},
+			addrs: []string{
+				"/dns/alice/tcp/7001",
+				"/dns/alice/udp/7001/quic-v1",
+				"/dns/alice/tcp/7002/ws",
+			},
+		},
+		"public_addresses": {
+			cfg: &Config{
+				PublicIP:        "10.0.5.2",
+				PublicAddresses: []string{"/dns/alice/tcp/443/wss"},
+			},
+			addrs: []string{"/dns/alice/tcp/443/wss"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			addrs, err := externalAddresses(testCase.cfg, listenAddrs)
+			require.NoError(t, err)
+
+			expected := make([]ma.Multiaddr, len(testCase.addrs))
+			for i, addr := range testCase.addrs {
+				expected[i] = mustNewMultiAddr(addr)
+			}
+			assert.Equal(t, expected, addrs)
+		})
+	}
+}
@@ -93,7 +93,8 @@ func (s *Service) handleWarpSyncMessage(stream libp2pnetwork.Stream, msg message
 			return nil
 		}
 
-		if _, err = stream.Write(resp); err != nil {
+		err = s.host.writeLimited(stream, resp)
+		if err != nil {
 			logger.Debugf("failed to send WarpSyncResponse message to peer %s: %s", stream.Conn().RemotePeer(), err)
 			return err
 		}
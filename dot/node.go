@@ -35,6 +35,7 @@
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/lib/services"
+	"github.com/benbjohnson/clock"
 )
 
 var logger = log.NewFromGlobal(log.AddContext("pkg", "dot"))
@@ -73,7 +74,21 @@ type nodeBuilderIface interface {
 
 var _ nodeBuilderIface = (*nodeBuilder)(nil)
 
-type nodeBuilder struct{}
+type nodeBuilder struct {
+	options NodeOptions
+}
+
+// NodeOptions are the options of a node which are not part of its configuration.
+// They allow several nodes to run in the same process, for example on a simulated network.
+type NodeOptions struct {
+	// InMemory keeps the state of the node in an in-memory database, initialised from the genesis
+	InMemory bool
+	// Clock is the time source of the BABE slots and of the GRANDPA rounds, the system clock if nil
+	Clock clock.Clock
+	// Libp2pHost creates the libp2p host of the network service, a host listening
+	// on the configured addresses is created if nil
+	Libp2pHost network.Libp2pHostFunc
+}
 
 // IsNodeInitialised returns true if, within the configured data directory for the
 // node, the state database has been created and the genesis data can been loaded
@@ -230,18 +245,26 @@ func LoadGlobalNodeName(basepath string) (nodename string, err error) {
 
 // NewNode creates a node based on the given Config and key store.
 func NewNode(config *cfg.Config, ks *keystore.GlobalKeystore) (*Node, error) {
-	serviceRegistryLogger := logger.New(log.AddContext("pkg", "services"))
+	return NewNodeWithOptions(config, ks, NodeOptions{})
+}
 
-	isInitialised, err := IsNodeInitialised(config.BasePath)
-	if err != nil {
-		return nil, fmt.Errorf("checking if node is initialised: %w", err)
-	}
+// NewNodeWithOptions creates a node based on the given Config, key store and options.
+// A node kept in memory is initialised from the genesis each time it is created.
+func NewNodeWithOptions(config *cfg.Config, ks *keystore.GlobalKeystore, options NodeOptions) (*Node, error) {
+	serviceRegistryLogger := logger.New(log.AddContext("pkg", "services"))
 
-	builder := &nodeBuilder{}
-	if !isInitialised {
-		err := builder.initNode(config)
+	builder := &nodeBuilder{options: options}
+	if !options.InMemory {
+		isInitialised, err := IsNodeInitialised(config.BasePath)
 		if err != nil {
-			return nil, fmt.Errorf("cannot initialise node: %w", err)
+			return nil, fmt.Errorf("checking if node is initialised: %w", err)
+		}
+
+		if !isInitialised {
+			err := builder.initNode(config)
+			if err != nil {
+				return nil, fmt.Errorf("cannot initialise node: %w", err)
+			}
 		}
 	}
 
@@ -505,6 +528,11 @@ func (n *Node) Start() error {
 	return nil
 }
 
+// Started returns a channel closed once the node services are started
+func (n *Node) Started() <-chan struct{} {
+	return n.started
+}
+
 // Stop stops all dot node services
 func (n *Node) Stop() {
 	// stop all node services
@@ -68,6 +68,21 @@
 	// BadJustificationReason is used when peer send invalid justification.
 	BadJustificationReason = "Bad justification"
 
+	// GoodCatchUpResponseValue is used when a peer sends a valid grandpa catch up response.
+	GoodCatchUpResponseValue Reputation = 1 << 7
+	// GoodCatchUpResponseReason is used when a peer sends a valid grandpa catch up response.
+	GoodCatchUpResponseReason = "Good catch up response"
+
+	// BadCatchUpResponseValue is used when a peer sends an invalid grandpa catch up response.
+	BadCatchUpResponseValue Reputation = -(1 << 12)
+	// BadCatchUpResponseReason is used when a peer sends an invalid grandpa catch up response.
+	BadCatchUpResponseReason = "Bad catch up response"
+
+	// UnexpectedCatchUpResponseValue is used when a peer sends a grandpa catch up response we did not request.
+	UnexpectedCatchUpResponseValue Reputation = -(1 << 8)
+	// UnexpectedCatchUpResponseReason is used when a peer sends a grandpa catch up response we did not request.
+	UnexpectedCatchUpResponseReason = "Unexpected catch up response"
+
 	// GenesisMismatch is used when peer has a different genesis
 	GenesisMismatch Reputation = math.MinInt32
 	// GenesisMismatchReason used when a peer has a different genesis
@@ -76,4 +91,12 @@
 	// SameBlockSyncRequest used when a peer send us more than the max number of the same request.
 	SameBlockSyncRequest       Reputation = math.MinInt32
 	SameBlockSyncRequestReason            = "same block sync request"
+
+	// ExcessiveBlockRequestsValue is used when a peer exceeds the block requests rate limit.
+	ExcessiveBlockRequestsValue Reputation = -(1 << 12)
+	// ExcessiveBlockRequestsReason is used when a peer exceeds the block requests rate limit.
+	ExcessiveBlockRequestsReason = "Excessive block requests"
+
+	// RestoredReputationReason is used when the reputation of a peer is restored from the peer book.
+	RestoredReputationReason = "Restored reputation"
 )
@@ -57,6 +57,30 @@ func (h *Handler) SetReservedPeer(setID int, peers ...peer.ID) {
 	}
 }
 
+// SetReservedOnly switches the reserved-only mode of the peerSet.
+func (h *Handler) SetReservedOnly(setID int, reservedOnly bool) {
+	h.actionQueue <- action{
+		actionCall:   setReservedOnly,
+		setID:        setID,
+		reservedOnly: reservedOnly,
+	}
+}
+
+// IsReservedOnly returns true if the peerSet only accepts and connects to reserved peers.
+func (h *Handler) IsReservedOnly() bool {
+	return h.peerSet.isReservedOnly.Load()
+}
+
+// IsReserved returns true if the given peer is a reserved peer.
+func (h *Handler) IsReserved(peerID peer.ID) bool {
+	return h.peerSet.isReserved(peerID)
+}
+
+// ReservedPeers returns the reserved peers of the peerSet.
+func (h *Handler) ReservedPeers() peer.IDSlice {
+	return h.peerSet.reservedPeers()
+}
+
 // AddPeer adds peer to peerSet.
 func (h *Handler) AddPeer(setID int, peers ...peer.ID) {
 	h.actionQueue <- action{
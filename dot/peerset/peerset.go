@@ -10,6 +10,7 @@
 	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ChainSafe/gossamer/internal/log"
@@ -41,7 +42,7 @@
 	removeReservedPeer
 	// setReservedPeers is for setting peerList in peerSet reserved peers
 	setReservedPeers
-	// setReservedOnly is for setting peerList in peerSet reserved peers only
+	// setReservedOnly is for switching the peerSet in or out of reserved-only mode
 	setReservedOnly
 	// reportPeer is for reporting peers if it misbehaves
 	reportPeer
@@ -90,6 +91,7 @@ type action struct {
 	setID         int
 	reputation    ReputationChange
 	peers         peer.IDSlice
+	reservedOnly  bool
 	resultPeersCh chan peer.IDSlice
 }
 
@@ -98,8 +100,8 @@ func (a action) String() string {
 	for i := range a.peers {
 		peersStrings[i] = a.peers[i].String()
 	}
-	return fmt.Sprintf("{call=%s, set-id=%d, reputation change %v, peers=[%s]",
-		a.actionCall.String(), a.setID, a.reputation, strings.Join(peersStrings, ", "))
+	return fmt.Sprintf("{call=%s, set-id=%d, reputation change %v, reserved-only=%t, peers=[%s]",
+		a.actionCall.String(), a.setID, a.reputation, a.reservedOnly, strings.Join(peersStrings, ", "))
 }
 
 // Status represents the enum value for Message
@@ -175,9 +177,9 @@ type PeerSet struct {
 
 	reservedLock sync.RWMutex
 	reservedNode map[peer.ID]struct{}
-	// TODO: this will be useful for reserved only mode
-	// this is for future purpose if reserved-only flag is enabled (#1888).
-	isReservedOnly bool
+	// isReservedOnly when set, only reserved nodes are connected to and
+	// accepted, every other peer is rejected or dropped.
+	isReservedOnly atomic.Bool
 
 	// resultMsgCh is read by network.Service.
 	resultMsgCh chan Message
@@ -199,8 +201,7 @@ type config struct {
 	// maximum number of slot occupying nodes for outgoing connections.
 	maxOutPeers uint32
 
-	// TODO Use in future for reserved only peers
-	// if true, we only accept reservedNodes (#1888).
+	// if true, we only accept and connect to reservedNodes.
 	reservedOnly bool
 
 	// time duration for a peerSet to periodically call allocSlots.
@@ -245,11 +246,11 @@ func newPeerSet(cfg *ConfigSet) (*PeerSet, error) {
 	ps := &PeerSet{
 		peerState:              peerState,
 		reservedNode:           make(map[peer.ID]struct{}),
-		isReservedOnly:         cfgSet.reservedOnly,
 		created:                now,
 		latestTimeUpdate:       now,
 		nextPeriodicAllocSlots: cfgSet.periodicAllocTime,
 	}
+	ps.isReservedOnly.Store(cfgSet.reservedOnly)
 
 	return ps, nil
 }
@@ -412,7 +413,7 @@ func (ps *PeerSet) allocSlots(setIdx int) error {
 	}
 
 	// nothing more to do if we're in reserved mode.
-	if ps.isReservedOnly {
+	if ps.isReservedOnly.Load() {
 		return nil
 	}
 
@@ -492,13 +493,12 @@ func (ps *PeerSet) removeReservedPeers(setID int, peers ...peer.ID) error {
 		}
 
 		// nothing more to do if not in reservedOnly mode.
-		if !ps.isReservedOnly {
-			return nil
+		if !ps.isReservedOnly.Load() {
+			continue
 		}
 
-		// reservedOnly mode is not yet implemented for future this code will help.
-		// If however the peerSet is in reserved-only mode, then non-reserved node peers needs to be
-		// disconnected.
+		// If however the peerSet is in reserved-only mode, then the peer is no longer
+		// reserved and needs to be disconnected.
 		if ps.peerState.peerStatus(setID, peerID) == connectedPeer {
 			err := ps.peerState.disconnect(setID, peerID)
 			if err != nil {
@@ -550,6 +550,60 @@ func (ps *PeerSet) setReservedPeer(setID int, peers ...peer.ID) error {
 	return nil
 }
 
+// setReservedOnly switches the reserved-only mode of the peerSet. Enabling it drops every
+// connected peer which is not reserved, disabling it allocates the free slots again.
+func (ps *PeerSet) setReservedOnly(setID int, reservedOnly bool) error {
+	if ps.isReservedOnly.Swap(reservedOnly) == reservedOnly {
+		return nil
+	}
+
+	if !reservedOnly {
+		return ps.allocSlots(setID)
+	}
+
+	ps.reservedLock.RLock()
+	defer ps.reservedLock.RUnlock()
+
+	for _, pid := range ps.peerState.sortedPeers(setID) {
+		if _, ok := ps.reservedNode[pid]; ok {
+			continue
+		}
+
+		err := ps.peerState.disconnect(setID, pid)
+		if err != nil {
+			return fmt.Errorf("cannot disconnect: %w", err)
+		}
+
+		ps.resultMsgCh <- Message{
+			Status: Drop,
+			setID:  uint64(setID), //nolint:gosec
+			PeerID: pid,
+		}
+	}
+
+	return nil
+}
+
+// isReserved returns true if the given peer is one of the reserved nodes.
+func (ps *PeerSet) isReserved(pid peer.ID) bool {
+	ps.reservedLock.RLock()
+	defer ps.reservedLock.RUnlock()
+	_, ok := ps.reservedNode[pid]
+	return ok
+}
+
+// reservedPeers returns the list of reserved nodes.
+func (ps *PeerSet) reservedPeers() peer.IDSlice {
+	ps.reservedLock.RLock()
+	defer ps.reservedLock.RUnlock()
+
+	peers := make(peer.IDSlice, 0, len(ps.reservedNode))
+	for pid := range ps.reservedNode {
+		peers = append(peers, pid)
+	}
+	return peers
+}
+
 // addPeer checks peer existence in peerSet and if it does not insert the peer in to peerstate with
 // default reputation and notConnected status. Afterwards runs allocSlots that checks availability of outgoing slots
 // and put notConnected peers in to them
@@ -609,9 +663,8 @@ func (ps *PeerSet) incoming(setID int, peers ...peer.ID) error {
 	}
 
 	for _, pid := range peers {
-		if ps.isReservedOnly {
-			_, has := ps.reservedNode[pid]
-			if !has {
+		if ps.isReservedOnly.Load() {
+			if !ps.isReserved(pid) {
 				ps.resultMsgCh <- Message{
 					Status: Reject,
 					setID:  uint64(setID), //nolint:gosec
@@ -764,8 +817,7 @@ func (ps *PeerSet) listenActionAllocSlots(ctx context.Context) {
 				// TODO: this is not used yet, might required to implement RPC Call for this.
 				err = ps.setReservedPeer(act.setID, act.peers...)
 			case setReservedOnly:
-				// TODO: not yet implemented (#1888)
-				err = fmt.Errorf("not implemented yet")
+				err = ps.setReservedOnly(act.setID, act.reservedOnly)
 			case reportPeer:
 				err = ps.reportPeer(act.reputation, act.peers...)
 			case addToPeerSet:
@@ -314,6 +314,60 @@ func TestSetReservePeer(t *testing.T) {
 	}
 }
 
+func TestReservedOnlyRejectsNonReservedPeers(t *testing.T) {
+	const testSetID = 0
+
+	t.Parallel()
+	handler := newTestPeerSet(t, 2, 2, nil, []peer.ID{reservedPeer}, true)
+
+	ps := handler.peerSet
+	checkMessageStatus(t, <-ps.resultMsgCh, Connect)
+	require.True(t, handler.IsReservedOnly())
+	require.Equal(t, peer.IDSlice{reservedPeer}, handler.ReservedPeers())
+
+	// discovered peers are known but never dialled in reserved-only mode
+	handler.AddPeer(testSetID, discovered1)
+	time.Sleep(100 * time.Millisecond)
+	checkNodePeerExists(t, ps.peerState, discovered1)
+	require.Equal(t, notConnectedPeer, ps.peerState.peerStatus(testSetID, discovered1))
+	require.Len(t, ps.resultMsgCh, 0)
+
+	handler.Incoming(testSetID, incomingPeer)
+	checkMessageStatus(t, <-ps.resultMsgCh, Reject)
+	checkPeerStateSetNumIn(t, ps.peerState, testSetID, 0)
+}
+
+func TestSetReservedOnly(t *testing.T) {
+	const testSetID = 0
+
+	t.Parallel()
+	handler := newTestPeerSet(t, 2, 2, []peer.ID{discovered1}, []peer.ID{reservedPeer}, false)
+
+	ps := handler.peerSet
+	require.Len(t, ps.resultMsgCh, 2)
+	for len(ps.resultMsgCh) != 0 {
+		checkMessageStatus(t, <-ps.resultMsgCh, Connect)
+	}
+
+	handler.SetReservedOnly(testSetID, true)
+	time.Sleep(100 * time.Millisecond)
+
+	require.True(t, handler.IsReservedOnly())
+	msg := <-ps.resultMsgCh
+	checkMessageStatus(t, msg, Drop)
+	require.Equal(t, discovered1, msg.PeerID)
+	require.Equal(t, notConnectedPeer, ps.peerState.peerStatus(testSetID, discovered1))
+	require.Equal(t, connectedPeer, ps.peerState.peerStatus(testSetID, reservedPeer))
+
+	handler.SetReservedOnly(testSetID, false)
+	time.Sleep(100 * time.Millisecond)
+
+	require.False(t, handler.IsReservedOnly())
+	msg = <-ps.resultMsgCh
+	checkMessageStatus(t, msg, Connect)
+	require.Equal(t, discovered1, msg.PeerID)
+}
+
 func getNodePeer(ps *PeersState, pid peer.ID) (node, bool) {
 	ps.RLock()
 	defer ps.RUnlock()
@@ -0,0 +1,41 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"fmt"
+
+	cfg "github.com/ChainSafe/gossamer/config"
+	"github.com/ChainSafe/gossamer/dot/telemetry"
+	"github.com/ChainSafe/gossamer/dot/types"
+)
+
+// RevertBlocks reverts the chain of the node with the given configuration by the given number of
+// blocks from its best block, removing the reverted blocks from the database and restoring the
+// GRANDPA authority set, the BABE epoch data and the finalised block at the new best block.
+// Finalised blocks are only reverted if revertFinalised is set, in which case the new best block
+// becomes the finalised block. It returns the new best block header.
+func RevertBlocks(config *cfg.Config, blocks uint, revertFinalised bool) (best *types.Header, err error) {
+	builder := nodeBuilder{}
+	stateSrvc, err := builder.createStateService(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state service: %s", err)
+	}
+
+	// the finalisation of the new best block is not announced, there is no telemetry to send
+	stateSrvc.Telemetry = telemetry.NewNoopMailer()
+
+	err = startStateService(*config.State, stateSrvc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		stopErr := stateSrvc.Stop()
+		if err == nil && stopErr != nil {
+			err = fmt.Errorf("stopping state service: %w", stopErr)
+		}
+	}()
+
+	return stateSrvc.Revert(blocks, revertFinalised)
+}
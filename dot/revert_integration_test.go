@@ -0,0 +1,69 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+//go:build integration
+
+package dot
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/telemetry"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertBlocks(t *testing.T) {
+	config := DefaultTestWestendDevConfig(t)
+	config.ChainSpec = NewTestGenesisRawFile(t, config)
+
+	err := InitNode(config)
+	require.NoError(t, err)
+
+	startState := func() *state.Service {
+		stateSrvc, err := nodeBuilder{}.createStateService(config)
+		require.NoError(t, err)
+		stateSrvc.Telemetry = telemetry.NewNoopMailer()
+		err = startStateService(*config.State, stateSrvc)
+		require.NoError(t, err)
+		return stateSrvc
+	}
+
+	stateSrvc := startState()
+	chain, _ := state.AddBlocksToState(t, stateSrvc.Block, 8, false)
+	err = stateSrvc.Block.SetFinalisedHash(chain[4].Hash(), 1, 0)
+	require.NoError(t, err)
+	err = stateSrvc.Stop()
+	require.NoError(t, err)
+
+	// the blocks not finalised are not kept by the stopped node, its best block is #5
+	_, err = RevertBlocks(config, 3, false)
+	require.ErrorIs(t, err, state.ErrRevertFinalised)
+
+	// the refused revert leaves the chain untouched
+	stateSrvc = startState()
+	require.Equal(t, chain[4].Hash(), stateSrvc.Block.BestBlockHash())
+	err = stateSrvc.Stop()
+	require.NoError(t, err)
+
+	best, err := RevertBlocks(config, 3, true)
+	require.NoError(t, err)
+	require.Equal(t, chain[1].Hash(), best.Hash())
+
+	stateSrvc = startState()
+	t.Cleanup(func() {
+		err := stateSrvc.Stop()
+		require.NoError(t, err)
+	})
+
+	require.Equal(t, chain[1].Hash(), stateSrvc.Block.BestBlockHash())
+	finalised, err := stateSrvc.Block.GetHighestFinalisedHash()
+	require.NoError(t, err)
+	require.Equal(t, chain[1].Hash(), finalised)
+
+	for _, header := range chain[2:5] {
+		_, err = stateSrvc.Block.GetHeader(header.Hash())
+		require.ErrorIs(t, err, database.ErrNotFound)
+	}
+}
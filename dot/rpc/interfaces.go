@@ -5,6 +5,7 @@
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/ChainSafe/gossamer/dot/core"
 	"github.com/ChainSafe/gossamer/dot/state"
@@ -62,6 +63,13 @@ type NetworkAPI interface {
 	StartingBlock() int64
 	AddReservedPeers(addrs ...string) error
 	RemoveReservedPeers(addrs ...string) error
+	ReservedPeers() []string
+	IsReservedOnly() bool
+	SetReservedOnly(reservedOnly bool)
+	KnownPeers() []common.KnownPeerInfo
+	BanPeer(peerID string, duration time.Duration) error
+	UnbanPeer(peerID string) error
+	Bandwidth() common.NetworkBandwidth
 }
 
 // BlockProducerAPI is the interface for BlockProducer methods
@@ -123,6 +131,10 @@ type SyncStateAPI interface {
 // SyncAPI is the interface to interact with the sync service
 type SyncAPI interface {
 	HighestBlock() uint
+	PeerStats() []common.SyncPeerStats
+	SyncProgress() common.SyncProgress
+	AddBadBlock(hash common.Hash)
+	AddForkBlock(number uint, hash common.Hash)
 }
 
 // Telemetry is the telemetry client to send telemetry messages.
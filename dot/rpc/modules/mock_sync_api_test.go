@@ -12,6 +12,7 @@
 import (
 	reflect "reflect"
 
+	common "github.com/ChainSafe/gossamer/lib/common"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -38,6 +39,30 @@ func (m *MockSyncAPI) EXPECT() *MockSyncAPIMockRecorder {
 	return m.recorder
 }
 
+// AddBadBlock mocks base method.
+func (m *MockSyncAPI) AddBadBlock(arg0 common.Hash) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddBadBlock", arg0)
+}
+
+// AddBadBlock indicates an expected call of AddBadBlock.
+func (mr *MockSyncAPIMockRecorder) AddBadBlock(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBadBlock", reflect.TypeOf((*MockSyncAPI)(nil).AddBadBlock), arg0)
+}
+
+// AddForkBlock mocks base method.
+func (m *MockSyncAPI) AddForkBlock(arg0 uint, arg1 common.Hash) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddForkBlock", arg0, arg1)
+}
+
+// AddForkBlock indicates an expected call of AddForkBlock.
+func (mr *MockSyncAPIMockRecorder) AddForkBlock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddForkBlock", reflect.TypeOf((*MockSyncAPI)(nil).AddForkBlock), arg0, arg1)
+}
+
 // HighestBlock mocks base method.
 func (m *MockSyncAPI) HighestBlock() uint {
 	m.ctrl.T.Helper()
@@ -51,3 +76,31 @@ func (mr *MockSyncAPIMockRecorder) HighestBlock() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HighestBlock", reflect.TypeOf((*MockSyncAPI)(nil).HighestBlock))
 }
+
+// PeerStats mocks base method.
+func (m *MockSyncAPI) PeerStats() []common.SyncPeerStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeerStats")
+	ret0, _ := ret[0].([]common.SyncPeerStats)
+	return ret0
+}
+
+// PeerStats indicates an expected call of PeerStats.
+func (mr *MockSyncAPIMockRecorder) PeerStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeerStats", reflect.TypeOf((*MockSyncAPI)(nil).PeerStats))
+}
+
+// SyncProgress mocks base method.
+func (m *MockSyncAPI) SyncProgress() common.SyncProgress {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncProgress")
+	ret0, _ := ret[0].(common.SyncProgress)
+	return ret0
+}
+
+// SyncProgress indicates an expected call of SyncProgress.
+func (mr *MockSyncAPIMockRecorder) SyncProgress() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncProgress", reflect.TypeOf((*MockSyncAPI)(nil).SyncProgress))
+}
@@ -11,6 +11,7 @@
 
 import (
 	reflect "reflect"
+	time "time"
 
 	core "github.com/ChainSafe/gossamer/dot/core"
 	state "github.com/ChainSafe/gossamer/dot/state"
@@ -470,6 +471,34 @@ func (mr *MockNetworkAPIMockRecorder) AddReservedPeers(arg0 ...any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReservedPeers", reflect.TypeOf((*MockNetworkAPI)(nil).AddReservedPeers), arg0...)
 }
 
+// BanPeer mocks base method.
+func (m *MockNetworkAPI) BanPeer(arg0 string, arg1 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanPeer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BanPeer indicates an expected call of BanPeer.
+func (mr *MockNetworkAPIMockRecorder) BanPeer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanPeer", reflect.TypeOf((*MockNetworkAPI)(nil).BanPeer), arg0, arg1)
+}
+
+// Bandwidth mocks base method.
+func (m *MockNetworkAPI) Bandwidth() common.NetworkBandwidth {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bandwidth")
+	ret0, _ := ret[0].(common.NetworkBandwidth)
+	return ret0
+}
+
+// Bandwidth indicates an expected call of Bandwidth.
+func (mr *MockNetworkAPIMockRecorder) Bandwidth() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bandwidth", reflect.TypeOf((*MockNetworkAPI)(nil).Bandwidth))
+}
+
 // Health mocks base method.
 func (m *MockNetworkAPI) Health() common.Health {
 	m.ctrl.T.Helper()
@@ -484,6 +513,34 @@ func (mr *MockNetworkAPIMockRecorder) Health() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockNetworkAPI)(nil).Health))
 }
 
+// IsReservedOnly mocks base method.
+func (m *MockNetworkAPI) IsReservedOnly() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsReservedOnly")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsReservedOnly indicates an expected call of IsReservedOnly.
+func (mr *MockNetworkAPIMockRecorder) IsReservedOnly() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsReservedOnly", reflect.TypeOf((*MockNetworkAPI)(nil).IsReservedOnly))
+}
+
+// KnownPeers mocks base method.
+func (m *MockNetworkAPI) KnownPeers() []common.KnownPeerInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KnownPeers")
+	ret0, _ := ret[0].([]common.KnownPeerInfo)
+	return ret0
+}
+
+// KnownPeers indicates an expected call of KnownPeers.
+func (mr *MockNetworkAPIMockRecorder) KnownPeers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KnownPeers", reflect.TypeOf((*MockNetworkAPI)(nil).KnownPeers))
+}
+
 // NetworkState mocks base method.
 func (m *MockNetworkAPI) NetworkState() common.NetworkState {
 	m.ctrl.T.Helper()
@@ -544,6 +601,32 @@ func (mr *MockNetworkAPIMockRecorder) RemoveReservedPeers(arg0 ...any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReservedPeers", reflect.TypeOf((*MockNetworkAPI)(nil).RemoveReservedPeers), arg0...)
 }
 
+// ReservedPeers mocks base method.
+func (m *MockNetworkAPI) ReservedPeers() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReservedPeers")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// ReservedPeers indicates an expected call of ReservedPeers.
+func (mr *MockNetworkAPIMockRecorder) ReservedPeers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReservedPeers", reflect.TypeOf((*MockNetworkAPI)(nil).ReservedPeers))
+}
+
+// SetReservedOnly mocks base method.
+func (m *MockNetworkAPI) SetReservedOnly(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetReservedOnly", arg0)
+}
+
+// SetReservedOnly indicates an expected call of SetReservedOnly.
+func (mr *MockNetworkAPIMockRecorder) SetReservedOnly(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReservedOnly", reflect.TypeOf((*MockNetworkAPI)(nil).SetReservedOnly), arg0)
+}
+
 // Start mocks base method.
 func (m *MockNetworkAPI) Start() error {
 	m.ctrl.T.Helper()
@@ -586,6 +669,20 @@ func (mr *MockNetworkAPIMockRecorder) Stop() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockNetworkAPI)(nil).Stop))
 }
 
+// UnbanPeer mocks base method.
+func (m *MockNetworkAPI) UnbanPeer(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanPeer", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanPeer indicates an expected call of UnbanPeer.
+func (mr *MockNetworkAPIMockRecorder) UnbanPeer(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanPeer", reflect.TypeOf((*MockNetworkAPI)(nil).UnbanPeer), arg0)
+}
+
 // MockBlockProducerAPI is a mock of BlockProducerAPI interface.
 type MockBlockProducerAPI struct {
 	ctrl     *gomock.Controller
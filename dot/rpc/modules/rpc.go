@@ -12,6 +12,12 @@
 	UnsafeMethods = []string{
 		"system_addReservedPeer",
 		"system_removeReservedPeer",
+		"system_reservedPeers",
+		"system_knownPeers",
+		"system_banPeer",
+		"system_unbanPeer",
+		"system_addBadBlock",
+		"system_addForkBlock",
 		"author_submitExtrinsic",
 		"author_removeExtrinsic",
 		"author_insertKey",
@@ -9,9 +9,11 @@
 	"math/big"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/crypto"
+	"github.com/ChainSafe/gossamer/lib/genesis"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 	"github.com/btcsuite/btcutil/base58"
 	ctypes "github.com/centrifuge/go-substrate-rpc-client/v4/types"
@@ -67,6 +69,93 @@ type SyncStateResponse struct {
 	StartingBlock uint32 `json:"startingBlock"`
 }
 
+// SyncProgressResponse is the struct to return on the system_syncProgress rpc call
+type SyncProgressResponse struct {
+	StartingBlock uint32  `json:"startingBlock"`
+	CurrentBlock  uint32  `json:"currentBlock"`
+	HighestBlock  uint32  `json:"highestBlock"`
+	ImportRate    float64 `json:"importRate"`
+	EtaSeconds    uint64  `json:"etaSeconds"`
+	Stalled       bool    `json:"stalled"`
+	LastProgress  int64   `json:"lastProgress,omitempty"`
+}
+
+// ReservedPeersRequest holds the optional reserved-only mode to switch to
+type ReservedPeersRequest struct {
+	ReservedOnly *bool
+}
+
+// ReservedPeersResponse is the struct to return on the system_reservedPeers rpc call
+type ReservedPeersResponse struct {
+	ReservedOnly bool     `json:"reservedOnly"`
+	Peers        []string `json:"peers"`
+}
+
+// KnownPeerResponse is a peer of the peer book returned on the system_knownPeers rpc call
+type KnownPeerResponse struct {
+	PeerID      string   `json:"peerId"`
+	Addrs       []string `json:"addrs"`
+	LastSeen    int64    `json:"lastSeen"`
+	Successes   uint32   `json:"successes"`
+	Failures    uint32   `json:"failures"`
+	Reputation  int32    `json:"reputation"`
+	BannedUntil int64    `json:"bannedUntil,omitempty"`
+}
+
+// SyncPeerStatsResponse is the statistics of a sync peer returned on the system_syncPeerStats rpc call
+type SyncPeerStatsResponse struct {
+	PeerID         string  `json:"peerId"`
+	Requests       uint64  `json:"requests"`
+	Failures       uint64  `json:"failures"`
+	BlocksServed   uint64  `json:"blocksServed"`
+	LatencyMs      int64   `json:"latencyMs"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
+}
+
+// NetworkBandwidthResponse is the struct to return on the system_networkBandwidth rpc call
+type NetworkBandwidthResponse struct {
+	TotalIn   uint64                      `json:"totalIn"`
+	TotalOut  uint64                      `json:"totalOut"`
+	RateIn    float64                     `json:"rateIn"`
+	RateOut   float64                     `json:"rateOut"`
+	Protocols []ProtocolBandwidthResponse `json:"protocols"`
+	Peers     []PeerBandwidthResponse     `json:"peers"`
+}
+
+// ProtocolBandwidthResponse is the traffic of a protocol returned on the system_networkBandwidth rpc call
+type ProtocolBandwidthResponse struct {
+	Protocol        string  `json:"protocol"`
+	TotalIn         uint64  `json:"totalIn"`
+	TotalOut        uint64  `json:"totalOut"`
+	RateIn          float64 `json:"rateIn"`
+	RateOut         float64 `json:"rateOut"`
+	MessagesIn      uint64  `json:"messagesIn"`
+	MessagesOut     uint64  `json:"messagesOut"`
+	InboundStreams  uint64  `json:"inboundStreams"`
+	OutboundStreams uint64  `json:"outboundStreams"`
+}
+
+// PeerBandwidthResponse is the traffic exchanged with a peer returned on the system_networkBandwidth rpc call
+type PeerBandwidthResponse struct {
+	PeerID   string  `json:"peerId"`
+	TotalIn  uint64  `json:"totalIn"`
+	TotalOut uint64  `json:"totalOut"`
+	RateIn   float64 `json:"rateIn"`
+	RateOut  float64 `json:"rateOut"`
+}
+
+// BanPeerRequest holds the peer id to ban and the ban duration in seconds
+type BanPeerRequest struct {
+	PeerID   string
+	Duration uint64
+}
+
+// ForkBlockRequest holds the number and the hash of the fork block to add
+type ForkBlockRequest struct {
+	Number uint
+	Hash   string
+}
+
 // NewSystemModule creates a new API instance
 func NewSystemModule(net NetworkAPI, sys SystemAPI, core CoreAPI,
 	storage StorageAPI, txAPI TransactionStateAPI, blockAPI BlockAPI,
@@ -243,6 +332,68 @@ func (sm *SystemModule) SyncState(r *http.Request, req *EmptyRequest, res *SyncS
 	return nil
 }
 
+// SyncProgress returns the progress of the sync: the import rate in blocks per second, the estimated
+// number of seconds to reach the highest block announced by the peers, and whether the sync stalled.
+func (sm *SystemModule) SyncProgress(r *http.Request, req *EmptyRequest, res *SyncProgressResponse) error {
+	progress := sm.syncAPI.SyncProgress()
+
+	*res = SyncProgressResponse{
+		StartingBlock: uint32(progress.StartingBlock), //nolint:gosec
+		CurrentBlock:  uint32(progress.CurrentBlock),  //nolint:gosec
+		HighestBlock:  uint32(progress.HighestBlock),  //nolint:gosec
+		ImportRate:    progress.ImportRate,
+		EtaSeconds:    uint64(progress.ETA.Seconds()),
+		Stalled:       progress.Stalled,
+	}
+	if !progress.LastProgressAt.IsZero() {
+		res.LastProgress = progress.LastProgressAt.Unix()
+	}
+	return nil
+}
+
+// SyncPeerStats returns the statistics of the block requests made to each sync peer.
+func (sm *SystemModule) SyncPeerStats(r *http.Request, req *EmptyRequest, res *[]SyncPeerStatsResponse) error {
+	stats := sm.syncAPI.PeerStats()
+	resp := make([]SyncPeerStatsResponse, len(stats))
+	for i, s := range stats {
+		resp[i] = SyncPeerStatsResponse{
+			PeerID:         s.PeerID,
+			Requests:       s.Requests,
+			Failures:       s.Failures,
+			BlocksServed:   s.BlocksServed,
+			LatencyMs:      s.Latency.Milliseconds(),
+			BytesPerSecond: s.BytesPerSecond,
+		}
+	}
+
+	*res = resp
+	return nil
+}
+
+// NetworkBandwidth returns the bytes sent and received by the node, in total, per protocol and
+// per peer, with the rates in bytes per second, and the messages and open streams of each protocol.
+func (sm *SystemModule) NetworkBandwidth(r *http.Request, req *EmptyRequest, res *NetworkBandwidthResponse) error {
+	bandwidth := sm.networkAPI.Bandwidth()
+
+	resp := NetworkBandwidthResponse{
+		TotalIn:   bandwidth.TotalIn,
+		TotalOut:  bandwidth.TotalOut,
+		RateIn:    bandwidth.RateIn,
+		RateOut:   bandwidth.RateOut,
+		Protocols: make([]ProtocolBandwidthResponse, len(bandwidth.Protocols)),
+		Peers:     make([]PeerBandwidthResponse, len(bandwidth.Peers)),
+	}
+	for i, p := range bandwidth.Protocols {
+		resp.Protocols[i] = ProtocolBandwidthResponse(p)
+	}
+	for i, p := range bandwidth.Peers {
+		resp.Peers[i] = PeerBandwidthResponse(p)
+	}
+
+	*res = resp
+	return nil
+}
+
 // LocalListenAddresses Returns the libp2p multiaddresses that the local node is listening on
 func (sm *SystemModule) LocalListenAddresses(r *http.Request, req *EmptyRequest, res *[]string) error {
 	netstate := sm.networkAPI.NetworkState()
@@ -289,3 +440,87 @@ func (sm *SystemModule) RemoveReservedPeer(r *http.Request, req *StringRequest,
 
 	return sm.networkAPI.RemoveReservedPeers(req.String)
 }
+
+// ReservedPeers returns the reserved peers and whether the node is in reserved-only mode.
+// If the reserved-only parameter is given, the mode is switched before responding.
+func (sm *SystemModule) ReservedPeers(r *http.Request, req *ReservedPeersRequest, res *ReservedPeersResponse) error {
+	reservedOnly := sm.networkAPI.IsReservedOnly()
+	if req.ReservedOnly != nil && *req.ReservedOnly != reservedOnly {
+		reservedOnly = *req.ReservedOnly
+		sm.networkAPI.SetReservedOnly(reservedOnly)
+	}
+
+	*res = ReservedPeersResponse{
+		ReservedOnly: reservedOnly,
+		Peers:        sm.networkAPI.ReservedPeers(),
+	}
+	return nil
+}
+
+// KnownPeers returns the peers of the peer book, with their statistics, reputation and ban expiry.
+func (sm *SystemModule) KnownPeers(r *http.Request, req *EmptyRequest, res *[]KnownPeerResponse) error {
+	peers := sm.networkAPI.KnownPeers()
+	resp := make([]KnownPeerResponse, len(peers))
+	for i, p := range peers {
+		resp[i] = KnownPeerResponse{
+			PeerID:     p.PeerID,
+			Addrs:      p.Addrs,
+			LastSeen:   p.LastSeen.Unix(),
+			Successes:  p.Successes,
+			Failures:   p.Failures,
+			Reputation: p.Reputation,
+		}
+		if !p.BannedUntil.IsZero() {
+			resp[i].BannedUntil = p.BannedUntil.Unix()
+		}
+	}
+
+	*res = resp
+	return nil
+}
+
+// BanPeer disconnects the given peer and refuses any connection with it for the given number of seconds.
+func (sm *SystemModule) BanPeer(r *http.Request, req *BanPeerRequest, res *[]byte) error {
+	if strings.TrimSpace(req.PeerID) == "" {
+		return errors.New("cannot ban an empty peer id")
+	}
+
+	if req.Duration == 0 {
+		return errors.New("ban duration must be greater than zero")
+	}
+
+	return sm.networkAPI.BanPeer(req.PeerID, time.Duration(req.Duration)*time.Second)
+}
+
+// UnbanPeer lifts the ban of the given peer. The string should encode only the PeerId
+func (sm *SystemModule) UnbanPeer(r *http.Request, req *StringRequest, res *[]byte) error {
+	if strings.TrimSpace(req.String) == "" {
+		return errors.New("cannot unban an empty peer id")
+	}
+
+	return sm.networkAPI.UnbanPeer(req.String)
+}
+
+// AddBadBlock rejects the block with the given hash and its descendants during the sync.
+// The string should encode the 0x prefixed block hash.
+func (sm *SystemModule) AddBadBlock(r *http.Request, req *StringRequest, res *[]byte) error {
+	hash, err := genesis.ParseBlockHash(req.String)
+	if err != nil {
+		return err
+	}
+
+	sm.syncAPI.AddBadBlock(hash)
+	return nil
+}
+
+// AddForkBlock pins the block with the given hash at the given number,
+// rejecting the other blocks at this number and their descendants during the sync.
+func (sm *SystemModule) AddForkBlock(r *http.Request, req *ForkBlockRequest, res *[]byte) error {
+	hash, err := genesis.ParseBlockHash(req.Hash)
+	if err != nil {
+		return err
+	}
+
+	sm.syncAPI.AddForkBlock(req.Number, hash)
+	return nil
+}
@@ -7,6 +7,7 @@
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ChainSafe/gossamer/dot/rpc/modules/mocks"
 	testdata "github.com/ChainSafe/gossamer/dot/rpc/modules/test_data"
@@ -644,3 +645,340 @@ type args struct {
 		})
 	}
 }
+
+func TestSystemModule_ReservedPeers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	reservedOnly := true
+	notReservedOnly := false
+
+	tests := []struct {
+		name       string
+		networkAPI func() NetworkAPI
+		req        *ReservedPeersRequest
+		exp        ReservedPeersResponse
+	}{
+		{
+			name: "inspect",
+			networkAPI: func() NetworkAPI {
+				mockNetworkAPI := mocks.NewMockNetworkAPI(ctrl)
+				mockNetworkAPI.EXPECT().IsReservedOnly().Return(false)
+				mockNetworkAPI.EXPECT().ReservedPeers().Return([]string{"jimbo"})
+				return mockNetworkAPI
+			},
+			req: &ReservedPeersRequest{},
+			exp: ReservedPeersResponse{
+				ReservedOnly: false,
+				Peers:        []string{"jimbo"},
+			},
+		},
+		{
+			name: "switch to reserved only",
+			networkAPI: func() NetworkAPI {
+				mockNetworkAPI := mocks.NewMockNetworkAPI(ctrl)
+				mockNetworkAPI.EXPECT().IsReservedOnly().Return(false)
+				mockNetworkAPI.EXPECT().SetReservedOnly(true)
+				mockNetworkAPI.EXPECT().ReservedPeers().Return([]string{"jimbo"})
+				return mockNetworkAPI
+			},
+			req: &ReservedPeersRequest{ReservedOnly: &reservedOnly},
+			exp: ReservedPeersResponse{
+				ReservedOnly: true,
+				Peers:        []string{"jimbo"},
+			},
+		},
+		{
+			name: "already in requested mode",
+			networkAPI: func() NetworkAPI {
+				mockNetworkAPI := mocks.NewMockNetworkAPI(ctrl)
+				mockNetworkAPI.EXPECT().IsReservedOnly().Return(false)
+				mockNetworkAPI.EXPECT().ReservedPeers().Return(nil)
+				return mockNetworkAPI
+			},
+			req: &ReservedPeersRequest{ReservedOnly: &notReservedOnly},
+			exp: ReservedPeersResponse{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewSystemModule(tt.networkAPI(), nil, nil, nil, nil, nil, nil)
+			var res ReservedPeersResponse
+			err := sm.ReservedPeers(nil, tt.req, &res)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.exp, res)
+		})
+	}
+}
+
+func TestSystemModule_KnownPeers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	lastSeen := time.Unix(1000, 0)
+	bannedUntil := time.Unix(2000, 0)
+
+	mockNetworkAPI := mocks.NewMockNetworkAPI(ctrl)
+	mockNetworkAPI.EXPECT().KnownPeers().Return([]common.KnownPeerInfo{
+		{
+			PeerID:     "jimbo",
+			Addrs:      []string{"/ip4/127.0.0.1/tcp/7001"},
+			LastSeen:   lastSeen,
+			Successes:  2,
+			Failures:   1,
+			Reputation: 10,
+		},
+		{
+			PeerID:      "jimmy",
+			LastSeen:    lastSeen,
+			Reputation:  -10,
+			BannedUntil: bannedUntil,
+		},
+	})
+
+	sm := NewSystemModule(mockNetworkAPI, nil, nil, nil, nil, nil, nil)
+	var res []KnownPeerResponse
+	err := sm.KnownPeers(nil, nil, &res)
+	require.NoError(t, err)
+
+	expected := []KnownPeerResponse{
+		{
+			PeerID:     "jimbo",
+			Addrs:      []string{"/ip4/127.0.0.1/tcp/7001"},
+			LastSeen:   1000,
+			Successes:  2,
+			Failures:   1,
+			Reputation: 10,
+		},
+		{
+			PeerID:      "jimmy",
+			LastSeen:    1000,
+			Reputation:  -10,
+			BannedUntil: 2000,
+		},
+	}
+	assert.Equal(t, expected, res)
+}
+
+func TestSystemModule_BanPeer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	tests := []struct {
+		name       string
+		networkAPI func() NetworkAPI
+		req        *BanPeerRequest
+		expErrMsg  string
+	}{
+		{
+			name: "empty peer id",
+			networkAPI: func() NetworkAPI {
+				return mocks.NewMockNetworkAPI(ctrl)
+			},
+			req:       &BanPeerRequest{Duration: 60},
+			expErrMsg: "cannot ban an empty peer id",
+		},
+		{
+			name: "zero duration",
+			networkAPI: func() NetworkAPI {
+				return mocks.NewMockNetworkAPI(ctrl)
+			},
+			req:       &BanPeerRequest{PeerID: "jimbo"},
+			expErrMsg: "ban duration must be greater than zero",
+		},
+		{
+			name: "ban error",
+			networkAPI: func() NetworkAPI {
+				mockNetworkAPI := mocks.NewMockNetworkAPI(ctrl)
+				mockNetworkAPI.EXPECT().BanPeer("jimbo", time.Minute).Return(errors.New("ban error"))
+				return mockNetworkAPI
+			},
+			req:       &BanPeerRequest{PeerID: "jimbo", Duration: 60},
+			expErrMsg: "ban error",
+		},
+		{
+			name: "ok",
+			networkAPI: func() NetworkAPI {
+				mockNetworkAPI := mocks.NewMockNetworkAPI(ctrl)
+				mockNetworkAPI.EXPECT().BanPeer("jimbo", time.Minute).Return(nil)
+				return mockNetworkAPI
+			},
+			req: &BanPeerRequest{PeerID: "jimbo", Duration: 60},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewSystemModule(tt.networkAPI(), nil, nil, nil, nil, nil, nil)
+			err := sm.BanPeer(nil, tt.req, nil)
+			if tt.expErrMsg != "" {
+				assert.EqualError(t, err, tt.expErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSystemModule_UnbanPeer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockNetworkAPI := mocks.NewMockNetworkAPI(ctrl)
+	mockNetworkAPI.EXPECT().UnbanPeer("jimbo").Return(nil)
+
+	sm := NewSystemModule(mockNetworkAPI, nil, nil, nil, nil, nil, nil)
+	err := sm.UnbanPeer(nil, &StringRequest{String: "jimbo"}, nil)
+	require.NoError(t, err)
+
+	err = sm.UnbanPeer(nil, &StringRequest{String: ""}, nil)
+	require.EqualError(t, err, "cannot unban an empty peer id")
+}
+
+func TestSystemModule_SyncPeerStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockSyncAPI := NewMockSyncAPI(ctrl)
+	mockSyncAPI.EXPECT().PeerStats().Return([]common.SyncPeerStats{
+		{
+			PeerID:         "jimbo",
+			Requests:       10,
+			Failures:       1,
+			BlocksServed:   1152,
+			Latency:        1500 * time.Millisecond,
+			BytesPerSecond: 2048.5,
+		},
+	})
+
+	sm := NewSystemModule(nil, nil, nil, nil, nil, nil, mockSyncAPI)
+	var res []SyncPeerStatsResponse
+	err := sm.SyncPeerStats(nil, nil, &res)
+	require.NoError(t, err)
+
+	expected := []SyncPeerStatsResponse{
+		{
+			PeerID:         "jimbo",
+			Requests:       10,
+			Failures:       1,
+			BlocksServed:   1152,
+			LatencyMs:      1500,
+			BytesPerSecond: 2048.5,
+		},
+	}
+	assert.Equal(t, expected, res)
+}
+
+func TestSystemModule_AddBadBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hash := common.Hash{1, 2, 3}
+
+	mockSyncAPI := NewMockSyncAPI(ctrl)
+	mockSyncAPI.EXPECT().AddBadBlock(hash)
+
+	sm := NewSystemModule(nil, nil, nil, nil, nil, nil, mockSyncAPI)
+	err := sm.AddBadBlock(nil, &StringRequest{String: hash.String()}, nil)
+	require.NoError(t, err)
+
+	err = sm.AddBadBlock(nil, &StringRequest{String: "0x0102"}, nil)
+	require.EqualError(t, err, `block hash "0x0102" must be a 0x prefixed 32 bytes hex string`)
+}
+
+func TestSystemModule_AddForkBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hash := common.Hash{1, 2, 3}
+
+	mockSyncAPI := NewMockSyncAPI(ctrl)
+	mockSyncAPI.EXPECT().AddForkBlock(uint(10), hash)
+
+	sm := NewSystemModule(nil, nil, nil, nil, nil, nil, mockSyncAPI)
+	err := sm.AddForkBlock(nil, &ForkBlockRequest{Number: 10, Hash: hash.String()}, nil)
+	require.NoError(t, err)
+
+	err = sm.AddForkBlock(nil, &ForkBlockRequest{Number: 10}, nil)
+	require.EqualError(t, err, `block hash "" must be a 0x prefixed 32 bytes hex string`)
+}
+
+func TestSystemModule_SyncProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockSyncAPI := NewMockSyncAPI(ctrl)
+	mockSyncAPI.EXPECT().SyncProgress().Return(common.SyncProgress{
+		StartingBlock:  10,
+		CurrentBlock:   100,
+		HighestBlock:   1000,
+		ImportRate:     12.5,
+		ETA:            72 * time.Second,
+		Stalled:        true,
+		LastProgressAt: time.Unix(1700000000, 0),
+	})
+
+	sm := NewSystemModule(nil, nil, nil, nil, nil, nil, mockSyncAPI)
+
+	var res SyncProgressResponse
+	err := sm.SyncProgress(nil, nil, &res)
+	require.NoError(t, err)
+	require.Equal(t, SyncProgressResponse{
+		StartingBlock: 10,
+		CurrentBlock:  100,
+		HighestBlock:  1000,
+		ImportRate:    12.5,
+		EtaSeconds:    72,
+		Stalled:       true,
+		LastProgress:  1700000000,
+	}, res)
+}
+
+func TestSystemModule_NetworkBandwidth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockNetworkAPI := mocks.NewMockNetworkAPI(ctrl)
+	mockNetworkAPI.EXPECT().Bandwidth().Return(common.NetworkBandwidth{
+		TotalIn:  1000,
+		TotalOut: 60,
+		RateIn:   100.5,
+		RateOut:  6,
+		Protocols: []common.ProtocolBandwidth{{
+			Protocol:        "sync",
+			TotalIn:         1000,
+			TotalOut:        10,
+			RateIn:          100.5,
+			RateOut:         1,
+			MessagesIn:      1,
+			MessagesOut:     1,
+			InboundStreams:  1,
+			OutboundStreams: 2,
+		}},
+		Peers: []common.PeerBandwidth{{
+			PeerID:   "alice",
+			TotalIn:  1000,
+			TotalOut: 60,
+			RateIn:   100.5,
+			RateOut:  6,
+		}},
+	})
+
+	sm := NewSystemModule(mockNetworkAPI, nil, nil, nil, nil, nil, nil)
+
+	var res NetworkBandwidthResponse
+	err := sm.NetworkBandwidth(nil, nil, &res)
+	require.NoError(t, err)
+	require.Equal(t, NetworkBandwidthResponse{
+		TotalIn:  1000,
+		TotalOut: 60,
+		RateIn:   100.5,
+		RateOut:  6,
+		Protocols: []ProtocolBandwidthResponse{{
+			Protocol:        "sync",
+			TotalIn:         1000,
+			TotalOut:        10,
+			RateIn:          100.5,
+			RateOut:         1,
+			MessagesIn:      1,
+			MessagesOut:     1,
+			InboundStreams:  1,
+			OutboundStreams: 2,
+		}},
+		Peers: []PeerBandwidthResponse{{
+			PeerID:   "alice",
+			TotalIn:  1000,
+			TotalOut: 60,
+			RateIn:   100.5,
+			RateOut:  6,
+		}},
+	}, res)
+}
@@ -23,15 +23,43 @@ func TestNewService(t *testing.T) {
 }
 
 func TestService_Methods(t *testing.T) {
-	qtySystemMethods := 15
+	qtySystemMethods := 24
 	qtyRPCMethods := 1
 	qtyAuthorMethods := 8
 
+	expectedSystemMethods := []string{
+		"system_accountNextIndex",
+		"system_addBadBlock",
+		"system_addForkBlock",
+		"system_addReservedPeer",
+		"system_banPeer",
+		"system_chain",
+		"system_chainType",
+		"system_health",
+		"system_knownPeers",
+		"system_localListenAddresses",
+		"system_localPeerId",
+		"system_name",
+		"system_networkBandwidth",
+		"system_networkState",
+		"system_nodeRoles",
+		"system_peers",
+		"system_properties",
+		"system_removeReservedPeer",
+		"system_reservedPeers",
+		"system_syncPeerStats",
+		"system_syncProgress",
+		"system_syncState",
+		"system_unbanPeer",
+		"system_version",
+	}
+
 	rpcService := NewService()
 	sysMod := modules.NewSystemModule(nil, nil, nil, nil, nil, nil, nil)
 	rpcService.BuildMethodNames(sysMod, "system")
 	m := rpcService.Methods()
 	require.Equal(t, qtySystemMethods, len(m)) // check to confirm quantity for methods is correct
+	require.ElementsMatch(t, expectedSystemMethods, m)
 
 	rpcMod := modules.NewRPCModule(nil)
 	rpcService.BuildMethodNames(rpcMod, "rpc")
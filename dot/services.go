@@ -6,6 +6,7 @@
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,8 +18,10 @@
 	"github.com/ChainSafe/gossamer/dot/rpc"
 	"github.com/ChainSafe/gossamer/dot/rpc/modules"
 	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/state/pruner"
 	"github.com/ChainSafe/gossamer/dot/sync"
 	"github.com/ChainSafe/gossamer/dot/system"
+	"github.com/ChainSafe/gossamer/dot/telemetry"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/database"
 	"github.com/ChainSafe/gossamer/internal/log"
@@ -63,8 +66,9 @@ func newInMemoryDB() (database.Database, error) {
 	return database.LoadDatabase("", true)
 }
 
-// createStateService creates the state service and initialise state database
-func (nodeBuilder) createStateService(config *cfg.Config) (*state.Service, error) {
+// createStateService creates the state service and initialise state database,
+// or the in-memory state initialised from the genesis if the node is kept in memory
+func (nb nodeBuilder) createStateService(config *cfg.Config) (*state.Service, error) {
 	logger.Debug("creating state service...")
 
 	gen, err := genesis.NewGenesisFromJSONRaw(config.ChainSpec)
@@ -108,10 +112,23 @@ func (nodeBuilder) createStateService(config *cfg.Config) (*state.Service, error
 		LogLevel:          stateLogLevel,
 		Metrics:           metrics.NewIntervalConfig(config.PrometheusExternal),
 		GenesisBABEConfig: babeCfg,
+		StorageBackend:    state.StorageBackend(config.State.Backend),
+		PrunerCfg: pruner.Config{
+			Mode:           config.Pruning,
+			RetainedBlocks: config.RetainBlocks,
+		},
 	}
 
 	stateSrvc := state.NewService(stateConfig)
 
+	if nb.options.InMemory {
+		err = initialiseInMemoryState(stateSrvc, gen)
+		if err != nil {
+			return nil, err
+		}
+		return stateSrvc, nil
+	}
+
 	if err := stateSrvc.SetupBase(); err != nil {
 		return nil, fmt.Errorf("cannot setup base: %w", err)
 	}
@@ -119,6 +136,29 @@ func (nodeBuilder) createStateService(config *cfg.Config) (*state.Service, error
 	return stateSrvc, nil
 }
 
+// initialiseInMemoryState initialises the state service in memory with the genesis.
+func initialiseInMemoryState(stateSrvc *state.Service, gen *genesis.Genesis) error {
+	t, err := runtime.NewTrieFromGenesis(*gen)
+	if err != nil {
+		return fmt.Errorf("creating trie from genesis: %w", err)
+	}
+
+	header, err := runtime.GenesisBlockFromTrie(t)
+	if err != nil {
+		return fmt.Errorf("creating genesis block from trie: %w", err)
+	}
+
+	// the telemetry mailer is only created once the genesis data is loaded from the initialised state
+	stateSrvc.Telemetry = telemetry.NewNoopMailer()
+	stateSrvc.UseMemDB()
+	err = stateSrvc.Initialise(gen, &header, t)
+	if err != nil {
+		return fmt.Errorf("initialising in-memory state: %w", err)
+	}
+
+	return nil
+}
+
 func startStateService(config cfg.StateConfig, stateSrvc *state.Service) error {
 	logger.Debug("starting state service...")
 
@@ -236,7 +276,7 @@ type KeyStore interface {
 	Keypairs() []keystore.KeyPair
 }
 
-func (nodeBuilder) createBABEServiceWithBuilder(config *cfg.Config, st *state.Service, ks KeyStore,
+func (nb nodeBuilder) createBABEServiceWithBuilder(config *cfg.Config, st *state.Service, ks KeyStore,
 	cs *core.Service, telemetryMailer Telemetry, newBabeService ServiceBuilder) (
 	service *babe.Service, err error) {
 	logger.Info("creating BABE service" +
@@ -266,6 +306,7 @@ func (nodeBuilder) createBABEServiceWithBuilder(config *cfg.Config, st *state.Se
 		Authority:          config.Core.BabeAuthority,
 		IsDev:              config.ID == "dev",
 		Telemetry:          telemetryMailer,
+		Clock:              nb.options.Clock,
 	}
 
 	if config.Core.BabeAuthority {
@@ -332,7 +373,7 @@ func (nodeBuilder) createCoreService(config *cfg.Config, ks *keystore.GlobalKeys
 // Network Service
 
 // createNetworkService creates a network service from the command configuration and genesis data
-func (nodeBuilder) createNetworkService(config *cfg.Config, stateSrvc *state.Service,
+func (nb nodeBuilder) createNetworkService(config *cfg.Config, stateSrvc *state.Service,
 	telemetryMailer Telemetry) (*network.Service, error) {
 	logger.Debugf(
 		"creating network service with role %d, port %d, bootnodes %s, protocol ID %s, nobootstrap=%t and noMDNS=%t...",
@@ -355,27 +396,33 @@ func (nodeBuilder) createNetworkService(config *cfg.Config, stateSrvc *state.Ser
 
 	// network service configuation
 	networkConfig := network.Config{
-		LogLvl:            networkLogLevel,
-		BlockState:        stateSrvc.Block,
-		BasePath:          config.BasePath,
-		Roles:             config.Core.Role,
-		Port:              config.Network.Port,
-		Bootnodes:         config.Network.Bootnodes,
-		ProtocolID:        config.Network.ProtocolID,
-		NoBootstrap:       config.Network.NoBootstrap,
-		NoMDNS:            config.Network.NoMDNS,
-		MinPeers:          config.Network.MinPeers,
-		MaxPeers:          config.Network.MaxPeers,
-		PersistentPeers:   config.Network.PersistentPeers,
-		DiscoveryInterval: config.Network.DiscoveryInterval,
-		SlotDuration:      slotDuration,
-		PublicIP:          config.Network.PublicIP,
-		Telemetry:         telemetryMailer,
-		PublicDNS:         config.Network.PublicDNS,
-		Metrics:           metrics.NewIntervalConfig(config.PrometheusExternal),
-		NodeKey:           config.Network.NodeKey,
-		ListenAddress:     config.Network.ListenAddress,
-		WarpSyncProvider:  warpSyncProvider,
+		LogLvl:             networkLogLevel,
+		BlockState:         stateSrvc.Block,
+		BasePath:           config.BasePath,
+		Roles:              config.Core.Role,
+		Port:               config.Network.Port,
+		Bootnodes:          config.Network.Bootnodes,
+		ProtocolID:         config.Network.ProtocolID,
+		NoBootstrap:        config.Network.NoBootstrap,
+		NoMDNS:             config.Network.NoMDNS,
+		MinPeers:           config.Network.MinPeers,
+		MaxPeers:           config.Network.MaxPeers,
+		PersistentPeers:    config.Network.PersistentPeers,
+		ReservedOnly:       config.Network.ReservedOnly,
+		DiscoveryInterval:  config.Network.DiscoveryInterval,
+		SlotDuration:       slotDuration,
+		PublicIP:           config.Network.PublicIP,
+		Telemetry:          telemetryMailer,
+		PublicDNS:          config.Network.PublicDNS,
+		Metrics:            metrics.NewIntervalConfig(config.PrometheusExternal),
+		NodeKey:            config.Network.NodeKey,
+		ListenAddress:      config.Network.ListenAddress,
+		ListenAddresses:    config.Network.ListenAddresses,
+		PublicAddresses:    config.Network.PublicAddresses,
+		Transports:         config.Network.Transports,
+		OutboundRateLimits: config.Network.OutboundRateLimits,
+		WarpSyncProvider:   warpSyncProvider,
+		Libp2pHost:         nb.options.Libp2pHost,
 	}
 
 	networkSrvc, err := network.NewService(&networkConfig)
@@ -455,7 +502,7 @@ func (nodeBuilder) createSystemService(cfg *types.SystemInfo, stateSrvc *state.S
 }
 
 // createGRANDPAService creates a new GRANDPA service
-func (nodeBuilder) createGRANDPAService(config *cfg.Config, st *state.Service, ks KeyStore,
+func (nb nodeBuilder) createGRANDPAService(config *cfg.Config, st *state.Service, ks KeyStore,
 	net *network.Service, telemetryMailer Telemetry) (*grandpa.Service, error) {
 	bestBlockHash := st.Block.BestBlockHash()
 	rt, err := st.Block.GetRuntime(bestBlockHash)
@@ -492,6 +539,7 @@ func (nodeBuilder) createGRANDPAService(config *cfg.Config, st *state.Service, k
 		Network:      net,
 		Interval:     config.Core.GrandpaInterval,
 		Telemetry:    telemetryMailer,
+		Clock:        nb.options.Clock,
 	}
 
 	if config.Core.GrandpaAuthority {
@@ -501,8 +549,12 @@ func (nodeBuilder) createGRANDPAService(config *cfg.Config, st *state.Service, k
 	return grandpa.NewService(gsCfg)
 }
 
-func (nodeBuilder) createBlockVerifier(st *state.Service) *babe.VerificationManager {
-	return babe.NewVerificationManager(st.Block, st.Slot, st.Epoch)
+func (nb nodeBuilder) createBlockVerifier(st *state.Service) *babe.VerificationManager {
+	verifier := babe.NewVerificationManager(st.Block, st.Slot, st.Epoch)
+	if nb.options.Clock != nil {
+		verifier.SetClock(nb.options.Clock)
+	}
+	return verifier
 }
 
 func (nodeBuilder) newSyncService(config *cfg.Config, st *state.Service, fg sync.FinalityGadget,
@@ -518,6 +570,11 @@ func (nodeBuilder) newSyncService(config *cfg.Config, st *state.Service, fg sync
 		return nil, err
 	}
 
+	blockRules, err := createBlockRules(config, genesisData)
+	if err != nil {
+		return nil, err
+	}
+
 	requestMaker := net.GetRequestResponseProtocol(network.SyncID,
 		blockRequestTimeout, network.MaxBlockResponseSize)
 
@@ -527,9 +584,10 @@ func (nodeBuilder) newSyncService(config *cfg.Config, st *state.Service, fg sync
 		TransactionState:   st.Transaction,
 		FinalityGadget:     fg,
 		BabeVerifier:       verifier,
+		GrandpaState:       st.Grandpa,
 		BlockImportHandler: cs,
 		Telemetry:          telemetryMailer,
-		BadBlocks:          genesisData.BadBlocks,
+		BlockRules:         blockRules,
 		RequestMaker:       requestMaker,
 	}
 	fullSync := sync.NewFullSyncStrategy(syncCfg)
@@ -540,9 +598,34 @@ func (nodeBuilder) newSyncService(config *cfg.Config, st *state.Service, fg sync
 		sync.WithSlotDuration(slotDuration),
 		sync.WithStrategies(fullSync, nil),
 		sync.WithMinPeers(config.Network.MinPeers),
+		sync.WithBlockRules(blockRules),
 	), nil
 }
 
+// createBlockRules creates the block rules from the bad blocks and the fork blocks
+// of the chain spec and of the configuration.
+func createBlockRules(config *cfg.Config, genesisData *genesis.Data) (*sync.BlockRules, error) {
+	badBlocks := make([]common.Hash, 0, len(genesisData.BadBlocks)+len(config.Core.BadBlocks))
+	for _, badBlock := range slices.Concat(genesisData.BadBlocks, config.Core.BadBlocks) {
+		hash, err := genesis.ParseBlockHash(badBlock)
+		if err != nil {
+			return nil, fmt.Errorf("parsing bad block: %w", err)
+		}
+		badBlocks = append(badBlocks, hash)
+	}
+
+	forkBlocks := append([]genesis.ForkBlock{}, genesisData.ForkBlocks...)
+	for _, forkBlock := range config.Core.ForkBlocks {
+		fork, err := genesis.ParseForkBlock(forkBlock)
+		if err != nil {
+			return nil, fmt.Errorf("parsing fork block: %w", err)
+		}
+		forkBlocks = append(forkBlocks, fork)
+	}
+
+	return sync.NewBlockRules(badBlocks, forkBlocks), nil
+}
+
 func (nodeBuilder) createDigestHandler(st *state.Service) (*digest.Handler, error) {
 	return digest.NewHandler(st.Block, st.Epoch, st.Grandpa)
 }
@@ -0,0 +1,36 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package simulation
+
+import (
+	"sync"
+	"time"
+)
+
+// linkDelay is the time messages take to go through the links, measured on the virtual
+// clock so the messages reach the other nodes after the same clock steps on every run.
+type linkDelay struct {
+	mtx       sync.Mutex
+	latency   time.Duration
+	bandwidth float64
+}
+
+func (d *linkDelay) set(latency time.Duration, bandwidth float64) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.latency = latency
+	d.bandwidth = bandwidth
+}
+
+// delay returns the time a message of the given size takes to reach the other node
+func (d *linkDelay) delay(size int) time.Duration {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	delay := d.latency
+	if d.bandwidth > 0 {
+		delay += time.Duration(float64(size) / d.bandwidth * float64(time.Second))
+	}
+	return delay
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package simulation
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+
+	"github.com/benbjohnson/clock"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// messageLoss drops messages with a probability, using a seeded source so the
+// same messages are dropped when the nodes send them in the same order.
+type messageLoss struct {
+	mtx         sync.Mutex
+	random      *rand.Rand
+	probability float64
+}
+
+func newMessageLoss(seed int64, probability float64) (*messageLoss, error) {
+	loss := &messageLoss{
+		random: rand.New(rand.NewSource(seed)), //nolint:gosec
+	}
+	err := loss.set(probability)
+	if err != nil {
+		return nil, err
+	}
+	return loss, nil
+}
+
+func (l *messageLoss) set(probability float64) error {
+	if probability < 0 || probability > 1 {
+		return ErrInvalidMessageLoss
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.probability = probability
+	return nil
+}
+
+// drop returns true if the next message must be dropped
+func (l *messageLoss) drop() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.probability == 0 {
+		return false
+	}
+	return l.random.Float64() < l.probability
+}
+
+// lossyHost is a libp2p host whose streams drop messages and delay them on the virtual clock.
+type lossyHost struct {
+	libp2phost.Host
+	loss  *messageLoss
+	delay *linkDelay
+	clock clock.Clock
+}
+
+func (h *lossyHost) NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error) {
+	stream, err := h.Host.NewStream(ctx, p, pids...)
+	if err != nil {
+		return nil, err
+	}
+	return h.wrapStream(stream), nil
+}
+
+func (h *lossyHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	h.Host.SetStreamHandler(pid, h.wrapHandler(handler))
+}
+
+func (h *lossyHost) SetStreamHandlerMatch(pid protocol.ID, match func(protocol.ID) bool,
+	handler network.StreamHandler) {
+	h.Host.SetStreamHandlerMatch(pid, match, h.wrapHandler(handler))
+}
+
+func (h *lossyHost) wrapHandler(handler network.StreamHandler) network.StreamHandler {
+	return func(stream network.Stream) {
+		handler(h.wrapStream(stream))
+	}
+}
+
+func (h *lossyHost) wrapStream(stream network.Stream) *lossyStream {
+	return &lossyStream{
+		Stream: stream,
+		loss:   h.loss,
+		delay:  h.delay,
+		clock:  h.clock,
+	}
+}
+
+// lossyStream is a stream dropping and delaying messages. The nodes write each message with a single
+// write, so a dropped write drops a whole message without breaking the framing of the stream.
+type lossyStream struct {
+	network.Stream
+	loss  *messageLoss
+	delay *linkDelay
+	clock clock.Clock
+}
+
+func (s *lossyStream) Write(p []byte) (int, error) {
+	if s.loss.drop() {
+		return len(p), nil
+	}
+
+	delay := s.delay.delay(len(p))
+	if delay > 0 {
+		s.clock.Sleep(delay)
+	}
+	return s.Stream.Write(p)
+}
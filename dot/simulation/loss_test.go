@@ -0,0 +1,168 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package simulation
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/libp2p/go-libp2p/core/network"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMessageLoss(t *testing.T) {
+	t.Parallel()
+
+	_, err := newMessageLoss(1, -0.1)
+	require.ErrorIs(t, err, ErrInvalidMessageLoss)
+	_, err = newMessageLoss(1, 1.1)
+	require.ErrorIs(t, err, ErrInvalidMessageLoss)
+
+	loss, err := newMessageLoss(1, 0)
+	require.NoError(t, err)
+	for range 100 {
+		require.False(t, loss.drop())
+	}
+
+	require.NoError(t, loss.set(1))
+	for range 100 {
+		require.True(t, loss.drop())
+	}
+}
+
+func TestMessageLoss_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	first, err := newMessageLoss(42, 0.5)
+	require.NoError(t, err)
+	second, err := newMessageLoss(42, 0.5)
+	require.NoError(t, err)
+
+	var dropped int
+	for range 1000 {
+		drop := first.drop()
+		require.Equal(t, drop, second.drop())
+		if drop {
+			dropped++
+		}
+	}
+	require.InDelta(t, 500, dropped, 100)
+}
+
+func TestLossyHost(t *testing.T) {
+	t.Parallel()
+
+	mn := mocknet.New()
+	t.Cleanup(func() { _ = mn.Close() })
+
+	loss, err := newMessageLoss(1, 0)
+	require.NoError(t, err)
+
+	sender, err := mn.GenPeer()
+	require.NoError(t, err)
+	receiver, err := mn.GenPeer()
+	require.NoError(t, err)
+	require.NoError(t, mn.LinkAll())
+
+	const protocolID = "/test/1"
+	received := make(chan []byte)
+	lossyReceiver := &lossyHost{Host: receiver, loss: loss, delay: &linkDelay{}}
+	lossyReceiver.SetStreamHandler(protocolID, func(stream network.Stream) {
+		require.IsType(t, &lossyStream{}, stream)
+		data, err := io.ReadAll(stream)
+		require.NoError(t, err)
+		received <- data
+	})
+
+	lossySender := &lossyHost{Host: sender, loss: loss, delay: &linkDelay{}}
+	_, err = mn.ConnectPeers(sender.ID(), receiver.ID())
+	require.NoError(t, err)
+
+	stream, err := lossySender.NewStream(context.Background(), receiver.ID(), protocolID)
+	require.NoError(t, err)
+
+	n, err := stream.Write([]byte("first"))
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+
+	// the dropped message is reported as written
+	require.NoError(t, loss.set(1))
+	n, err = stream.Write([]byte("dropped"))
+	require.NoError(t, err)
+	require.Equal(t, 7, n)
+
+	require.NoError(t, loss.set(0))
+	_, err = stream.Write([]byte("last"))
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+
+	require.Equal(t, []byte("firstlast"), <-received)
+}
+
+func TestLossyHost_Delay(t *testing.T) {
+	t.Parallel()
+
+	mn := mocknet.New()
+	t.Cleanup(func() { _ = mn.Close() })
+
+	loss, err := newMessageLoss(1, 0)
+	require.NoError(t, err)
+	delay := &linkDelay{}
+	delay.set(time.Second, 0)
+	mockClock := clock.NewMock()
+
+	sender, err := mn.GenPeer()
+	require.NoError(t, err)
+	receiver, err := mn.GenPeer()
+	require.NoError(t, err)
+	require.NoError(t, mn.LinkAll())
+
+	const protocolID = "/test/1"
+	received := make(chan []byte)
+	lossyReceiver := &lossyHost{Host: receiver, loss: loss, delay: delay, clock: mockClock}
+	lossyReceiver.SetStreamHandler(protocolID, func(stream network.Stream) {
+		data, err := io.ReadAll(stream)
+		require.NoError(t, err)
+		received <- data
+	})
+
+	lossySender := &lossyHost{Host: sender, loss: loss, delay: delay, clock: mockClock}
+	_, err = mn.ConnectPeers(sender.ID(), receiver.ID())
+	require.NoError(t, err)
+
+	stream, err := lossySender.NewStream(context.Background(), receiver.ID(), protocolID)
+	require.NoError(t, err)
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := stream.Write([]byte("message"))
+		written <- err
+	}()
+
+	// the message is only sent once the latency elapsed on the virtual clock
+	require.Never(t, func() bool { return len(written) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		mockClock.Add(time.Second)
+		return len(written) > 0
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, <-written)
+
+	require.NoError(t, stream.Close())
+	require.Equal(t, []byte("message"), <-received)
+}
+
+func TestLinkDelay(t *testing.T) {
+	t.Parallel()
+
+	delay := &linkDelay{}
+	require.Zero(t, delay.delay(1000))
+
+	delay.set(10*time.Millisecond, 1000)
+	require.Equal(t, 10*time.Millisecond, delay.delay(0))
+	require.Equal(t, 510*time.Millisecond, delay.delay(500))
+}
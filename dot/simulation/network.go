@@ -0,0 +1,501 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package simulation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"slices"
+	"time"
+
+	cfg "github.com/ChainSafe/gossamer/config"
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/benbjohnson/clock"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// DefaultClockStep is the default time the virtual clock is advanced by while waiting
+	DefaultClockStep = 500 * time.Millisecond
+
+	basePort = 30333
+)
+
+var (
+	// ErrNoNodes is returned when the simulated network is configured without nodes
+	ErrNoNodes = errors.New("no nodes configured")
+	// ErrInvalidMessageLoss is returned when the message loss is not between 0 and 1
+	ErrInvalidMessageLoss = errors.New("message loss must be between 0 and 1")
+	// ErrInvalidNodeIndex is returned when a node index is out of range
+	ErrInvalidNodeIndex = errors.New("invalid node index")
+)
+
+// NodeConfig is the configuration of a simulated node
+type NodeConfig struct {
+	// Key is the name of the built-in test key of the node, such as alice, bob or charlie
+	Key string
+	// BabeAuthority runs the node as a BABE authority producing blocks with its key
+	BabeAuthority bool
+	// GrandpaAuthority runs the node as a GRANDPA authority voting with its key
+	GrandpaAuthority bool
+}
+
+// LinkOptions are the options of the links between the nodes
+type LinkOptions struct {
+	// Latency is the time a message takes to reach the other node, on the virtual clock
+	Latency time.Duration
+	// Bandwidth is the number of bytes per second of virtual time a link transfers, unlimited if 0
+	Bandwidth float64
+	// MessageLoss is the probability, between 0 and 1, for a message to be dropped
+	MessageLoss float64
+}
+
+// Config is the configuration of a simulated network
+type Config struct {
+	// BasePath is the directory holding the directories of the nodes
+	BasePath string
+	// ChainSpec is the path of the raw chain spec shared by the nodes
+	ChainSpec string
+	// Nodes are the nodes of the network
+	Nodes []NodeConfig
+	// Links are the options of the links between the nodes
+	Links LinkOptions
+	// Start is the time the virtual clock starts at, the current time if zero
+	Start time.Time
+	// ClockStep is the time the virtual clock is advanced by while waiting, DefaultClockStep if zero
+	ClockStep time.Duration
+	// Seed seeds the identities of the nodes and the message loss
+	Seed int64
+	// LogLevel is the log level of the nodes, error if empty
+	LogLevel string
+}
+
+// Network runs several nodes in the same process on a simulated libp2p network, with in-memory
+// databases and a virtual clock driving the BABE slots and the GRANDPA rounds of all the nodes.
+type Network struct {
+	mocknet   mocknet.Mocknet
+	clock     *clock.Mock
+	loss      *messageLoss
+	delay     *linkDelay
+	settler   *settler
+	clockStep time.Duration
+	nodes     []*Node
+}
+
+// NewNetwork creates the nodes of the simulated network, linked to each other.
+// The nodes are started with Start.
+func NewNetwork(config Config) (*Network, error) {
+	if len(config.Nodes) == 0 {
+		return nil, ErrNoNodes
+	}
+
+	loss, err := newMessageLoss(config.Seed, config.Links.MessageLoss)
+	if err != nil {
+		return nil, err
+	}
+
+	start := config.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	mockClock := clock.NewMock()
+	mockClock.Set(start)
+
+	n := &Network{
+		mocknet:   mocknet.New(),
+		clock:     mockClock,
+		loss:      loss,
+		delay:     &linkDelay{},
+		settler:   newSettler(),
+		clockStep: config.ClockStep,
+	}
+	if n.clockStep == 0 {
+		n.clockStep = DefaultClockStep
+	}
+	// the messages are delayed on the virtual clock by the hosts, not in real time by the mocknet links
+	n.delay.set(config.Links.Latency, config.Links.Bandwidth)
+
+	random := rand.New(rand.NewSource(config.Seed)) //nolint:gosec
+	keys := make([][]byte, len(config.Nodes))
+	addrs := make([]string, len(config.Nodes))
+	for i := range config.Nodes {
+		keys[i] = make([]byte, ed25519.SeedSize)
+		_, _ = random.Read(keys[i])
+
+		id, err := peerIDFromSeed(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		addrs[i] = fmt.Sprintf("%s/p2p/%s", nodeAddress(i), id)
+	}
+
+	for i, nodeConfig := range config.Nodes {
+		node, err := n.newNode(config, i, nodeConfig, keys[i], addrs)
+		if err != nil {
+			_ = n.mocknet.Close()
+			return nil, fmt.Errorf("creating node %d: %w", i, err)
+		}
+		n.nodes = append(n.nodes, node)
+	}
+
+	err = n.mocknet.LinkAll()
+	if err != nil {
+		_ = n.mocknet.Close()
+		return nil, fmt.Errorf("linking nodes: %w", err)
+	}
+
+	return n, nil
+}
+
+func (n *Network) newNode(config Config, index int, nodeConfig NodeConfig, nodeKey []byte,
+	addrs []string) (*Node, error) {
+	logLevel := config.LogLevel
+	if logLevel == "" {
+		logLevel = "error"
+	}
+
+	name := fmt.Sprintf("node-%d", index)
+	if nodeConfig.Key != "" {
+		name = fmt.Sprintf("%s-%d", nodeConfig.Key, index)
+	}
+
+	nodeCfg := cfg.DefaultConfig()
+	nodeCfg.Name = name
+	nodeCfg.BasePath = filepath.Join(config.BasePath, name)
+	nodeCfg.ChainSpec = config.ChainSpec
+	nodeCfg.NoTelemetry = true
+	nodeCfg.LogLevel = logLevel
+	nodeCfg.Log = &cfg.LogConfig{
+		Core:    logLevel,
+		Digest:  logLevel,
+		Sync:    logLevel,
+		Network: logLevel,
+		RPC:     logLevel,
+		State:   logLevel,
+		Runtime: logLevel,
+		Babe:    logLevel,
+		Grandpa: logLevel,
+		Wasmer:  logLevel,
+	}
+	nodeCfg.Core.BabeAuthority = nodeConfig.BabeAuthority
+	nodeCfg.Core.GrandpaAuthority = nodeConfig.GrandpaAuthority
+	if nodeConfig.BabeAuthority || nodeConfig.GrandpaAuthority {
+		nodeCfg.Core.Role = common.AuthorityRole
+	} else {
+		nodeCfg.Core.Role = common.FullNodeRole
+	}
+	nodeCfg.Network.NoMDNS = true
+	nodeCfg.Network.NodeKey = common.BytesToHex(nodeKey)[2:]
+	nodeCfg.Network.Port = uint16(basePort + index) //nolint:gosec
+	// the nodes are bootstrapped from the first node and discover each other with the DHT
+	if index != 0 {
+		nodeCfg.Network.Bootnodes = []string{addrs[0]}
+	}
+
+	ks := keystore.NewGlobalKeystore()
+	if nodeConfig.Key != "" {
+		err := loadTestKeys(nodeConfig.Key, ks)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var host libp2phost.Host
+	options := dot.NodeOptions{
+		InMemory: true,
+		Clock:    n.clock,
+		Libp2pHost: func(privateKey crypto.PrivKey, ps peerstore.Peerstore) (libp2phost.Host, error) {
+			h, err := n.addHost(index, privateKey, ps)
+			host = h
+			return h, err
+		},
+	}
+
+	node, err := dot.NewNodeWithOptions(nodeCfg, ks, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return newNode(name, node, host), nil
+}
+
+// addHost adds the libp2p host of the node with the given index to the simulated network.
+func (n *Network) addHost(index int, privateKey crypto.PrivKey, ps peerstore.Peerstore) (libp2phost.Host, error) {
+	id, err := peer.IDFromPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("getting peer id: %w", err)
+	}
+
+	err = ps.AddPrivKey(id, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("adding private key to peer store: %w", err)
+	}
+	err = ps.AddPubKey(id, privateKey.GetPublic())
+	if err != nil {
+		return nil, fmt.Errorf("adding public key to peer store: %w", err)
+	}
+	ps.AddAddr(id, nodeAddress(index), peerstore.PermanentAddrTTL)
+
+	h, err := n.mocknet.AddPeerWithPeerstore(id, ps)
+	if err != nil {
+		return nil, fmt.Errorf("adding peer to mock network: %w", err)
+	}
+
+	return &lossyHost{
+		Host:  h,
+		loss:  n.loss,
+		delay: n.delay,
+		clock: n.clock,
+	}, nil
+}
+
+// Start starts the nodes and waits for their services to be started.
+func (n *Network) Start() {
+	for _, node := range n.nodes {
+		node.start()
+	}
+}
+
+// Stop stops the nodes and closes the simulated network. The virtual clock keeps being
+// advanced while the nodes are stopped, so the slots and rounds in progress can end.
+func (n *Network) Stop() {
+	stopped := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stopped:
+				return
+			default:
+				n.AdvanceClock(n.clockStep)
+			}
+		}
+	}()
+
+	for _, node := range n.nodes {
+		node.stop()
+	}
+	close(stopped)
+	_ = n.mocknet.Close()
+}
+
+// Nodes returns the nodes of the network, in the order of their configuration.
+func (n *Network) Nodes() []*Node {
+	return n.nodes
+}
+
+// Node returns the node with the given index.
+func (n *Network) Node(index int) *Node {
+	return n.nodes[index]
+}
+
+// Clock returns the virtual clock of the nodes.
+func (n *Network) Clock() *clock.Mock {
+	return n.clock
+}
+
+// AdvanceClock advances the virtual clock by the given duration, in clock steps. After each step,
+// the clock is only advanced further once the nodes handled the step and the messages sent in
+// response to it, and are all waiting.
+func (n *Network) AdvanceClock(d time.Duration) {
+	for d > 0 {
+		step := min(d, n.clockStep)
+		n.clock.Add(step)
+		n.settler.settle()
+		d -= step
+	}
+}
+
+// SetLinkOptions sets the latency, bandwidth and message loss of all the links.
+func (n *Network) SetLinkOptions(options LinkOptions) error {
+	err := n.loss.set(options.MessageLoss)
+	if err != nil {
+		return err
+	}
+
+	n.delay.set(options.Latency, options.Bandwidth)
+	return nil
+}
+
+// Partition splits the network in the given groups of node indexes: the nodes of different
+// groups are disconnected and cannot connect to each other until the network is healed.
+// The nodes which are not part of any group are isolated from all the others.
+func (n *Network) Partition(groups ...[]int) error {
+	group := make([]int, len(n.nodes))
+	for i := range group {
+		group[i] = -1 - i
+	}
+	for g, indexes := range groups {
+		for _, index := range indexes {
+			if index < 0 || index >= len(n.nodes) {
+				return fmt.Errorf("%w: %d", ErrInvalidNodeIndex, index)
+			}
+			group[index] = g
+		}
+	}
+
+	for i := range n.nodes {
+		for j := i + 1; j < len(n.nodes); j++ {
+			if group[i] == group[j] {
+				continue
+			}
+
+			a, b := n.nodes[i].PeerID(), n.nodes[j].PeerID()
+			if len(n.mocknet.LinksBetweenPeers(a, b)) == 0 {
+				continue
+			}
+			err := n.mocknet.UnlinkPeers(a, b)
+			if err != nil {
+				return fmt.Errorf("unlinking nodes %d and %d: %w", i, j, err)
+			}
+			err = n.mocknet.DisconnectPeers(a, b)
+			if err != nil {
+				return fmt.Errorf("disconnecting nodes %d and %d: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Heal links back all the nodes partitioned from each other and reconnects them.
+func (n *Network) Heal() error {
+	for i := range n.nodes {
+		for j := i + 1; j < len(n.nodes); j++ {
+			a, b := n.nodes[i].PeerID(), n.nodes[j].PeerID()
+			if len(n.mocknet.LinksBetweenPeers(a, b)) != 0 {
+				continue
+			}
+
+			_, err := n.mocknet.LinkPeers(a, b)
+			if err != nil {
+				return fmt.Errorf("linking nodes %d and %d: %w", i, j, err)
+			}
+			_, err = n.mocknet.ConnectPeers(a, b)
+			if err != nil {
+				return fmt.Errorf("connecting nodes %d and %d: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// WaitFor advances the virtual clock until the condition is met or the context is done.
+func (n *Network) WaitFor(ctx context.Context, condition func() bool) error {
+	for !condition() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		n.AdvanceClock(n.clockStep)
+	}
+	return nil
+}
+
+// WaitForBestBlock waits for the given nodes, or all the nodes if none is given,
+// to have a best block with at least the given number.
+func (n *Network) WaitForBestBlock(ctx context.Context, number uint, indexes ...int) error {
+	err := n.WaitFor(ctx, func() bool {
+		return n.all(indexes, func(node *Node) bool {
+			return node.BestBlockNumber() >= number
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for best block #%d: %w", number, err)
+	}
+	return nil
+}
+
+// WaitForFinalisedBlock waits for the given nodes, or all the nodes if none is given,
+// to have finalised a block with at least the given number.
+func (n *Network) WaitForFinalisedBlock(ctx context.Context, number uint, indexes ...int) error {
+	err := n.WaitFor(ctx, func() bool {
+		return n.all(indexes, func(node *Node) bool {
+			return node.FinalisedBlockNumber() >= number
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for finalised block #%d: %w", number, err)
+	}
+	return nil
+}
+
+// WaitForPeers waits for the given nodes, or all the nodes if none is given,
+// to be connected to at least the given number of peers.
+func (n *Network) WaitForPeers(ctx context.Context, peers int, indexes ...int) error {
+	err := n.WaitFor(ctx, func() bool {
+		return n.all(indexes, func(node *Node) bool {
+			return node.PeerCount() >= peers
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for %d peers: %w", peers, err)
+	}
+	return nil
+}
+
+// all returns true if the condition is met by the nodes with the given indexes, or all the nodes if none is given.
+func (n *Network) all(indexes []int, condition func(*Node) bool) bool {
+	for i, node := range n.nodes {
+		if len(indexes) != 0 && !slices.Contains(indexes, i) {
+			continue
+		}
+		if !condition(node) {
+			return false
+		}
+	}
+	return true
+}
+
+func nodeAddress(index int) ma.Multiaddr {
+	return ma.StringCast(fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", basePort+index))
+}
+
+func peerIDFromSeed(seed []byte) (peer.ID, error) {
+	privateKey, err := crypto.UnmarshalEd25519PrivateKey(ed25519.NewKeyFromSeed(seed))
+	if err != nil {
+		return "", fmt.Errorf("decoding ed25519 key: %w", err)
+	}
+	return peer.IDFromPrivateKey(privateKey)
+}
+
+// loadTestKeys loads the built-in test key with the given name in the account, BABE and GRANDPA keystores.
+func loadTestKeys(key string, ks *keystore.GlobalKeystore) error {
+	sr25519KeyRing, err := keystore.NewSr25519Keyring()
+	if err != nil {
+		return fmt.Errorf("creating sr25519 keyring: %w", err)
+	}
+
+	ed25519KeyRing, err := keystore.NewEd25519Keyring()
+	if err != nil {
+		return fmt.Errorf("creating ed25519 keyring: %w", err)
+	}
+
+	err = keystore.LoadKeystore(key, ks.Acco, sr25519KeyRing)
+	if err != nil {
+		return fmt.Errorf("loading account keystore: %w", err)
+	}
+
+	err = keystore.LoadKeystore(key, ks.Babe, sr25519KeyRing)
+	if err != nil {
+		return fmt.Errorf("loading babe keystore: %w", err)
+	}
+
+	err = keystore.LoadKeystore(key, ks.Gran, ed25519KeyRing)
+	if err != nil {
+		return fmt.Errorf("loading grandpa keystore: %w", err)
+	}
+
+	return nil
+}
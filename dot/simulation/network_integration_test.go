@@ -0,0 +1,75 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+//go:build integration
+
+package simulation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNetwork(t *testing.T, nodes []NodeConfig, links LinkOptions) *Network {
+	t.Helper()
+
+	network, err := NewNetwork(Config{
+		BasePath:  t.TempDir(),
+		ChainSpec: utils.GetWestendLocalRawGenesisPath(t),
+		Nodes:     nodes,
+		Links:     links,
+		Seed:      1,
+	})
+	require.NoError(t, err)
+
+	network.Start()
+	t.Cleanup(network.Stop)
+	return network
+}
+
+// westendLocalAuthorities are the GRANDPA authorities of the westend-local chain with a single
+// block producer, so the rounds do not vote for blocks of forks the other nodes did not import.
+var westendLocalAuthorities = []NodeConfig{
+	{Key: "alice", BabeAuthority: true, GrandpaAuthority: true},
+	{Key: "bob", GrandpaAuthority: true},
+	{Key: "charlie", GrandpaAuthority: true},
+}
+
+func TestNetwork_ProduceAndFinaliseBlocks(t *testing.T) {
+	network := newTestNetwork(t, westendLocalAuthorities, LinkOptions{Latency: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	require.NoError(t, network.WaitForPeers(ctx, 2))
+	require.NoError(t, network.WaitForBestBlock(ctx, 5))
+	require.NoError(t, network.WaitForFinalisedBlock(ctx, 3))
+}
+
+func TestNetwork_PartitionAndHeal(t *testing.T) {
+	network := newTestNetwork(t, westendLocalAuthorities, LinkOptions{Latency: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	require.NoError(t, network.WaitForPeers(ctx, 2))
+	require.NoError(t, network.WaitForBestBlock(ctx, 2))
+
+	require.NoError(t, network.Partition([]int{0, 1}, []int{2}))
+	// the connections of the mocknet are closed asynchronously
+	require.NoError(t, network.WaitFor(ctx, func() bool {
+		return network.Node(2).PeerCount() == 0
+	}))
+	isolatedBest := network.Node(2).BestBlockNumber()
+
+	require.NoError(t, network.WaitForBestBlock(ctx, isolatedBest+3, 0, 1))
+	require.Equal(t, isolatedBest, network.Node(2).BestBlockNumber())
+
+	require.NoError(t, network.Heal())
+	require.NoError(t, network.WaitForPeers(ctx, 2))
+	require.NoError(t, network.WaitForBestBlock(ctx, isolatedBest+3, 2))
+}
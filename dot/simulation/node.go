@@ -0,0 +1,105 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package simulation
+
+import (
+	"github.com/ChainSafe/gossamer/dot"
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/state"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// Node is a node of the simulated network
+type Node struct {
+	name    string
+	node    *dot.Node
+	host    libp2phost.Host
+	state   *state.Service
+	network *network.Service
+
+	started bool
+	stopped chan struct{}
+}
+
+func newNode(name string, node *dot.Node, host libp2phost.Host) *Node {
+	stateSrvc, _ := node.ServiceRegistry.Get(&state.Service{}).(*state.Service)
+	networkSrvc, _ := node.ServiceRegistry.Get(&network.Service{}).(*network.Service)
+	return &Node{
+		name:    name,
+		node:    node,
+		host:    host,
+		state:   stateSrvc,
+		network: networkSrvc,
+		stopped: make(chan struct{}),
+	}
+}
+
+func (n *Node) start() {
+	n.started = true
+	go func() {
+		_ = n.node.Start()
+		close(n.stopped)
+	}()
+	<-n.node.Started()
+}
+
+func (n *Node) stop() {
+	if !n.started {
+		return
+	}
+	n.started = false
+	n.node.Stop()
+	<-n.stopped
+}
+
+// Name returns the name of the node
+func (n *Node) Name() string {
+	return n.name
+}
+
+// Node returns the node services
+func (n *Node) Node() *dot.Node {
+	return n.node
+}
+
+// PeerID returns the peer ID of the node
+func (n *Node) PeerID() peer.ID {
+	return n.host.ID()
+}
+
+// State returns the state service of the node
+func (n *Node) State() *state.Service {
+	return n.state
+}
+
+// Network returns the network service of the node
+func (n *Node) Network() *network.Service {
+	return n.network
+}
+
+// BestBlockNumber returns the number of the best block of the node
+func (n *Node) BestBlockNumber() uint {
+	number, err := n.state.Block.BestBlockNumber()
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+// FinalisedBlockNumber returns the number of the highest finalised block of the node
+func (n *Node) FinalisedBlockNumber() uint {
+	header, err := n.state.Block.GetHighestFinalisedHeader()
+	if err != nil {
+		return 0
+	}
+	return header.Number
+}
+
+// PeerCount returns the number of peers the node is connected to. It does not go through the
+// network service, whose health waits for the sync service, itself possibly waiting for a slot
+// of the virtual clock to end.
+func (n *Node) PeerCount() int {
+	return len(n.host.Network().Peers())
+}
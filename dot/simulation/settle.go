@@ -0,0 +1,80 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package simulation
+
+import (
+	"bytes"
+	"runtime"
+)
+
+const (
+	// settleIdlePolls is the number of consecutive polls finding all the goroutines blocked
+	// before the nodes are considered idle
+	settleIdlePolls = 2
+	// maxSettlePolls bounds the polls of the goroutines after a clock step, in case a goroutine
+	// never blocks
+	maxSettlePolls = 10_000
+	// settleYields is the number of times the processor is yielded to the other goroutines
+	// between two polls, a poll stopping the world to list the goroutines
+	settleYields = 100
+)
+
+// settler waits for all the goroutines of the process to be blocked, which is when the nodes
+// handled the clock step and the messages sent in response to it. It does not wait in real time,
+// so the nodes handle the same events between two clock steps on every run.
+type settler struct {
+	buf []byte
+}
+
+func newSettler() *settler {
+	return &settler{buf: make([]byte, 1<<20)}
+}
+
+// settle yields to the other goroutines until they are all blocked.
+func (s *settler) settle() {
+	idlePolls := 0
+	for polls := 0; polls < maxSettlePolls && idlePolls < settleIdlePolls; polls++ {
+		for range settleYields {
+			runtime.Gosched()
+		}
+
+		if countBusyGoroutines(s.stacks()) == 0 {
+			idlePolls++
+		} else {
+			idlePolls = 0
+		}
+	}
+}
+
+// stacks returns the stacks of all the goroutines, the one of the caller first.
+func (s *settler) stacks() []byte {
+	for {
+		n := runtime.Stack(s.buf, true)
+		if n < len(s.buf) {
+			return s.buf[:n]
+		}
+		s.buf = make([]byte, 2*len(s.buf))
+	}
+}
+
+// countBusyGoroutines returns the number of goroutines running or runnable in the given
+// stacks, other than the first goroutine listing them.
+func countBusyGoroutines(stacks []byte) (busy int) {
+	for i, stack := range bytes.Split(stacks, []byte("\n\n")) {
+		if i == 0 {
+			continue
+		}
+
+		// the header of a goroutine stack is formatted as "goroutine 1 [state, duration]:"
+		header, _, _ := bytes.Cut(stack, []byte("\n"))
+		_, state, found := bytes.Cut(header, []byte("["))
+		if !found {
+			continue
+		}
+		if bytes.HasPrefix(state, []byte("running")) || bytes.HasPrefix(state, []byte("runnable")) {
+			busy++
+		}
+	}
+	return busy
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package simulation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_countBusyGoroutines(t *testing.T) {
+	t.Parallel()
+
+	stacks := []byte(`goroutine 1 [running]:
+main.main()
+
+goroutine 2 [runnable]:
+main.work()
+
+goroutine 3 [chan receive, 2 minutes]:
+main.wait()
+
+goroutine 4 [running]:
+main.work()
+
+goroutine 5 [select]:
+main.loop()`)
+	require.Equal(t, 2, countBusyGoroutines(stacks))
+}
+
+func TestSettler_settle(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan struct{})
+	go func() {
+		// a goroutine busy until done is closed
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+	}()
+	require.Positive(t, countBusyGoroutines(newSettler().stacks()))
+	close(done)
+
+	// the goroutines blocked on channels do not keep the settler from returning
+	blocked := make(chan struct{})
+	t.Cleanup(func() { close(blocked) })
+	go func() { <-blocked }()
+	newSettler().settle()
+}
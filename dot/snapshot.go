@@ -0,0 +1,90 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	cfg "github.com/ChainSafe/gossamer/config"
+	"github.com/ChainSafe/gossamer/dot/telemetry"
+	"github.com/ChainSafe/gossamer/internal/snapshot"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// ExportSnapshot writes the snapshot of the finalised block with the given hash, from the database
+// of the node with the given configuration, to the snapshot file out. The snapshot holds the header
+// of the block, its state including the child tries, and the BABE epoch and GRANDPA authority set
+// data needed to continue syncing from it.
+func ExportSnapshot(config *cfg.Config, blockHash common.Hash, out string) (err error) {
+	builder := nodeBuilder{}
+	stateSrvc, err := builder.createStateService(config)
+	if err != nil {
+		return fmt.Errorf("failed to create state service: %s", err)
+	}
+
+	// nothing is finalised during the export, there is no telemetry to send
+	stateSrvc.Telemetry = telemetry.NewNoopMailer()
+
+	err = startStateService(*config.State, stateSrvc)
+	if err != nil {
+		return fmt.Errorf("cannot start state service: %w", err)
+	}
+	defer func() {
+		stopErr := stateSrvc.Stop()
+		if err == nil && stopErr != nil {
+			err = fmt.Errorf("stopping state service: %w", stopErr)
+		}
+	}()
+
+	file, err := os.Create(filepath.Clean(out))
+	if err != nil {
+		return fmt.Errorf("creating snapshot file: %w", err)
+	}
+	defer func() {
+		closeErr := file.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("closing snapshot file: %w", closeErr)
+		}
+	}()
+
+	return stateSrvc.ExportSnapshot(blockHash, file)
+}
+
+// ImportSnapshot imports the snapshot file into the database of the initialised node with the given
+// configuration, which must not have imported blocks yet. The node then continues syncing from the
+// block of the snapshot. It returns the number of the block of the snapshot.
+func ImportSnapshot(config *cfg.Config, snapshotPath string) (number uint, err error) {
+	file, err := os.Open(filepath.Clean(snapshotPath))
+	if err != nil {
+		return 0, fmt.Errorf("opening snapshot file: %w", err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	reader, err := snapshot.NewReader(file)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	builder := nodeBuilder{}
+	stateSrvc, err := builder.createStateService(config)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create state service: %s", err)
+	}
+	defer func() {
+		closeErr := stateSrvc.DB().Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("closing database: %w", closeErr)
+		}
+	}()
+
+	err = stateSrvc.ImportSnapshot(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	return reader.Header.Number, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+//go:build integration
+
+package dot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/telemetry"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportSnapshot(t *testing.T) {
+	config := DefaultTestWestendDevConfig(t)
+	config.ChainSpec = NewTestGenesisRawFile(t, config)
+
+	err := InitNode(config)
+	require.NoError(t, err)
+
+	stateSrvc, err := nodeBuilder{}.createStateService(config)
+	require.NoError(t, err)
+	stateSrvc.Telemetry = telemetry.NewNoopMailer()
+	err = startStateService(*config.State, stateSrvc)
+	require.NoError(t, err)
+
+	// the blocks keep the genesis state, holding the runtime giving the state version
+	genesisHeader, err := stateSrvc.Block.BestBlockHeader()
+	require.NoError(t, err)
+	var chain []*types.Header
+	parentHash := genesisHeader.Hash()
+	for number := uint(1); number <= 5; number++ {
+		preDigest, err := types.NewBabePrimaryPreDigest(0, uint64(number), [32]byte{}, [64]byte{}).ToPreRuntimeDigest()
+		require.NoError(t, err)
+		digest := types.NewDigest()
+		require.NoError(t, digest.Add(*preDigest))
+
+		block := &types.Block{
+			Header: types.Header{
+				ParentHash: parentHash,
+				Number:     number,
+				StateRoot:  genesisHeader.StateRoot,
+				Digest:     digest,
+			},
+			Body: types.Body{},
+		}
+		err = stateSrvc.Block.AddBlockWithArrivalTime(block, time.Now())
+		require.NoError(t, err)
+
+		chain = append(chain, &block.Header)
+		parentHash = block.Header.Hash()
+	}
+	err = stateSrvc.Epoch.SetEpochDataRaw(1, &types.EpochDataRaw{Randomness: [32]byte{1}})
+	require.NoError(t, err)
+	err = stateSrvc.Block.SetFinalisedHash(chain[3].Hash(), 1, 0)
+	require.NoError(t, err)
+	err = stateSrvc.Stop()
+	require.NoError(t, err)
+
+	out := filepath.Join(t.TempDir(), "snapshot.bin")
+	err = ExportSnapshot(config, chain[2].Hash(), out)
+	require.NoError(t, err)
+
+	importConfig := DefaultTestWestendDevConfig(t)
+	importConfig.ChainSpec = config.ChainSpec
+	err = InitNode(importConfig)
+	require.NoError(t, err)
+
+	number, err := ImportSnapshot(importConfig, out)
+	require.NoError(t, err)
+	require.Equal(t, uint(3), number)
+
+	_, err = ImportSnapshot(importConfig, out)
+	require.ErrorIs(t, err, state.ErrNodeNotEmpty)
+
+	importedSrvc, err := nodeBuilder{}.createStateService(importConfig)
+	require.NoError(t, err)
+	importedSrvc.Telemetry = telemetry.NewNoopMailer()
+	err = startStateService(*importConfig.State, importedSrvc)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := importedSrvc.Stop()
+		require.NoError(t, err)
+	})
+
+	require.Equal(t, chain[2].Hash(), importedSrvc.Block.BestBlockHash())
+	finalised, err := importedSrvc.Block.GetHighestFinalisedHash()
+	require.NoError(t, err)
+	require.Equal(t, chain[2].Hash(), finalised)
+	epochData, err := importedSrvc.Epoch.GetEpochDataRaw(1, nil)
+	require.NoError(t, err)
+	require.Equal(t, &types.EpochDataRaw{Randomness: [32]byte{1}}, epochData)
+}
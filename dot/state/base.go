@@ -6,8 +6,10 @@
 import (
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/ChainSafe/gossamer/internal/database"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/genesis"
 )
@@ -80,6 +82,26 @@ func (s *BaseState) LoadCodeSubstitutedBlockHash() common.Hash {
 	return common.NewHash(hash)
 }
 
+// StoreSchemaVersion stores the version of the database schema at the SchemaVersionKey key
+func (s *BaseState) StoreSchemaVersion(version uint32) error {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, version)
+	return s.db.Put(common.SchemaVersionKey, buf)
+}
+
+// LoadSchemaVersion loads the version of the database schema stored at the SchemaVersionKey key,
+// or 0 if the database was created before the version was stored
+func (s *BaseState) LoadSchemaVersion() (uint32, error) {
+	data, err := s.db.Get(common.SchemaVersionKey)
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(data), nil
+}
+
 // Put stores key/value pair in database
 func (s *BaseState) Put(key, value []byte) error {
 	return s.db.Put(key, value)
@@ -63,3 +63,20 @@ func TestStoreAndLoadGenesisData(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expected, gen)
 }
+
+func TestStoreAndLoadSchemaVersion(t *testing.T) {
+	db := NewInMemoryDB(t)
+	base := NewBaseState(db)
+
+	// databases created before the schema version was stored have the version 0
+	version, err := base.LoadSchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), version)
+
+	err = base.StoreSchemaVersion(2)
+	require.NoError(t, err)
+
+	version, err = base.LoadSchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), version)
+}
@@ -10,6 +10,8 @@
 
 	"github.com/ChainSafe/gossamer/dot/telemetry"
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/blocktree"
 	"github.com/ChainSafe/gossamer/lib/common"
 )
 
@@ -282,3 +284,165 @@ func (bs *BlockState) handleFinalisedBlock(currentFinalizedHash common.Hash) err
 
 	return batch.Flush()
 }
+
+// revert writes to the given batch the removal of the blocks with a number greater than the given
+// block and, if the given block is finalised, sets it as the highest finalised block, finalised in
+// the highest round remaining. It returns the hashes of the reverted blocks along with the round and
+// set ID of the highest finalised block remaining. The block state in memory is left unchanged until
+// applyRevert is called once the batch is flushed.
+func (bs *BlockState) revert(batch database.Batch, target *types.Header) (
+	reverted []common.Hash, round, setID uint64, err error) {
+	bs.lock.RLock()
+	defer bs.lock.RUnlock()
+
+	finalised, err := bs.GetHeader(bs.lastFinalised)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("getting last finalised header: %w", err)
+	}
+
+	for number := max(target.Number, finalised.Number) + 1; ; number++ {
+		hashes := bs.bt.GetHashesAtNumber(number)
+		if len(hashes) == 0 {
+			break
+		}
+		reverted = append(reverted, hashes...)
+	}
+
+	for _, hash := range reverted {
+		err = deleteBlockData(batch, hash)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	if target.Number >= finalised.Number {
+		return reverted, bs.lastRound, bs.lastSetID, nil
+	}
+
+	for number := finalised.Number; number > target.Number; number-- {
+		encodedHash, err := bs.db.Get(headerHashKey(uint64(number)))
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("getting hash of finalised block #%d: %w", number, err)
+		}
+		hash := common.NewHash(encodedHash)
+		reverted = append(reverted, hash)
+
+		for _, key := range [][]byte{headerKey(hash), blockBodyKey(hash), arrivalTimeKey(hash),
+			headerHashKey(uint64(number))} {
+			err = batch.Del(key)
+			if err != nil {
+				return nil, 0, 0, fmt.Errorf("deleting finalised block #%d: %w", number, err)
+			}
+		}
+
+		err = deleteBlockData(batch, hash)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	if target.Number == 0 {
+		// the first slot number is set again when the block #1 is finalised
+		err = batch.Del(firstSlotNumberKey)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("deleting first slot number: %w", err)
+		}
+	}
+
+	round, setID, err = bs.revertFinalisedHashes(batch, reverted)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	targetHash := target.Hash()
+	err = batch.Put(finalisedHashKey(round, setID), targetHash[:])
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("setting finalised hash key: %w", err)
+	}
+
+	err = batch.Put(highestRoundAndSetIDKey, roundAndSetIDToBytes(round, setID))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("setting highest round and set ID: %w", err)
+	}
+
+	return reverted, round, setID, nil
+}
+
+// applyRevert removes the blocks with a number greater than the given block from the block state
+// in memory, once the changes written by revert are flushed to the database. The finalised header
+// is the highest finalised header before reverting.
+func (bs *BlockState) applyRevert(target, finalised *types.Header, round, setID uint64) {
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+
+	for _, hash := range bs.bt.Revert(target.Number) {
+		blockHeader := bs.unfinalisedBlocks.delete(hash)
+		if blockHeader != nil {
+			bs.tries.delete(blockHeader.StateRoot)
+		}
+	}
+
+	if target.Number >= finalised.Number {
+		return
+	}
+
+	bs.tries.delete(finalised.StateRoot)
+	bs.bt = blocktree.NewBlockTreeFromRoot(target)
+	bs.lastFinalised = target.Hash()
+	bs.lastRound = round
+	bs.lastSetID = setID
+
+	logger.Infof("reverted finalised blocks from #%d (%s) to #%d (%s), round %d, set id %d",
+		finalised.Number, finalised.Hash(), target.Number, target.Hash(), round, setID)
+}
+
+// revertFinalisedHashes deletes the finalised hashes of the reverted blocks and returns the
+// highest round and set ID remaining, or zeros if none remains.
+func (bs *BlockState) revertFinalisedHashes(batch database.Batch, reverted []common.Hash) (
+	round, setID uint64, err error) {
+	revertedSet := make(map[common.Hash]struct{}, len(reverted))
+	for _, hash := range reverted {
+		revertedSet[hash] = struct{}{}
+	}
+
+	iter, err := bs.db.NewPrefixIterator(common.FinalizedBlockHashKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("creating finalised hashes iterator: %w", err)
+	}
+	defer iter.Release()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		key := iter.Key()
+		if len(key) != len(blockPrefix)+len(common.FinalizedBlockHashKey)+16 {
+			continue
+		}
+
+		recordRound := binary.LittleEndian.Uint64(key[len(key)-16 : len(key)-8])
+		recordSetID := binary.LittleEndian.Uint64(key[len(key)-8:])
+		if _, ok := revertedSet[common.NewHash(iter.Value())]; ok {
+			err = batch.Del(finalisedHashKey(recordRound, recordSetID))
+			if err != nil {
+				return 0, 0, fmt.Errorf("deleting finalised hash of round %d and set id %d: %w",
+					recordRound, recordSetID, err)
+			}
+			continue
+		}
+
+		if recordSetID > setID || (recordSetID == setID && recordRound > round) {
+			round, setID = recordRound, recordSetID
+		}
+	}
+
+	return round, setID, nil
+}
+
+// deleteBlockData deletes the receipt, message queue and justification of the block
+func deleteBlockData(batch database.Batch, hash common.Hash) error {
+	for _, prefix := range [][]byte{receiptPrefix, messageQueuePrefix, justificationPrefix} {
+		err := batch.Del(prefixKey(hash, prefix))
+		if err != nil {
+			return fmt.Errorf("deleting data of block %s: %w", hash, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,584 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ChainSafe/gossamer/dot/state/pruner"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/ChainSafe/gossamer/pkg/trie/codec"
+	"github.com/ChainSafe/gossamer/pkg/trie/node"
+)
+
+// Names of the checks run by Service.Check, reported in the issues found.
+const (
+	// CheckChain verifies the canonical chain is continuous from the finalised block to the genesis block.
+	CheckChain = "chain"
+	// CheckIndex verifies the block number to block hash index of the canonical chain.
+	CheckIndex = "index"
+	// CheckBody verifies the bodies of the canonical chain blocks are stored.
+	CheckBody = "body"
+	// CheckJustification verifies the justifications of the GRANDPA set change blocks are stored.
+	CheckJustification = "justification"
+	// CheckState verifies all the nodes of the retained state tries are stored.
+	CheckState = "state"
+	// CheckEpoch verifies the BABE epoch and config data match the digests of the canonical chain.
+	CheckEpoch = "epoch"
+	// CheckGrandpa verifies the GRANDPA set changes and authorities match the digests of the canonical chain.
+	CheckGrandpa = "grandpa"
+)
+
+// checkProgressInterval is the number of blocks or trie nodes checked between progress logs.
+const checkProgressInterval = 10000
+
+// CheckIssue is an inconsistency found in the database.
+type CheckIssue struct {
+	Check    string       `json:"check"`
+	Number   uint         `json:"number"`
+	Hash     *common.Hash `json:"hash,omitempty"`
+	Message  string       `json:"message"`
+	Repaired bool         `json:"repaired"`
+}
+
+// CheckReport is the result of the check of the database.
+type CheckReport struct {
+	FinalisedNumber   uint         `json:"finalisedNumber"`
+	FinalisedHash     common.Hash  `json:"finalisedHash"`
+	BlocksChecked     uint         `json:"blocksChecked"`
+	StateRootsChecked uint         `json:"stateRootsChecked"`
+	TrieNodesChecked  uint         `json:"trieNodesChecked"`
+	Issues            []CheckIssue `json:"issues"`
+}
+
+// OK returns true if no issue was found, or if all of them were repaired.
+func (r *CheckReport) OK() bool {
+	for _, issue := range r.Issues {
+		if !issue.Repaired {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *CheckReport) addIssue(check string, number uint, hash *common.Hash, format string, args ...any) {
+	r.Issues = append(r.Issues, CheckIssue{
+		Check:   check,
+		Number:  number,
+		Hash:    hash,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkedStateRoot is the state root of the most recent canonical block using it.
+type checkedStateRoot struct {
+	number uint
+	hash   common.Hash
+	root   common.Hash
+}
+
+// checkedSetChange is a GRANDPA set change found in the digests of the canonical chain.
+type checkedSetChange struct {
+	announcingNumber uint
+	effectiveNumber  uint
+	auths            []types.GrandpaAuthoritiesRaw
+}
+
+// Check verifies the consistency of the database of a started service with the canonical chain
+// from its finalised block down to the genesis block: the chain continuity, the block number index,
+// the block bodies, the justifications of the GRANDPA set change blocks, the state tries of the
+// retained blocks, and the BABE epoch data and GRANDPA authority sets against the block digests.
+// The block number index is repaired if repair is set. The inconsistencies found are returned in
+// the report, the error is only set if the check could not be run.
+func (s *Service) Check(repair bool) (report *CheckReport, err error) {
+	finalisedHash, err := s.Block.GetHighestFinalisedHash()
+	if err != nil {
+		return nil, fmt.Errorf("getting highest finalised hash: %w", err)
+	}
+	finalised, err := s.Block.loadHeaderFromDatabase(finalisedHash)
+	if err != nil {
+		return nil, fmt.Errorf("loading finalised header: %w", err)
+	}
+
+	report = &CheckReport{
+		FinalisedNumber: finalised.Number,
+		FinalisedHash:   finalisedHash,
+		Issues:          []CheckIssue{},
+	}
+
+	// the set change blocks stored must have a justification
+	setChanges := make(map[uint]uint64)
+	for setID := uint64(1); ; setID++ {
+		number, err := s.Grandpa.GetSetIDChange(setID)
+		if errors.Is(err, database.ErrNotFound) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("getting set id change %d: %w", setID, err)
+		}
+		setChanges[number] = setID
+	}
+
+	// the first slot is required to get the epoch of the blocks without walking the chain twice
+	var firstSlot uint64
+	checkEpochs := finalised.Number > 1
+	if checkEpochs {
+		firstSlot, err = s.Epoch.retrieveFirstNonOriginBlockSlot(finalisedHash)
+		if err != nil {
+			report.addIssue(CheckEpoch, 1, nil, "cannot get the slot of the first block: %s", err)
+			checkEpochs = false
+		}
+	}
+
+	var stateFloor uint
+	if s.PrunerCfg.Mode == pruner.Full && finalised.Number >= uint(s.PrunerCfg.RetainedBlocks) {
+		stateFloor = finalised.Number - uint(s.PrunerCfg.RetainedBlocks) + 1
+	}
+
+	batch := s.Block.db.NewBatch()
+	defer batch.Reset()
+
+	var (
+		stateRoots     []checkedStateRoot
+		setChangesSeen []checkedSetChange
+		// followingEpoch is the first epoch following the epoch of the current block in which
+		// a block of the canonical chain was produced, or 0 if there is none
+		followingEpoch, childEpoch uint64
+	)
+
+	hash := finalisedHash
+	for number := finalised.Number; ; number-- {
+		blockHash := hash
+		report.BlocksChecked++
+
+		header, err := s.Block.loadHeaderFromDatabase(blockHash)
+		switch {
+		case errors.Is(err, database.ErrNotFound):
+			report.addIssue(CheckChain, number, &blockHash, "header is missing")
+			header = nil
+		case err != nil:
+			report.addIssue(CheckChain, number, &blockHash, "cannot load header: %s", err)
+			header = nil
+		case header.Number != number:
+			report.addIssue(CheckChain, number, &blockHash, "header has number %d", header.Number)
+		}
+
+		err = s.checkHashIndex(report, batch, number, blockHash, repair)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = s.Block.GetBlockBody(blockHash)
+		if errors.Is(err, database.ErrNotFound) {
+			report.addIssue(CheckBody, number, &blockHash, "body is missing")
+		} else if err != nil {
+			report.addIssue(CheckBody, number, &blockHash, "cannot get body: %s", err)
+		}
+
+		if setID, ok := setChanges[number]; ok {
+			has, err := s.Block.HasJustification(blockHash)
+			if err != nil {
+				return nil, fmt.Errorf("checking justification of block #%d: %w", number, err)
+			}
+			if !has {
+				report.addIssue(CheckJustification, number, &blockHash,
+					"justification of the change to set %d is missing", setID)
+			}
+		}
+
+		if header != nil {
+			if number >= stateFloor {
+				stateRoots = append(stateRoots, checkedStateRoot{number, blockHash, header.StateRoot})
+			}
+
+			var epoch uint64
+			if checkEpochs && number > 1 {
+				slot, err := header.SlotNumber()
+				if err != nil {
+					report.addIssue(CheckEpoch, number, &blockHash, "cannot get slot number: %s", err)
+				} else {
+					epoch = (slot - firstSlot) / s.Epoch.epochLength
+				}
+			}
+			if number != finalised.Number && childEpoch > epoch {
+				followingEpoch = childEpoch
+			}
+			childEpoch = epoch
+
+			changes, err := s.checkBlockDigests(report, header, epoch, followingEpoch, checkEpochs)
+			if err != nil {
+				return nil, err
+			}
+			setChangesSeen = append(setChangesSeen, changes...)
+		}
+
+		if number%checkProgressInterval == 0 && number != finalised.Number {
+			logger.Infof("checked %d blocks, down to block #%d", report.BlocksChecked, number)
+		}
+
+		if number == 0 {
+			if blockHash != s.Block.genesisHash {
+				report.addIssue(CheckChain, number, &blockHash,
+					"chain does not lead to the genesis block %s", s.Block.genesisHash)
+			}
+			break
+		}
+
+		if header != nil {
+			hash = header.ParentHash
+			continue
+		}
+
+		// the chain is followed with the block number index, if the header of the block is missing
+		parentHash, err := s.Block.db.Get(headerHashKey(uint64(number - 1)))
+		if err != nil {
+			report.addIssue(CheckChain, number-1, nil, "cannot follow the chain below block #%d: %s", number, err)
+			break
+		}
+		hash = common.NewHash(parentHash)
+	}
+
+	err = s.checkStaleHashIndexes(report, batch, finalised.Number, repair)
+	if err != nil {
+		return nil, err
+	}
+
+	if repair {
+		err = batch.Flush()
+		if err != nil {
+			return nil, fmt.Errorf("flushing repairs: %w", err)
+		}
+	}
+
+	err = s.checkSetChanges(report, setChangesSeen, finalised.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkStateRoots(report, stateRoots)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("checked %d blocks, %d state tries and %d trie nodes, found %d issues",
+		report.BlocksChecked, report.StateRootsChecked, report.TrieNodesChecked, len(report.Issues))
+	return report, nil
+}
+
+// checkHashIndex checks the block number index points to the canonical block with the given number.
+func (s *Service) checkHashIndex(report *CheckReport, batch database.Batch, number uint,
+	hash common.Hash, repair bool) error {
+	indexed, err := s.Block.db.Get(headerHashKey(uint64(number)))
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		report.addIssue(CheckIndex, number, &hash, "block number index is missing")
+	case err != nil:
+		return fmt.Errorf("getting block number index of block #%d: %w", number, err)
+	case !bytes.Equal(indexed, hash[:]):
+		report.addIssue(CheckIndex, number, &hash, "block number index points to %s", common.NewHash(indexed))
+	default:
+		return nil
+	}
+
+	if !repair {
+		return nil
+	}
+	err = batch.Put(headerHashKey(uint64(number)), hash.ToBytes())
+	if err != nil {
+		return fmt.Errorf("repairing block number index of block #%d: %w", number, err)
+	}
+	report.Issues[len(report.Issues)-1].Repaired = true
+	return nil
+}
+
+// checkStaleHashIndexes checks there is no block number index above the finalised block,
+// since only the finalised blocks are stored.
+func (s *Service) checkStaleHashIndexes(report *CheckReport, batch database.Batch,
+	finalisedNumber uint, repair bool) error {
+	iter, err := s.Block.db.NewPrefixIterator(headerHashPrefix)
+	if err != nil {
+		return fmt.Errorf("creating block number index iterator: %w", err)
+	}
+	defer iter.Release()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		key := bytes.TrimPrefix(iter.Key(), []byte(blockPrefix))
+		if len(key) != len(headerHashPrefix)+8 {
+			continue
+		}
+
+		number := binary.BigEndian.Uint64(key[len(headerHashPrefix):])
+		if number <= uint64(finalisedNumber) {
+			continue
+		}
+
+		hash := common.NewHash(iter.Value())
+		report.addIssue(CheckIndex, uint(number), &hash, "block number index is above the finalised block")
+		if !repair {
+			continue
+		}
+		err = batch.Del(headerHashKey(number))
+		if err != nil {
+			return fmt.Errorf("deleting block number index of block #%d: %w", number, err)
+		}
+		report.Issues[len(report.Issues)-1].Repaired = true
+	}
+	return nil
+}
+
+// checkBlockDigests checks the BABE epoch and config data announced by the block are stored,
+// and returns the GRANDPA set changes it announces. The data announced in the given epoch is
+// stored for the following epoch, or for followingEpoch if the epochs between are skipped.
+func (s *Service) checkBlockDigests(report *CheckReport, header *types.Header, epoch, followingEpoch uint64,
+	checkEpochs bool) (changes []checkedSetChange, err error) {
+	hash := header.Hash()
+	for _, item := range header.Digest {
+		value, err := item.Value()
+		if err != nil {
+			return nil, fmt.Errorf("getting digest value of block #%d: %w", header.Number, err)
+		}
+		digest, ok := value.(types.ConsensusDigest)
+		if !ok {
+			continue
+		}
+
+		switch digest.ConsensusEngineID {
+		case types.GrandpaEngineID:
+			data := types.NewGrandpaConsensusDigest()
+			err = scale.Unmarshal(digest.Data, &data)
+			if err != nil {
+				report.addIssue(CheckGrandpa, header.Number, &hash, "cannot decode GRANDPA digest: %s", err)
+				continue
+			}
+			dataValue, err := data.Value()
+			if err != nil {
+				return nil, fmt.Errorf("getting GRANDPA digest value of block #%d: %w", header.Number, err)
+			}
+			switch val := dataValue.(type) {
+			case types.GrandpaScheduledChange:
+				changes = append(changes, checkedSetChange{header.Number, header.Number + uint(val.Delay), val.Auths})
+			case types.GrandpaForcedChange:
+				changes = append(changes, checkedSetChange{header.Number, header.Number + uint(val.Delay), val.Auths})
+			}
+		case types.BabeEngineID:
+			if !checkEpochs {
+				continue
+			}
+			data := types.NewBabeConsensusDigest()
+			err = scale.Unmarshal(digest.Data, &data)
+			if err != nil {
+				report.addIssue(CheckEpoch, header.Number, &hash, "cannot decode BABE digest: %s", err)
+				continue
+			}
+			dataValue, err := data.Value()
+			if err != nil {
+				return nil, fmt.Errorf("getting BABE digest value of block #%d: %w", header.Number, err)
+			}
+			switch val := dataValue.(type) {
+			case types.NextEpochData:
+				checkEpochDefinition(report, s.Epoch.db, header, epoch, followingEpoch,
+					epochDataKey, val.ToEpochDataRaw())
+			case types.VersionedNextConfigData:
+				configValue, err := val.Value()
+				if err != nil {
+					return nil, fmt.Errorf("getting next config data value of block #%d: %w", header.Number, err)
+				}
+				if nextConfigData, ok := configValue.(types.NextConfigDataV1); ok {
+					checkEpochDefinition(report, s.Epoch.db, header, epoch, followingEpoch,
+						configDataKey, nextConfigData.ToConfigData())
+				}
+			}
+		}
+	}
+	return changes, nil
+}
+
+// checkEpochDefinition checks the epoch or config data announced in the given epoch is stored.
+func checkEpochDefinition[T types.EpochDataRaw | types.ConfigData](report *CheckReport, db Getter,
+	header *types.Header, epoch, followingEpoch uint64, usePrefix prefixedKeyBuilder, announced *T) {
+	hash := header.Hash()
+	targets := []uint64{epoch + 1}
+	if followingEpoch > epoch+1 {
+		targets = append(targets, followingEpoch)
+	}
+
+	for _, target := range targets {
+		stored, err := getEpochDefinitionFromDatabase[T](db, target, usePrefix)
+		if errors.Is(err, database.ErrNotFound) {
+			continue
+		} else if err != nil {
+			report.addIssue(CheckEpoch, header.Number, &hash, "cannot get %T of epoch %d: %s", *announced, target, err)
+			return
+		}
+
+		if !reflect.DeepEqual(stored, announced) {
+			report.addIssue(CheckEpoch, header.Number, &hash, "%T of epoch %d does not match the digest", *announced, target)
+		}
+		return
+	}
+
+	report.addIssue(CheckEpoch, header.Number, &hash, "%T announced for epoch %d is missing", *announced, epoch+1)
+}
+
+// checkSetChanges checks the GRANDPA set changes applied up to the finalised block match the
+// set changes announced in the digests of the canonical chain.
+func (s *Service) checkSetChanges(report *CheckReport, changes []checkedSetChange, finalisedNumber uint) error {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].effectiveNumber < changes[j].effectiveNumber
+	})
+
+	var setID uint64
+	for _, change := range changes {
+		if change.effectiveNumber > finalisedNumber {
+			break
+		}
+		setID++
+
+		number, err := s.Grandpa.GetSetIDChange(setID)
+		if errors.Is(err, database.ErrNotFound) {
+			report.addIssue(CheckGrandpa, change.announcingNumber, nil,
+				"change to set %d at block #%d is missing", setID, change.effectiveNumber)
+			continue
+		} else if err != nil {
+			return fmt.Errorf("getting set id change %d: %w", setID, err)
+		}
+		if number != change.effectiveNumber {
+			report.addIssue(CheckGrandpa, change.announcingNumber, nil,
+				"change to set %d is at block #%d instead of block #%d", setID, number, change.effectiveNumber)
+		}
+
+		auths, err := types.GrandpaAuthoritiesRawToAuthorities(change.auths)
+		if err != nil {
+			report.addIssue(CheckGrandpa, change.announcingNumber, nil, "cannot decode authorities: %s", err)
+			continue
+		}
+		voters, err := s.Grandpa.GetAuthorities(setID)
+		if err != nil {
+			report.addIssue(CheckGrandpa, change.announcingNumber, nil,
+				"cannot get authorities of set %d: %s", setID, err)
+			continue
+		}
+		if !reflect.DeepEqual(voters, types.NewGrandpaVotersFromAuthorities(auths)) {
+			report.addIssue(CheckGrandpa, change.announcingNumber, nil,
+				"authorities of set %d do not match the digest", setID)
+		}
+	}
+
+	number, err := s.Grandpa.GetSetIDChange(setID + 1)
+	if err == nil && number <= finalisedNumber {
+		report.addIssue(CheckGrandpa, number, nil, "change to set %d is not announced by a digest", setID+1)
+	} else if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("getting set id change %d: %w", setID+1, err)
+	}
+	return nil
+}
+
+// checkStateRoots checks all the nodes and values of the given state tries, including their child
+// tries, are stored. The nodes shared by the tries are only checked once.
+func (s *Service) checkStateRoots(report *CheckReport, roots []checkedStateRoot) error {
+	storage := database.NewTable(s.db, storagePrefix)
+	visited := make(map[common.Hash]struct{})
+
+	type pendingNode struct {
+		merkleValue []byte
+		// node is the decoded node if it is inlined in its parent
+		node *node.Node
+		// keyNibbles is the key of the node up to its partial key
+		keyNibbles []byte
+	}
+
+	for _, stateRoot := range roots {
+		root, blockHash := stateRoot.root, stateRoot.hash
+		if root == trie.EmptyHash {
+			continue
+		}
+		if _, ok := visited[root]; ok {
+			continue
+		}
+		report.StateRootsChecked++
+
+		pending := []pendingNode{{merkleValue: root.ToBytes()}}
+		for len(pending) > 0 {
+			current := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+
+			n := current.node
+			if n == nil {
+				nodeHash := common.NewHash(current.merkleValue)
+				if _, ok := visited[nodeHash]; ok {
+					continue
+				}
+				visited[nodeHash] = struct{}{}
+
+				encoding, err := storage.Get(nodeHash[:])
+				if errors.Is(err, database.ErrNotFound) {
+					report.addIssue(CheckState, stateRoot.number, &blockHash, "node %s of state trie %s is missing", nodeHash, root)
+					continue
+				} else if err != nil {
+					return fmt.Errorf("getting node %s: %w", nodeHash, err)
+				}
+
+				n, err = node.Decode(bytes.NewReader(encoding))
+				if err != nil {
+					report.addIssue(CheckState, stateRoot.number, &blockHash,
+						"cannot decode node %s of state trie %s: %s", nodeHash, root, err)
+					continue
+				}
+
+				report.TrieNodesChecked++
+				if report.TrieNodesChecked%checkProgressInterval == 0 {
+					logger.Infof("checked %d state tries and %d trie nodes",
+						report.StateRootsChecked, report.TrieNodesChecked)
+				}
+			}
+
+			keyNibbles := append(bytes.Clone(current.keyNibbles), n.PartialKey...)
+			value := n.StorageValue
+			if n.IsHashedValue {
+				prefixedKey := bytes.Join([][]byte{n.PartialKey, n.StorageValue}, nil)
+				var err error
+				value, err = storage.Get(prefixedKey)
+				if errors.Is(err, database.ErrNotFound) {
+					value, err = storage.Get(n.StorageValue)
+				}
+				if errors.Is(err, database.ErrNotFound) {
+					report.addIssue(CheckState, stateRoot.number, &blockHash,
+						"value 0x%x of state trie %s is missing", n.StorageValue, root)
+				} else if err != nil {
+					return fmt.Errorf("getting value 0x%x: %w", n.StorageValue, err)
+				}
+			}
+
+			// the values of the child storage keys are the roots of the child tries
+			if len(keyNibbles)%2 == 0 && len(value) == common.HashLength &&
+				bytes.HasPrefix(codec.NibblesToKeyLE(keyNibbles), trie.ChildStorageKeyPrefix) {
+				childRoot := common.NewHash(value)
+				if _, ok := visited[childRoot]; !ok && childRoot != trie.EmptyHash {
+					pending = append(pending, pendingNode{merkleValue: value})
+				}
+			}
+
+			for i, child := range n.Children {
+				if child == nil {
+					continue
+				}
+				childKeyNibbles := append(bytes.Clone(keyNibbles), byte(i))
+				if len(child.MerkleValue) == common.HashLength {
+					pending = append(pending, pendingNode{merkleValue: child.MerkleValue, keyNibbles: childKeyNibbles})
+					continue
+				}
+				pending = append(pending, pendingNode{node: child, keyNibbles: childKeyNibbles})
+			}
+		}
+	}
+	return nil
+}
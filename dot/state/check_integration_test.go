@@ -0,0 +1,177 @@
+//go:build integration
+
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/ChainSafe/gossamer/tests/utils/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestConsensusDigest(t *testing.T, engineID types.ConsensusEngineID, value any) types.ConsensusDigest {
+	t.Helper()
+
+	var data []byte
+	var err error
+	switch engineID {
+	case types.GrandpaEngineID:
+		digest := types.NewGrandpaConsensusDigest()
+		require.NoError(t, digest.SetValue(value))
+		data, err = scale.Marshal(digest)
+	default:
+		digest := types.NewBabeConsensusDigest()
+		require.NoError(t, digest.SetValue(value))
+		data, err = scale.Marshal(digest)
+	}
+	require.NoError(t, err)
+
+	return types.ConsensusDigest{ConsensusEngineID: engineID, Data: data}
+}
+
+func TestService_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockTelemetry(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	serv := NewService(Config{
+		Path:              t.TempDir(),
+		LogLevel:          log.Info,
+		Telemetry:         telemetryMock,
+		GenesisBABEConfig: config.BABEConfigurationTestDefault,
+	})
+
+	genData, genTrie, genesisHeader := newWestendDevGenesisWithTrieAndHeader(t)
+	err := serv.Initialise(&genData, &genesisHeader, genTrie)
+	require.NoError(t, err)
+	err = serv.SetupBase()
+	require.NoError(t, err)
+	err = serv.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := serv.Stop()
+		require.NoError(t, err)
+	})
+
+	auths := []types.GrandpaAuthoritiesRaw{{Key: [32]byte{1}, ID: 0}}
+	nextEpochData := types.NextEpochData{
+		Authorities: []types.AuthorityRaw{{Key: [32]byte{2}, Weight: 1}},
+		Randomness:  [32]byte{3},
+	}
+
+	// the block #1 announces the epoch 1 and the block #2 the set 1 starting at the block #3
+	consensusDigests := map[uint]types.ConsensusDigest{
+		1: newTestConsensusDigest(t, types.BabeEngineID, nextEpochData),
+		2: newTestConsensusDigest(t, types.GrandpaEngineID, types.GrandpaScheduledChange{Auths: auths, Delay: 1}),
+	}
+
+	var chain []*types.Header
+	parentHash := genesisHeader.Hash()
+	for number := uint(1); number <= 5; number++ {
+		preDigest, err := types.NewBabePrimaryPreDigest(0, uint64(number), [32]byte{}, [64]byte{}).ToPreRuntimeDigest()
+		require.NoError(t, err)
+		digest := types.NewDigest()
+		require.NoError(t, digest.Add(*preDigest))
+		if consensusDigest, ok := consensusDigests[number]; ok {
+			require.NoError(t, digest.Add(consensusDigest))
+		}
+
+		block := &types.Block{
+			Header: types.Header{
+				ParentHash: parentHash,
+				Number:     number,
+				StateRoot:  genesisHeader.StateRoot,
+				Digest:     digest,
+			},
+			Body: types.Body{},
+		}
+		err = serv.Block.AddBlockWithArrivalTime(block, time.Now())
+		require.NoError(t, err)
+
+		chain = append(chain, &block.Header)
+		parentHash = block.Header.Hash()
+	}
+
+	authorities, err := types.GrandpaAuthoritiesRawToAuthorities(auths)
+	require.NoError(t, err)
+	err = serv.Grandpa.SetNextChange(types.NewGrandpaVotersFromAuthorities(authorities), 3)
+	require.NoError(t, err)
+	_, err = serv.Grandpa.IncrementSetID()
+	require.NoError(t, err)
+	err = serv.Block.SetJustification(chain[2].Hash(), []byte("justification"))
+	require.NoError(t, err)
+	err = serv.Epoch.SetEpochDataRaw(1, nextEpochData.ToEpochDataRaw())
+	require.NoError(t, err)
+	err = serv.Block.SetFinalisedHash(chain[3].Hash(), 1, 1)
+	require.NoError(t, err)
+
+	report, err := serv.Check(false)
+	require.NoError(t, err)
+	require.Equal(t, &CheckReport{
+		FinalisedNumber:   4,
+		FinalisedHash:     chain[3].Hash(),
+		BlocksChecked:     5,
+		StateRootsChecked: 1,
+		TrieNodesChecked:  report.TrieNodesChecked,
+		Issues:            []CheckIssue{},
+	}, report)
+	require.NotZero(t, report.TrieNodesChecked)
+	require.True(t, report.OK())
+
+	// corrupt the database
+	hash1, hash2, hash3, hash4 := chain[0].Hash(), chain[1].Hash(), chain[2].Hash(), chain[3].Hash()
+	hash5 := chain[4].Hash()
+	stateRoot := genesisHeader.StateRoot
+	require.NoError(t, serv.Block.db.Del(blockBodyKey(hash3)))
+	require.NoError(t, serv.Block.db.Del(prefixKey(hash3, justificationPrefix)))
+	require.NoError(t, serv.Block.db.Del(headerHashKey(2)))
+	require.NoError(t, serv.Block.db.Put(headerHashKey(5), hash5[:]))
+	require.NoError(t, serv.Epoch.db.Del(epochDataKey(1)))
+	require.NoError(t, database.NewTable(serv.db, storagePrefix).Del(stateRoot[:]))
+
+	expectedIssues := []CheckIssue{
+		{Check: CheckBody, Number: 3, Hash: &hash3, Message: "body is missing"},
+		{Check: CheckJustification, Number: 3, Hash: &hash3,
+			Message: "justification of the change to set 1 is missing"},
+		{Check: CheckIndex, Number: 2, Hash: &hash2, Message: "block number index is missing"},
+		{Check: CheckEpoch, Number: 1, Hash: &hash1,
+			Message: "types.EpochDataRaw announced for epoch 1 is missing"},
+		{Check: CheckIndex, Number: 5, Hash: &hash5, Message: "block number index is above the finalised block"},
+		{Check: CheckState, Number: 4, Hash: &hash4,
+			Message: "node " + stateRoot.String() + " of state trie " + stateRoot.String() + " is missing"},
+	}
+
+	report, err = serv.Check(false)
+	require.NoError(t, err)
+	require.Equal(t, expectedIssues, report.Issues)
+	require.Equal(t, uint(0), report.TrieNodesChecked)
+	require.False(t, report.OK())
+
+	report, err = serv.Check(true)
+	require.NoError(t, err)
+	expectedIssues[2].Repaired = true
+	expectedIssues[4].Repaired = true
+	require.Equal(t, expectedIssues, report.Issues)
+
+	// the block number index is repaired
+	hash, err := serv.Block.GetHashByNumber(2)
+	require.NoError(t, err)
+	require.Equal(t, hash2, hash)
+	has, err := serv.Block.db.Has(headerHashKey(5))
+	require.NoError(t, err)
+	require.False(t, has)
+
+	report, err = serv.Check(false)
+	require.NoError(t, err)
+	require.Equal(t, []CheckIssue{expectedIssues[0], expectedIssues[1], expectedIssues[3], expectedIssues[5]},
+		report.Issues)
+}
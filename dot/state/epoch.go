@@ -1164,3 +1164,114 @@ func findFinalizedHeaderForEpoch[T types.NextConfigDataV1 | types.NextEpochData]
 
 	return nil, errHashNotPersisted
 }
+
+// revert writes to the given batch the current epoch of the block the chain is reverted to, along
+// with the removal of the definitions of the epochs following the next epoch and of the next epoch
+// and config data for these epochs or announced by the reverted blocks. The next epoch and config
+// data are removed from memory by applyRevert once the batch is flushed.
+func (s *EpochState) revert(batch database.Batch, epoch uint64, reverted map[common.Hash]struct{}) error {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, epoch)
+	err := batch.Put(currentEpochKey, buf)
+	if err != nil {
+		return fmt.Errorf("storing current epoch: %w", err)
+	}
+
+	for _, prefix := range [][]byte{epochDataPrefix, configDataPrefix} {
+		err = deleteEpochDefinitionsAfter(s.db, batch, prefix, epoch+1)
+		if err != nil {
+			return fmt.Errorf("deleting epoch definitions after epoch %d: %w", epoch+1, err)
+		}
+	}
+
+	s.nextEpochDataLock.RLock()
+	err = revertNextEpochMap(batch, s.nextEpochData, nextEpochDataKey, epoch+1, reverted)
+	s.nextEpochDataLock.RUnlock()
+	if err != nil {
+		return fmt.Errorf("reverting next epoch data: %w", err)
+	}
+
+	s.nextConfigDataLock.RLock()
+	err = revertNextEpochMap(batch, s.nextConfigData, nextConfigDataKey, epoch+1, reverted)
+	s.nextConfigDataLock.RUnlock()
+	if err != nil {
+		return fmt.Errorf("reverting next config data: %w", err)
+	}
+
+	return nil
+}
+
+// applyRevert removes from memory the next epoch and config data for the epochs following the next
+// epoch or announced by the reverted blocks, once the changes written by revert are flushed.
+func (s *EpochState) applyRevert(epoch uint64, reverted map[common.Hash]struct{}) {
+	s.nextEpochDataLock.Lock()
+	s.nextEpochData.revert(epoch+1, reverted)
+	s.nextEpochDataLock.Unlock()
+
+	s.nextConfigDataLock.Lock()
+	s.nextConfigData.revert(epoch+1, reverted)
+	s.nextConfigDataLock.Unlock()
+}
+
+// deleteEpochDefinitionsAfter writes to the given batch the removal of the epoch data or config data
+// of the epochs greater than the given epoch
+func deleteEpochDefinitionsAfter(db database.Table, batch database.Batch, prefix []byte, epoch uint64) error {
+	iter, err := db.NewPrefixIterator(prefix)
+	if err != nil {
+		return fmt.Errorf("creating iterator: %w", err)
+	}
+	defer iter.Release()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		key := bytes.TrimPrefix(iter.Key(), []byte(epochPrefix))
+		if len(key) != len(prefix)+8 || binary.LittleEndian.Uint64(key[len(prefix):]) <= epoch {
+			continue
+		}
+
+		err = batch.Del(bytes.Clone(key))
+		if err != nil {
+			return fmt.Errorf("deleting epoch definition: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// revertNextEpochMap writes to the given batch the removal of the next epoch or config data of the
+// epochs greater than the given epoch, or announced by one of the reverted blocks
+func revertNextEpochMap[T types.NextEpochData | types.NextConfigDataV1](batch database.Batch,
+	nem nextEpochMap[T], key func(epoch uint64, hash common.Hash) []byte,
+	epoch uint64, reverted map[common.Hash]struct{}) error {
+	for e, hashes := range nem {
+		for hash := range hashes {
+			_, isReverted := reverted[hash]
+			if !isReverted && e <= epoch {
+				continue
+			}
+
+			err := batch.Del(key(e, hash))
+			if err != nil {
+				return fmt.Errorf("deleting data of epoch %d announced by block %s: %w", e, hash, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// revert removes the next epoch or config data of the epochs greater than the given epoch,
+// or announced by one of the reverted blocks
+func (nem nextEpochMap[T]) revert(epoch uint64, reverted map[common.Hash]struct{}) {
+	for e, hashes := range nem {
+		for hash := range hashes {
+			_, isReverted := reverted[hash]
+			if isReverted || e > epoch {
+				delete(hashes, hash)
+			}
+		}
+
+		if len(hashes) == 0 {
+			delete(nem, e)
+		}
+	}
+}
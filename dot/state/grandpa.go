@@ -361,9 +361,13 @@ func (s *GrandpaState) GetAuthorities(setID uint64) ([]types.GrandpaVoter, error
 
 // setCurrentSetID sets the current set ID
 func (s *GrandpaState) setCurrentSetID(setID uint64) error {
+	return s.db.Put(currentSetIDKey, setIDToBytes(setID))
+}
+
+func setIDToBytes(setID uint64) []byte {
 	buf := make([]byte, 8)
 	binary.LittleEndian.PutUint64(buf, setID)
-	return s.db.Put(currentSetIDKey, buf)
+	return buf
 }
 
 // GetCurrentSetID retrieves the current set ID
@@ -382,9 +386,13 @@ func (s *GrandpaState) GetCurrentSetID() (uint64, error) {
 
 // SetLatestRound sets the latest finalised GRANDPA round in the db
 func (s *GrandpaState) SetLatestRound(round uint64) error {
+	return s.db.Put(common.LatestFinalizedRoundKey, roundToBytes(round))
+}
+
+func roundToBytes(round uint64) []byte {
 	buf := make([]byte, 8)
 	binary.LittleEndian.PutUint64(buf, round)
-	return s.db.Put(common.LatestFinalizedRoundKey, buf)
+	return buf
 }
 
 // GetLatestRound gets the latest finalised GRANDPA roundfrom the db
@@ -496,6 +504,49 @@ func (s *GrandpaState) GetSetIDByBlockNumber(blockNumber uint) (uint64, error) {
 	}
 }
 
+// revert writes to the given batch the current set ID of the block following the given block number
+// the chain is reverted to, along with the removal of the authority set changes applied above it.
+// It returns the current set ID before and after reverting. The pending changes announced above the
+// block number are removed by applyRevert once the batch is flushed.
+func (s *GrandpaState) revert(batch database.Batch, number uint) (prevSetID, setID uint64, err error) {
+	prevSetID, err = s.GetCurrentSetID()
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot get current set ID: %w", err)
+	}
+
+	setID, err = s.GetSetIDByBlockNumber(number + 1)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot get set ID for block number %d: %w", number+1, err)
+	}
+
+	err = batch.Put(currentSetIDKey, setIDToBytes(setID))
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot set current set ID: %w", err)
+	}
+
+	// remove previously set grandpa changes, need to go up to prevSetID+1 in case of a scheduled change
+	for i := setID + 1; i <= prevSetID+1; i++ {
+		err = batch.Del(setIDChangeKey(i))
+		if err != nil {
+			return 0, 0, fmt.Errorf("cannot delete set ID change %d: %w", i, err)
+		}
+
+		err = batch.Del(authoritiesKey(i))
+		if err != nil {
+			return 0, 0, fmt.Errorf("cannot delete authorities of set ID %d: %w", i, err)
+		}
+	}
+
+	return prevSetID, setID, nil
+}
+
+// applyRevert removes the pending changes announced above the given block number the chain is
+// reverted to, once the changes written by revert are flushed to the database.
+func (s *GrandpaState) applyRevert(number uint) {
+	s.forcedChanges.revert(number)
+	s.scheduledChangeRoots.revert(number)
+}
+
 // SetNextPause sets the next grandpa pause at the given block number
 func (s *GrandpaState) SetNextPause(number uint) error {
 	value := common.UintToBytes(number)
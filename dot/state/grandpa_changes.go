@@ -132,6 +132,18 @@ func (oc *orderedPendingChanges) pruneChanges(hash common.Hash, isDescendantOf i
 	return nil
 }
 
+// revert removes the changes announced by blocks with a number greater than the given number
+func (oc *orderedPendingChanges) revert(number uint) {
+	onRevertedChainForcedChanges := make([]pendingChange, 0, oc.Len())
+	for _, forcedChange := range *oc {
+		if forcedChange.announcingHeader.Number <= number {
+			onRevertedChainForcedChanges = append(onRevertedChainForcedChanges, forcedChange)
+		}
+	}
+
+	*oc = onRevertedChainForcedChanges
+}
+
 func (oc *orderedPendingChanges) pruneAll() {
 	*oc = make([]pendingChange, 0, oc.Len())
 }
@@ -319,6 +331,24 @@ func (ct *changeTree) pruneChanges(hash common.Hash, isDescendantOf isDescendant
 	return nil
 }
 
+// revert removes the changes announced by blocks with a number greater than the given number,
+// since a change node always has a greater number than its parent node, their children are removed too
+func (ct *changeTree) revert(number uint) {
+	var onRevertedChainChanges []*pendingChangeNode
+	for _, node := range *ct {
+		if node.change.announcingHeader.Number > number {
+			continue
+		}
+
+		children := changeTree(node.nodes)
+		children.revert(number)
+		node.nodes = children
+		onRevertedChainChanges = append(onRevertedChainChanges, node)
+	}
+
+	*ct = onRevertedChainChanges
+}
+
 func (ct *changeTree) pruneAll() {
 	*ct = []*pendingChangeNode{}
 }
@@ -43,7 +43,7 @@ func (s *Service) Initialise(gen *genesis.Genesis, header *types.Header, t trie.
 
 	// TODO: all trie related db operations should be done in pkg/trie
 	if inmemoryTrie, ok := t.(*inmemory_trie.InMemoryTrie); ok {
-		if err = inmemoryTrie.WriteDirty(database.NewTable(db, storagePrefix)); err != nil {
+		if err = inmemoryTrie.WriteDirty(trieWriter(database.NewTable(db, storagePrefix))); err != nil {
 			return fmt.Errorf("failed to write genesis trie to database: %w", err)
 		}
 	}
@@ -76,7 +76,7 @@ func (s *Service) Initialise(gen *genesis.Genesis, header *types.Header, t trie.
 	}
 
 	// create storage state from genesis trie
-	storageState, err := NewStorageState(db, blockState, tries)
+	storageState, err := newStorageState(s.storageBackend, db, blockState, tries)
 	if err != nil {
 		return fmt.Errorf("failed to create storage state from trie: %s", err)
 	}
@@ -142,7 +142,7 @@ func (s *Service) storeInitialValues(data *genesis.Data, t trie.Trie) error {
 	// write genesis trie to database
 	// TODO: all trie related db operations should be done in pkg/trie
 	if inmemoryTrie, ok := t.(*inmemory_trie.InMemoryTrie); ok {
-		if err := inmemoryTrie.WriteDirty(database.NewTable(s.db, storagePrefix)); err != nil {
+		if err := inmemoryTrie.WriteDirty(trieWriter(database.NewTable(s.db, storagePrefix))); err != nil {
 			return fmt.Errorf("failed to write genesis trie to database: %w", err)
 		}
 	}
@@ -152,6 +152,11 @@ func (s *Service) storeInitialValues(data *genesis.Data, t trie.Trie) error {
 		return fmt.Errorf("failed to write genesis data to database: %s", err)
 	}
 
+	// a new database has the latest schema, it does not need any migration
+	if err := s.Base.StoreSchemaVersion(SchemaVersion); err != nil {
+		return fmt.Errorf("failed to write schema version to database: %s", err)
+	}
+
 	return nil
 }
 
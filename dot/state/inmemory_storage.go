@@ -31,16 +31,15 @@ func errTrieDoesNotExist(hash common.Hash) error {
 
 // InmemoryStorageState is the struct that holds the trie, db and lock
 type InmemoryStorageState struct {
+	storageNotifier
+
 	blockState *BlockState
 	tries      *Tries
 
 	db GetterPutterNewBatcher
 	sync.RWMutex
 
-	// change notifiers
-	observerListMutex sync.RWMutex
-	observerList      []Observer
-	pruner            pruner.Pruner
+	pruner pruner.Pruner
 }
 
 // NewStorageState creates a new StorageState backed by the given block state
@@ -49,13 +48,22 @@ func NewStorageState(db database.Database, blockState *BlockState,
 	tries *Tries) (*InmemoryStorageState, error) {
 	storageTable := database.NewTable(db, storagePrefix)
 
-	return &InmemoryStorageState{
+	s := &InmemoryStorageState{
+		blockState: blockState,
+		tries:      tries,
+		db:         storageTable,
+		pruner:     &pruner.ArchiveNode{},
+	}
+	s.storageNotifier = storageNotifier{
 		blockState:   blockState,
-		tries:        tries,
-		db:           storageTable,
+		trieState:    s.TrieState,
 		observerList: []Observer{},
-		pruner:       &pruner.ArchiveNode{},
-	}, nil
+	}
+	return s, nil
+}
+
+func (s *InmemoryStorageState) setPruner(p pruner.Pruner) {
+	s.pruner = p
 }
 
 // StoreTrie stores the given trie in the StorageState and writes it to the database
@@ -70,7 +78,7 @@ func (s *InmemoryStorageState) StoreTrie(ts *storage.TrieState, header *types.He
 		}
 
 		err = s.pruner.StoreJournalRecord(
-			deletedNodeHashes, insertedNodeHashes, header.Hash(), int64(header.Number)) //nolint:gosec
+			deletedNodeHashes, insertedNodeHashes, header.Hash(), header.ParentHash, header.Number)
 		if err != nil {
 			return fmt.Errorf("storing journal record: %w", err)
 		}
@@ -80,7 +88,7 @@ func (s *InmemoryStorageState) StoreTrie(ts *storage.TrieState, header *types.He
 
 	// TODO: all trie related db operations should be done in pkg/trie
 	if inmemoryTrie, ok := ts.Trie().(*inmemory_trie.InMemoryTrie); ok {
-		if err := inmemoryTrie.WriteDirty(s.db); err != nil {
+		if err := inmemoryTrie.WriteDirty(trieWriter(s.db)); err != nil {
 			logger.Warnf("failed to write trie with root %s to database: %s", root, err)
 			return err
 		}
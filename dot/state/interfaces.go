@@ -26,6 +26,7 @@ type BlockStateDatabase interface {
 	GetPutDeleter
 	Haser
 	NewBatcher
+	NewPrefixIterator(prefix []byte) (database.Iterator, error)
 }
 
 // GetPutter has methods to get and put key values.
@@ -0,0 +1,189 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// SchemaVersion is the version of the database schema written by this node, the version of
+// the last migration.
+const SchemaVersion uint32 = 1
+
+// ErrSchemaVersionTooNew is returned when the database schema is newer than the one of this node,
+// the database being written by a newer node.
+var ErrSchemaVersionTooNew = errors.New("database schema version is newer than supported")
+
+// Migration changes the layout of the database from the schema version preceding its version.
+type Migration struct {
+	Version     uint32
+	Description string
+	migrate     func(db database.Database) error
+}
+
+// migrations are the migrations of the database schema, in ascending order of versions.
+// A migration is added along with the increment of SchemaVersion for each change of layout.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "store the hashed values of the state tries under their hash",
+		migrate:     migrateHashedTrieValues,
+	},
+}
+
+// Migrate runs the migrations of the database schema pending, from the schema version stored in
+// the database to SchemaVersion. It returns the schema version of the database before the migration
+// and the migrations pending, which are only listed and not run if dryRun is set.
+func Migrate(db database.Database, dryRun bool) (version uint32, pending []Migration, err error) {
+	return runMigrations(db, migrations, dryRun)
+}
+
+func runMigrations(db database.Database, registry []Migration, dryRun bool) (
+	version uint32, pending []Migration, err error) {
+	base := NewBaseState(db)
+	version, err = base.LoadSchemaVersion()
+	if err != nil {
+		return 0, nil, fmt.Errorf("loading schema version: %w", err)
+	}
+
+	var latest uint32
+	if len(registry) > 0 {
+		latest = registry[len(registry)-1].Version
+	}
+	if version > latest {
+		return version, nil, fmt.Errorf("%w: %d, latest is %d", ErrSchemaVersionTooNew, version, latest)
+	}
+
+	for _, migration := range registry {
+		if migration.Version > version {
+			pending = append(pending, migration)
+		}
+	}
+
+	if dryRun {
+		return version, pending, nil
+	}
+
+	current := version
+	for _, migration := range pending {
+		logger.Infof("migrating database schema from version %d to version %d: %s",
+			current, migration.Version, migration.Description)
+
+		err = migration.migrate(db)
+		if err != nil {
+			return version, pending, fmt.Errorf("migrating database schema to version %d: %w", migration.Version, err)
+		}
+
+		// the version is stored after each migration, so a failed migration is run again on restart
+		err = base.StoreSchemaVersion(migration.Version)
+		if err != nil {
+			return version, pending, fmt.Errorf("storing schema version %d: %w", migration.Version, err)
+		}
+		current = migration.Version
+	}
+
+	return version, pending, nil
+}
+
+// migrationBatchSize is the number of keys processed by a migration between two flushes of its
+// changes, along with its progress.
+const migrationBatchSize = 10000
+
+// migrateHashedTrieValues stores the hashed values of the state tries, stored under the partial key
+// of their node followed by their hash only, under their hash as well, as read by the trie database.
+// The changes are flushed along with the last key processed every migrationBatchSize keys, so an
+// interrupted migration resumes after this key.
+func migrateHashedTrieValues(db database.Database) error {
+	storagePrefixBytes := []byte(storagePrefix)
+	iter, err := db.NewPrefixIterator(storagePrefixBytes)
+	if err != nil {
+		return fmt.Errorf("creating prefix iterator: %w", err)
+	}
+	defer iter.Release()
+
+	progress, err := db.Get(common.MigrationProgressKey)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		iter.First()
+	case err != nil:
+		return fmt.Errorf("getting migration progress: %w", err)
+	default:
+		logger.Infof("resuming migration after key 0x%x", progress)
+		if iter.SeekGE(progress) && bytes.Equal(iter.Key(), progress) {
+			iter.Next()
+		}
+	}
+
+	storage := database.NewTable(db, storagePrefix)
+	batch := db.NewBatch()
+	defer func() { batch.Reset() }()
+	storageBatch := database.NewTableBatch(batch, storagePrefix)
+
+	var processed, migrated uint
+	for ; iter.Valid(); iter.Next() {
+		processed++
+		if processed%migrationBatchSize == 0 {
+			err = batch.Put(common.MigrationProgressKey, iter.Key())
+			if err != nil {
+				return fmt.Errorf("storing migration progress: %w", err)
+			}
+
+			err = batch.Flush()
+			if err != nil {
+				return fmt.Errorf("flushing batch: %w", err)
+			}
+			batch = db.NewBatch()
+			storageBatch = database.NewTableBatch(batch, storagePrefix)
+			logger.Debugf("processed %d keys, stored %d hashed values", processed, migrated)
+		}
+
+		// the trie nodes are stored under their hash, the hashed values under a longer key
+		key := bytes.TrimPrefix(iter.Key(), storagePrefixBytes)
+		if len(key) <= common.HashLength {
+			continue
+		}
+
+		hash := key[len(key)-common.HashLength:]
+		value := iter.Value()
+		valueHash, err := common.Blake2bHash(value)
+		if err != nil {
+			return fmt.Errorf("hashing value: %w", err)
+		}
+		if !bytes.Equal(valueHash[:], hash) {
+			continue
+		}
+
+		has, err := storage.Has(hash)
+		if err != nil {
+			return fmt.Errorf("checking value 0x%x: %w", hash, err)
+		}
+		if has {
+			continue
+		}
+
+		err = storageBatch.Put(hash, value)
+		if err != nil {
+			return fmt.Errorf("storing value 0x%x: %w", hash, err)
+		}
+		migrated++
+	}
+
+	err = batch.Del(common.MigrationProgressKey)
+	if err != nil {
+		return fmt.Errorf("deleting migration progress: %w", err)
+	}
+
+	err = batch.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing batch: %w", err)
+	}
+
+	logger.Infof("stored %d hashed values of the state tries under their hash", migrated)
+	return nil
+}
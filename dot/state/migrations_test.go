@@ -0,0 +1,242 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_migrations(t *testing.T) {
+	t.Parallel()
+
+	require.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.Equal(t, uint32(i+1), migration.Version)
+		assert.NotEmpty(t, migration.Description)
+		assert.NotNil(t, migration.migrate)
+	}
+	assert.Equal(t, SchemaVersion, migrations[len(migrations)-1].Version)
+}
+
+func Test_runMigrations(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	testCases := map[string]struct {
+		storedVersion   uint32
+		dryRun          bool
+		failingVersion  uint32
+		version         uint32
+		pendingVersions []uint32
+		ranVersions     []uint32
+		finalVersion    uint32
+		errWrapped      error
+		errMessage      string
+	}{
+		"database_without_version": {
+			version:         0,
+			pendingVersions: []uint32{1, 2, 3},
+			ranVersions:     []uint32{1, 2, 3},
+			finalVersion:    3,
+		},
+		"partially_migrated_database": {
+			storedVersion:   1,
+			version:         1,
+			pendingVersions: []uint32{2, 3},
+			ranVersions:     []uint32{2, 3},
+			finalVersion:    3,
+		},
+		"latest_database": {
+			storedVersion: 3,
+			version:       3,
+			finalVersion:  3,
+		},
+		"dry_run": {
+			storedVersion:   1,
+			dryRun:          true,
+			version:         1,
+			pendingVersions: []uint32{2, 3},
+			finalVersion:    1,
+		},
+		"newer_database": {
+			storedVersion: 4,
+			version:       4,
+			finalVersion:  4,
+			errWrapped:    ErrSchemaVersionTooNew,
+			errMessage:    "database schema version is newer than supported: 4, latest is 3",
+		},
+		"failing_migration": {
+			failingVersion:  2,
+			version:         0,
+			pendingVersions: []uint32{1, 2, 3},
+			ranVersions:     []uint32{1, 2},
+			finalVersion:    1,
+			errWrapped:      errTest,
+			errMessage:      "migrating database schema to version 2: test error",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := NewInMemoryDB(t)
+			base := NewBaseState(db)
+			if testCase.storedVersion != 0 {
+				err := base.StoreSchemaVersion(testCase.storedVersion)
+				require.NoError(t, err)
+			}
+
+			var ranVersions []uint32
+			var registry []Migration
+			for version := uint32(1); version <= 3; version++ {
+				version := version
+				registry = append(registry, Migration{
+					Version:     version,
+					Description: "test migration",
+					migrate: func(database.Database) error {
+						ranVersions = append(ranVersions, version)
+						if version == testCase.failingVersion {
+							return errTest
+						}
+						return nil
+					},
+				})
+			}
+
+			version, pending, err := runMigrations(db, registry, testCase.dryRun)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.version, version)
+			var pendingVersions []uint32
+			for _, migration := range pending {
+				pendingVersions = append(pendingVersions, migration.Version)
+			}
+			assert.Equal(t, testCase.pendingVersions, pendingVersions)
+			assert.Equal(t, testCase.ranVersions, ranVersions)
+
+			finalVersion, err := base.LoadSchemaVersion()
+			require.NoError(t, err)
+			assert.Equal(t, testCase.finalVersion, finalVersion)
+		})
+	}
+}
+
+func Test_migrateHashedTrieValues(t *testing.T) {
+	t.Parallel()
+
+	db := NewInMemoryDB(t)
+	storage := database.NewTable(db, storagePrefix)
+
+	value := make([]byte, 64)
+	value[0] = 1
+	valueHash := common.MustBlake2bHash(value)
+	partialKey := []byte{1, 2, 3}
+	prefixedKey := append(partialKey, valueHash[:]...)
+	require.NoError(t, storage.Put(prefixedKey, value))
+
+	// the nodes and the keys not ending with the hash of their value are left unchanged
+	nodeEncoding := []byte{1, 2}
+	nodeHash := common.MustBlake2bHash(nodeEncoding)
+	require.NoError(t, storage.Put(nodeHash[:], nodeEncoding))
+	otherKey := append([]byte{4}, nodeHash[:]...)
+	require.NoError(t, storage.Put(otherKey, value))
+
+	err := migrateHashedTrieValues(db)
+	require.NoError(t, err)
+
+	migrated, err := storage.Get(valueHash[:])
+	require.NoError(t, err)
+	assert.Equal(t, value, migrated)
+
+	stored, err := storage.Get(prefixedKey)
+	require.NoError(t, err)
+	assert.Equal(t, value, stored)
+
+	stored, err = storage.Get(nodeHash[:])
+	require.NoError(t, err)
+	assert.Equal(t, nodeEncoding, stored)
+
+	// running the migration again changes nothing
+	err = migrateHashedTrieValues(db)
+	require.NoError(t, err)
+	migrated, err = storage.Get(valueHash[:])
+	require.NoError(t, err)
+	assert.Equal(t, value, migrated)
+}
+
+func Test_migrateHashedTrieValues_resume(t *testing.T) {
+	t.Parallel()
+
+	db := NewInMemoryDB(t)
+	storage := database.NewTable(db, storagePrefix)
+
+	hashes := make([]common.Hash, 3)
+	for i := range hashes {
+		value := make([]byte, 64)
+		value[0] = byte(i)
+		hashes[i] = common.MustBlake2bHash(value)
+		require.NoError(t, storage.Put(append([]byte{byte(i)}, hashes[i][:]...), value))
+	}
+
+	// the migration was interrupted after processing the second key
+	progress := append([]byte(storagePrefix), 1)
+	progress = append(progress, hashes[1][:]...)
+	require.NoError(t, db.Put(common.MigrationProgressKey, progress))
+
+	err := migrateHashedTrieValues(db)
+	require.NoError(t, err)
+
+	for i, hash := range hashes {
+		has, err := storage.Has(hash[:])
+		require.NoError(t, err)
+		assert.Equal(t, i == 2, has, "value %d", i)
+	}
+
+	_, err = db.Get(common.MigrationProgressKey)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func Test_migrateHashedTrieValues_progress(t *testing.T) {
+	t.Parallel()
+
+	db := NewInMemoryDB(t)
+	storage := database.NewTable(db, storagePrefix)
+
+	for i := 0; i < migrationBatchSize+1; i++ {
+		value := binary.BigEndian.AppendUint32(make([]byte, 60), uint32(i))
+		hash := common.MustBlake2bHash(value)
+		require.NoError(t, storage.Put(append([]byte{1}, hash[:]...), value))
+	}
+
+	err := migrateHashedTrieValues(db)
+	require.NoError(t, err)
+
+	iter, err := db.NewPrefixIterator([]byte(storagePrefix))
+	require.NoError(t, err)
+	defer iter.Release()
+
+	var hashKeys int
+	for iter.First(); iter.Valid(); iter.Next() {
+		if len(iter.Key()) == len(storagePrefix)+common.HashLength {
+			hashKeys++
+		}
+	}
+	assert.Equal(t, migrationBatchSize+1, hashKeys)
+
+	_, err = db.Get(common.MigrationProgressKey)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
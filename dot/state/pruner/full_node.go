@@ -0,0 +1,421 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pruner
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// journalPrefix is the prefix of the database table of the journal
+const journalPrefix = "journal"
+
+var (
+	recordKeyPrefix  = []byte("record")
+	canonicalisedKey = []byte("canonicalised")
+)
+
+var logger = log.NewFromGlobal(
+	log.AddContext("pkg", "pruner"),
+)
+
+// journalRecord holds the hashes of the trie nodes inserted and deleted by the state trie
+// of a block, compared to the state trie of its parent block.
+type journalRecord struct {
+	BlockHash   common.Hash
+	BlockNumber uint
+	ParentHash  common.Hash
+	Inserted    []common.Hash
+	Deleted     []common.Hash
+}
+
+// recordKey is the key of the journal record of the block, ordered by block number
+func recordKey(blockNumber uint, blockHash common.Hash) []byte {
+	key := make([]byte, 0, len(recordKeyPrefix)+8+len(blockHash))
+	key = append(key, recordKeyPrefix...)
+	key = binary.BigEndian.AppendUint64(key, uint64(blockNumber))
+	return append(key, blockHash[:]...)
+}
+
+// canonicalisedBlock is the finalised block the journal was last canonicalised at
+type canonicalisedBlock struct {
+	Hash   common.Hash
+	Number uint
+}
+
+// FullNode prunes the trie nodes of the state tries a full node no longer needs: the state
+// tries of the finalised blocks more than the retained blocks behind the finalised block,
+// and the state tries of the blocks of the forks pruned on finalisation.
+//
+// The trie nodes inserted and deleted by each block are journaled in the database. On
+// finalisation, the records of the pruned forks are discarded along with the nodes they
+// inserted, then the records of the finalised blocks no longer retained are discarded along
+// with the nodes they deleted. A node is kept as long as a record of a block still retained
+// or not finalised yet inserts it, so the nodes shared between forks or inserted again by a
+// later block are not deleted. The nodes are deleted before the journal is updated, so a
+// prune interrupted by a crash is done again on the next finalisation.
+// The storage values stored apart from the trie nodes are not pruned.
+type FullNode struct {
+	mtx          sync.Mutex
+	journalDB    database.Table
+	storageDB    database.Table
+	retainBlocks uint
+
+	records map[common.Hash]*journalRecord
+	// insertions is the number of records inserting each trie node
+	insertions map[common.Hash]uint
+	// canonicalised is only valid once hasCanonicalised is set
+	canonicalised    canonicalisedBlock
+	hasCanonicalised bool
+}
+
+// NewFullNode creates a full node pruner journaling in the given database and deleting the trie
+// nodes from the storage database, keeping the state tries of the given number of blocks behind
+// the finalised block. The journal is loaded from the database.
+func NewFullNode(db database.Database, storageDB database.Table, retainBlocks uint32) (*FullNode, error) {
+	p := &FullNode{
+		journalDB:    database.NewTable(db, journalPrefix),
+		storageDB:    storageDB,
+		retainBlocks: uint(retainBlocks),
+		records:      make(map[common.Hash]*journalRecord),
+		insertions:   make(map[common.Hash]uint),
+	}
+
+	err := p.loadJournal()
+	if err != nil {
+		return nil, fmt.Errorf("loading journal: %w", err)
+	}
+
+	return p, nil
+}
+
+func (p *FullNode) loadJournal() error {
+	encoded, err := p.journalDB.Get(canonicalisedKey)
+	if err == nil {
+		err = scale.Unmarshal(encoded, &p.canonicalised)
+		if err != nil {
+			return fmt.Errorf("decoding canonicalised block: %w", err)
+		}
+		p.hasCanonicalised = true
+	} else if !errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("getting canonicalised block: %w", err)
+	}
+
+	iter, err := p.journalDB.NewPrefixIterator(recordKeyPrefix)
+	if err != nil {
+		return fmt.Errorf("creating journal iterator: %w", err)
+	}
+	defer iter.Release()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		record := new(journalRecord)
+		err = scale.Unmarshal(iter.Value(), record)
+		if err != nil {
+			return fmt.Errorf("decoding journal record: %w", err)
+		}
+		p.addRecord(record)
+	}
+
+	logger.Debugf("loaded %d journal records", len(p.records))
+	return nil
+}
+
+// addRecord adds the record to the journal in memory, replacing the record of the same block.
+// The caller must hold the lock.
+func (p *FullNode) addRecord(record *journalRecord) {
+	previous, ok := p.records[record.BlockHash]
+	if ok {
+		p.removeRecord(previous)
+	}
+
+	p.records[record.BlockHash] = record
+	for _, hash := range record.Inserted {
+		p.insertions[hash]++
+	}
+}
+
+// removeRecord removes the record from the journal in memory. The caller must hold the lock.
+func (p *FullNode) removeRecord(record *journalRecord) {
+	delete(p.records, record.BlockHash)
+	for _, hash := range record.Inserted {
+		p.insertions[hash]--
+		if p.insertions[hash] == 0 {
+			delete(p.insertions, hash)
+		}
+	}
+}
+
+// StoreJournalRecord journals the hashes of the trie nodes deleted and inserted by the state trie
+// of the block, compared to the state trie of its parent block. It must be called before the
+// inserted nodes are written to the storage database.
+func (p *FullNode) StoreJournalRecord(deletedNodeHashes, insertedNodeHashes map[common.Hash]struct{},
+	blockHash, parentHash common.Hash, blockNumber uint) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.hasCanonicalised && blockNumber <= p.canonicalised.Number {
+		// the block is either finalised already or on a fork pruned already,
+		// its state trie is never pruned
+		logger.Debugf("not journaling block #%d (%s) at or below the canonicalised block #%d",
+			blockNumber, blockHash, p.canonicalised.Number)
+		return nil
+	}
+
+	record := &journalRecord{
+		BlockHash:   blockHash,
+		BlockNumber: blockNumber,
+		ParentHash:  parentHash,
+		Inserted:    make([]common.Hash, 0, len(insertedNodeHashes)),
+		Deleted:     make([]common.Hash, 0, len(deletedNodeHashes)),
+	}
+
+	// a node both deleted and inserted is in the state tries of both the parent block
+	// and the block, it is neither inserted nor deleted by the block
+	for hash := range insertedNodeHashes {
+		if _, ok := deletedNodeHashes[hash]; !ok {
+			record.Inserted = append(record.Inserted, hash)
+		}
+	}
+	for hash := range deletedNodeHashes {
+		if _, ok := insertedNodeHashes[hash]; !ok {
+			record.Deleted = append(record.Deleted, hash)
+		}
+	}
+
+	encoded, err := scale.Marshal(*record)
+	if err != nil {
+		return fmt.Errorf("encoding journal record: %w", err)
+	}
+
+	err = p.journalDB.Put(recordKey(blockNumber, blockHash), encoded)
+	if err != nil {
+		return fmt.Errorf("storing journal record: %w", err)
+	}
+
+	p.addRecord(record)
+	return nil
+}
+
+// Prune canonicalises the journal at the finalised block and deletes the trie nodes no longer
+// needed. The records of the blocks not descending from the finalised block, up to its number,
+// are discarded along with the nodes they inserted. The records of the finalised blocks more than
+// the retained blocks behind the finalised block are discarded along with the nodes they deleted.
+// A block finalised before the block the journal was canonicalised at is ignored.
+func (p *FullNode) Prune(finalisedHash common.Hash, finalisedNumber uint) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.hasCanonicalised && finalisedNumber < p.canonicalised.Number {
+		return nil
+	}
+
+	canonical, complete := p.finalisedChain(finalisedHash)
+	if !complete && p.hasCanonicalised {
+		// blocks between the canonicalised and the finalised blocks were not journaled, the nodes
+		// they inserted are unknown so no node inserted or deleted before them can be deleted
+		logger.Warnf("journal does not have all the blocks from the canonicalised block #%d (%s) "+
+			"to the finalised block #%d (%s), discarding the records without pruning their nodes",
+			p.canonicalised.Number, p.canonicalised.Hash, finalisedNumber, finalisedHash)
+	}
+
+	var discarded, pruned []*journalRecord
+	for hash, record := range p.records {
+		if record.BlockNumber > finalisedNumber {
+			continue
+		}
+
+		_, isCanonical := canonical[hash]
+		if complete && p.hasCanonicalised && record.BlockNumber <= p.canonicalised.Number {
+			isCanonical = true
+		}
+
+		if !isCanonical {
+			discarded = append(discarded, record)
+		} else if finalisedNumber >= p.retainBlocks && record.BlockNumber <= finalisedNumber-p.retainBlocks {
+			pruned = append(pruned, record)
+		}
+	}
+
+	// the nodes are kept while a record still in the journal inserts them, so
+	// the insertions of the records are removed in the order they are discarded
+	removedInsertions := make(map[common.Hash]uint)
+	isInserted := func(hash common.Hash) bool {
+		return p.insertions[hash] > removedInsertions[hash]
+	}
+
+	deletedNodes := make(map[common.Hash]struct{})
+	for _, record := range discarded {
+		for _, hash := range record.Inserted {
+			removedInsertions[hash]++
+		}
+	}
+	if complete {
+		for _, record := range discarded {
+			for _, hash := range record.Inserted {
+				if !isInserted(hash) {
+					deletedNodes[hash] = struct{}{}
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(pruned, func(a, b *journalRecord) int {
+		return cmp.Compare(a.BlockNumber, b.BlockNumber)
+	})
+	for _, record := range pruned {
+		for _, hash := range record.Inserted {
+			removedInsertions[hash]++
+		}
+		for _, hash := range record.Deleted {
+			if !isInserted(hash) {
+				deletedNodes[hash] = struct{}{}
+			}
+		}
+	}
+
+	err := p.deleteNodes(deletedNodes)
+	if err != nil {
+		return err
+	}
+
+	finalised := canonicalisedBlock{Hash: finalisedHash, Number: finalisedNumber}
+	err = p.storeJournal(append(discarded, pruned...), finalised)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range discarded {
+		p.removeRecord(record)
+	}
+	for _, record := range pruned {
+		p.removeRecord(record)
+	}
+	p.canonicalised = finalised
+	p.hasCanonicalised = true
+
+	if len(deletedNodes) > 0 {
+		logger.Debugf("pruned %d trie nodes of %d discarded and %d finalised blocks at finalised block #%d (%s)",
+			len(deletedNodes), len(discarded), len(pruned), finalisedNumber, finalisedHash)
+	}
+	return nil
+}
+
+// Revert discards the records of the blocks above the target block the chain is reverted to,
+// and canonicalises the journal back at the target block if it was canonicalised above it.
+// The nodes inserted by the discarded records are not deleted: the records inserting the nodes
+// of the state trie of the target block might be pruned already, so these nodes cannot be told
+// apart from the nodes only inserted by the reverted blocks.
+func (p *FullNode) Revert(targetHash common.Hash, targetNumber uint) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	var discarded []*journalRecord
+	for _, record := range p.records {
+		if record.BlockNumber > targetNumber {
+			discarded = append(discarded, record)
+		}
+	}
+
+	canonicalised := p.canonicalised
+	if !p.hasCanonicalised || canonicalised.Number > targetNumber {
+		canonicalised = canonicalisedBlock{Hash: targetHash, Number: targetNumber}
+	}
+
+	err := p.storeJournal(discarded, canonicalised)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range discarded {
+		p.removeRecord(record)
+	}
+	p.canonicalised = canonicalised
+	p.hasCanonicalised = true
+
+	logger.Debugf("discarded %d journal records above reverted block #%d (%s)",
+		len(discarded), targetNumber, targetHash)
+	return nil
+}
+
+// finalisedChain returns the hashes of the journaled blocks from the finalised block to the
+// block the journal was canonicalised at, excluded. It returns false if the chain does not
+// reach the canonicalised block because a block was not journaled.
+func (p *FullNode) finalisedChain(finalisedHash common.Hash) (chain map[common.Hash]struct{}, complete bool) {
+	chain = make(map[common.Hash]struct{})
+	hash := finalisedHash
+	for {
+		if p.hasCanonicalised && hash == p.canonicalised.Hash {
+			return chain, true
+		}
+
+		record, ok := p.records[hash]
+		if !ok || (p.hasCanonicalised && record.BlockNumber <= p.canonicalised.Number) {
+			return chain, false
+		}
+
+		chain[hash] = struct{}{}
+		hash = record.ParentHash
+	}
+}
+
+func (p *FullNode) deleteNodes(hashes map[common.Hash]struct{}) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	batch := p.storageDB.NewBatch()
+	for hash := range hashes {
+		err := batch.Del(hash.ToBytes())
+		if err != nil {
+			batch.Reset()
+			return fmt.Errorf("deleting trie node %s: %w", hash, err)
+		}
+	}
+
+	err := batch.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing deleted trie nodes: %w", err)
+	}
+	return nil
+}
+
+// storeJournal removes the records from the journal in the database
+// and stores the block the journal was canonicalised at.
+func (p *FullNode) storeJournal(removed []*journalRecord, canonicalised canonicalisedBlock) error {
+	batch := p.journalDB.NewBatch()
+	for _, record := range removed {
+		err := batch.Del(recordKey(record.BlockNumber, record.BlockHash))
+		if err != nil {
+			batch.Reset()
+			return fmt.Errorf("deleting journal record: %w", err)
+		}
+	}
+
+	encoded, err := scale.Marshal(canonicalised)
+	if err != nil {
+		batch.Reset()
+		return fmt.Errorf("encoding canonicalised block: %w", err)
+	}
+
+	err = batch.Put(canonicalisedKey, encoded)
+	if err != nil {
+		batch.Reset()
+		return fmt.Errorf("storing canonicalised block: %w", err)
+	}
+
+	err = batch.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pruner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFullNode(t *testing.T, retainBlocks uint32) (*FullNode, database.Database, database.Table) {
+	t.Helper()
+
+	db, err := database.LoadDatabase(t.TempDir(), true)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	storageDB := database.NewTable(db, "storage")
+	p, err := NewFullNode(db, storageDB, retainBlocks)
+	require.NoError(t, err)
+	return p, db, storageDB
+}
+
+func nodeHash(name string) common.Hash {
+	return common.MustBlake2bHash([]byte(name))
+}
+
+func hashSet(names ...string) map[common.Hash]struct{} {
+	set := make(map[common.Hash]struct{}, len(names))
+	for _, name := range names {
+		set[nodeHash(name)] = struct{}{}
+	}
+	return set
+}
+
+// importBlock journals the block and writes the nodes it inserts to the storage database
+func importBlock(t *testing.T, p *FullNode, storageDB database.Table, hash, parent common.Hash,
+	number uint, inserted, deleted []string) {
+	t.Helper()
+
+	err := p.StoreJournalRecord(hashSet(deleted...), hashSet(inserted...), hash, parent, number)
+	require.NoError(t, err)
+
+	for _, name := range inserted {
+		err = storageDB.Put(nodeHash(name).ToBytes(), []byte(name))
+		require.NoError(t, err)
+	}
+}
+
+func assertNodes(t *testing.T, storageDB database.Table, present, pruned []string) {
+	t.Helper()
+
+	for _, name := range present {
+		has, err := storageDB.Has(nodeHash(name).ToBytes())
+		require.NoError(t, err)
+		require.Truef(t, has, "node %s is pruned", name)
+	}
+	for _, name := range pruned {
+		has, err := storageDB.Has(nodeHash(name).ToBytes())
+		require.NoError(t, err)
+		require.Falsef(t, has, "node %s is not pruned", name)
+	}
+}
+
+func Test_FullNode_Prune_finalisedBlocks(t *testing.T) {
+	t.Parallel()
+
+	p, _, storageDB := newTestFullNode(t, 2)
+	genesis := common.Hash{0xff}
+	importBlock(t, p, storageDB, genesis, common.Hash{}, 0, []string{"root0"}, nil)
+	require.NoError(t, p.Prune(genesis, 0))
+
+	parent := genesis
+	hashes := []common.Hash{genesis}
+	for number := uint(1); number <= 5; number++ {
+		hash := common.Hash{byte(number)}
+		inserted := []string{"root" + string(rune('0'+number)), "shared"}
+		deleted := []string{"root" + string(rune('0'+number-1))}
+		if number > 1 {
+			// the shared node is inserted by the first block and kept by the next ones
+			inserted = inserted[:1]
+		}
+		importBlock(t, p, storageDB, hash, parent, number, inserted, deleted)
+		hashes = append(hashes, hash)
+		parent = hash
+	}
+
+	require.NoError(t, p.Prune(hashes[5], 5))
+
+	// the state tries of the blocks 3 to 5 are retained
+	assertNodes(t, storageDB, []string{"root3", "root4", "root5", "shared"}, []string{"root0", "root1", "root2"})
+	require.Len(t, p.records, 2)
+	require.Contains(t, p.records, hashes[4])
+	require.Contains(t, p.records, hashes[5])
+}
+
+func Test_FullNode_Prune_forks(t *testing.T) {
+	t.Parallel()
+
+	p, _, storageDB := newTestFullNode(t, 10)
+	genesis := common.Hash{0xff}
+	require.NoError(t, p.Prune(genesis, 0))
+
+	block1 := common.Hash{1}
+	importBlock(t, p, storageDB, block1, genesis, 1, []string{"a1"}, nil)
+	block2a := common.Hash{2, 'a'}
+	importBlock(t, p, storageDB, block2a, block1, 2, []string{"a2", "shared"}, []string{"a1"})
+	block2b := common.Hash{2, 'b'}
+	importBlock(t, p, storageDB, block2b, block1, 2, []string{"b2", "shared"}, []string{"a1"})
+	block3b := common.Hash{3, 'b'}
+	importBlock(t, p, storageDB, block3b, block2b, 3, []string{"b3"}, []string{"b2"})
+	block3a := common.Hash{3, 'a'}
+	importBlock(t, p, storageDB, block3a, block2a, 3, []string{"a3"}, nil)
+
+	require.NoError(t, p.Prune(block2a, 2))
+
+	// the blocks of the fork not descending from the finalised block are pruned once finalised
+	// at their number, the nodes shared with the finalised block are kept
+	assertNodes(t, storageDB, []string{"a1", "a2", "shared", "a3", "b3"}, []string{"b2"})
+	require.NotContains(t, p.records, block2b)
+	require.Contains(t, p.records, block3b)
+
+	require.NoError(t, p.Prune(block3a, 3))
+
+	assertNodes(t, storageDB, []string{"a1", "a2", "shared", "a3"}, []string{"b2", "b3"})
+	require.Len(t, p.records, 3)
+}
+
+func Test_FullNode_Prune_reinsertedNode(t *testing.T) {
+	t.Parallel()
+
+	p, _, storageDB := newTestFullNode(t, 0)
+	genesis := common.Hash{0xff}
+	importBlock(t, p, storageDB, genesis, common.Hash{}, 0, []string{"value"}, nil)
+	require.NoError(t, p.Prune(genesis, 0))
+
+	block1 := common.Hash{1}
+	importBlock(t, p, storageDB, block1, genesis, 1, []string{"other"}, []string{"value"})
+	block2 := common.Hash{2}
+	importBlock(t, p, storageDB, block2, block1, 2, []string{"value"}, []string{"other"})
+
+	require.NoError(t, p.Prune(block1, 1))
+
+	// the node deleted by the finalised block is inserted again by the next block
+	assertNodes(t, storageDB, []string{"value", "other"}, nil)
+
+	require.NoError(t, p.Prune(block2, 2))
+
+	assertNodes(t, storageDB, []string{"value"}, []string{"other"})
+}
+
+func Test_FullNode_Prune_missingRecord(t *testing.T) {
+	t.Parallel()
+
+	p, _, storageDB := newTestFullNode(t, 0)
+	genesis := common.Hash{0xff}
+	require.NoError(t, p.Prune(genesis, 0))
+
+	block1 := common.Hash{1}
+	importBlock(t, p, storageDB, block1, genesis, 1, []string{"node1"}, []string{"node0"})
+	block1b := common.Hash{1, 'b'}
+	importBlock(t, p, storageDB, block1b, genesis, 1, []string{"fork1"}, []string{"node0"})
+	block3 := common.Hash{3}
+	importBlock(t, p, storageDB, block3, common.Hash{2}, 3, []string{"node3"}, []string{"node2"})
+	require.NoError(t, storageDB.Put(nodeHash("node0").ToBytes(), nil))
+	require.NoError(t, storageDB.Put(nodeHash("node2").ToBytes(), nil))
+
+	require.NoError(t, p.Prune(block3, 3))
+
+	// the block 2 was not journaled, the nodes inserted and deleted by the blocks before it are
+	// unknown, so only the nodes deleted by the blocks journaled since are pruned
+	assertNodes(t, storageDB, []string{"node0", "node1", "fork1", "node3"}, []string{"node2"})
+	require.Empty(t, p.records)
+	require.Equal(t, canonicalisedBlock{Hash: block3, Number: 3}, p.canonicalised)
+}
+
+func Test_FullNode_StoreJournalRecord_canonicalisedBlock(t *testing.T) {
+	t.Parallel()
+
+	p, _, storageDB := newTestFullNode(t, 0)
+	block5 := common.Hash{5}
+	require.NoError(t, p.Prune(block5, 5))
+
+	importBlock(t, p, storageDB, common.Hash{4, 'b'}, common.Hash{3}, 4, []string{"fork"}, nil)
+	importBlock(t, p, storageDB, common.Hash{6}, block5, 6, []string{"node6"}, []string{"node5"})
+
+	require.Len(t, p.records, 1)
+	require.Contains(t, p.records, common.Hash{6})
+}
+
+func Test_FullNode_StoreJournalRecord_insertedAndDeleted(t *testing.T) {
+	t.Parallel()
+
+	p, _, _ := newTestFullNode(t, 0)
+	block := common.Hash{1}
+	err := p.StoreJournalRecord(hashSet("deleted", "both"), hashSet("inserted", "both"), block, common.Hash{}, 1)
+	require.NoError(t, err)
+
+	record := p.records[block]
+	require.Equal(t, []common.Hash{nodeHash("inserted")}, record.Inserted)
+	require.Equal(t, []common.Hash{nodeHash("deleted")}, record.Deleted)
+	require.Equal(t, map[common.Hash]uint{nodeHash("inserted"): 1}, p.insertions)
+}
+
+func Test_NewFullNode_loadsJournal(t *testing.T) {
+	t.Parallel()
+
+	p, db, storageDB := newTestFullNode(t, 1)
+	genesis := common.Hash{0xff}
+	require.NoError(t, p.Prune(genesis, 0))
+	block1 := common.Hash{1}
+	importBlock(t, p, storageDB, block1, genesis, 1, []string{"node1"}, []string{"node0"})
+	block2 := common.Hash{2}
+	importBlock(t, p, storageDB, block2, block1, 2, []string{"node2"}, []string{"node1"})
+	require.NoError(t, p.Prune(block1, 1))
+
+	loaded, err := NewFullNode(db, storageDB, 1)
+	require.NoError(t, err)
+
+	require.Equal(t, p.records, loaded.records)
+	require.Equal(t, p.insertions, loaded.insertions)
+	require.Equal(t, p.canonicalised, loaded.canonicalised)
+	require.True(t, loaded.hasCanonicalised)
+}
+
+func Test_FullNode_Revert(t *testing.T) {
+	t.Parallel()
+
+	p, db, storageDB := newTestFullNode(t, 10)
+	genesis := common.Hash{0xff}
+	require.NoError(t, p.Prune(genesis, 0))
+	block1 := common.Hash{1}
+	importBlock(t, p, storageDB, block1, genesis, 1, []string{"node1"}, []string{"node0"})
+	block2 := common.Hash{2}
+	importBlock(t, p, storageDB, block2, block1, 2, []string{"node2"}, []string{"node1"})
+	block3 := common.Hash{3}
+	importBlock(t, p, storageDB, block3, block2, 3, []string{"node3"}, []string{"node2"})
+	require.NoError(t, p.Prune(block3, 3))
+
+	err := p.Revert(block1, 1)
+	require.NoError(t, err)
+
+	// the nodes inserted by the reverted blocks are kept
+	assertNodes(t, storageDB, []string{"node1", "node2", "node3"}, nil)
+	require.Len(t, p.records, 1)
+	require.Contains(t, p.records, block1)
+	require.Equal(t, canonicalisedBlock{Hash: block1, Number: 1}, p.canonicalised)
+
+	restarted, err := NewFullNode(db, storageDB, 10)
+	require.NoError(t, err)
+	require.Equal(t, p.records, restarted.records)
+	require.Equal(t, p.canonicalised, restarted.canonicalised)
+
+	// the blocks imported again above the reverted block are journaled and pruned
+	block2b := common.Hash{2, 'b'}
+	importBlock(t, restarted, storageDB, block2b, block1, 2, []string{"fork2"}, []string{"node1"})
+	block2c := common.Hash{2, 'c'}
+	importBlock(t, restarted, storageDB, block2c, block1, 2, []string{"other2"}, []string{"node1"})
+	require.NoError(t, restarted.Prune(block2b, 2))
+	assertNodes(t, storageDB, []string{"node1", "fork2"}, []string{"other2"})
+}
+
+var errTestFlush = errors.New("test flush error")
+
+// failingTable is a table whose batches fail to flush, as if the node crashed before the flush
+type failingTable struct {
+	database.Table
+}
+
+func (f failingTable) NewBatch() database.Batch {
+	return failingBatch{Batch: f.Table.NewBatch()}
+}
+
+type failingBatch struct {
+	database.Batch
+}
+
+func (failingBatch) Flush() error {
+	return errTestFlush
+}
+
+func Test_FullNode_Prune_interrupted(t *testing.T) {
+	t.Parallel()
+
+	p, db, storageDB := newTestFullNode(t, 0)
+	genesis := common.Hash{0xff}
+	require.NoError(t, p.Prune(genesis, 0))
+	block1 := common.Hash{1}
+	importBlock(t, p, storageDB, block1, genesis, 1, []string{"node1"}, []string{"node0"})
+	block1b := common.Hash{1, 'b'}
+	importBlock(t, p, storageDB, block1b, genesis, 1, []string{"fork1"}, []string{"node0"})
+	require.NoError(t, storageDB.Put(nodeHash("node0").ToBytes(), nil))
+
+	// the nodes are deleted but the journal is not updated
+	p.journalDB = failingTable{Table: p.journalDB}
+	err := p.Prune(block1, 1)
+	require.ErrorIs(t, err, errTestFlush)
+	assertNodes(t, storageDB, []string{"node1"}, []string{"node0", "fork1"})
+	require.Len(t, p.records, 2)
+
+	restarted, err := NewFullNode(db, storageDB, 0)
+	require.NoError(t, err)
+	require.Len(t, restarted.records, 2)
+
+	err = restarted.Prune(block1, 1)
+	require.NoError(t, err)
+	assertNodes(t, storageDB, []string{"node1"}, []string{"node0", "fork1"})
+	require.Empty(t, restarted.records)
+	require.Empty(t, restarted.insertions)
+}
@@ -10,6 +10,8 @@
 const (
 	// Archive pruner mode.
 	Archive = Mode("archive")
+	// Full pruner mode.
+	Full = Mode("full")
 )
 
 // Mode online pruning mode of historical state tries
@@ -18,7 +20,7 @@
 // IsValid checks whether the pruning mode is valid
 func (p Mode) IsValid() bool {
 	switch p {
-	case Archive:
+	case Archive, Full:
 		return true
 	default:
 		return false
@@ -34,7 +36,9 @@ type Config struct {
 // Pruner is implemented by FullNode and ArchiveNode.
 type Pruner interface {
 	StoreJournalRecord(deletedNodeHashes, insertedNodeHashes map[common.Hash]struct{},
-		blockHash common.Hash, blockNum int64) error
+		blockHash, parentHash common.Hash, blockNumber uint) error
+	Prune(finalisedHash common.Hash, finalisedNumber uint) error
+	Revert(targetHash common.Hash, targetNumber uint) error
 }
 
 // ArchiveNode is a no-op since we don't prune nodes in archive mode.
@@ -42,6 +46,16 @@ type ArchiveNode struct{
 
 // StoreJournalRecord for archive node doesn't do anything.
 func (*ArchiveNode) StoreJournalRecord(_, _ map[common.Hash]struct{},
-	_ common.Hash, _ int64) error {
+	_, _ common.Hash, _ uint) error {
+	return nil
+}
+
+// Prune for archive node doesn't do anything.
+func (*ArchiveNode) Prune(_ common.Hash, _ uint) error {
+	return nil
+}
+
+// Revert for archive node doesn't do anything.
+func (*ArchiveNode) Revert(_ common.Hash, _ uint) error {
 	return nil
 }
@@ -4,6 +4,7 @@
 package state
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 
@@ -13,6 +14,7 @@
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/internal/metrics"
 	"github.com/ChainSafe/gossamer/lib/blocktree"
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/pkg/trie"
 	inmemory_trie "github.com/ChainSafe/gossamer/pkg/trie/inmemory"
 )
@@ -21,6 +23,9 @@
 	log.AddContext("pkg", "state"),
 )
 
+// ErrRevertFinalised is returned when reverting finalised blocks without allowing it.
+var ErrRevertFinalised = errors.New("cannot revert finalised blocks")
+
 // Service is the struct that holds storage, block and network states
 type Service struct {
 	dbPath            string
@@ -28,14 +33,18 @@ type Service struct {
 	db                database.Database
 	isMemDB           bool // set to true if using an in-memory database; only used for testing.
 	Base              *BaseState
-	Storage           *InmemoryStorageState
+	Storage           StorageState
 	Block             *BlockState
 	Transaction       *TransactionState
 	Epoch             *EpochState
 	Grandpa           *GrandpaState
 	Slot              *SlotState
 	closeCh           chan interface{}
+	prunerDone        chan struct{}
 	genesisBABEConfig *types.BabeConfiguration
+	storageBackend    StorageBackend
+	pruner            pruner.Pruner
+	started           bool
 
 	PrunerCfg pruner.Config
 	Telemetry Telemetry
@@ -58,6 +67,7 @@ type Config struct {
 	Telemetry         Telemetry
 	Metrics           metrics.IntervalConfig
 	GenesisBABEConfig *types.BabeConfiguration
+	StorageBackend    StorageBackend
 }
 
 // NewService create a new instance of Service
@@ -75,6 +85,7 @@ func NewService(config Config) *Service {
 		PrunerCfg:         config.PrunerCfg,
 		Telemetry:         config.Telemetry,
 		genesisBABEConfig: config.GenesisBABEConfig,
+		storageBackend:    config.StorageBackend,
 	}
 }
 
@@ -115,11 +126,23 @@ func (s *Service) SetupBase() error {
 }
 
 // Start initialises the Storage database and the Block database.
+// The node starts the state service before creating the services depending on it, then again
+// along with them, so the states are only created by the first start and are not replaced once
+// shared with these services.
 func (s *Service) Start() (err error) {
+	if s.started {
+		return nil
+	}
+
 	if !s.isMemDB && (s.Storage != nil || s.Block != nil || s.Epoch != nil || s.Grandpa != nil) {
 		return nil
 	}
 
+	_, _, err = Migrate(s.db, false)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
 	tries := NewTries()
 	tries.SetEmptyTrie()
 
@@ -139,7 +162,7 @@ func (s *Service) Start() (err error) {
 	logger.Debugf("start with latest state root: %s", stateRoot)
 
 	// create storage state
-	s.Storage, err = NewStorageState(s.db, s.Block, tries)
+	s.Storage, err = newStorageState(s.storageBackend, s.db, s.Block, tries)
 	if err != nil {
 		return fmt.Errorf("failed to create storage state: %w", err)
 	}
@@ -150,6 +173,11 @@ func (s *Service) Start() (err error) {
 		return fmt.Errorf("failed to load storage trie from database: %w", err)
 	}
 
+	err = s.startPruner(bestHeader)
+	if err != nil {
+		return fmt.Errorf("failed to start state pruner: %w", err)
+	}
+
 	// create transaction queue
 	s.Transaction = NewTransactionState(s.Telemetry)
 
@@ -162,6 +190,8 @@ func (s *Service) Start() (err error) {
 	}
 
 	s.Grandpa = NewGrandpaState(s.db, s.Block, s.Telemetry)
+	s.started = true
+
 	num, _ := s.Block.BestBlockNumber()
 	logger.Infof(
 		"created state service with head %s, highest number %d and genesis hash %s",
@@ -170,85 +200,186 @@ func (s *Service) Start() (err error) {
 	return nil
 }
 
-// Rewind rewinds the chain to the given block number.
-// If the given number of blocks is greater than the chain height, it will rewind to genesis.
+// startPruner creates the online pruner of the state trie nodes for the pruning mode, prunes
+// the nodes no longer needed at the finalised block and then on each finalisation.
+func (s *Service) startPruner(finalised *types.Header) error {
+	if s.PrunerCfg.Mode != pruner.Full {
+		s.pruner = &pruner.ArchiveNode{}
+		s.Storage.setPruner(s.pruner)
+		return nil
+	}
+
+	fullNode, err := pruner.NewFullNode(s.db, database.NewTable(s.db, storagePrefix), s.PrunerCfg.RetainedBlocks)
+	if err != nil {
+		return fmt.Errorf("creating full node pruner: %w", err)
+	}
+
+	err = fullNode.Prune(finalised.Hash(), finalised.Number)
+	if err != nil {
+		return fmt.Errorf("pruning at finalised block #%d: %w", finalised.Number, err)
+	}
+
+	s.pruner = fullNode
+	s.Storage.setPruner(fullNode)
+	s.prunerDone = make(chan struct{})
+	go s.pruneOnFinalisation(fullNode, s.Block.GetFinalisedNotifierChannel())
+
+	logger.Infof("pruning state tries, retaining %d blocks behind the finalised block", s.PrunerCfg.RetainedBlocks)
+	return nil
+}
+
+// pruneOnFinalisation prunes the state trie nodes no longer needed on each
+// finalisation, until the service is stopped.
+func (s *Service) pruneOnFinalisation(p pruner.Pruner, finalised chan *types.FinalisationInfo) {
+	defer close(s.prunerDone)
+	defer s.Block.FreeFinalisedNotifierChannel(finalised)
+
+	for {
+		select {
+		case <-s.closeCh:
+			return
+		case info := <-finalised:
+			hash := info.Header.Hash()
+			err := p.Prune(hash, info.Header.Number)
+			if err != nil {
+				logger.Errorf("failed to prune state tries at finalised block #%d (%s): %s",
+					info.Header.Number, hash, err)
+			}
+		}
+	}
+}
+
+// Rewind rewinds the chain to the given block number, reverting finalised blocks if needed.
 func (s *Service) Rewind(toBlock uint) error {
-	num, _ := s.Block.BestBlockNumber()
+	num, err := s.Block.BestBlockNumber()
+	if err != nil {
+		return fmt.Errorf("getting best block number: %w", err)
+	}
+
 	if toBlock > num {
 		return fmt.Errorf("cannot rewind, given height is higher than our current height")
 	}
 
-	logger.Infof(
-		"rewinding state from current height %s to desired height %d...",
-		num, toBlock)
+	_, err = s.Revert(num-toBlock, true)
+	return err
+}
 
-	root, err := s.Block.GetBlockByNumber(toBlock)
+// Revert reverts the chain by the given number of blocks from the best block, down to the genesis
+// block at most, and returns the header of the new best block. The reverted blocks are removed from
+// the block tree and the database, and the GRANDPA authority set, the BABE epoch data and the
+// finalised block are restored as they were at the new best block.
+// Reverting finalised blocks returns ErrRevertFinalised unless revertFinalised is set, the new best
+// block then becomes the finalised block, so its runtime must be loaded again.
+func (s *Service) Revert(blocks uint, revertFinalised bool) (target *types.Header, err error) {
+	best, err := s.Block.BestBlockHeader()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("getting best block header: %w", err)
 	}
 
-	s.Block.bt = blocktree.NewBlockTreeFromRoot(&root.Header)
+	targetNumber := best.Number - min(blocks, best.Number)
+	target, err = s.Block.GetHeaderByNumber(targetNumber)
+	if err != nil {
+		return nil, fmt.Errorf("getting header of block #%d: %w", targetNumber, err)
+	}
 
-	header, err := s.Block.BestBlockHeader()
+	finalised, err := s.Block.GetHighestFinalisedHeader()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("getting highest finalised header: %w", err)
 	}
 
-	s.Block.lastFinalised = header.Hash()
-	logger.Infof(
-		"rewinding state for new height %s and best block hash %s...",
-		header.Number, header.Hash())
+	revertsFinalised := target.Number < finalised.Number
+	if revertsFinalised {
+		if !revertFinalised {
+			return nil, fmt.Errorf("%w: block #%d is below finalised block #%d",
+				ErrRevertFinalised, target.Number, finalised.Number)
+		}
 
-	epoch, err := s.Epoch.GetEpochForBlock(header)
-	if err != nil {
-		return err
+		// the state trie of the target block might be pruned already
+		_, err = s.Storage.LoadFromDB(target.StateRoot)
+		if err != nil {
+			return nil, fmt.Errorf("loading state trie of block #%d: %w", target.Number, err)
+		}
 	}
 
-	err = s.Epoch.StoreCurrentEpoch(epoch)
+	// the first slot number is needed to get the epoch of the target block, so
+	// the epoch must be found before reverting the block #1
+	epoch, err := s.Epoch.GetEpochForBlock(target)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("getting epoch of block #%d: %w", target.Number, err)
 	}
 
-	s.Block.lastFinalised = header.Hash()
+	logger.Infof("reverting chain from best block #%d (%s) to block #%d (%s)...",
+		best.Number, best.Hash(), target.Number, target.Hash())
 
-	// TODO: this is broken, it needs to set the latest finalised header after
-	// rewinding to some block number, but there is no reverse lookup function
-	// for block -> (round, setID) where it was finalised (#1859)
-	err = s.Block.SetFinalisedHash(header.Hash(), 0, 0)
+	// the block, grandpa and epoch changes are flushed at once, so the database is never left
+	// partially reverted
+	batch := s.db.NewBatch()
+	defer batch.Reset()
+
+	reverted, round, roundSetID, err := s.Block.revert(database.NewTableBatch(batch, blockPrefix), target)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("reverting blocks: %w", err)
 	}
 
-	// update the current grandpa set ID
-	prevSetID, err := s.Grandpa.GetCurrentSetID()
+	grandpaBatch := database.NewTableBatch(batch, grandpaPrefix)
+	prevSetID, setID, err := s.Grandpa.revert(grandpaBatch, target.Number)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("reverting grandpa state: %w", err)
+	}
+
+	if revertsFinalised || setID != prevSetID {
+		// the rounds of the new set start again from zero
+		latestRound := round
+		if roundSetID != setID {
+			latestRound = 0
+		}
+
+		err = grandpaBatch.Put(common.LatestFinalizedRoundKey, roundToBytes(latestRound))
+		if err != nil {
+			return nil, fmt.Errorf("setting latest grandpa round: %w", err)
+		}
 	}
 
-	newSetID, err := s.Grandpa.GetSetIDByBlockNumber(header.Number)
+	revertedSet := make(map[common.Hash]struct{}, len(reverted))
+	for _, hash := range reverted {
+		revertedSet[hash] = struct{}{}
+	}
+
+	err = s.Epoch.revert(database.NewTableBatch(batch, epochPrefix), epoch, revertedSet)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("reverting epoch state: %w", err)
 	}
 
-	err = s.Grandpa.setCurrentSetID(newSetID)
+	err = batch.Flush()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("flushing reverted chain: %w", err)
 	}
 
-	// remove previously set grandpa changes, need to go up to prevSetID+1 in case of a scheduled change
-	for i := newSetID + 1; i <= prevSetID+1; i++ {
-		err = s.Grandpa.db.Del(setIDChangeKey(i))
+	s.Block.applyRevert(target, finalised, round, roundSetID)
+	s.Grandpa.applyRevert(target.Number)
+	s.Epoch.applyRevert(epoch, revertedSet)
+
+	if revertsFinalised && s.pruner != nil {
+		err = s.pruner.Revert(target.Hash(), target.Number)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("reverting state pruner: %w", err)
 		}
 	}
 
-	return nil
+	logger.Infof("reverted %d blocks, best block is #%d (%s) with grandpa set id %d and epoch %d",
+		len(reverted), target.Number, target.Hash(), setID, epoch)
+	return target, nil
 }
 
 // Stop closes each state database
 func (s *Service) Stop() error {
 	close(s.closeCh)
+	if s.prunerDone != nil {
+		<-s.prunerDone
+	}
+	// the states are created again by the next start, once the database is set up again
+	s.started = false
+	s.closeCh = make(chan interface{})
 
 	hash, err := s.Block.GetHighestFinalisedHash()
 	if err != nil {
@@ -320,7 +451,7 @@ func (s *Service) Import(header *types.Header, t trie.Trie,
 
 	// TODO: all trie related db operations should be done in pkg/trie
 	if inmemoryTrie, ok := t.(*inmemory_trie.InMemoryTrie); ok {
-		if err := inmemoryTrie.WriteDirty(storage.db); err != nil {
+		if err := inmemoryTrie.WriteDirty(trieWriter(storage.db)); err != nil {
 			return err
 		}
 	}
@@ -75,6 +75,85 @@ func TestService_Start(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestService_StartTwice(t *testing.T) {
+	state := newTestMemDBService(t)
+
+	genData, genTrie, genesisHeader := newWestendDevGenesisWithTrieAndHeader(t)
+	err := state.Initialise(&genData, &genesisHeader, genTrie)
+	require.NoError(t, err)
+
+	err = state.Start()
+	require.NoError(t, err)
+
+	// the states shared with the other services are kept when the service is started again
+	block, storage, transaction := state.Block, state.Storage, state.Transaction
+	err = state.Start()
+	require.NoError(t, err)
+	require.Same(t, block, state.Block)
+	require.Same(t, storage, state.Storage)
+	require.Same(t, transaction, state.Transaction)
+
+	// the states are created again once the service is stopped
+	err = state.Stop()
+	require.NoError(t, err)
+	require.False(t, state.started)
+
+	genData, genTrie, genesisHeader = newWestendDevGenesisWithTrieAndHeader(t)
+	err = state.Initialise(&genData, &genesisHeader, genTrie)
+	require.NoError(t, err)
+	err = state.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := state.Stop()
+		require.NoError(t, err)
+	})
+	require.NotSame(t, block, state.Block)
+	require.NotSame(t, storage, state.Storage)
+}
+
+func TestService_StartMigratesDatabase(t *testing.T) {
+	state := newTestService(t)
+
+	genData, genTrie, genesisHeader := newWestendDevGenesisWithTrieAndHeader(t)
+	err := state.Initialise(&genData, &genesisHeader, genTrie)
+	require.NoError(t, err)
+
+	err = state.SetupBase()
+	require.NoError(t, err)
+
+	version, err := state.Base.LoadSchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, SchemaVersion, version)
+
+	// the database is created before the schema version was stored
+	err = state.Base.Del(common.SchemaVersionKey)
+	require.NoError(t, err)
+
+	err = state.Start()
+	require.NoError(t, err)
+
+	version, err = state.Base.LoadSchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, SchemaVersion, version)
+
+	// the database is written by a newer node
+	err = state.Base.StoreSchemaVersion(SchemaVersion + 1)
+	require.NoError(t, err)
+	err = state.Stop()
+	require.NoError(t, err)
+
+	state = NewService(Config{Path: state.dbPath, LogLevel: log.Info})
+	err = state.SetupBase()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := state.DB().Close()
+		require.NoError(t, err)
+	})
+
+	err = state.Start()
+	require.ErrorIs(t, err, ErrSchemaVersionTooNew)
+}
+
 func TestService_Initialise(t *testing.T) {
 	state := newTestService(t)
 
@@ -174,18 +253,16 @@ func TestService_BlockTree(t *testing.T) {
 }
 
 func TestService_StorageTriePruning(t *testing.T) {
-	t.Skip() // Unskip once https://github.com/ChainSafe/gossamer/pull/2831 is done
-
 	ctrl := gomock.NewController(t)
 	telemetryMock := NewMockTelemetry(ctrl)
-	telemetryMock.EXPECT().SendMessage(gomock.Any())
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
 
 	const retainBlocks uint = 2
 	config := Config{
 		Path:     t.TempDir(),
 		LogLevel: log.Info,
 		PrunerCfg: pruner.Config{
-			// Mode:           pruner.Full,
+			Mode:           pruner.Full,
 			RetainedBlocks: uint32(retainBlocks),
 		},
 		Telemetry:         telemetryMock,
@@ -208,17 +285,24 @@ func TestService_StorageTriePruning(t *testing.T) {
 	for i := uint(1); i < totalBlock; i++ {
 		block, trieState := generateBlockWithRandomTrie(t, serv, &parentHash, i)
 
-		err = serv.Storage.blockState.AddBlock(block)
+		err = serv.Storage.StoreTrie(trieState, &block.Header)
 		require.NoError(t, err)
 
-		err = serv.Storage.StoreTrie(trieState, &block.Header)
+		err = serv.Block.AddBlock(block)
 		require.NoError(t, err)
 
 		blocks = append(blocks, block)
 		parentHash = block.Header.Hash()
 	}
 
-	time.Sleep(2 * time.Second)
+	// the state tries are pruned once finalised
+	err = serv.Block.SetFinalisedHash(parentHash, 1, 0)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, err := serv.Storage.LoadFromDB(genesisHeader.StateRoot)
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
 
 	for _, b := range blocks {
 		_, err := serv.Storage.LoadFromDB(b.Header.StateRoot)
@@ -266,7 +350,7 @@ type prunedBlock struct {
 		require.NoError(t, err)
 		block.Header.Digest = digest
 
-		err = serv.Storage.blockState.AddBlock(block)
+		err = serv.Block.AddBlock(block)
 		require.NoError(t, err)
 
 		err = serv.Storage.StoreTrie(trieState, nil)
@@ -284,7 +368,7 @@ type prunedBlock struct {
 	for i := uint(0); i < 3; i++ {
 		block, trieState := generateBlockWithRandomTrie(t, serv, &parentHash, i+1)
 
-		err = serv.Storage.blockState.AddBlock(block)
+		err = serv.Block.AddBlock(block)
 		require.NoError(t, err)
 
 		err = serv.Storage.StoreTrie(trieState, nil)
@@ -307,7 +391,7 @@ type prunedBlock struct {
 	time.Sleep(1 * time.Second)
 
 	for _, v := range prunedArr {
-		tr := serv.Storage.blockState.tries.get(v.hash)
+		tr := serv.Block.tries.get(v.hash)
 		require.Nil(t, tr)
 	}
 }
@@ -315,7 +399,7 @@ type prunedBlock struct {
 func TestService_Rewind(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	telemetryMock := NewMockTelemetry(ctrl)
-	telemetryMock.EXPECT().SendMessage(gomock.Any()).Times(3)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).Times(2)
 
 	config := Config{
 		Path:              t.TempDir(),
@@ -357,6 +441,10 @@ func TestService_Rewind(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, uint(6), num)
 
+	finalised, err := serv.Block.GetHighestFinalisedHeader()
+	require.NoError(t, err)
+	require.Equal(t, uint(6), finalised.Number)
+
 	setID, err := serv.Grandpa.GetCurrentSetID()
 	require.NoError(t, err)
 	require.Equal(t, uint64(1), setID)
@@ -371,6 +459,137 @@ func TestService_Rewind(t *testing.T) {
 	require.Equal(t, database.ErrNotFound, err)
 }
 
+func TestService_Revert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockTelemetry(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	config := Config{
+		Path:              t.TempDir(),
+		LogLevel:          log.Info,
+		Telemetry:         telemetryMock,
+		GenesisBABEConfig: config.BABEConfigurationTestDefault,
+	}
+	serv := NewService(config)
+
+	genData, genTrie, genesisHeader := newWestendDevGenesisWithTrieAndHeader(t)
+	err := serv.Initialise(&genData, &genesisHeader, genTrie)
+	require.NoError(t, err)
+	err = serv.SetupBase()
+	require.NoError(t, err)
+	err = serv.Start()
+	require.NoError(t, err)
+
+	chain, _ := AddBlocksToState(t, serv.Block, 12, false)
+
+	// the set 1 starts after the block #5
+	err = serv.Grandpa.SetNextChange(testAuths, 5)
+	require.NoError(t, err)
+	_, err = serv.Grandpa.IncrementSetID()
+	require.NoError(t, err)
+
+	err = serv.Block.SetFinalisedHash(chain[3].Hash(), 1, 0)
+	require.NoError(t, err)
+	err = serv.Block.SetFinalisedHash(chain[7].Hash(), 1, 1)
+	require.NoError(t, err)
+	err = serv.Grandpa.SetLatestRound(1)
+	require.NoError(t, err)
+	err = serv.Block.SetJustification(chain[7].Hash(), []byte("justification"))
+	require.NoError(t, err)
+
+	err = serv.Epoch.SetEpochDataRaw(1, &types.EpochDataRaw{})
+	require.NoError(t, err)
+	err = serv.Epoch.SetEpochDataRaw(2, &types.EpochDataRaw{})
+	require.NoError(t, err)
+	nextEpochData := types.NextEpochData{}
+	serv.Epoch.storeBABENextEpochData(1, chain[6].Hash(), nextEpochData)
+	err = serv.Epoch.setBABENextEpochDataInDB(1, chain[6].Hash(), nextEpochData)
+	require.NoError(t, err)
+
+	// reverting unfinalised blocks only
+	target, err := serv.Revert(3, false)
+	require.NoError(t, err)
+	require.Equal(t, chain[8], target)
+	require.Equal(t, chain[8].Hash(), serv.Block.BestBlockHash())
+	require.Equal(t, []common.Hash{chain[8].Hash()}, serv.Block.Leaves())
+
+	_, err = serv.Revert(5, false)
+	require.ErrorIs(t, err, ErrRevertFinalised)
+
+	target, err = serv.Revert(5, true)
+	require.NoError(t, err)
+	require.Equal(t, chain[3], target)
+
+	err = serv.Stop()
+	require.NoError(t, err)
+
+	// the reverted state is loaded on restart
+	serv = NewService(config)
+	err = serv.SetupBase()
+	require.NoError(t, err)
+	err = serv.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := serv.Stop()
+		require.NoError(t, err)
+	})
+
+	best, err := serv.Block.BestBlockHeader()
+	require.NoError(t, err)
+	require.Equal(t, chain[3].Hash(), best.Hash())
+
+	round, setID, err := serv.Block.GetHighestRoundAndSetID()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), round)
+	require.Equal(t, uint64(0), setID)
+
+	finalised, err := serv.Block.GetHighestFinalisedHash()
+	require.NoError(t, err)
+	require.Equal(t, chain[3].Hash(), finalised)
+
+	for _, header := range chain[4:8] {
+		_, err = serv.Block.GetHeader(header.Hash())
+		require.ErrorIs(t, err, database.ErrNotFound)
+		_, err = serv.Block.GetBlockBody(header.Hash())
+		require.ErrorIs(t, err, database.ErrNotFound)
+	}
+	_, err = serv.Block.GetHashByNumber(5)
+	require.Error(t, err)
+	has, err := serv.Block.HasJustification(chain[7].Hash())
+	require.NoError(t, err)
+	require.False(t, has)
+	has, err = serv.Block.HasFinalisedBlock(1, 1)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	setID, err = serv.Grandpa.GetCurrentSetID()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), setID)
+	_, err = serv.Grandpa.GetSetIDChange(1)
+	require.ErrorIs(t, err, database.ErrNotFound)
+	_, err = serv.Grandpa.GetAuthorities(1)
+	require.ErrorIs(t, err, database.ErrNotFound)
+	latestRound, err := serv.Grandpa.GetLatestRound()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), latestRound)
+
+	epoch, err := serv.Epoch.GetCurrentEpoch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), epoch)
+	has, err = serv.Epoch.db.Has(epochDataKey(1))
+	require.NoError(t, err)
+	require.True(t, has)
+	has, err = serv.Epoch.db.Has(epochDataKey(2))
+	require.NoError(t, err)
+	require.False(t, has)
+	require.Empty(t, serv.Epoch.nextEpochData)
+
+	// the reverted blocks can be imported and finalised again
+	chain, _ = AddBlocksToState(t, serv.Block, 2, false)
+	err = serv.Block.SetFinalisedHash(chain[1].Hash(), 2, 0)
+	require.NoError(t, err)
+}
+
 func TestService_Import(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	telemetryMock := NewMockTelemetry(ctrl)
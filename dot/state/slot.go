@@ -8,6 +8,7 @@
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/database"
@@ -29,7 +30,9 @@
 )
 
 type SlotState struct {
-	db database.Table
+	// lock serialises the equivocation checks, since headers can be verified concurrently
+	lock sync.Mutex
+	db   database.Table
 }
 
 func NewSlotState(db database.Database) *SlotState {
@@ -47,6 +50,9 @@ type headerAndSigner struct {
 
 func (s *SlotState) CheckEquivocation(slotNow, slot uint64, header *types.Header,
 	signer types.AuthorityID) (*types.BabeEquivocationProof, error) { //skipcq: GO-R1005
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	// We don't check equivocations for old headers out of our capacity.
 	// checking slotNow is greater than slot to avoid overflow, same as saturating_sub
 	if primitives.SaturatingSub(slotNow, slot) > maxSlotCapacity {
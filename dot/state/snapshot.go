@@ -0,0 +1,563 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/internal/snapshot"
+	"github.com/ChainSafe/gossamer/lib/blocktree"
+	"github.com/ChainSafe/gossamer/lib/common"
+	wazero_runtime "github.com/ChainSafe/gossamer/lib/runtime/wazero"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/ChainSafe/gossamer/pkg/trie/db"
+)
+
+const (
+	// snapshotProgressInterval is the number of state entries between two progress logs
+	snapshotProgressInterval = 1000000
+
+	// snapshotBatchSize is the number of trie nodes written to the database at once when
+	// importing a snapshot
+	snapshotBatchSize = 10000
+)
+
+var (
+	// ErrBlockNotFinalised is returned when exporting the snapshot of a block which is not finalised
+	ErrBlockNotFinalised = errors.New("block is not finalised")
+	// ErrPendingAuthoritySetChange is returned when exporting the snapshot of a block before which
+	// a GRANDPA authority set change is announced and after which it is applied
+	ErrPendingAuthoritySetChange = errors.New("authority set change pending at block")
+	// ErrSnapshotGenesisMismatch is returned when importing the snapshot of another chain
+	ErrSnapshotGenesisMismatch = errors.New("snapshot genesis hash does not match")
+	// ErrNodeNotEmpty is returned when importing a snapshot into a node which imported blocks
+	ErrNodeNotEmpty = errors.New("node already imported blocks")
+	// ErrSnapshotStateRoot is returned when the state of a snapshot does not match the state root
+	// of its block header
+	ErrSnapshotStateRoot = errors.New("snapshot state root does not match")
+)
+
+// ExportSnapshot writes the snapshot of the finalised block with the given hash to w: its header,
+// the BABE and GRANDPA data needed to continue from it, and its state including the child tries.
+func (s *Service) ExportSnapshot(hash common.Hash, w io.Writer) error {
+	header, err := s.Block.GetHeader(hash)
+	if err != nil {
+		return fmt.Errorf("getting header of block %s: %w", hash, err)
+	}
+
+	finalised, err := s.Block.GetHighestFinalisedHeader()
+	if err != nil {
+		return fmt.Errorf("getting highest finalised header: %w", err)
+	}
+	if header.Number > finalised.Number {
+		return fmt.Errorf("%w: block #%d is above the finalised block #%d", ErrBlockNotFinalised,
+			header.Number, finalised.Number)
+	}
+	canonicalHash, err := s.Block.GetHashByNumber(header.Number)
+	if err != nil {
+		return fmt.Errorf("getting hash of block #%d: %w", header.Number, err)
+	}
+	if canonicalHash != hash {
+		return fmt.Errorf("%w: block %s is not on the finalised chain", ErrBlockNotFinalised, hash)
+	}
+
+	consensus, err := s.snapshotConsensus(header)
+	if err != nil {
+		return err
+	}
+
+	t, err := s.Storage.LoadFromDB(header.StateRoot)
+	if err != nil {
+		return fmt.Errorf("loading state trie %s: %w", header.StateRoot, err)
+	}
+
+	stateVersion, err := runtimeStateVersion(t)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("exporting snapshot of block #%d (%s) with state root %s and state version %s...",
+		header.Number, hash, header.StateRoot, stateVersion)
+
+	writer, err := snapshot.NewWriter(w, s.Block.GenesisHash(), stateVersion, header, consensus)
+	if err != nil {
+		return err
+	}
+
+	var exported uint
+	childKeys, err := writeSnapshotEntries(writer, nil, t, &exported)
+	if err != nil {
+		return err
+	}
+
+	for _, childKey := range childKeys {
+		child, err := t.GetChild(childKey)
+		if err != nil {
+			return fmt.Errorf("getting child trie 0x%x: %w", childKey, err)
+		}
+		_, err = writeSnapshotEntries(writer, childKey, child, &exported)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("closing snapshot writer: %w", err)
+	}
+
+	logger.Infof("exported %d state entries, including %d child tries", exported, len(childKeys))
+	return nil
+}
+
+// writeSnapshotEntries writes the entries of the trie, or of the child trie with the given child
+// storage key if it is not empty, and returns the child storage keys of the child tries it holds.
+func writeSnapshotEntries(writer *snapshot.Writer, childKey []byte, t trie.TrieRead,
+	exported *uint) (childKeys [][]byte, err error) {
+	iter := t.Iter()
+	for key := iter.NextKey(); key != nil; key = iter.NextKey() {
+		err = writer.Write(childKey, key, t.Get(key))
+		if err != nil {
+			return nil, err
+		}
+
+		if childKey == nil && bytes.HasPrefix(key, trie.ChildStorageKeyPrefix) {
+			childKeys = append(childKeys, bytes.TrimPrefix(key, trie.ChildStorageKeyPrefix))
+		}
+
+		*exported++
+		if *exported%snapshotProgressInterval == 0 {
+			logger.Infof("exported %d state entries", *exported)
+		}
+	}
+	return childKeys, nil
+}
+
+// runtimeStateVersion returns the state trie version of the runtime code of the state trie,
+// as the version is not stored in the database.
+func runtimeStateVersion(t trie.TrieRead) (trie.TrieLayout, error) {
+	code := t.Get(common.CodeKey)
+	if len(code) == 0 {
+		return trie.NoVersion, errors.New("no runtime code in the state")
+	}
+
+	version, err := wazero_runtime.GetRuntimeVersion(code)
+	if err != nil {
+		return trie.NoVersion, fmt.Errorf("getting runtime version: %w", err)
+	}
+
+	stateVersion, err := trie.ParseVersion(version.StateVersion)
+	if err != nil {
+		return trie.NoVersion, fmt.Errorf("parsing runtime state version: %w", err)
+	}
+	return stateVersion, nil
+}
+
+// snapshotConsensus returns the BABE epoch and GRANDPA authority set data needed by a node
+// bootstrapped from the snapshot of the finalised block with the given header.
+func (s *Service) snapshotConsensus(header *types.Header) (*snapshot.Consensus, error) {
+	hash := header.Hash()
+	consensus := &snapshot.Consensus{}
+
+	var err error
+	if header.Number > 0 {
+		consensus.FirstSlot, err = s.Epoch.retrieveFirstNonOriginBlockSlot(hash)
+		if err != nil {
+			return nil, fmt.Errorf("getting first slot: %w", err)
+		}
+	}
+
+	consensus.Epoch, err = s.Epoch.GetEpochForBlock(header)
+	if err != nil {
+		return nil, fmt.Errorf("getting epoch of block #%d: %w", header.Number, err)
+	}
+
+	for i, epoch := range []uint64{consensus.Epoch, consensus.Epoch + 1} {
+		epochData, err := s.Epoch.GetEpochDataRaw(epoch, header)
+		if err != nil {
+			return nil, fmt.Errorf("getting epoch data of epoch %d: %w", epoch, err)
+		}
+		configData, err := s.Epoch.GetConfigData(epoch, header)
+		if err != nil {
+			return nil, fmt.Errorf("getting config data of epoch %d: %w", epoch, err)
+		}
+
+		if i == 0 {
+			consensus.EpochData, consensus.ConfigData = *epochData, *configData
+		} else {
+			consensus.NextEpochData, consensus.NextConfigData = *epochData, *configData
+		}
+	}
+
+	// the blocks following the block are finalised by the authority set it belongs to,
+	// or by the next one if its set changes at the block
+	consensus.SetID, err = s.Grandpa.GetSetIDByBlockNumber(header.Number + 1)
+	if err != nil {
+		return nil, fmt.Errorf("getting set ID of block #%d: %w", header.Number+1, err)
+	}
+	consensus.SetIDChange, err = s.Grandpa.GetSetIDChange(consensus.SetID)
+	if err != nil {
+		return nil, fmt.Errorf("getting change of set %d: %w", consensus.SetID, err)
+	}
+
+	authorities, err := s.Grandpa.GetAuthorities(consensus.SetID)
+	if err != nil {
+		return nil, fmt.Errorf("getting authorities of set %d: %w", consensus.SetID, err)
+	}
+	for _, authority := range authorities {
+		consensus.Authorities = append(consensus.Authorities, types.GrandpaAuthoritiesRaw{
+			Key: authority.PublicKeyBytes(),
+			ID:  authority.ID,
+		})
+	}
+
+	round, setID, err := s.Block.GetHighestRoundAndSetID()
+	if err != nil {
+		return nil, fmt.Errorf("getting highest round and set ID: %w", err)
+	}
+	finalisedHash, err := s.Block.GetFinalisedHash(round, setID)
+	if err != nil {
+		return nil, fmt.Errorf("getting highest finalised hash: %w", err)
+	}
+	if finalisedHash == hash && setID == consensus.SetID {
+		consensus.Round = round
+	}
+
+	err = s.checkPendingAuthoritySetChange(header, consensus.SetIDChange)
+	if err != nil {
+		return nil, err
+	}
+
+	return consensus, nil
+}
+
+// checkPendingAuthoritySetChange checks no GRANDPA authority set change is announced by the blocks
+// of the set of the given block and applied after it, since the pending changes are not stored.
+func (s *Service) checkPendingAuthoritySetChange(header *types.Header, setIDChange uint) error {
+	for current := header; current.Number > setIDChange; {
+		for _, item := range current.Digest {
+			value, err := item.Value()
+			if err != nil {
+				return fmt.Errorf("getting digest value of block #%d: %w", current.Number, err)
+			}
+			digest, ok := value.(types.ConsensusDigest)
+			if !ok || digest.ConsensusEngineID != types.GrandpaEngineID {
+				continue
+			}
+
+			data := types.NewGrandpaConsensusDigest()
+			err = scale.Unmarshal(digest.Data, &data)
+			if err != nil {
+				return fmt.Errorf("decoding GRANDPA digest of block #%d: %w", current.Number, err)
+			}
+			dataValue, err := data.Value()
+			if err != nil {
+				return fmt.Errorf("getting GRANDPA digest value of block #%d: %w", current.Number, err)
+			}
+
+			var delay uint32
+			switch val := dataValue.(type) {
+			case types.GrandpaScheduledChange:
+				delay = val.Delay
+			case types.GrandpaForcedChange:
+				delay = val.Delay
+			default:
+				continue
+			}
+
+			if current.Number+uint(delay) > header.Number {
+				return fmt.Errorf("%w: change announced at block #%d is applied at block #%d, after block #%d",
+					ErrPendingAuthoritySetChange, current.Number, current.Number+uint(delay), header.Number)
+			}
+		}
+
+		parent, err := s.Block.GetHeader(current.ParentHash)
+		if err != nil {
+			return fmt.Errorf("getting header of block #%d: %w", current.Number-1, err)
+		}
+		current = parent
+	}
+	return nil
+}
+
+// ImportSnapshot imports the snapshot of the given reader into the database set up with SetupBase,
+// of a node with no block imported after the genesis block. The state of the snapshot is verified
+// against the state root of its block, which is set as the finalised head of the chain along with
+// the BABE epoch and GRANDPA authority set data of the snapshot, so that the node continues syncing
+// from it.
+func (s *Service) ImportSnapshot(reader *snapshot.Reader) error {
+	block := &BlockState{
+		bt:                blocktree.NewEmptyBlockTree(),
+		db:                database.NewTable(s.db, blockPrefix),
+		unfinalisedBlocks: newHashToBlockMap(),
+	}
+
+	genesisHash, err := block.db.Get(headerHashKey(0))
+	if err != nil {
+		return fmt.Errorf("getting genesis hash: %w", err)
+	}
+	if common.BytesToHash(genesisHash) != reader.GenesisHash {
+		return fmt.Errorf("%w: snapshot genesis hash %s, node genesis hash %s",
+			ErrSnapshotGenesisMismatch, reader.GenesisHash, common.BytesToHash(genesisHash))
+	}
+	finalisedHash, err := block.GetHighestFinalisedHash()
+	if err != nil {
+		return fmt.Errorf("getting highest finalised hash: %w", err)
+	}
+	has, err := block.db.Has(headerHashKey(1))
+	if err != nil {
+		return fmt.Errorf("checking block #1 was imported: %w", err)
+	}
+	if has || finalisedHash != common.BytesToHash(genesisHash) {
+		return ErrNodeNotEmpty
+	}
+
+	header := reader.Header
+	hash := header.Hash()
+	consensus := reader.Consensus
+
+	logger.Infof("importing snapshot of block #%d (%s) with state root %s and state version %s...",
+		header.Number, hash, header.StateRoot, reader.StateVersion)
+
+	err = s.importSnapshotState(reader)
+	if err != nil {
+		return err
+	}
+
+	err = block.SetHeader(header)
+	if err != nil {
+		return fmt.Errorf("setting header: %w", err)
+	}
+	err = block.db.Put(headerHashKey(uint64(header.Number)), hash[:])
+	if err != nil {
+		return fmt.Errorf("setting hash of block #%d: %w", header.Number, err)
+	}
+	err = block.db.Put(finalisedHashKey(consensus.Round, consensus.SetID), hash[:])
+	if err != nil {
+		return fmt.Errorf("setting finalised hash: %w", err)
+	}
+	err = block.setHighestRoundAndSetID(consensus.Round, consensus.SetID)
+	if err != nil {
+		return fmt.Errorf("setting highest round and set ID: %w", err)
+	}
+	err = block.setFirstNonOriginSlotNumber(consensus.FirstSlot)
+	if err != nil {
+		return fmt.Errorf("setting first slot: %w", err)
+	}
+
+	epoch, err := NewEpochState(s.db, block, s.genesisBABEConfig)
+	if err != nil {
+		return fmt.Errorf("creating epoch state: %w", err)
+	}
+	err = importSnapshotEpoch(epoch, consensus)
+	if err != nil {
+		return err
+	}
+
+	err = importSnapshotAuthoritySet(NewGrandpaState(s.db, block, nil), consensus)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing database: %w", err)
+	}
+
+	logger.Infof("imported snapshot of block #%d (%s)", header.Number, hash)
+	return nil
+}
+
+// importSnapshotState writes the tries of the state entries of the snapshot to the database as
+// the entries are read, and verifies their roots against the child trie roots of the state and
+// the state root of the block. The nodes of a state failing the verification are written before
+// the failure is detected, they are left unreferenced in the database.
+func (s *Service) importSnapshotState(reader *snapshot.Reader) error {
+	writer := &snapshotTrieWriter{batcher: trieWriter(database.NewTable(s.db, storagePrefix))}
+	builder := trie.NewRootBuilderWithWriter(reader.StateVersion, writer)
+
+	// childRoots are the roots of the child tries of the state which are not imported yet
+	childRoots := make(map[string]common.Hash)
+	// childKey is the child storage key of the trie being imported, or nil for the state trie
+	var childKey []byte
+	var imported uint
+	for {
+		entry, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.ChildKey == nil && childKey != nil:
+			return fmt.Errorf("%w: entry of the state trie after the child tries", snapshot.ErrUnexpectedRecord)
+		case entry.ChildKey == nil:
+			if bytes.HasPrefix(entry.Key, trie.ChildStorageKeyPrefix) {
+				childRoots[string(bytes.TrimPrefix(entry.Key, trie.ChildStorageKeyPrefix))] =
+					common.BytesToHash(entry.Value)
+			}
+		case !bytes.Equal(entry.ChildKey, childKey):
+			err = verifySnapshotTrieRoot(builder, childKey, childRoots, reader.Header.StateRoot)
+			if err != nil {
+				return err
+			}
+			childKey = entry.ChildKey
+		}
+
+		err = builder.Add(entry.Key, entry.Value)
+		if err != nil {
+			return fmt.Errorf("adding entry 0x%x: %w", entry.Key, err)
+		}
+
+		imported++
+		if imported%snapshotProgressInterval == 0 {
+			logger.Infof("imported %d state entries", imported)
+		}
+	}
+
+	err := verifySnapshotTrieRoot(builder, childKey, childRoots, reader.Header.StateRoot)
+	if err != nil {
+		return err
+	}
+	for childKey, root := range childRoots {
+		// empty child tries have no entry in the snapshot
+		if root != trie.EmptyHash {
+			return fmt.Errorf("%w: child trie 0x%x is missing", ErrSnapshotStateRoot, childKey)
+		}
+	}
+
+	err = writer.flush()
+	if err != nil {
+		return fmt.Errorf("writing state tries: %w", err)
+	}
+
+	logger.Infof("imported %d state entries with state root %s", imported, reader.Header.StateRoot)
+	return nil
+}
+
+// verifySnapshotTrieRoot verifies the root of the trie built from the entries added to the builder,
+// against the state root if the child storage key is nil, or against the root of the child trie
+// in the state otherwise. It resets the builder for the next trie.
+func verifySnapshotTrieRoot(builder *trie.RootBuilder, childKey []byte, childRoots map[string]common.Hash,
+	stateRoot common.Hash) error {
+	root, err := builder.Root()
+	if err != nil {
+		return fmt.Errorf("computing trie root: %w", err)
+	}
+
+	if childKey == nil {
+		if root != stateRoot {
+			return fmt.Errorf("%w: state root %s, header state root %s", ErrSnapshotStateRoot, root, stateRoot)
+		}
+		return nil
+	}
+
+	expectedRoot, ok := childRoots[string(childKey)]
+	if !ok {
+		return fmt.Errorf("%w: child trie 0x%x is not in the state or is imported twice",
+			ErrSnapshotStateRoot, childKey)
+	}
+	if root != expectedRoot {
+		return fmt.Errorf("%w: child trie 0x%x has root %s, expected %s",
+			ErrSnapshotStateRoot, childKey, root, expectedRoot)
+	}
+	delete(childRoots, string(childKey))
+	return nil
+}
+
+// snapshotTrieWriter writes the trie nodes of an imported snapshot to the database in batches of
+// snapshotBatchSize nodes, so the state is never held in memory.
+type snapshotTrieWriter struct {
+	batcher db.NewBatcher
+	batch   database.Batch
+	size    int
+}
+
+// Put writes the trie node or value, flushing the batch once it is full.
+func (w *snapshotTrieWriter) Put(key, value []byte) error {
+	if w.batch == nil {
+		w.batch = w.batcher.NewBatch()
+	}
+
+	err := w.batch.Put(key, value)
+	if err != nil {
+		return err
+	}
+
+	w.size++
+	if w.size < snapshotBatchSize {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *snapshotTrieWriter) flush() error {
+	if w.batch == nil {
+		return nil
+	}
+
+	err := w.batch.Flush()
+	w.batch, w.size = nil, 0
+	return err
+}
+
+// importSnapshotEpoch stores the data of the epoch of the block of a snapshot and of the next one.
+func importSnapshotEpoch(epoch *EpochState, consensus *snapshot.Consensus) error {
+	err := epoch.SetEpochDataRaw(consensus.Epoch, &consensus.EpochData)
+	if err != nil {
+		return fmt.Errorf("setting epoch data of epoch %d: %w", consensus.Epoch, err)
+	}
+	err = epoch.StoreConfigData(consensus.Epoch, &consensus.ConfigData)
+	if err != nil {
+		return fmt.Errorf("setting config data of epoch %d: %w", consensus.Epoch, err)
+	}
+	err = epoch.SetEpochDataRaw(consensus.Epoch+1, &consensus.NextEpochData)
+	if err != nil {
+		return fmt.Errorf("setting epoch data of epoch %d: %w", consensus.Epoch+1, err)
+	}
+	err = epoch.StoreConfigData(consensus.Epoch+1, &consensus.NextConfigData)
+	if err != nil {
+		return fmt.Errorf("setting config data of epoch %d: %w", consensus.Epoch+1, err)
+	}
+
+	err = epoch.StoreCurrentEpoch(consensus.Epoch)
+	if err != nil {
+		return fmt.Errorf("setting current epoch: %w", err)
+	}
+	return nil
+}
+
+// importSnapshotAuthoritySet stores the GRANDPA authority set finalising the blocks following
+// the block of a snapshot as the current set.
+func importSnapshotAuthoritySet(grandpa *GrandpaState, consensus *snapshot.Consensus) error {
+	voters, err := types.NewGrandpaVotersFromAuthoritiesRaw(consensus.Authorities)
+	if err != nil {
+		return fmt.Errorf("decoding authorities of set %d: %w", consensus.SetID, err)
+	}
+
+	err = grandpa.setAuthorities(consensus.SetID, voters)
+	if err != nil {
+		return fmt.Errorf("setting authorities of set %d: %w", consensus.SetID, err)
+	}
+	err = grandpa.setChangeSetIDAtBlock(consensus.SetID, consensus.SetIDChange)
+	if err != nil {
+		return fmt.Errorf("setting change of set %d: %w", consensus.SetID, err)
+	}
+	err = grandpa.setCurrentSetID(consensus.SetID)
+	if err != nil {
+		return fmt.Errorf("setting current set ID: %w", err)
+	}
+	err = grandpa.SetLatestRound(consensus.Round)
+	if err != nil {
+		return fmt.Errorf("setting latest round: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+//go:build integration
+
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/snapshot"
+	"github.com/ChainSafe/gossamer/lib/common"
+	runtime "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Snapshot(t *testing.T) {
+	serv := newTestService(t)
+	genData, genTrie, genesisHeader := newWestendDevGenesisWithTrieAndHeader(t)
+	err := serv.Initialise(&genData, &genesisHeader, genTrie)
+	require.NoError(t, err)
+	err = serv.SetupBase()
+	require.NoError(t, err)
+	err = serv.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := serv.Stop()
+		require.NoError(t, err)
+	})
+
+	// the state of the blocks holds a child trie
+	stateTrie, err := serv.Storage.LoadFromDB(genesisHeader.StateRoot)
+	require.NoError(t, err)
+	err = stateTrie.PutIntoChild([]byte("child"), []byte("key"), []byte("value"))
+	require.NoError(t, err)
+	err = serv.Storage.StoreTrie(runtime.NewTrieState(stateTrie), nil)
+	require.NoError(t, err)
+	stateRoot := stateTrie.MustHash()
+
+	auths := []types.GrandpaAuthoritiesRaw{{Key: [32]byte{1}, ID: 0}}
+	nextEpochData := types.NextEpochData{
+		Authorities: []types.AuthorityRaw{{Key: [32]byte{2}, Weight: 1}},
+		Randomness:  [32]byte{3},
+	}
+
+	// the block #2 announces the set 1 starting after the block #3
+	var chain []*types.Header
+	parentHash := genesisHeader.Hash()
+	for number := uint(1); number <= 5; number++ {
+		preDigest, err := types.NewBabePrimaryPreDigest(0, uint64(number), [32]byte{}, [64]byte{}).ToPreRuntimeDigest()
+		require.NoError(t, err)
+		digest := types.NewDigest()
+		require.NoError(t, digest.Add(*preDigest))
+		if number == 2 {
+			scheduledChange := types.GrandpaScheduledChange{Auths: auths, Delay: 1}
+			require.NoError(t, digest.Add(newTestConsensusDigest(t, types.GrandpaEngineID, scheduledChange)))
+		}
+
+		block := &types.Block{
+			Header: types.Header{
+				ParentHash: parentHash,
+				Number:     number,
+				StateRoot:  stateRoot,
+				Digest:     digest,
+			},
+			Body: types.Body{},
+		}
+		err = serv.Block.AddBlockWithArrivalTime(block, time.Now())
+		require.NoError(t, err)
+
+		chain = append(chain, &block.Header)
+		parentHash = block.Header.Hash()
+	}
+
+	authorities, err := types.GrandpaAuthoritiesRawToAuthorities(auths)
+	require.NoError(t, err)
+	err = serv.Grandpa.SetNextChange(types.NewGrandpaVotersFromAuthorities(authorities), 3)
+	require.NoError(t, err)
+	_, err = serv.Grandpa.IncrementSetID()
+	require.NoError(t, err)
+	err = serv.Epoch.SetEpochDataRaw(1, nextEpochData.ToEpochDataRaw())
+	require.NoError(t, err)
+	err = serv.Block.SetFinalisedHash(chain[3].Hash(), 2, 1)
+	require.NoError(t, err)
+
+	err = serv.ExportSnapshot(chain[4].Hash(), new(bytes.Buffer))
+	require.ErrorIs(t, err, ErrBlockNotFinalised)
+	err = serv.ExportSnapshot(chain[1].Hash(), new(bytes.Buffer))
+	require.ErrorIs(t, err, ErrPendingAuthoritySetChange)
+
+	buf := new(bytes.Buffer)
+	err = serv.ExportSnapshot(chain[3].Hash(), buf)
+	require.NoError(t, err)
+	exported := buf.Bytes()
+
+	newReader := func(t *testing.T) *snapshot.Reader {
+		t.Helper()
+		reader, err := snapshot.NewReader(bytes.NewReader(exported))
+		require.NoError(t, err)
+		t.Cleanup(reader.Close)
+		return reader
+	}
+
+	// the test BABE configuration has no genesis authorities
+	genesisConfigData := types.ConfigData{C1: 1, C2: 4}
+	reader := newReader(t)
+	require.Equal(t, &snapshot.Consensus{
+		FirstSlot:      1,
+		EpochData:      types.EpochDataRaw{},
+		ConfigData:     genesisConfigData,
+		NextEpochData:  *nextEpochData.ToEpochDataRaw(),
+		NextConfigData: genesisConfigData,
+		Round:          2,
+		SetID:          1,
+		SetIDChange:    3,
+		Authorities:    auths,
+	}, reader.Consensus)
+
+	imported := newTestService(t)
+	err = imported.Initialise(&genData, &genesisHeader, genTrie)
+	require.NoError(t, err)
+	err = imported.SetupBase()
+	require.NoError(t, err)
+
+	reader = newReader(t)
+	reader.GenesisHash = common.Hash{1}
+	err = imported.ImportSnapshot(reader)
+	require.ErrorIs(t, err, ErrSnapshotGenesisMismatch)
+
+	reader = newReader(t)
+	reader.Header.StateRoot = genesisHeader.StateRoot
+	err = imported.ImportSnapshot(reader)
+	require.ErrorIs(t, err, ErrSnapshotStateRoot)
+
+	err = imported.ImportSnapshot(newReader(t))
+	require.NoError(t, err)
+	err = imported.ImportSnapshot(newReader(t))
+	require.ErrorIs(t, err, ErrNodeNotEmpty)
+
+	err = imported.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := imported.Stop()
+		require.NoError(t, err)
+	})
+
+	finalised, err := imported.Block.GetHighestFinalisedHeader()
+	require.NoError(t, err)
+	require.Equal(t, chain[3].Hash(), finalised.Hash())
+	round, setID, err := imported.Block.GetHighestRoundAndSetID()
+	require.NoError(t, err)
+	require.Equal(t, [2]uint64{2, 1}, [2]uint64{round, setID})
+	hash, err := imported.Block.GetHashByNumber(4)
+	require.NoError(t, err)
+	require.Equal(t, chain[3].Hash(), hash)
+
+	setID, err = imported.Grandpa.GetCurrentSetID()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), setID)
+	voters, err := imported.Grandpa.GetAuthorities(1)
+	require.NoError(t, err)
+	require.Equal(t, types.NewGrandpaVotersFromAuthorities(authorities), voters)
+	change, err := imported.Grandpa.GetSetIDChange(1)
+	require.NoError(t, err)
+	require.Equal(t, uint(3), change)
+
+	epoch, err := imported.Epoch.GetEpochForBlock(chain[3])
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), epoch)
+	epochData, err := imported.Epoch.GetEpochDataRaw(1, nil)
+	require.NoError(t, err)
+	require.Equal(t, nextEpochData.ToEpochDataRaw(), epochData)
+
+	value, err := imported.Storage.GetStorageFromChild(&stateRoot, []byte("child"), []byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), value)
+	importedTrie, err := imported.Storage.LoadFromDB(stateRoot)
+	require.NoError(t, err)
+	require.Equal(t, stateTrie.Entries(), importedTrie.Entries())
+}
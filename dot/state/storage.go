@@ -0,0 +1,74 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"sync"
+
+	"github.com/ChainSafe/gossamer/dot/state/pruner"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+)
+
+const (
+	// InmemoryStorageBackend keeps the state tries in memory, loading them entirely from the database.
+	InmemoryStorageBackend = StorageBackend("inmemory")
+	// TrieDBStorageBackend reads the nodes of the state tries lazily from the database.
+	TrieDBStorageBackend = StorageBackend("triedb")
+)
+
+// StorageBackend is the implementation of the storage state
+type StorageBackend string
+
+// IsValid checks whether the storage backend is valid
+func (b StorageBackend) IsValid() bool {
+	switch b {
+	case InmemoryStorageBackend, TrieDBStorageBackend:
+		return true
+	default:
+		return false
+	}
+}
+
+// StorageState stores the state tries of the blocks and reads their storage.
+// It is implemented by InmemoryStorageState and TrieDBStorageState.
+type StorageState interface {
+	StoreTrie(ts *storage.TrieState, header *types.Header) error
+	TrieState(root *common.Hash) (*storage.TrieState, error)
+	LoadFromDB(root common.Hash) (trie.Trie, error)
+	ExistsStorage(root *common.Hash, key []byte) (bool, error)
+	GetStorage(root *common.Hash, key []byte) ([]byte, error)
+	GetStorageByBlockHash(bhash *common.Hash, key []byte) ([]byte, error)
+	GetStateRootFromBlock(bhash *common.Hash) (*common.Hash, error)
+	StorageRoot() (common.Hash, error)
+	Entries(root *common.Hash) (map[string][]byte, error)
+	GetKeysWithPrefix(root *common.Hash, prefix []byte) ([][]byte, error)
+	GetStorageChild(root *common.Hash, keyToChild []byte) (trie.Trie, error)
+	GetStorageFromChild(root *common.Hash, keyToChild, key []byte) ([]byte, error)
+	LoadCode(hash *common.Hash) ([]byte, error)
+	LoadCodeHash(hash *common.Hash) (common.Hash, error)
+	GenerateTrieProof(stateRoot common.Hash, keys [][]byte) (encodedProofNodes [][]byte, err error)
+	RegisterStorageObserver(o Observer)
+	UnregisterStorageObserver(o Observer)
+	sync.Locker
+
+	setPruner(p pruner.Pruner)
+}
+
+var (
+	_ StorageState = (*InmemoryStorageState)(nil)
+	_ StorageState = (*TrieDBStorageState)(nil)
+)
+
+// newStorageState creates the storage state of the storage backend
+func newStorageState(backend StorageBackend, db database.Database, blockState *BlockState,
+	tries *Tries) (StorageState, error) {
+	if backend == TrieDBStorageBackend {
+		return NewTrieDBStorageState(db, blockState), nil
+	}
+	return NewStorageState(db, blockState, tries)
+}
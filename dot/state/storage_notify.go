@@ -7,8 +7,10 @@
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime/storage"
 )
 
 // KeyValue struct to hold key value pairs
@@ -44,8 +46,17 @@ type Observer interface {
 	GetFilter() map[string][]byte
 }
 
+// storageNotifier notifies the observers of the storage changes of the stored state tries
+type storageNotifier struct {
+	blockState *BlockState
+	trieState  func(root *common.Hash) (*storage.TrieState, error)
+
+	observerListMutex sync.RWMutex
+	observerList      []Observer
+}
+
 // RegisterStorageObserver to add abserver to notification list
-func (s *InmemoryStorageState) RegisterStorageObserver(o Observer) {
+func (s *storageNotifier) RegisterStorageObserver(o Observer) {
 	s.observerListMutex.Lock()
 	defer s.observerListMutex.Unlock()
 	s.observerList = append(s.observerList, o)
@@ -65,13 +76,13 @@ func (s *InmemoryStorageState) RegisterStorageObserver(o Observer) {
 }
 
 // UnregisterStorageObserver removes observer from notification list
-func (s *InmemoryStorageState) UnregisterStorageObserver(o Observer) {
+func (s *storageNotifier) UnregisterStorageObserver(o Observer) {
 	s.observerListMutex.Lock()
 	defer s.observerListMutex.Unlock()
 	s.observerList = s.removeFromSlice(s.observerList, o)
 }
 
-func (s *InmemoryStorageState) notifyAll(root common.Hash) {
+func (s *storageNotifier) notifyAll(root common.Hash) {
 	s.observerListMutex.RLock()
 	defer s.observerListMutex.RUnlock()
 	for _, observer := range s.observerList {
@@ -82,8 +93,8 @@ func (s *InmemoryStorageState) notifyAll(root common.Hash) {
 	}
 }
 
-func (s *InmemoryStorageState) notifyObserver(root common.Hash, o Observer) error {
-	t, err := s.TrieState(&root)
+func (s *storageNotifier) notifyObserver(root common.Hash, o Observer) error {
+	t, err := s.trieState(&root)
 	if err != nil {
 		return err
 	}
@@ -137,7 +148,7 @@ func (s *InmemoryStorageState) notifyObserver(root common.Hash, o Observer) erro
 	return nil
 }
 
-func (s *InmemoryStorageState) removeFromSlice(observerList []Observer, observerToRemove Observer) []Observer {
+func (s *storageNotifier) removeFromSlice(observerList []Observer, observerToRemove Observer) []Observer {
 	observerListLength := len(observerList)
 	for i, observer := range observerList {
 		if observerToRemove.GetID() == observer.GetID() {
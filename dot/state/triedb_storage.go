@@ -0,0 +1,268 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/dot/state/pruner"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	inmemory_cache "github.com/ChainSafe/gossamer/pkg/trie/cache/inmemory"
+	inmemory_trie "github.com/ChainSafe/gossamer/pkg/trie/inmemory"
+	"github.com/ChainSafe/gossamer/pkg/trie/inmemory/proof"
+)
+
+// TrieDBStorageState is the storage state reading the nodes of the state tries lazily
+// from the database, only the nodes changed by a block are written when it is stored.
+// The nodes and values read are cached in a local cache per block, or per read, which is
+// merged into the cache shared by the tries of all the blocks.
+type TrieDBStorageState struct {
+	storageNotifier
+
+	blockState *BlockState
+	db         database.Table
+	cache      *inmemory_cache.SharedTrieCache
+
+	sync.RWMutex
+
+	pruner pruner.Pruner
+}
+
+// NewTrieDBStorageState creates a new TrieDBStorageState backed by the given block state
+// and database.
+func NewTrieDBStorageState(db database.Database, blockState *BlockState) *TrieDBStorageState {
+	s := &TrieDBStorageState{
+		blockState: blockState,
+		db:         database.NewTable(db, storagePrefix),
+		cache:      inmemory_cache.NewSharedTrieCache(inmemory_cache.DefaultSharedCacheSize),
+		pruner:     &pruner.ArchiveNode{},
+	}
+	s.storageNotifier = storageNotifier{
+		blockState:   blockState,
+		trieState:    s.TrieState,
+		observerList: []Observer{},
+	}
+	return s
+}
+
+func (s *TrieDBStorageState) setPruner(p pruner.Pruner) {
+	s.pruner = p
+}
+
+// StoreTrie writes the nodes changed in the given trie to the database
+func (s *TrieDBStorageState) StoreTrie(ts *storage.TrieState, header *types.Header) error {
+	root := ts.Trie().MustHash()
+
+	if header != nil {
+		insertedNodeHashes, deletedNodeHashes, err := ts.GetChangedNodeHashes()
+		if err != nil {
+			return fmt.Errorf("getting trie changed node hashes for block hash %s: %w", header.Hash(), err)
+		}
+
+		err = s.pruner.StoreJournalRecord(
+			deletedNodeHashes, insertedNodeHashes, header.Hash(), header.ParentHash, header.Number)
+		if err != nil {
+			return fmt.Errorf("storing journal record: %w", err)
+		}
+	}
+
+	switch t := ts.Trie().(type) {
+	case *trieDBTrie:
+		err := t.db.writeChanges()
+		if err != nil {
+			logger.Warnf("failed to write trie with root %s to database: %s", root, err)
+			return err
+		}
+		t.cache.Merge()
+	case *inmemory_trie.InMemoryTrie:
+		err := t.WriteDirty(trieWriter(s.db))
+		if err != nil {
+			logger.Warnf("failed to write trie with root %s to database: %s", root, err)
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot store trie of type %T", t)
+	}
+
+	logger.Tracef("stored trie in storage state: %s", root)
+
+	go s.notifyAll(root)
+	return nil
+}
+
+// TrieState returns the TrieState for a given state root.
+// If no state root is provided, it returns the TrieState for the current chain head.
+func (s *TrieDBStorageState) TrieState(root *common.Hash) (*storage.TrieState, error) {
+	if root == nil {
+		sr, err := s.blockState.BestBlockStateRoot()
+		if err != nil {
+			return nil, fmt.Errorf("while getting best block state root: %w", err)
+		}
+		root = &sr
+	}
+
+	t, err := s.LoadFromDB(*root)
+	if err != nil {
+		return nil, fmt.Errorf("while loading from database: %w", err)
+	}
+
+	logger.Tracef("returning trie with root %s to be modified", root)
+	return storage.NewTrieState(t), nil
+}
+
+// LoadFromDB returns the trie with the given root, its nodes are read from the database
+// as they are accessed.
+func (s *TrieDBStorageState) LoadFromDB(root common.Hash) (trie.Trie, error) {
+	return s.loadFromDB(root)
+}
+
+func (s *TrieDBStorageState) loadFromDB(root common.Hash) (*trieDBTrie, error) {
+	if root != trie.EmptyHash {
+		_, err := s.db.Get(root.ToBytes())
+		if err != nil {
+			return nil, fmt.Errorf("getting root node %s: %w", root, err)
+		}
+	}
+
+	return newTrieDBTrie(root, newTrieNodeDB(s.db), s.cache.LocalCache()), nil
+}
+
+// loadTrie returns the trie with the given root, or the trie of the best block if the root
+// is nil, to read from. Its local cache must be merged once the trie is read.
+func (s *TrieDBStorageState) loadTrie(root *common.Hash) (*trieDBTrie, error) {
+	if root == nil {
+		sr, err := s.blockState.BestBlockStateRoot()
+		if err != nil {
+			return nil, err
+		}
+		root = &sr
+	}
+
+	tr, err := s.loadFromDB(*root)
+	if err != nil {
+		return nil, fmt.Errorf("trie does not exist at root %s: %w", *root, err)
+	}
+
+	return tr, nil
+}
+
+// ExistsStorage check if the key exists in the storage trie with the given storage hash
+// If no hash is provided, the current chain head is used
+func (s *TrieDBStorageState) ExistsStorage(root *common.Hash, key []byte) (bool, error) {
+	val, err := s.GetStorage(root, key)
+	return val != nil, err
+}
+
+// GetStorage gets the object from the trie using the given key and storage hash
+// If no hash is provided, the current chain head is used
+func (s *TrieDBStorageState) GetStorage(root *common.Hash, key []byte) ([]byte, error) {
+	tr, err := s.loadTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	defer tr.cache.Merge()
+
+	return tr.Get(key), nil
+}
+
+// GetStorageByBlockHash returns the value at the given key at the given block hash
+func (s *TrieDBStorageState) GetStorageByBlockHash(bhash *common.Hash, key []byte) ([]byte, error) {
+	root, err := s.GetStateRootFromBlock(bhash)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetStorage(root, key)
+}
+
+// GetStateRootFromBlock returns the state root hash of a given block hash
+func (s *TrieDBStorageState) GetStateRootFromBlock(bhash *common.Hash) (*common.Hash, error) {
+	if bhash == nil {
+		b := s.blockState.BestBlockHash()
+		bhash = &b
+	}
+
+	header, err := s.blockState.GetHeader(*bhash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &header.StateRoot, nil
+}
+
+// StorageRoot returns the root hash of the current storage trie
+func (s *TrieDBStorageState) StorageRoot() (common.Hash, error) {
+	return s.blockState.BestBlockStateRoot()
+}
+
+// Entries returns Entries from the trie with the given state root
+func (s *TrieDBStorageState) Entries(root *common.Hash) (map[string][]byte, error) {
+	tr, err := s.loadTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	defer tr.cache.Merge()
+
+	return tr.Entries(), nil
+}
+
+// GetKeysWithPrefix returns all that match the given prefix for the given hash
+// (or best block state root if hash is nil) in lexicographic order
+func (s *TrieDBStorageState) GetKeysWithPrefix(root *common.Hash, prefix []byte) ([][]byte, error) {
+	tr, err := s.loadTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	defer tr.cache.Merge()
+
+	return tr.GetKeysWithPrefix(prefix), nil
+}
+
+// GetStorageChild returns a child trie, if it exists
+func (s *TrieDBStorageState) GetStorageChild(root *common.Hash, keyToChild []byte) (trie.Trie, error) {
+	tr, err := s.loadTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	defer tr.cache.Merge()
+
+	return tr.GetChild(keyToChild)
+}
+
+// GetStorageFromChild get a value from a child trie
+func (s *TrieDBStorageState) GetStorageFromChild(root *common.Hash, keyToChild, key []byte) ([]byte, error) {
+	tr, err := s.loadTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	defer tr.cache.Merge()
+
+	return tr.GetFromChild(keyToChild, key)
+}
+
+// LoadCode returns the runtime code (located at :code)
+func (s *TrieDBStorageState) LoadCode(hash *common.Hash) ([]byte, error) {
+	return s.GetStorage(hash, codeKey)
+}
+
+// LoadCodeHash returns the hash of the runtime code (located at :code)
+func (s *TrieDBStorageState) LoadCodeHash(hash *common.Hash) (common.Hash, error) {
+	code, err := s.LoadCode(hash)
+	if err != nil {
+		return common.NewHash([]byte{}), err
+	}
+
+	return common.Blake2bHash(code)
+}
+
+// GenerateTrieProof returns the proofs related to the keys on the state root trie
+func (s *TrieDBStorageState) GenerateTrieProof(stateRoot common.Hash, keys [][]byte) (
+	encodedProofNodes [][]byte, err error) {
+	return proof.Generate(stateRoot[:], keys, newTrieNodeDB(s.db))
+}
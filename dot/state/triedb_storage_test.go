@@ -0,0 +1,251 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+	runtime "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	inmemory_trie "github.com/ChainSafe/gossamer/pkg/trie/inmemory"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTrieDBStorageState(t *testing.T, db database.Database) *TrieDBStorageState {
+	t.Helper()
+
+	tries := newTriesEmpty()
+	bs := newTestBlockState(t, tries)
+	return NewTrieDBStorageState(db, bs)
+}
+
+var testTrieDBStorageEntries = map[string][]byte{
+	"no":           {1},
+	"noot":         {1, 2},
+	"not":          {1, 2, 3},
+	"notable":      bytes.Repeat([]byte{4}, 33),
+	"notification": bytes.Repeat([]byte{5}, 64),
+	"test":         {6},
+	":code":        bytes.Repeat([]byte{7}, 100),
+}
+
+func putTestTrieDBStorageEntries(t *testing.T, ts *runtime.TrieState) {
+	t.Helper()
+
+	for key, value := range testTrieDBStorageEntries {
+		require.NoError(t, ts.Put([]byte(key), value))
+	}
+	require.NoError(t, ts.SetChildStorage([]byte("child"), []byte("key"), []byte("value")))
+	require.NoError(t, ts.SetChildStorage([]byte("child"), []byte("bigkey"), bytes.Repeat([]byte{8}, 40)))
+}
+
+func TestTrieDBStorage_StoreAndLoadTrie(t *testing.T) {
+	for _, version := range []trie.TrieLayout{trie.V0, trie.V1} {
+		t.Run(version.String(), func(t *testing.T) {
+			storage := newTestTrieDBStorageState(t, NewInMemoryDB(t))
+			ts, err := storage.TrieState(&trie.EmptyHash)
+			require.NoError(t, err)
+			ts.SetVersion(version)
+			putTestTrieDBStorageEntries(t, ts)
+
+			expected := inmemory_trie.NewEmptyTrie()
+			expected.SetVersion(version)
+			for key, value := range testTrieDBStorageEntries {
+				require.NoError(t, expected.Put([]byte(key), value))
+			}
+			require.NoError(t, expected.PutIntoChild([]byte("child"), []byte("key"), []byte("value")))
+			require.NoError(t, expected.PutIntoChild([]byte("child"), []byte("bigkey"), bytes.Repeat([]byte{8}, 40)))
+
+			root, err := ts.Trie().Hash()
+			require.NoError(t, err)
+			require.Equal(t, expected.MustHash(), root)
+
+			err = storage.StoreTrie(ts, nil)
+			require.NoError(t, err)
+
+			loaded, err := storage.TrieState(&root)
+			require.NoError(t, err)
+			require.Equal(t, root, loaded.Trie().MustHash())
+			require.Equal(t, expected.Entries(), loaded.TrieEntries())
+
+			value, err := storage.GetStorageFromChild(&root, []byte("child"), []byte("bigkey"))
+			require.NoError(t, err)
+			require.Equal(t, bytes.Repeat([]byte{8}, 40), value)
+
+			code, err := storage.LoadCode(&root)
+			require.NoError(t, err)
+			require.Equal(t, testTrieDBStorageEntries[":code"], code)
+
+			keys, err := storage.GetKeysWithPrefix(&root, []byte("not"))
+			require.NoError(t, err)
+			require.Equal(t, expected.GetKeysWithPrefix([]byte("not")), keys)
+
+			keys, err = storage.GetKeysWithPrefix(&root, []byte{})
+			require.NoError(t, err)
+			require.Equal(t, expected.GetKeysWithPrefix([]byte{}), keys)
+
+			for _, key := range [][]byte{{}, []byte("no"), []byte("nop"), []byte("test")} {
+				require.Equal(t, expected.NextKey(key), loaded.NextKey(key))
+			}
+
+			_, err = storage.GetStorageChild(&root, []byte("nochild"))
+			require.ErrorIs(t, err, trie.ErrChildTrieDoesNotExist)
+
+			_, err = storage.LoadFromDB(common.Hash{1})
+			require.ErrorIs(t, err, database.ErrNotFound)
+		})
+	}
+}
+
+func TestTrieDBStorage_ClearChildStorage(t *testing.T) {
+	storage := newTestTrieDBStorageState(t, NewInMemoryDB(t))
+	ts, err := storage.TrieState(&trie.EmptyHash)
+	require.NoError(t, err)
+	ts.SetVersion(trie.V1)
+
+	require.NoError(t, ts.SetChildStorage([]byte("child"), []byte("key"), []byte("value")))
+	require.NoError(t, ts.ClearChildStorage([]byte("child"), []byte("key")))
+
+	_, err = ts.Trie().GetChild([]byte("child"))
+	require.ErrorIs(t, err, trie.ErrChildTrieDoesNotExist)
+	require.Equal(t, trie.EmptyHash, ts.Trie().MustHash())
+}
+
+func TestTrieDBStorage_ChangedNodeHashes(t *testing.T) {
+	storage := newTestTrieDBStorageState(t, NewInMemoryDB(t))
+	ts, err := storage.TrieState(&trie.EmptyHash)
+	require.NoError(t, err)
+	ts.SetVersion(trie.V1)
+	putTestTrieDBStorageEntries(t, ts)
+
+	parentRoot, err := ts.Trie().Hash()
+	require.NoError(t, err)
+	require.NoError(t, storage.StoreTrie(ts, nil))
+
+	ts, err = storage.TrieState(&parentRoot)
+	require.NoError(t, err)
+	ts.SetVersion(trie.V1)
+	require.NoError(t, ts.Put([]byte("test"), []byte{9}))
+	// a node inserted and deleted within the block is neither inserted nor deleted
+	require.NoError(t, ts.Put([]byte("transient"), []byte{10}))
+	require.NoError(t, ts.Delete([]byte("transient")))
+
+	inserted, deleted, err := ts.GetChangedNodeHashes()
+	require.NoError(t, err)
+
+	root, err := ts.Trie().Hash()
+	require.NoError(t, err)
+	require.Contains(t, inserted, root)
+	require.Contains(t, deleted, parentRoot)
+	require.NotContains(t, inserted, parentRoot)
+
+	header := &types.Header{
+		ParentHash: common.Hash{1},
+		Number:     1,
+		StateRoot:  root,
+	}
+	require.NoError(t, storage.StoreTrie(ts, header))
+
+	value, err := storage.GetStorage(&root, []byte("test"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{9}, value)
+
+	// the parent state is kept until pruned
+	value, err = storage.GetStorage(&parentRoot, []byte("test"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{6}, value)
+}
+
+func TestTrieDBStorage_StoresInmemoryTrie(t *testing.T) {
+	db := NewInMemoryDB(t)
+	tries := newTriesEmpty()
+	inmemoryStorage, err := NewStorageState(db, newTestBlockState(t, tries), tries)
+	require.NoError(t, err)
+
+	ts, err := inmemoryStorage.TrieState(&trie.EmptyHash)
+	require.NoError(t, err)
+	ts.SetVersion(trie.V1)
+	putTestTrieDBStorageEntries(t, ts)
+
+	root, err := ts.Trie().Hash()
+	require.NoError(t, err)
+
+	// the in-memory tries of the genesis and imported states are written by the
+	// trie database storage so it can read their hashed values by hash
+	storage := newTestTrieDBStorageState(t, db)
+	require.NoError(t, storage.StoreTrie(ts, nil))
+	require.NoError(t, inmemoryStorage.StoreTrie(ts, nil))
+	entries, err := storage.Entries(&root)
+	require.NoError(t, err)
+	require.Equal(t, ts.TrieEntries(), entries)
+
+	value, err := storage.GetStorageFromChild(&root, []byte("child"), []byte("bigkey"))
+	require.NoError(t, err)
+	require.Equal(t, bytes.Repeat([]byte{8}, 40), value)
+
+	keys := [][]byte{[]byte("notable"), []byte("test")}
+	expectedProof, err := inmemoryStorage.GenerateTrieProof(root, keys)
+	require.NoError(t, err)
+	proof, err := storage.GenerateTrieProof(root, keys)
+	require.NoError(t, err)
+	require.ElementsMatch(t, expectedProof, proof)
+}
+
+func TestStorageBackends_ReadTriesWrittenByTheOtherBackend(t *testing.T) {
+	newInmemoryStorage := func(t *testing.T, db database.Database) *InmemoryStorageState {
+		tries := newTriesEmpty()
+		storage, err := NewStorageState(db, newTestBlockState(t, tries), tries)
+		require.NoError(t, err)
+		return storage
+	}
+
+	t.Run("inmemory_to_triedb", func(t *testing.T) {
+		db := NewInMemoryDB(t)
+		inmemoryStorage := newInmemoryStorage(t, db)
+		ts, err := inmemoryStorage.TrieState(&trie.EmptyHash)
+		require.NoError(t, err)
+		ts.SetVersion(trie.V1)
+		putTestTrieDBStorageEntries(t, ts)
+		root, err := ts.Trie().Hash()
+		require.NoError(t, err)
+		require.NoError(t, inmemoryStorage.StoreTrie(ts, nil))
+
+		storage := newTestTrieDBStorageState(t, db)
+		entries, err := storage.Entries(&root)
+		require.NoError(t, err)
+		require.Equal(t, ts.TrieEntries(), entries)
+
+		value, err := storage.GetStorageFromChild(&root, []byte("child"), []byte("bigkey"))
+		require.NoError(t, err)
+		require.Equal(t, bytes.Repeat([]byte{8}, 40), value)
+	})
+
+	t.Run("triedb_to_inmemory", func(t *testing.T) {
+		db := NewInMemoryDB(t)
+		storage := newTestTrieDBStorageState(t, db)
+		ts, err := storage.TrieState(&trie.EmptyHash)
+		require.NoError(t, err)
+		ts.SetVersion(trie.V1)
+		putTestTrieDBStorageEntries(t, ts)
+		root, err := ts.Trie().Hash()
+		require.NoError(t, err)
+		require.NoError(t, storage.StoreTrie(ts, nil))
+
+		inmemoryStorage := newInmemoryStorage(t, db)
+		loaded, err := inmemoryStorage.LoadFromDB(root)
+		require.NoError(t, err)
+		require.Equal(t, root, loaded.MustHash())
+		for key, value := range testTrieDBStorageEntries {
+			require.Equal(t, value, loaded.Get([]byte(key)))
+		}
+
+		value, err := inmemoryStorage.GetStorageFromChild(&root, []byte("child"), []byte("bigkey"))
+		require.NoError(t, err)
+		require.Equal(t, bytes.Repeat([]byte{8}, 40), value)
+	})
+}
@@ -0,0 +1,502 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/internal/primitives/core/hash"
+	primitives "github.com/ChainSafe/gossamer/internal/primitives/runtime"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	inmemory_cache "github.com/ChainSafe/gossamer/pkg/trie/cache/inmemory"
+	"github.com/ChainSafe/gossamer/pkg/trie/db"
+	inmemory_trie "github.com/ChainSafe/gossamer/pkg/trie/inmemory"
+	"github.com/ChainSafe/gossamer/pkg/trie/tracking"
+	"github.com/ChainSafe/gossamer/pkg/trie/triedb"
+)
+
+// emptyNode is the encoding of the empty trie root node
+var emptyNode = []byte{0}
+
+// trieNodeDB is the database of the trie database over the storage table. The trie
+// database prefixes the hashes of the nodes and values with their location in the trie,
+// they are stored by hash only as the in-memory tries do. The changes are kept in memory
+// until they are written to the storage table.
+type trieNodeDB struct {
+	table database.Table
+
+	mtx      sync.RWMutex
+	inserted map[common.Hash]*insertedTrieNode
+	deleted  map[common.Hash]struct{}
+}
+
+// insertedTrieNode is a node or value inserted since the changes were written, with
+// the number of times it is inserted. Identical nodes and values at different places of
+// the tries share the same hash, so one is removed only once all of them are deleted.
+type insertedTrieNode struct {
+	value      []byte
+	references uint
+}
+
+func newTrieNodeDB(table database.Table) *trieNodeDB {
+	return &trieNodeDB{
+		table:    table,
+		inserted: make(map[common.Hash]*insertedTrieNode),
+		deleted:  make(map[common.Hash]struct{}),
+	}
+}
+
+// hashFromKey returns the hash of the node or value the trie database key ends with
+func hashFromKey(key []byte) common.Hash {
+	return common.BytesToHash(key[max(len(key)-common.HashLength, 0):])
+}
+
+// Get returns the node or value with the hash the key ends with
+func (db *trieNodeDB) Get(key []byte) ([]byte, error) {
+	nodeHash := hashFromKey(key)
+	if nodeHash == trie.EmptyHash {
+		return emptyNode, nil
+	}
+
+	db.mtx.RLock()
+	node, ok := db.inserted[nodeHash]
+	db.mtx.RUnlock()
+	if ok {
+		return node.value, nil
+	}
+
+	return db.table.Get(nodeHash[:])
+}
+
+// Put inserts the node or value with the hash the key ends with
+func (db *trieNodeDB) Put(key, value []byte) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+
+	nodeHash := hashFromKey(key)
+	node, ok := db.inserted[nodeHash]
+	if !ok {
+		node = &insertedTrieNode{value: slices.Clone(value)}
+		db.inserted[nodeHash] = node
+	}
+	node.references++
+	delete(db.deleted, nodeHash)
+	return nil
+}
+
+// Del deletes the node or value with the hash the key ends with, the nodes
+// inserted since the changes were written and not stored before are discarded
+// once deleted as many times as they were inserted.
+func (db *trieNodeDB) Del(key []byte) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+
+	nodeHash := hashFromKey(key)
+	node, inserted := db.inserted[nodeHash]
+	if inserted {
+		node.references--
+		if node.references > 0 {
+			return nil
+		}
+		delete(db.inserted, nodeHash)
+		stored, err := db.table.Has(nodeHash[:])
+		if err != nil {
+			return fmt.Errorf("checking node %s is stored: %w", nodeHash, err)
+		}
+		if !stored {
+			return nil
+		}
+	}
+
+	db.deleted[nodeHash] = struct{}{}
+	return nil
+}
+
+// Flush does nothing since the changes are written with writeChanges.
+func (*trieNodeDB) Flush() error {
+	return nil
+}
+
+// NewBatch returns a batch applying its changes to the database on flush.
+func (db *trieNodeDB) NewBatch() database.Batch {
+	return &trieNodeBatch{db: db}
+}
+
+// changes returns copies of the sets of the node and value hashes inserted and deleted
+// since the changes were last written.
+func (db *trieNodeDB) changes() (inserted, deleted map[common.Hash]struct{}) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+
+	inserted = make(map[common.Hash]struct{}, len(db.inserted))
+	for nodeHash := range db.inserted {
+		inserted[nodeHash] = struct{}{}
+	}
+	deleted = make(map[common.Hash]struct{}, len(db.deleted))
+	for nodeHash := range db.deleted {
+		deleted[nodeHash] = struct{}{}
+	}
+	return inserted, deleted
+}
+
+// writeChanges writes the inserted nodes and values to the storage table. The deleted
+// ones are left to the pruner.
+func (db *trieNodeDB) writeChanges() error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+
+	batch := db.table.NewBatch()
+	for nodeHash, node := range db.inserted {
+		err := batch.Put(nodeHash.ToBytes(), node.value)
+		if err != nil {
+			batch.Reset()
+			return fmt.Errorf("putting node %s: %w", nodeHash, err)
+		}
+	}
+
+	err := batch.Flush()
+	if err != nil {
+		return err
+	}
+
+	db.inserted = make(map[common.Hash]*insertedTrieNode)
+	db.deleted = make(map[common.Hash]struct{})
+	return nil
+}
+
+// hashedValueBatcher writes the in-memory tries with their hashed values under their hash as well
+// as under their prefixed key, so the trie database, which reads the hashed values by their hash
+// only, can read the tries whatever the storage backend which wrote them.
+type hashedValueBatcher struct {
+	batcher db.NewBatcher
+}
+
+func (b hashedValueBatcher) NewBatch() database.Batch {
+	return hashedValueBatch{Batch: b.batcher.NewBatch()}
+}
+
+type hashedValueBatch struct {
+	database.Batch
+}
+
+func (b hashedValueBatch) Put(key, value []byte) error {
+	err := b.Batch.Put(key, value)
+	if err != nil || len(key) <= common.HashLength {
+		return err
+	}
+	return b.Batch.Put(hashFromKey(key).ToBytes(), value)
+}
+
+// trieWriter returns where to write the in-memory tries to, for both storage backends.
+func trieWriter(batcher db.NewBatcher) db.NewBatcher {
+	return hashedValueBatcher{batcher: batcher}
+}
+
+type trieNodeBatchOp struct {
+	key, value []byte
+	del        bool
+}
+
+// trieNodeBatch is the batch of the trie node database
+type trieNodeBatch struct {
+	db  *trieNodeDB
+	ops []trieNodeBatchOp
+}
+
+func (b *trieNodeBatch) Put(key, value []byte) error {
+	b.ops = append(b.ops, trieNodeBatchOp{key: key, value: value})
+	return nil
+}
+
+func (b *trieNodeBatch) Del(key []byte) error {
+	b.ops = append(b.ops, trieNodeBatchOp{key: key, del: true})
+	return nil
+}
+
+func (b *trieNodeBatch) Flush() error {
+	for _, op := range b.ops {
+		var err error
+		if op.del {
+			err = b.db.Del(op.key)
+		} else {
+			err = b.db.Put(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	b.ops = nil
+	return nil
+}
+
+func (b *trieNodeBatch) ValueSize() int {
+	size := 0
+	for _, op := range b.ops {
+		size += len(op.value)
+	}
+	return size
+}
+
+func (b *trieNodeBatch) Reset() {
+	b.ops = nil
+}
+
+func (*trieNodeBatch) Close() error {
+	return nil
+}
+
+// trieDBTrie is a state trie of the trie database, its nodes are read lazily from
+// the node database and cached in the local cache of the block, merged into the
+// shared cache once the trie is stored or read.
+type trieDBTrie struct {
+	db      *trieNodeDB
+	cache   *inmemory_cache.LocalTrieCache
+	version trie.TrieLayout
+	trie    *triedb.TrieDB[hash.H256, primitives.BlakeTwo256]
+}
+
+var _ trie.Trie = (*trieDBTrie)(nil)
+
+func newTrieDBTrie(root common.Hash, db *trieNodeDB, cache *inmemory_cache.LocalTrieCache) *trieDBTrie {
+	return &trieDBTrie{
+		db:    db,
+		cache: cache,
+		trie: triedb.NewTrieDB[hash.H256, primitives.BlakeTwo256](
+			hash.H256(root.ToBytes()), db,
+			triedb.WithCache[hash.H256, primitives.BlakeTwo256](cache),
+			triedb.WithSharedValues[hash.H256, primitives.BlakeTwo256](),
+		),
+	}
+}
+
+func (t *trieDBTrie) String() string {
+	return fmt.Sprintf("trie database trie with root %s", t.MustHash())
+}
+
+func (t *trieDBTrie) Get(key []byte) []byte {
+	return t.trie.Get(key)
+}
+
+// Hash commits the changes of the trie to the node database and returns its root hash
+func (t *trieDBTrie) Hash() (common.Hash, error) {
+	root, err := t.trie.Hash()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(root.Bytes()), nil
+}
+
+func (t *trieDBTrie) MustHash() common.Hash {
+	root, err := t.Hash()
+	if err != nil {
+		panic(err)
+	}
+	return root
+}
+
+func (t *trieDBTrie) Put(key, value []byte) error {
+	return t.trie.Put(key, value)
+}
+
+func (t *trieDBTrie) Delete(key []byte) error {
+	return t.trie.Delete(key)
+}
+
+func (t *trieDBTrie) SetVersion(v trie.TrieLayout) {
+	t.version = v
+	t.trie.SetVersion(v)
+}
+
+// Iter returns an iterator over the entries of the trie, with the changes committed.
+func (t *trieDBTrie) Iter() trie.TrieIterator {
+	t.MustHash()
+	return triedb.NewTrieDBIterator(t.trie)
+}
+
+// PrefixedIter returns an iterator over the entries of the trie after the given key,
+// with the changes committed.
+func (t *trieDBTrie) PrefixedIter(prefix []byte) trie.TrieIterator {
+	t.MustHash()
+	return triedb.NewTrieDBIterator(t.trie,
+		triedb.WithCursorAt[hash.H256, primitives.BlakeTwo256](slices.Clone(prefix)))
+}
+
+func (t *trieDBTrie) Entries() (keyValueMap map[string][]byte) {
+	keyValueMap = make(map[string][]byte)
+	iter := t.Iter()
+	for entry := iter.NextEntry(); entry != nil; entry = iter.NextEntry() {
+		keyValueMap[string(entry.Key)] = entry.Value
+	}
+	return keyValueMap
+}
+
+func (t *trieDBTrie) NextKey(key []byte) []byte {
+	return t.PrefixedIter(key).NextKey()
+}
+
+func (t *trieDBTrie) GetKeysWithPrefix(prefix []byte) (keysLE [][]byte) {
+	// the iteration starts after the prefix, which can be a key itself
+	if t.Get(prefix) != nil {
+		keysLE = append(keysLE, slices.Clone(prefix))
+	}
+
+	iter := t.PrefixedIter(prefix)
+	for key := iter.NextKey(); key != nil && bytes.HasPrefix(key, prefix); key = iter.NextKey() {
+		keysLE = append(keysLE, key)
+	}
+	return keysLE
+}
+
+func (t *trieDBTrie) ClearPrefix(prefix []byte) (err error) {
+	for _, key := range t.GetKeysWithPrefix(prefix) {
+		err = t.Delete(key)
+		if err != nil {
+			return fmt.Errorf("deleting key 0x%x: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (t *trieDBTrie) ClearPrefixLimit(prefix []byte, limit uint32) (
+	deleted uint32, allDeleted bool, err error) {
+	keys := t.GetKeysWithPrefix(prefix)
+	for _, key := range keys {
+		if deleted == limit {
+			return deleted, false, nil
+		}
+
+		err = t.Delete(key)
+		if err != nil {
+			return deleted, false, fmt.Errorf("deleting key 0x%x: %w", key, err)
+		}
+		deleted++
+	}
+	return deleted, true, nil
+}
+
+func childStorageKey(keyToChild []byte) []byte {
+	return bytes.Join([][]byte{inmemory_trie.ChildStorageKeyPrefix, keyToChild}, nil)
+}
+
+// getChild returns the child trie at key :child_storage:[keyToChild],
+// sharing the node database of the trie.
+func (t *trieDBTrie) getChild(keyToChild []byte) (*trieDBTrie, error) {
+	childRoot := t.Get(childStorageKey(keyToChild))
+	if childRoot == nil {
+		return nil, fmt.Errorf("%w at key 0x%x%x",
+			trie.ErrChildTrieDoesNotExist, inmemory_trie.ChildStorageKeyPrefix, keyToChild)
+	}
+
+	child := newTrieDBTrie(common.BytesToHash(childRoot), t.db, t.cache)
+	child.SetVersion(t.version)
+	return child, nil
+}
+
+// setChild puts the root hash of the child trie at key :child_storage:[keyToChild]
+func (t *trieDBTrie) setChild(keyToChild []byte, child *trieDBTrie) error {
+	childHash, err := child.Hash()
+	if err != nil {
+		return fmt.Errorf("hashing child trie at key 0x%x: %w", keyToChild, err)
+	}
+
+	err = t.Put(childStorageKey(keyToChild), childHash.ToBytes())
+	if err != nil {
+		return fmt.Errorf("putting child trie root hash %s in trie: %w", childHash, err)
+	}
+	return nil
+}
+
+func (t *trieDBTrie) GetChild(keyToChild []byte) (trie.Trie, error) {
+	child, err := t.getChild(keyToChild)
+	if err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+func (t *trieDBTrie) GetFromChild(keyToChild, key []byte) ([]byte, error) {
+	child, err := t.getChild(keyToChild)
+	if err != nil {
+		return nil, err
+	}
+	return child.Get(key), nil
+}
+
+// GetChildTries returns the child tries of the trie by root hash
+func (t *trieDBTrie) GetChildTries() map[common.Hash]trie.Trie {
+	children := make(map[common.Hash]trie.Trie)
+	for _, key := range t.GetKeysWithPrefix(inmemory_trie.ChildStorageKeyPrefix) {
+		childRoot := common.BytesToHash(t.Get(key))
+		children[childRoot] = newTrieDBTrie(childRoot, t.db, t.cache)
+	}
+	return children
+}
+
+func (t *trieDBTrie) PutIntoChild(keyToChild, key, value []byte) error {
+	child, err := t.getChild(keyToChild)
+	if errors.Is(err, trie.ErrChildTrieDoesNotExist) {
+		child = newTrieDBTrie(trie.EmptyHash, t.db, t.cache)
+		child.SetVersion(t.version)
+	} else if err != nil {
+		return fmt.Errorf("getting child: %w", err)
+	}
+
+	err = child.Put(key, value)
+	if err != nil {
+		return fmt.Errorf("putting into child trie located at key 0x%x: %w", keyToChild, err)
+	}
+
+	return t.setChild(keyToChild, child)
+}
+
+func (t *trieDBTrie) DeleteChild(keyToChild []byte) (err error) {
+	err = t.Delete(childStorageKey(keyToChild))
+	if err != nil {
+		return fmt.Errorf("deleting child trie located at key 0x%x: %w", keyToChild, err)
+	}
+	return nil
+}
+
+func (t *trieDBTrie) ClearFromChild(keyToChild, key []byte) error {
+	child, err := t.getChild(keyToChild)
+	if err != nil {
+		return err
+	}
+
+	err = child.Delete(key)
+	if err != nil {
+		return fmt.Errorf("deleting from child trie located at key 0x%x: %w", keyToChild, err)
+	}
+
+	childHash, err := child.Hash()
+	if err != nil {
+		return fmt.Errorf("hashing child trie at key 0x%x: %w", keyToChild, err)
+	}
+	if childHash == trie.EmptyHash {
+		return t.DeleteChild(keyToChild)
+	}
+
+	return t.setChild(keyToChild, child)
+}
+
+// GetChangedNodeHashes commits the changes of the trie and returns the hashes of the nodes
+// and values inserted and deleted since the changes were last written to the storage table.
+func (t *trieDBTrie) GetChangedNodeHashes() (inserted, deleted map[common.Hash]struct{}, err error) {
+	_, err = t.Hash()
+	if err != nil {
+		return nil, nil, fmt.Errorf("committing trie changes: %w", err)
+	}
+
+	inserted, deleted = t.db.changes()
+	return inserted, deleted, nil
+}
+
+// HandleTrackedDeltas does nothing since the node database tracks the deleted nodes.
+func (*trieDBTrie) HandleTrackedDeltas(bool, tracking.Getter) {}
@@ -0,0 +1,115 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	inmemory_trie "github.com/ChainSafe/gossamer/pkg/trie/inmemory"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_trieDBTrie_MatchesInMemoryTrie(t *testing.T) {
+	for _, version := range []trie.TrieLayout{trie.V0, trie.V1} {
+		t.Run(version.String(), func(t *testing.T) {
+			generator := newGenerator()
+			storage := newTestTrieDBStorageState(t, NewInMemoryDB(t))
+
+			expected := inmemory_trie.NewEmptyTrie()
+			expected.SetVersion(version)
+			root := trie.EmptyHash
+
+			// the keys are written to a single buffer reused across the operations,
+			// as the runtime host functions do with the memory of the instance
+			buffer := make([]byte, 0, 64)
+			var keys [][]byte
+			randomKey := func() []byte {
+				if len(keys) > 0 && generator.Intn(2) == 0 {
+					return append(buffer[:0], keys[generator.Intn(len(keys))]...)
+				}
+				// few distinct bytes so the keys share prefixes
+				key := buffer[:1+generator.Intn(40)]
+				for i := range key {
+					key[i] = byte(generator.Intn(3))
+				}
+				keys = append(keys, append([]byte(nil), key...))
+				return key
+			}
+
+			const blocks, operationsPerBlock = 10, 100
+			for block := 0; block < blocks; block++ {
+				ts, err := storage.TrieState(&root)
+				require.NoError(t, err)
+				ts.SetVersion(version)
+				tr := ts.Trie()
+
+				for i := 0; i < operationsPerBlock; i++ {
+					key := randomKey()
+					switch operation := generator.Intn(10); {
+					case operation < 6:
+						value := generateRandBytes(t, generator.Intn(64), generator)
+						require.NoError(t, expected.Put(key, value))
+						require.NoError(t, tr.Put(key, value))
+					case operation < 9:
+						require.NoError(t, expected.Delete(key))
+						require.NoError(t, tr.Delete(key))
+					default:
+						prefix := key[:1+generator.Intn(len(key))]
+						if prefix[len(prefix)-1]&0x0f == 0 {
+							// the in-memory trie trims a last zero nibble from the prefixes
+							// to clear, so it clears more keys than the trie database does
+							continue
+						}
+						require.NoError(t, expected.ClearPrefix(prefix))
+						require.NoError(t, tr.ClearPrefix(prefix))
+					}
+				}
+
+				root, err = tr.Hash()
+				require.NoError(t, err)
+				require.Equal(t, expected.MustHash(), root)
+				require.NoError(t, storage.StoreTrie(ts, nil))
+
+				loaded, err := storage.LoadFromDB(root)
+				require.NoError(t, err)
+				for _, key := range keys {
+					require.Equal(t, expected.Get(key), loaded.Get(key), "key 0x%x", key)
+				}
+				require.Equal(t, common.BytesToHash(root[:]), loaded.MustHash())
+			}
+		})
+	}
+}
+
+func Test_trieDBTrie_SharedNodeDeletedOnce(t *testing.T) {
+	db := NewInMemoryDB(t)
+	storage := newTestTrieDBStorageState(t, db)
+	ts, err := storage.TrieState(&trie.EmptyHash)
+	require.NoError(t, err)
+	tr := ts.Trie()
+
+	// the leaves of the keys are identical nodes stored once by hash
+	value := bytes.Repeat([]byte{1}, 40)
+	for _, key := range []string{"a1", "b1", "c1"} {
+		require.NoError(t, tr.Put([]byte(key), value))
+	}
+	_, err = tr.Hash()
+	require.NoError(t, err)
+
+	require.NoError(t, tr.Delete([]byte("a1")))
+	root, err := tr.Hash()
+	require.NoError(t, err)
+	require.NoError(t, storage.StoreTrie(ts, nil))
+
+	// read without the cache of the storage state
+	loaded := newTestTrieDBStorageState(t, db)
+	for _, key := range []string{"b1", "c1"} {
+		storedValue, err := loaded.GetStorage(&root, []byte(key))
+		require.NoError(t, err)
+		require.Equal(t, value, storedValue)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	lrucache "github.com/ChainSafe/gossamer/lib/utils/lru-cache"
+)
+
+const (
+	// blockDataCacheSize is the number of block data kept to serve the block requests
+	blockDataCacheSize = 512
+
+	// blockDataCacheTTL is the time a block data is served from the cache, so the
+	// data added to a block afterwards, such as its justification, is eventually served
+	blockDataCacheTTL = 30 * time.Second
+)
+
+type blockDataKey struct {
+	hash          common.Hash
+	requestedData byte
+}
+
+type cachedBlockData struct {
+	data      *types.BlockData
+	size      uint64
+	expiresAt time.Time
+}
+
+// blockDataCache is a short-lived cache of the block data served to the peers, with the
+// size of their encoding, since syncing peers usually request the same recent ranges.
+type blockDataCache struct {
+	mtx     sync.Mutex
+	ttl     time.Duration
+	entries *lrucache.LRUCache[blockDataKey, *cachedBlockData]
+}
+
+func newBlockDataCache(capacity uint, ttl time.Duration) *blockDataCache {
+	return &blockDataCache{
+		ttl:     ttl,
+		entries: lrucache.NewLRUCache[blockDataKey, *cachedBlockData](capacity),
+	}
+}
+
+// get returns the cached data of the block with the given hash and requested data, and the size of its
+// encoding. It returns false if the data is not cached or expired.
+func (c *blockDataCache) get(hash common.Hash, requestedData byte, now time.Time) (
+	data *types.BlockData, size uint64, ok bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	cached := c.entries.Get(blockDataKey{hash: hash, requestedData: requestedData})
+	if cached == nil || now.After(cached.expiresAt) {
+		return nil, 0, false
+	}
+	return cached.data, cached.size, true
+}
+
+func (c *blockDataCache) put(hash common.Hash, requestedData byte, data *types.BlockData, size uint64,
+	now time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.entries.Put(blockDataKey{hash: hash, requestedData: requestedData}, &cachedBlockData{
+		data:      data,
+		size:      size,
+		expiresAt: now.Add(c.ttl),
+	})
+}
@@ -45,6 +45,7 @@
 	// BabeVerifier deals with BABE block verification
 	BabeVerifier interface {
 		VerifyBlock(header *types.Header) error
+		VerifyBlockAhead(header, parentHeader, importedAncestor *types.Header) error
 	}
 
 	// FinalityGadget implements justification verification functionality
@@ -66,6 +67,7 @@ type blockImporter struct {
 	finalityGadget     FinalityGadget
 	blockImportHandler BlockImportHandler
 	telemetry          Telemetry
+	blockRules         *BlockRules
 }
 
 func newBlockImporter(cfg *FullSyncConfig) *blockImporter {
@@ -77,6 +79,7 @@ func newBlockImporter(cfg *FullSyncConfig) *blockImporter {
 		finalityGadget:     cfg.FinalityGadget,
 		blockImportHandler: cfg.BlockImportHandler,
 		telemetry:          cfg.Telemetry,
+		blockRules:         cfg.BlockRules,
 	}
 }
 
@@ -90,6 +93,11 @@ func (b *blockImporter) importBlock(bd *types.BlockData, origin BlockOrigin) (im
 		return false, nil
 	}
 
+	err = b.checkBlockRules(bd)
+	if err != nil {
+		return false, err
+	}
+
 	err = b.processBlockData(*bd, origin)
 	if err != nil {
 		logger.Errorf("processing block #%d (%s) failed: %s", bd.Header.Number, bd.Hash, err)
@@ -99,6 +107,15 @@ func (b *blockImporter) importBlock(bd *types.BlockData, origin BlockOrigin) (im
 	return true, nil
 }
 
+// checkBlockRules returns an error if the block is a bad block, descends from a bad block
+// or conflicts with a fork block.
+func (b *blockImporter) checkBlockRules(bd *types.BlockData) error {
+	if b.blockRules == nil {
+		return nil
+	}
+	return checkBlockRules(b.blockRules, b.blockState, bd)
+}
+
 // processBlockData processes the BlockData from a BlockResponse and
 // returns the index of the last BlockData it handled on success,
 // or the index of the block data that errored on failure.
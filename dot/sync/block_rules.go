@@ -0,0 +1,236 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/genesis"
+	lrucache "github.com/ChainSafe/gossamer/lib/utils/lru-cache"
+)
+
+// maxRejectedBlocks is the number of rejected blocks remembered to reject their descendants
+const maxRejectedBlocks = 4096
+
+// BlockRules holds the blocks which must not be imported: the bad blocks and their descendants,
+// and the blocks conflicting with the fork blocks, which pin the only block that can be imported
+// at their number. Bad blocks and fork blocks can be added while the node is running.
+type BlockRules struct {
+	mtx        sync.Mutex
+	badBlocks  map[common.Hash]struct{}
+	forkBlocks map[uint]common.Hash
+
+	// rejected holds the blocks descending from a bad block or conflicting with a
+	// fork block, so their descendants are rejected even if the bad block is not known
+	rejected *lrucache.LRUCache[common.Hash, bool]
+
+	// verified holds the number of the unfinalised blocks whose known ancestry down to the
+	// highest finalised block passes the rules, so it is not walked again. It is cleared when
+	// a rule is added and pruned when blocks are finalised.
+	verified map[common.Hash]uint
+	// prunedAt is the number of the highest finalised block when verified was last pruned
+	prunedAt uint
+	// generation is increased when a rule is added, to detect rules added while the
+	// ancestors of a block are read from the block state
+	generation uint64
+}
+
+// NewBlockRules creates the rules rejecting the given bad blocks and the blocks conflicting
+// with the given fork blocks.
+func NewBlockRules(badBlocks []common.Hash, forkBlocks []genesis.ForkBlock) *BlockRules {
+	rules := &BlockRules{
+		badBlocks:  make(map[common.Hash]struct{}, len(badBlocks)),
+		forkBlocks: make(map[uint]common.Hash, len(forkBlocks)),
+		rejected:   lrucache.NewLRUCache[common.Hash, bool](maxRejectedBlocks),
+		verified:   make(map[common.Hash]uint),
+	}
+
+	for _, hash := range badBlocks {
+		rules.badBlocks[hash] = struct{}{}
+	}
+	for _, fork := range forkBlocks {
+		rules.forkBlocks[fork.Number] = fork.Hash
+	}
+	return rules
+}
+
+// AddBadBlock rejects the block with the given hash and its descendants.
+func (r *BlockRules) AddBadBlock(hash common.Hash) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.badBlocks[hash] = struct{}{}
+	r.rulesChanged()
+}
+
+// AddForkBlock pins the block with the given hash at the given number, rejecting its siblings.
+func (r *BlockRules) AddForkBlock(number uint, hash common.Hash) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.forkBlocks[number] = hash
+	r.rulesChanged()
+}
+
+// rulesChanged forgets the verified blocks after a rule was added. The caller must hold the lock.
+func (r *BlockRules) rulesChanged() {
+	clear(r.verified)
+	r.generation++
+}
+
+// isBad returns true if the block with the given hash is a bad block,
+// or was rejected because it descends from a bad block or conflicts with a fork block.
+func (r *BlockRules) isBad(hash common.Hash) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	_, bad := r.badBlocks[hash]
+	return bad || r.rejected.Get(hash)
+}
+
+// check returns an error if the block is a bad block, if it descends from a bad block or from a
+// rejected block, or if it conflicts with a fork block. The ancestors known by the block state are
+// walked down to the highest finalised block or to a verified block, so the blocks imported before
+// a rule was added are taken into account. The block state is read without holding the lock. The
+// block is remembered as rejected unless it is a bad block itself, so the blocks not imported yet
+// are expected to be checked in ascending order.
+func (r *BlockRules) check(blockState BlockState, header *types.Header) error {
+	hash := header.Hash()
+	for {
+		r.mtx.Lock()
+		generation := r.generation
+		err := r.checkParent(hash, header)
+		noRules := len(r.badBlocks) == 0 && len(r.forkBlocks) == 0
+		r.mtx.Unlock()
+		if err != nil || noRules {
+			return err
+		}
+
+		ancestors, complete := r.unverifiedAncestors(blockState, header.ParentHash)
+
+		r.mtx.Lock()
+		if r.generation != generation {
+			// a rule was added while reading the ancestors, they are checked again
+			r.mtx.Unlock()
+			continue
+		}
+		err = r.checkAncestors(hash, header, ancestors, complete)
+		r.mtx.Unlock()
+		return err
+	}
+}
+
+// checkParent checks the rules against the block and its parent. The caller must hold the lock.
+func (r *BlockRules) checkParent(hash common.Hash, header *types.Header) error {
+	if _, bad := r.badBlocks[hash]; bad {
+		return fmt.Errorf("%w: #%d (%s)", errBadBlockReceived, header.Number, hash)
+	}
+
+	if _, bad := r.badBlocks[header.ParentHash]; bad || r.rejected.Get(header.ParentHash) {
+		r.rejected.Put(hash, true)
+		return fmt.Errorf("%w: #%d (%s) has rejected parent %s",
+			errBadBlockDescendant, header.Number, hash, header.ParentHash)
+	}
+
+	pinned, ok := r.forkBlocks[header.Number]
+	if ok && pinned != hash {
+		r.rejected.Put(hash, true)
+		return fmt.Errorf("%w: #%d (%s) instead of %s", errForkBlockMismatch, header.Number, hash, pinned)
+	}
+
+	return nil
+}
+
+// checkAncestors checks the rules against the unverified ancestors of the block, from the
+// parent down. If they pass and the ancestry is complete, the block and its ancestors are
+// remembered as verified. The caller must hold the lock.
+func (r *BlockRules) checkAncestors(hash common.Hash, header *types.Header,
+	ancestors []*types.Header, complete bool) error {
+	for _, ancestor := range ancestors {
+		ancestorHash := ancestor.Hash()
+		_, bad := r.badBlocks[ancestorHash]
+		pinned, ok := r.forkBlocks[ancestor.Number]
+		if bad || r.rejected.Get(ancestorHash) || (ok && pinned != ancestorHash) {
+			r.rejected.Put(ancestorHash, true)
+			r.rejected.Put(hash, true)
+			return fmt.Errorf("%w: #%d (%s) has rejected ancestor #%d (%s)",
+				errBadBlockDescendant, header.Number, hash, ancestor.Number, ancestorHash)
+		}
+	}
+
+	if complete {
+		for _, ancestor := range ancestors {
+			r.verified[ancestor.Hash()] = ancestor.Number
+		}
+		r.verified[hash] = header.Number
+	}
+	return nil
+}
+
+// unverifiedAncestors returns the ancestors known by the block state of the block with the given
+// parent, from the parent down to the highest finalised block or to a verified block, excluded.
+// It returns false if the ancestry could not be read down to one of them.
+func (r *BlockRules) unverifiedAncestors(blockState BlockState, parentHash common.Hash) (
+	ancestors []*types.Header, complete bool) {
+	finalised, err := blockState.GetHighestFinalisedHeader()
+	if err != nil {
+		logger.Warnf("cannot check the ancestors of block %s: getting highest finalised header: %s",
+			parentHash, err)
+		return nil, false
+	}
+	r.pruneVerified(finalised.Number)
+
+	for hash := parentHash; ; {
+		if r.isVerified(hash) {
+			return ancestors, true
+		}
+
+		ancestor, err := blockState.GetHeader(hash)
+		if err != nil {
+			if !errors.Is(err, database.ErrNotFound) {
+				logger.Warnf("cannot check the ancestors of block %s: getting header %s: %s",
+					parentHash, hash, err)
+			}
+			return ancestors, false
+		}
+
+		if ancestor.Number <= finalised.Number {
+			return ancestors, true
+		}
+
+		ancestors = append(ancestors, ancestor)
+		hash = ancestor.ParentHash
+	}
+}
+
+// isVerified returns true if the ancestry of the block with the given hash passes the rules
+func (r *BlockRules) isVerified(hash common.Hash) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	_, ok := r.verified[hash]
+	return ok
+}
+
+// pruneVerified forgets the verified blocks which are not above the highest finalised block
+func (r *BlockRules) pruneVerified(finalised uint) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if finalised <= r.prunedAt {
+		return
+	}
+
+	for hash, number := range r.verified {
+		if number <= finalised {
+			delete(r.verified, hash)
+		}
+	}
+	r.prunedAt = finalised
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/genesis"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBlockRules_check(t *testing.T) {
+	newHeader := func(parent common.Hash, number uint) *types.Header {
+		return types.NewHeader(parent, common.Hash{}, common.Hash{}, number, types.NewDigest())
+	}
+
+	genesisHeader := newHeader(common.Hash{}, 0)
+	block1 := newHeader(genesisHeader.Hash(), 1)
+	badBlock := newHeader(block1.Hash(), 2)
+	descendant := newHeader(badBlock.Hash(), 3)
+	grandChild := newHeader(descendant.Hash(), 4)
+
+	// newBlockState returns a block state knowing the given headers, finalised at the given header
+	newBlockState := func(t *testing.T, finalised *types.Header, headers ...*types.Header) BlockState {
+		known := make(map[common.Hash]*types.Header, len(headers))
+		for _, header := range headers {
+			known[header.Hash()] = header
+		}
+
+		ctrl := gomock.NewController(t)
+		blockState := NewMockBlockState(ctrl)
+		blockState.EXPECT().GetHighestFinalisedHeader().Return(finalised, nil).AnyTimes()
+		blockState.EXPECT().GetHeader(gomock.Any()).DoAndReturn(func(hash common.Hash) (*types.Header, error) {
+			header, ok := known[hash]
+			if !ok {
+				return nil, database.ErrNotFound
+			}
+			return header, nil
+		}).AnyTimes()
+		return blockState
+	}
+
+	t.Run("bad_block_and_descendants", func(t *testing.T) {
+		rules := NewBlockRules([]common.Hash{badBlock.Hash()}, nil)
+		blockState := newBlockState(t, genesisHeader, genesisHeader)
+
+		require.NoError(t, rules.check(blockState, block1))
+		require.ErrorIs(t, rules.check(blockState, badBlock), errBadBlockReceived)
+		require.ErrorIs(t, rules.check(blockState, descendant), errBadBlockDescendant)
+		require.ErrorIs(t, rules.check(blockState, grandChild), errBadBlockDescendant)
+
+		require.True(t, rules.isBad(badBlock.Hash()))
+		require.True(t, rules.isBad(grandChild.Hash()))
+		require.False(t, rules.isBad(block1.Hash()))
+	})
+
+	t.Run("fork_block_mismatch", func(t *testing.T) {
+		sibling := newHeader(common.Hash{9}, 2)
+		rules := NewBlockRules(nil, []genesis.ForkBlock{{Number: 2, Hash: badBlock.Hash()}})
+		blockState := newBlockState(t, genesisHeader, genesisHeader)
+
+		require.NoError(t, rules.check(blockState, badBlock))
+		require.ErrorIs(t, rules.check(blockState, sibling), errForkBlockMismatch)
+		require.ErrorIs(t, rules.check(blockState, newHeader(sibling.Hash(), 3)), errBadBlockDescendant)
+	})
+
+	t.Run("rules_added_at_runtime", func(t *testing.T) {
+		rules := NewBlockRules(nil, nil)
+		blockState := newBlockState(t, genesisHeader, genesisHeader)
+		require.NoError(t, rules.check(blockState, badBlock))
+
+		rules.AddBadBlock(badBlock.Hash())
+		require.ErrorIs(t, rules.check(blockState, descendant), errBadBlockDescendant)
+
+		rules.AddForkBlock(1, common.Hash{1})
+		require.ErrorIs(t, rules.check(blockState, block1), errForkBlockMismatch)
+	})
+
+	t.Run("imported_ancestors", func(t *testing.T) {
+		// the blocks were imported before the rules were added
+		blockState := newBlockState(t, genesisHeader, genesisHeader, block1, badBlock, descendant)
+
+		rules := NewBlockRules(nil, nil)
+		rules.AddBadBlock(badBlock.Hash())
+		require.ErrorIs(t, rules.check(blockState, grandChild), errBadBlockDescendant)
+		require.True(t, rules.isBad(grandChild.Hash()))
+
+		rules = NewBlockRules(nil, nil)
+		rules.AddForkBlock(1, common.Hash{1})
+		require.ErrorIs(t, rules.check(blockState, grandChild), errBadBlockDescendant)
+
+		// the finalised blocks are not checked again
+		blockState = newBlockState(t, badBlock, genesisHeader, block1, badBlock, descendant)
+		rules = NewBlockRules(nil, nil)
+		rules.AddBadBlock(badBlock.Hash())
+		require.NoError(t, rules.check(blockState, grandChild))
+	})
+
+	t.Run("verified_ancestors_not_walked_again", func(t *testing.T) {
+		rules := NewBlockRules([]common.Hash{{1}}, nil)
+
+		ctrl := gomock.NewController(t)
+		blockState := NewMockBlockState(ctrl)
+		blockState.EXPECT().GetHighestFinalisedHeader().Return(genesisHeader, nil).AnyTimes()
+		// the ancestry of block #2 is read once, down to the finalised genesis block
+		blockState.EXPECT().GetHeader(block1.Hash()).Return(block1, nil)
+		blockState.EXPECT().GetHeader(genesisHeader.Hash()).Return(genesisHeader, nil)
+
+		require.NoError(t, rules.check(blockState, badBlock))
+		require.NoError(t, rules.check(blockState, descendant))
+		require.NoError(t, rules.check(blockState, grandChild))
+
+		// adding a rule forgets the verified blocks
+		rules.AddBadBlock(block1.Hash())
+		blockState.EXPECT().GetHeader(descendant.Hash()).Return(descendant, nil)
+		blockState.EXPECT().GetHeader(badBlock.Hash()).Return(badBlock, nil)
+		blockState.EXPECT().GetHeader(block1.Hash()).Return(block1, nil)
+		blockState.EXPECT().GetHeader(genesisHeader.Hash()).Return(genesisHeader, nil)
+		require.ErrorIs(t, rules.check(blockState, grandChild), errBadBlockDescendant)
+	})
+}
@@ -38,3 +38,15 @@ func WithMinPeers(min int) ServiceConfig {
 		svc.minPeers = min
 	}
 }
+
+func WithBlockRules(blockRules *BlockRules) ServiceConfig {
+	return func(svc *SyncService) {
+		svc.blockRules = blockRules
+	}
+}
+
+func WithStallTimeout(stallTimeout time.Duration) ServiceConfig {
+	return func(svc *SyncService) {
+		svc.progress = newSyncProgress(stallTimeout)
+	}
+}
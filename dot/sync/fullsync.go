@@ -28,6 +28,9 @@
 	errNilHeaderInResponse = errors.New("expected header, received none")
 	errNilBodyInResponse   = errors.New("expected body, received none")
 	errBadBlockReceived    = errors.New("bad block received")
+	errBadBlockDescendant  = errors.New("block descends from a bad block")
+	errForkBlockMismatch   = errors.New("block conflicts with fork block")
+	errBadExtrinsicsRoot   = errors.New("extrinsics root does not match block body")
 )
 
 // Config is the configuration for the sync Service.
@@ -36,16 +39,18 @@ type FullSyncConfig struct {
 	TransactionState   TransactionState
 	BabeVerifier       BabeVerifier
 	FinalityGadget     FinalityGadget
+	GrandpaState       GrandpaState
 	BlockImportHandler BlockImportHandler
 	Telemetry          Telemetry
 	BlockState         BlockState
-	BadBlocks          []string
+	BlockRules         *BlockRules
 	NumOfTasks         int
 	RequestMaker       network.RequestMaker
 }
 
 type importer interface {
 	importBlock(*types.BlockData, BlockOrigin) (imported bool, err error)
+	importBlocks([]*types.BlockData) (imported int, err error)
 }
 
 // FullSyncStrategy protocol is the "default" protocol.
@@ -55,13 +60,16 @@ type FullSyncStrategy struct {
 	requestQueue  *requestsQueue[*messages.BlockRequestMessage]
 	unreadyBlocks *unreadyBlocks
 	peers         *peerViewSet
-	badBlocks     []string
+	blockRules    *BlockRules
 	reqMaker      network.RequestMaker
 	blockState    BlockState
 	numOfTasks    int
 	startedAt     time.Time
 	syncedBlocks  int
 	blockImporter importer
+
+	// justifications is nil if the missing justifications are not requested
+	justifications *justificationRequests
 }
 
 func NewFullSyncStrategy(cfg *FullSyncConfig) *FullSyncStrategy {
@@ -69,8 +77,17 @@ func NewFullSyncStrategy(cfg *FullSyncConfig) *FullSyncStrategy {
 		cfg.NumOfTasks = defaultNumOfTasks
 	}
 
+	if cfg.BlockRules == nil {
+		cfg.BlockRules = NewBlockRules(nil, nil)
+	}
+
+	var justifications *justificationRequests
+	if cfg.GrandpaState != nil {
+		justifications = newJustificationRequests(cfg.BlockState, cfg.GrandpaState, cfg.FinalityGadget)
+	}
+
 	return &FullSyncStrategy{
-		badBlocks:     cfg.BadBlocks,
+		blockRules:    cfg.BlockRules,
 		reqMaker:      cfg.RequestMaker,
 		blockState:    cfg.BlockState,
 		numOfTasks:    cfg.NumOfTasks,
@@ -83,6 +100,7 @@ func NewFullSyncStrategy(cfg *FullSyncConfig) *FullSyncStrategy {
 			view:   make(map[peer.ID]peerView),
 			target: 0,
 		},
+		justifications: justifications,
 	}
 }
 
@@ -101,6 +119,14 @@ func (f *FullSyncStrategy) NextActions() ([]*SyncTask, error) {
 		reqsFromQueue = append(reqsFromQueue, msg)
 	}
 
+	if f.justifications != nil {
+		err := f.justifications.refresh()
+		if err != nil {
+			logger.Warnf("refreshing missing justifications: %s", err)
+		}
+		reqsFromQueue = append(reqsFromQueue, f.justifications.requests(time.Now())...)
+	}
+
 	currentTarget := f.peers.getTarget()
 	bestBlockHeader, err := f.blockState.BestBlockHeader()
 	if err != nil {
@@ -148,7 +174,16 @@ func (f *FullSyncStrategy) createTasks(requests []*messages.BlockRequestMessage)
 // peers to block/ban, or an error. FullSyncStrategy is intended to run as long as the node lives.
 func (f *FullSyncStrategy) Process(results []*SyncTaskResult) (
 	isFinished bool, reputations []Change, bans []peer.ID, err error) {
-	repChanges, peersToIgnore, validResp := validateResults(results, f.badBlocks)
+	var justificationRepChanges []Change
+	if f.justifications != nil {
+		results, justificationRepChanges, err = f.justifications.handleResults(results)
+		if err != nil {
+			return false, nil, nil, fmt.Errorf("while handling justifications: %w", err)
+		}
+	}
+
+	repChanges, peersToIgnore, validResp := validateResults(results, f.blockRules, f.blockState)
+	repChanges = append(repChanges, justificationRepChanges...)
 	logger.Debugf("evaluating %d task results, %d valid responses", len(results), len(validResp))
 
 	var highestFinalized *types.Header
@@ -205,15 +240,10 @@ func (f *FullSyncStrategy) Process(results []*SyncTaskResult) (
 
 	// this loop goal is to import ready blocks as well as update the highestFinalized header
 	for len(nextBlocksToImport) > 0 || len(disjointFragments) > 0 {
-		for _, blockToImport := range nextBlocksToImport {
-			imported, err := f.blockImporter.importBlock(blockToImport, networkInitialSync)
-			if err != nil {
-				return false, nil, nil, fmt.Errorf("while handling ready block: %w", err)
-			}
-
-			if imported {
-				f.syncedBlocks += 1
-			}
+		imported, err := f.blockImporter.importBlocks(nextBlocksToImport)
+		f.syncedBlocks += imported
+		if err != nil {
+			return false, nil, nil, fmt.Errorf("while handling ready blocks: %w", err)
 		}
 
 		nextBlocksToImport = make([]*types.BlockData, 0)
@@ -277,6 +307,24 @@ func (f *FullSyncStrategy) ShowMetrics() {
 		totalSyncAndImportSeconds, bps, f.peers.getTarget())
 }
 
+// SyncTarget returns the highest block number announced by the peers
+func (f *FullSyncStrategy) SyncTarget() uint {
+	return uint(f.peers.getTarget())
+}
+
+// OnStall drops the queued requests, the missing blocks being requested
+// again from the best block with the next actions.
+func (f *FullSyncStrategy) OnStall() {
+	dropped := 0
+	for {
+		if _, ok := f.requestQueue.PopFront(); !ok {
+			break
+		}
+		dropped++
+	}
+	logger.Debugf("dropped %d queued requests", dropped)
+}
+
 func (f *FullSyncStrategy) OnBlockAnnounceHandshake(from peer.ID, msg *network.BlockAnnounceHandshake) error {
 	f.peers.update(from, msg.BestBlockHash, msg.BestBlockNumber)
 	return nil
@@ -298,9 +346,8 @@ func (f *FullSyncStrategy) OnBlockAnnounce(from peer.ID, msg *network.BlockAnnou
 		msg.BestBlock,
 	)
 
-	if slices.Contains(f.badBlocks, blockAnnounceHeaderHash.String()) {
-		logger.Infof("bad block received from %s: #%d (%s) is a bad block",
-			from, blockAnnounceHeader.Number, blockAnnounceHeaderHash)
+	if err := f.blockRules.check(f.blockState, blockAnnounceHeader); err != nil {
+		logger.Infof("bad block received from %s: %s", from, err)
 
 		return &Change{
 			who: from,
@@ -308,7 +355,7 @@ func (f *FullSyncStrategy) OnBlockAnnounce(from peer.ID, msg *network.BlockAnnou
 				Value:  peerset.BadBlockAnnouncementValue,
 				Reason: peerset.BadBlockAnnouncementReason,
 			},
-		}, errBadBlockReceived
+		}, err
 	}
 
 	if msg.BestBlock {
@@ -395,8 +442,8 @@ type RequestResponseData struct {
 	responseData []*types.BlockData
 }
 
-func validateResults(results []*SyncTaskResult, badBlocks []string) (repChanges []Change,
-	peersToBlock []peer.ID, validRes []RequestResponseData) {
+func validateResults(results []*SyncTaskResult, blockRules *BlockRules, blockState BlockState) (
+	repChanges []Change, peersToBlock []peer.ID, validRes []RequestResponseData) {
 
 	repChanges = make([]Change, 0)
 	peersToBlock = make([]peer.ID, 0)
@@ -450,9 +497,9 @@ func validateResults(results []*SyncTaskResult, badBlocks []string) (repChanges
 		}
 
 		for _, block := range response.BlockData {
-			if slices.Contains(badBlocks, block.Hash.String()) {
-				logger.Warnf("%s sent a known bad block: #%d (%s)",
-					result.who, block.Number(), block.Hash.String())
+			err := checkBlockRules(blockRules, blockState, block)
+			if err != nil {
+				logger.Warnf("%s sent a bad block: %s", result.who, err)
 
 				peersToBlock = append(peersToBlock, result.who)
 				repChanges = append(repChanges, Change{
@@ -580,3 +627,16 @@ func isResponseAChain(responseBlockData []*types.BlockData) bool {
 
 	return true
 }
+
+// checkBlockRules checks the block against the block rules, the block being only
+// checked by hash if the header was not requested.
+func checkBlockRules(blockRules *BlockRules, blockState BlockState, block *types.BlockData) error {
+	if block.Header != nil {
+		return blockRules.check(blockState, block.Header)
+	}
+
+	if blockRules.isBad(block.Hash) {
+		return fmt.Errorf("%w: %s", errBadBlockReceived, block.Hash)
+	}
+	return nil
+}
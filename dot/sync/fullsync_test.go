@@ -234,11 +234,15 @@ func TestFullSyncProcess(t *testing.T) {
 			Return(false, nil).
 			Times(2)
 
+		importedBlocks := 0
 		mockImporter := NewMockimporter(ctrl)
 		mockImporter.EXPECT().
-			importBlock(gomock.AssignableToTypeOf(&types.BlockData{}), networkInitialSync).
-			Return(true, nil).
-			Times(10 + 128 + 128)
+			importBlocks(gomock.AssignableToTypeOf([]*types.BlockData{})).
+			DoAndReturn(func(blocks []*types.BlockData) (int, error) {
+				importedBlocks += len(blocks)
+				return len(blocks), nil
+			}).
+			AnyTimes()
 
 		cfg := &FullSyncConfig{
 			BlockState: mockBlockState,
@@ -289,6 +293,7 @@ func TestFullSyncProcess(t *testing.T) {
 		require.Equal(t, fs.requestQueue.Len(), 0)
 		require.Len(t, fs.unreadyBlocks.incompleteBlocks, 0)
 		require.Len(t, fs.unreadyBlocks.disjointFragments, 0)
+		require.Equal(t, 10+128+128, importedBlocks)
 	})
 }
 
@@ -484,4 +489,52 @@ func TestFullSyncBlockAnnounce(t *testing.T) {
 		})
 	})
 
+	t.Run("announce_descendant_of_bad_block", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().IsPaused().Return(false)
+
+		badBlockHash := common.BytesToHash([]byte{0, 1, 2})
+		fsCfg := &FullSyncConfig{
+			BlockState: mockBlockState,
+			BlockRules: NewBlockRules([]common.Hash{badBlockHash}, nil),
+		}
+
+		fs := NewFullSyncStrategy(fsCfg)
+
+		announce := &network.BlockAnnounceMessage{
+			ParentHash: badBlockHash,
+			Number:     17,
+			Digest:     types.NewDigest(),
+			BestBlock:  true,
+		}
+
+		peerID := peer.ID("fst-peer")
+		rep, err := fs.OnBlockAnnounce(peerID, announce)
+		require.ErrorIs(t, err, errBadBlockDescendant)
+
+		expectedReputation := &Change{
+			who: peerID,
+			rep: peerset.ReputationChange{
+				Value:  peerset.BadBlockAnnouncementValue,
+				Reason: peerset.BadBlockAnnouncementReason,
+			},
+		}
+		require.Equal(t, expectedReputation, rep)
+		require.Zero(t, fs.requestQueue.Len())
+	})
+}
+
+func TestFullSyncOnStall(t *testing.T) {
+	fs := NewFullSyncStrategy(&FullSyncConfig{})
+	fs.peers.update(peer.ID("alice"), common.Hash{1}, 100)
+
+	request := messages.NewBlockRequest(*messages.NewFromBlock(uint(1)),
+		messages.MaxBlocksInResponse, messages.BootstrapRequestData, messages.Ascending)
+	fs.requestQueue.PushBack(request)
+	fs.requestQueue.PushBack(request)
+
+	fs.OnStall()
+	require.Zero(t, fs.requestQueue.Len())
+	require.Equal(t, uint(100), fs.SyncTarget())
 }
@@ -0,0 +1,292 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+)
+
+// maxBlocksVerifiedAhead is the maximum number of blocks verified ahead of the
+// block being executed, it bounds the memory held by the import pipeline.
+const maxBlocksVerifiedAhead = 256
+
+// pipelinedBlock is a block going through the import pipeline.
+type pipelinedBlock struct {
+	data *types.BlockData
+
+	// parent and ancestor are the header of the parent and the header of an imported
+	// ancestor, used to verify the header of the block before its parent is imported.
+	parent   *types.Header
+	ancestor *types.Header
+
+	// verified is closed once the block was verified, err being the verification error
+	verified chan struct{}
+	err      error
+	// executed is closed once the block was executed or skipped
+	executed chan struct{}
+}
+
+// ImportBlocks imports the chain of blocks the way blocks are imported during the initial sync,
+// it is used to import blocks that were not received from the network. The headers and bodies of
+// the blocks are verified ahead of their execution, unless skipVerify is set. It returns the number
+// of blocks imported, the blocks already imported being skipped.
+func ImportBlocks(cfg *FullSyncConfig, blocks []*types.BlockData, skipVerify bool) (imported int, err error) {
+	importer := newBlockImporter(cfg)
+	if !skipVerify {
+		return importer.importBlocks(blocks)
+	}
+
+	for _, bd := range blocks {
+		ok, err := importer.importBlock(bd, networkInitialSync)
+		if err != nil {
+			return imported, err
+		}
+		if ok {
+			imported++
+		}
+	}
+	return imported, nil
+}
+
+// importBlocks imports the chain of blocks during the initial sync. The bodies and the headers
+// of the blocks are verified by a pool of workers ahead of the execution, while the blocks are
+// executed one after the other. It returns the number of blocks imported.
+func (b *blockImporter) importBlocks(blocks []*types.BlockData) (imported int, err error) {
+	if len(blocks) == 0 {
+		return 0, nil
+	}
+
+	pipeline := make([]*pipelinedBlock, len(blocks))
+	for i, bd := range blocks {
+		pipeline[i] = &pipelinedBlock{
+			data:     bd,
+			verified: make(chan struct{}),
+			executed: make(chan struct{}),
+		}
+	}
+
+	quit := make(chan struct{})
+	// window holds a token for each block dispatched and not executed yet,
+	// so the verification stops when it gets too far ahead of the execution
+	window := make(chan struct{}, maxBlocksVerifiedAhead)
+	jobs := make(chan *pipelinedBlock)
+
+	var wg sync.WaitGroup
+	workers := min(runtime.NumCPU(), len(blocks))
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pb := range jobs {
+				pb.err = b.verifyAhead(pb)
+				close(pb.verified)
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		b.dispatchVerifications(pipeline, window, jobs, quit)
+	}()
+
+	defer func() {
+		close(quit)
+		wg.Wait()
+	}()
+
+	for _, pb := range pipeline {
+		<-pb.verified
+		ok, err := b.importVerifiedBlock(pb)
+		close(pb.executed)
+		<-window
+
+		if err != nil {
+			return imported, err
+		}
+		if ok {
+			imported++
+		}
+	}
+
+	return imported, nil
+}
+
+// dispatchVerifications sends the blocks to the verification workers in order. The header of a
+// block is verified using the header of its parent when the parent is part of the pipeline, and
+// the epoch data of the last imported ancestor. The blocks following a block that may change the
+// epoch data, or whose parent is not the previous block, wait for the previous blocks to be
+// imported before being verified.
+func (b *blockImporter) dispatchVerifications(pipeline []*pipelinedBlock, window chan<- struct{},
+	jobs chan<- *pipelinedBlock, quit <-chan struct{}) {
+	var parent, ancestor *types.Header
+	for i, pb := range pipeline {
+		select {
+		case window <- struct{}{}:
+		case <-quit:
+			return
+		}
+
+		header := pb.data.Header
+		if header == nil || pb.data.Body == nil {
+			// nothing to verify ahead, the block is handled on execution
+			parent = nil
+			close(pb.verified)
+			continue
+		}
+
+		if parent == nil || parent.Hash() != header.ParentHash {
+			if i > 0 {
+				select {
+				case <-pipeline[i-1].executed:
+				case <-quit:
+					return
+				}
+			}
+
+			var err error
+			ancestor, err = b.blockState.GetHeader(header.ParentHash)
+			if err != nil {
+				pb.err = fmt.Errorf("%w: %s", errFailedToGetParent, err)
+				parent = nil
+				close(pb.verified)
+				continue
+			}
+			parent = ancestor
+		}
+
+		pb.parent = parent
+		pb.ancestor = ancestor
+
+		// copy the header before it is verified, the verification temporarily removes its seal
+		next, err := header.DeepCopy()
+		if err != nil {
+			pb.err = fmt.Errorf("copying header: %w", err)
+			parent = nil
+			close(pb.verified)
+			continue
+		}
+		parent = next
+		if changesEpochData(header) {
+			parent = nil
+		}
+
+		select {
+		case jobs <- pb:
+		case <-quit:
+			return
+		}
+	}
+}
+
+// verifyAhead verifies the body and the header of the block, before its parent is imported.
+func (b *blockImporter) verifyAhead(pb *pipelinedBlock) error {
+	err := verifyExtrinsicsRoot(pb.data.Header, *pb.data.Body)
+	if err != nil {
+		return err
+	}
+
+	err = b.babeVerifier.VerifyBlockAhead(pb.data.Header, pb.parent, pb.ancestor)
+	if err != nil {
+		return fmt.Errorf("babe verifying block ahead: %w", err)
+	}
+
+	return nil
+}
+
+// importVerifiedBlock imports the block once it went through the verification stage.
+func (b *blockImporter) importVerifiedBlock(pb *pipelinedBlock) (imported bool, err error) {
+	bd := pb.data
+	blockAlreadyExists, err := b.blockState.HasHeader(bd.Hash)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return false, err
+	}
+
+	if blockAlreadyExists {
+		return false, nil
+	}
+
+	err = b.checkBlockRules(bd)
+	if err != nil {
+		return false, err
+	}
+
+	if pb.err != nil {
+		if errors.Is(pb.err, errBadExtrinsicsRoot) {
+			return false, fmt.Errorf("block #%d (%s): %w", bd.Header.Number, bd.Hash, pb.err)
+		}
+
+		// the state needed to verify the header ahead might not have been available,
+		// so it is verified again now that the parent is imported.
+		logger.Debugf("verifying block #%d (%s) again after: %s", bd.Header.Number, bd.Hash, pb.err)
+		err = b.babeVerifier.VerifyBlock(bd.Header)
+		if err != nil {
+			return false, fmt.Errorf("babe verifying block #%d (%s): %w", bd.Header.Number, bd.Hash, err)
+		}
+	}
+
+	// the block was verified, it does not need to be verified again when processed
+	err = b.processBlockData(*bd, networkInitialSync)
+	if err != nil {
+		logger.Errorf("processing block #%d (%s) failed: %s", bd.Header.Number, bd.Hash, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// verifyExtrinsicsRoot checks the extrinsics root of the header against the body. The root is
+// built using the state version of the runtime, which is only known once the parent is imported,
+// so the body is valid if it matches the root built using either of the trie versions.
+func verifyExtrinsicsRoot(header *types.Header, body types.Body) error {
+	values, err := body.AsEncodedExtrinsics()
+	if err != nil {
+		return fmt.Errorf("encoding extrinsics: %w", err)
+	}
+
+	encodedValues := types.ExtrinsicsArrayToBytesArray(values)
+	for _, version := range []trie.TrieLayout{trie.V0, trie.V1} {
+		root, err := version.OrderedRoot(encodedValues)
+		if err != nil {
+			return fmt.Errorf("computing extrinsics root: %w", err)
+		}
+
+		if root == header.ExtrinsicsRoot {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: header extrinsics root %s", errBadExtrinsicsRoot, header.ExtrinsicsRoot)
+}
+
+// changesEpochData returns true if importing the block may change the data used to verify its
+// descendants: the first block of the chain, from which the epochs are computed, and the blocks
+// with a BABE consensus digest, announcing the next epoch data or config data, or disabling an authority.
+func changesEpochData(header *types.Header) bool {
+	if header.Number == 1 {
+		return true
+	}
+
+	for _, item := range header.Digest {
+		value, err := item.Value()
+		if err != nil {
+			continue
+		}
+
+		digest, ok := value.(types.ConsensusDigest)
+		if ok && digest.ConsensusEngineID == types.BabeEngineID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,255 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/network/messages"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/common"
+	mocksruntime "github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/ChainSafe/gossamer/pkg/trie/inmemory"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gopkg.in/yaml.v3"
+)
+
+// newTestChain returns a chain of blocks with empty bodies on top of the parent,
+// the blocks at the given numbers carry a BABE consensus digest.
+func newTestChain(t *testing.T, parent *types.Header, length uint, babeDigestAt ...uint) []*types.BlockData {
+	t.Helper()
+
+	emptyBodyRoot, err := trie.V0.OrderedRoot(nil)
+	require.NoError(t, err)
+
+	blocks := make([]*types.BlockData, 0, length)
+	parentHash := parent.Hash()
+	for number := parent.Number + 1; number <= parent.Number+length; number++ {
+		digest := types.NewDigest()
+		prd, err := types.NewBabeSecondaryPlainPreDigest(0, uint64(number)).ToPreRuntimeDigest()
+		require.NoError(t, err)
+		require.NoError(t, digest.Add(*prd))
+
+		for _, at := range babeDigestAt {
+			if at == number {
+				require.NoError(t, digest.Add(types.ConsensusDigest{
+					ConsensusEngineID: types.BabeEngineID,
+					Data:              []byte{1},
+				}))
+			}
+		}
+
+		header := types.NewHeader(parentHash, trie.EmptyHash, emptyBodyRoot, number, digest)
+		blocks = append(blocks, &types.BlockData{
+			Hash:   header.Hash(),
+			Header: header,
+			Body:   types.NewBody([]types.Extrinsic{}),
+		})
+		parentHash = header.Hash()
+	}
+	return blocks
+}
+
+type verifiedAhead struct {
+	parent   uint
+	ancestor uint
+}
+
+// newTestPipelineImporter returns a block importer executing the blocks against mocks,
+// the blocks are stored in the returned map once imported.
+func newTestPipelineImporter(t *testing.T, best *types.Header) (
+	*blockImporter, *MockBabeVerifier, map[common.Hash]*types.Header, *sync.Mutex) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+
+	var mtx sync.Mutex
+	imported := map[common.Hash]*types.Header{best.Hash(): best}
+
+	blockState := NewMockBlockState(ctrl)
+	blockState.EXPECT().HasHeader(gomock.Any()).DoAndReturn(func(hash common.Hash) (bool, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		_, ok := imported[hash]
+		return ok, nil
+	}).AnyTimes()
+	blockState.EXPECT().GetHeader(gomock.Any()).DoAndReturn(func(hash common.Hash) (*types.Header, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		header, ok := imported[hash]
+		if !ok {
+			return nil, database.ErrNotFound
+		}
+		return header.DeepCopy()
+	}).AnyTimes()
+
+	blockState.EXPECT().CompareAndSetBlockData(gomock.Any()).AnyTimes()
+
+	runtime := mocksruntime.NewMockInstance(ctrl)
+	runtime.EXPECT().SetContextStorage(gomock.Any()).AnyTimes()
+	runtime.EXPECT().ExecuteBlock(gomock.Any()).AnyTimes()
+	blockState.EXPECT().GetRuntime(gomock.Any()).Return(runtime, nil).AnyTimes()
+
+	storageState := NewMockStorageState(ctrl)
+	storageState.EXPECT().Lock().AnyTimes()
+	storageState.EXPECT().Unlock().AnyTimes()
+	storageState.EXPECT().TrieState(gomock.Any()).DoAndReturn(func(*common.Hash) (*rtstorage.TrieState, error) {
+		return rtstorage.NewTrieState(inmemory.NewEmptyTrie()), nil
+	}).AnyTimes()
+
+	blockImportHandler := NewMockBlockImportHandler(ctrl)
+	blockImportHandler.EXPECT().HandleBlockImport(gomock.Any(), gomock.Any(), false).DoAndReturn(
+		func(block *types.Block, _ *rtstorage.TrieState, _ bool) error {
+			mtx.Lock()
+			defer mtx.Unlock()
+			header := block.Header
+			imported[header.Hash()] = &header
+			return nil
+		}).AnyTimes()
+
+	telemetry := NewMockTelemetry(ctrl)
+	telemetry.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	babeVerifier := NewMockBabeVerifier(ctrl)
+
+	importer := newBlockImporter(&FullSyncConfig{
+		BlockState:         blockState,
+		StorageState:       storageState,
+		BabeVerifier:       babeVerifier,
+		BlockImportHandler: blockImportHandler,
+		Telemetry:          telemetry,
+	})
+	return importer, babeVerifier, imported, &mtx
+}
+
+func TestImportBlocks(t *testing.T) {
+	// the chains imported start after block #1, which would stop the verification ahead
+	best := types.NewHeader(common.Hash{}, trie.EmptyHash, common.Hash{}, 1, types.NewDigest())
+
+	t.Run("blocks_verified_ahead", func(t *testing.T) {
+		importer, babeVerifier, imported, mtx := newTestPipelineImporter(t, best)
+		blocks := newTestChain(t, best, 6, 4)
+
+		var verifiedMtx sync.Mutex
+		verified := make(map[uint]verifiedAhead)
+		babeVerifier.EXPECT().VerifyBlockAhead(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(header, parent, ancestor *types.Header) error {
+				// the parent must be imported before its descendants are verified
+				// when it changes the epoch data
+				mtx.Lock()
+				_, ok := imported[ancestor.Hash()]
+				mtx.Unlock()
+				require.True(t, ok)
+
+				verifiedMtx.Lock()
+				defer verifiedMtx.Unlock()
+				verified[header.Number] = verifiedAhead{parent: parent.Number, ancestor: ancestor.Number}
+				return nil
+			}).Times(len(blocks))
+
+		count, err := importer.importBlocks(blocks)
+		require.NoError(t, err)
+		require.Equal(t, len(blocks), count)
+
+		expected := map[uint]verifiedAhead{
+			2: {parent: 1, ancestor: 1},
+			3: {parent: 2, ancestor: 1},
+			4: {parent: 3, ancestor: 1},
+			5: {parent: 4, ancestor: 4},
+			6: {parent: 5, ancestor: 4},
+			7: {parent: 6, ancestor: 4},
+		}
+		require.Equal(t, expected, verified)
+		require.Len(t, imported, len(blocks)+1)
+	})
+
+	t.Run("already_imported_blocks_are_skipped", func(t *testing.T) {
+		importer, babeVerifier, imported, _ := newTestPipelineImporter(t, best)
+		blocks := newTestChain(t, best, 3)
+		imported[blocks[0].Hash] = blocks[0].Header
+
+		babeVerifier.EXPECT().VerifyBlockAhead(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).Times(len(blocks))
+
+		count, err := importer.importBlocks(blocks)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+	})
+
+	t.Run("header_verified_again_once_parent_imported", func(t *testing.T) {
+		importer, babeVerifier, _, _ := newTestPipelineImporter(t, best)
+		blocks := newTestChain(t, best, 3)
+
+		errTest := errors.New("test error")
+		babeVerifier.EXPECT().VerifyBlockAhead(blocks[0].Header, gomock.Any(), gomock.Any()).Return(nil)
+		babeVerifier.EXPECT().VerifyBlockAhead(blocks[1].Header, gomock.Any(), gomock.Any()).Return(errTest)
+		babeVerifier.EXPECT().VerifyBlockAhead(blocks[2].Header, gomock.Any(), gomock.Any()).Return(nil)
+		babeVerifier.EXPECT().VerifyBlock(blocks[1].Header).Return(nil)
+
+		count, err := importer.importBlocks(blocks)
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+	})
+
+	t.Run("invalid_header", func(t *testing.T) {
+		importer, babeVerifier, imported, _ := newTestPipelineImporter(t, best)
+		blocks := newTestChain(t, best, 3)
+
+		errTest := errors.New("test error")
+		babeVerifier.EXPECT().VerifyBlockAhead(gomock.Any(), gomock.Any(), gomock.Any()).Return(errTest).AnyTimes()
+		babeVerifier.EXPECT().VerifyBlock(blocks[0].Header).Return(errTest)
+
+		count, err := importer.importBlocks(blocks)
+		require.ErrorIs(t, err, errTest)
+		require.Zero(t, count)
+		require.Len(t, imported, 1)
+	})
+
+	t.Run("invalid_extrinsics_root", func(t *testing.T) {
+		importer, babeVerifier, imported, _ := newTestPipelineImporter(t, best)
+		blocks := newTestChain(t, best, 3)
+		blocks[1].Body = types.NewBody([]types.Extrinsic{{1, 2, 3}})
+
+		babeVerifier.EXPECT().VerifyBlockAhead(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		count, err := importer.importBlocks(blocks)
+		require.ErrorIs(t, err, errBadExtrinsicsRoot)
+		require.Equal(t, 1, count)
+		require.Len(t, imported, 2)
+	})
+}
+
+func TestVerifyExtrinsicsRoot(t *testing.T) {
+	westendBlocks := &WestendBlocks{}
+	err := yaml.Unmarshal(rawWestendBlocks, westendBlocks)
+	require.NoError(t, err)
+
+	response := &messages.BlockResponseMessage{}
+	err = response.Decode(common.MustHexToBytes(westendBlocks.Blocks1To128))
+	require.NoError(t, err)
+
+	for _, bd := range response.BlockData {
+		err = verifyExtrinsicsRoot(bd.Header, *bd.Body)
+		require.NoError(t, err)
+	}
+
+	bd := response.BlockData[0]
+	body := append(types.Body{}, (*bd.Body)[1:]...)
+	err = verifyExtrinsicsRoot(bd.Header, body)
+	require.ErrorIs(t, err, errBadExtrinsicsRoot)
+}
+
+func TestChangesEpochData(t *testing.T) {
+	genesis := types.NewHeader(common.Hash{}, trie.EmptyHash, common.Hash{}, 0, types.NewDigest())
+	blocks := newTestChain(t, genesis, 3, 3)
+
+	require.True(t, changesEpochData(blocks[0].Header))
+	require.False(t, changesEpochData(blocks[1].Header))
+	require.True(t, changesEpochData(blocks[2].Header))
+}
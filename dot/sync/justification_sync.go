@@ -0,0 +1,206 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/network/messages"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// justificationPeriod is the number of blocks between the blocks whose justification
+	// is kept by the nodes, in addition to the blocks enacting an authority set change
+	justificationPeriod = 512
+
+	// justificationRequestPeers is the number of peers a justification is requested from at once
+	justificationRequestPeers = 3
+
+	// justificationRetryInterval is the time to wait before requesting a justification again
+	justificationRetryInterval = 30 * time.Second
+)
+
+// GrandpaState is the interface for the GRANDPA authority set changes
+type GrandpaState interface {
+	NextGrandpaAuthorityChange(bestBlockHash common.Hash, bestBlockNumber uint) (blockNumber uint, err error)
+}
+
+type pendingJustification struct {
+	number      uint
+	requestedAt time.Time
+}
+
+// justificationRequests tracks the imported blocks whose justification is missing: the block
+// enacting the next authority set change, which must be finalised for the change to be enacted,
+// and the blocks of the finality gap left by the blocks imported without justification.
+type justificationRequests struct {
+	blockState     BlockState
+	grandpaState   GrandpaState
+	finalityGadget FinalityGadget
+
+	pending map[common.Hash]*pendingJustification
+}
+
+func newJustificationRequests(blockState BlockState, grandpaState GrandpaState,
+	finalityGadget FinalityGadget) *justificationRequests {
+	return &justificationRequests{
+		blockState:     blockState,
+		grandpaState:   grandpaState,
+		finalityGadget: finalityGadget,
+		pending:        make(map[common.Hash]*pendingJustification),
+	}
+}
+
+// refresh drops the tracked blocks that were finalised or are not part of the best chain anymore,
+// and tracks the next block whose justification is needed. The block enacting the next authority set
+// change comes first, since the justifications of its descendants are signed by the next authority set.
+// Otherwise, if the finality gap is larger than the justification period, the last block of the gap
+// whose justification is kept by the nodes is tracked.
+func (j *justificationRequests) refresh() error {
+	finalised, err := j.blockState.GetHighestFinalisedHeader()
+	if err != nil {
+		return fmt.Errorf("getting highest finalised header: %w", err)
+	}
+
+	best, err := j.blockState.BestBlockHeader()
+	if err != nil {
+		return fmt.Errorf("getting best block header: %w", err)
+	}
+
+	for hash, pending := range j.pending {
+		if pending.number <= finalised.Number {
+			delete(j.pending, hash)
+			continue
+		}
+
+		canonical, err := j.blockState.GetHashByNumber(pending.number)
+		if err != nil || canonical != hash {
+			delete(j.pending, hash)
+		}
+	}
+
+	number, err := j.grandpaState.NextGrandpaAuthorityChange(best.Hash(), best.Number)
+	if err != nil && !errors.Is(err, state.ErrNoNextAuthorityChange) {
+		return fmt.Errorf("getting next authority change: %w", err)
+	}
+
+	if number == 0 && best.Number-finalised.Number >= justificationPeriod {
+		number = best.Number - best.Number%justificationPeriod
+	}
+
+	if number <= finalised.Number {
+		return nil
+	}
+
+	hash, err := j.blockState.GetHashByNumber(number)
+	if err != nil {
+		return fmt.Errorf("getting hash of block #%d: %w", number, err)
+	}
+
+	if _, ok := j.pending[hash]; !ok {
+		logger.Debugf("tracking missing justification of block #%d (%s)", number, hash.Short())
+		j.pending[hash] = &pendingJustification{number: number}
+	}
+	return nil
+}
+
+// requests returns the requests for the tracked justifications which were not requested
+// during the last retry interval, each justification being requested from several peers.
+func (j *justificationRequests) requests(now time.Time) []*messages.BlockRequestMessage {
+	var requests []*messages.BlockRequestMessage
+	for hash, pending := range j.pending {
+		if now.Sub(pending.requestedAt) < justificationRetryInterval {
+			continue
+		}
+		pending.requestedAt = now
+
+		logger.Infof("requesting justification of block #%d (%s)", pending.number, hash.Short())
+		for i := 0; i < justificationRequestPeers; i++ {
+			requests = append(requests, messages.NewBlockRequest(*messages.NewFromBlock(hash),
+				1, messages.RequestedDataJustification, messages.Ascending))
+		}
+	}
+	return requests
+}
+
+// handleResults verifies the justifications received for the tracked blocks, and finalises
+// the blocks with a valid justification. It returns the results of the other requests and
+// the reputation changes of the peers which sent invalid justifications.
+func (j *justificationRequests) handleResults(results []*SyncTaskResult) (
+	remaining []*SyncTaskResult, repChanges []Change, err error) {
+	remaining = make([]*SyncTaskResult, 0, len(results))
+	for _, result := range results {
+		request, ok := result.request.(*messages.BlockRequestMessage)
+		if !ok || !isJustificationRequest(request) {
+			remaining = append(remaining, result)
+			continue
+		}
+
+		if !result.completed {
+			continue
+		}
+
+		response := result.response.(*messages.BlockResponseMessage)
+		repChange, err := j.handleResponse(result.who, response)
+		if err != nil {
+			return nil, nil, err
+		}
+		if repChange != nil {
+			repChanges = append(repChanges, *repChange)
+		}
+	}
+
+	return remaining, repChanges, nil
+}
+
+func (j *justificationRequests) handleResponse(who peer.ID, response *messages.BlockResponseMessage) (
+	*Change, error) {
+	for _, bd := range response.BlockData {
+		pending, ok := j.pending[bd.Hash]
+		if !ok || bd.Justification == nil || len(*bd.Justification) == 0 {
+			// the justification was already received from another peer,
+			// or the peer does not have it
+			continue
+		}
+
+		round, setID, err := j.finalityGadget.VerifyBlockJustification(bd.Hash, pending.number, *bd.Justification)
+		if err != nil {
+			logger.Warnf("invalid justification of block #%d (%s) from %s: %s",
+				pending.number, bd.Hash.Short(), who, err)
+			return &Change{
+				who: who,
+				rep: peerset.ReputationChange{
+					Value:  peerset.BadJustificationValue,
+					Reason: peerset.BadJustificationReason,
+				},
+			}, nil
+		}
+
+		err = j.blockState.SetFinalisedHash(bd.Hash, round, setID)
+		if err != nil {
+			return nil, fmt.Errorf("setting finalised hash: %w", err)
+		}
+
+		err = j.blockState.SetJustification(bd.Hash, *bd.Justification)
+		if err != nil {
+			return nil, fmt.Errorf("setting justification for block number %d: %w", pending.number, err)
+		}
+
+		logger.Infof("finalised block #%d (%s) with the justification from %s", pending.number, bd.Hash.Short(), who)
+		delete(j.pending, bd.Hash)
+	}
+
+	return nil, nil
+}
+
+// isJustificationRequest returns true if the request only asks for the justification of a block
+func isJustificationRequest(request *messages.BlockRequestMessage) bool {
+	return request.RequestedData == messages.RequestedDataJustification
+}
@@ -0,0 +1,213 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/network/messages"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJustificationRequests_refresh(t *testing.T) {
+	finalised := types.NewHeader(common.Hash{}, common.Hash{}, common.Hash{}, 100, types.NewDigest())
+
+	cases := map[string]struct {
+		bestNumber   uint
+		changeNumber uint
+		changeErr    error
+		expected     []uint
+	}{
+		"authority_change_block": {
+			bestNumber:   200,
+			changeNumber: 150,
+			expected:     []uint{150},
+		},
+		"authority_change_block_before_finality_gap": {
+			bestNumber:   2000,
+			changeNumber: 150,
+			expected:     []uint{150},
+		},
+		"finality_gap": {
+			bestNumber: 1200,
+			changeErr:  state.ErrNoNextAuthorityChange,
+			expected:   []uint{1024},
+		},
+		"small_finality_gap": {
+			bestNumber: 500,
+			changeErr:  state.ErrNoNextAuthorityChange,
+			expected:   []uint{},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			best := types.NewHeader(common.Hash{}, common.Hash{}, common.Hash{}, tt.bestNumber, types.NewDigest())
+
+			blockState := NewMockBlockState(ctrl)
+			blockState.EXPECT().GetHighestFinalisedHeader().Return(finalised, nil)
+			blockState.EXPECT().BestBlockHeader().Return(best, nil)
+			blockState.EXPECT().GetHashByNumber(gomock.Any()).DoAndReturn(func(number uint) (common.Hash, error) {
+				return common.Hash{byte(number >> 8), byte(number)}, nil
+			}).AnyTimes()
+
+			grandpaState := NewMockGrandpaState(ctrl)
+			grandpaState.EXPECT().NextGrandpaAuthorityChange(best.Hash(), best.Number).
+				Return(tt.changeNumber, tt.changeErr)
+
+			justifications := newJustificationRequests(blockState, grandpaState, nil)
+			err := justifications.refresh()
+			require.NoError(t, err)
+
+			expected := make(map[common.Hash]*pendingJustification, len(tt.expected))
+			for _, number := range tt.expected {
+				hash := common.Hash{byte(number >> 8), byte(number)}
+				expected[hash] = &pendingJustification{number: number}
+			}
+			require.Equal(t, expected, justifications.pending)
+		})
+	}
+
+	t.Run("finalised_and_non_canonical_blocks_are_dropped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		best := types.NewHeader(common.Hash{}, common.Hash{}, common.Hash{}, 200, types.NewDigest())
+
+		blockState := NewMockBlockState(ctrl)
+		blockState.EXPECT().GetHighestFinalisedHeader().Return(finalised, nil)
+		blockState.EXPECT().BestBlockHeader().Return(best, nil)
+		blockState.EXPECT().GetHashByNumber(uint(150)).Return(common.Hash{0x15}, nil)
+
+		grandpaState := NewMockGrandpaState(ctrl)
+		grandpaState.EXPECT().NextGrandpaAuthorityChange(best.Hash(), best.Number).
+			Return(uint(0), state.ErrNoNextAuthorityChange)
+
+		justifications := newJustificationRequests(blockState, grandpaState, nil)
+		justifications.pending[common.Hash{0x09}] = &pendingJustification{number: 90}
+		justifications.pending[common.Hash{0x14}] = &pendingJustification{number: 150}
+
+		err := justifications.refresh()
+		require.NoError(t, err)
+		require.Empty(t, justifications.pending)
+	})
+
+	t.Run("next_authority_change_error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		best := types.NewHeader(common.Hash{}, common.Hash{}, common.Hash{}, 200, types.NewDigest())
+
+		blockState := NewMockBlockState(ctrl)
+		blockState.EXPECT().GetHighestFinalisedHeader().Return(finalised, nil)
+		blockState.EXPECT().BestBlockHeader().Return(best, nil)
+
+		errTest := errors.New("test error")
+		grandpaState := NewMockGrandpaState(ctrl)
+		grandpaState.EXPECT().NextGrandpaAuthorityChange(best.Hash(), best.Number).Return(uint(0), errTest)
+
+		justifications := newJustificationRequests(blockState, grandpaState, nil)
+		err := justifications.refresh()
+		require.ErrorIs(t, err, errTest)
+	})
+}
+
+func TestJustificationRequests_requests(t *testing.T) {
+	justifications := newJustificationRequests(nil, nil, nil)
+	hash := common.Hash{1}
+	justifications.pending[hash] = &pendingJustification{number: 150}
+
+	now := time.Now()
+	requests := justifications.requests(now)
+	require.Len(t, requests, justificationRequestPeers)
+
+	expected := messages.NewBlockRequest(*messages.NewFromBlock(hash),
+		1, messages.RequestedDataJustification, messages.Ascending)
+	for _, request := range requests {
+		require.Equal(t, expected, request)
+		require.True(t, isJustificationRequest(request))
+	}
+
+	require.Empty(t, justifications.requests(now.Add(justificationRetryInterval/2)))
+	require.Len(t, justifications.requests(now.Add(justificationRetryInterval)), justificationRequestPeers)
+}
+
+func TestJustificationRequests_handleResults(t *testing.T) {
+	hash := common.Hash{1}
+	justification := []byte{1, 2, 3}
+	blockRequest := messages.NewBlockRequest(*messages.NewFromBlock(common.Hash{2}),
+		1, messages.BootstrapRequestData, messages.Ascending)
+
+	newResult := func(who peer.ID, request *messages.BlockRequestMessage, justification []byte) *SyncTaskResult {
+		return &SyncTaskResult{
+			who:       who,
+			completed: true,
+			request:   request,
+			response: &messages.BlockResponseMessage{
+				BlockData: []*types.BlockData{{Hash: hash, Justification: &justification}},
+			},
+		}
+	}
+	newJustificationRequest := func() *messages.BlockRequestMessage {
+		return messages.NewBlockRequest(*messages.NewFromBlock(hash),
+			1, messages.RequestedDataJustification, messages.Ascending)
+	}
+
+	t.Run("valid_justification", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		blockState := NewMockBlockState(ctrl)
+		blockState.EXPECT().SetFinalisedHash(hash, uint64(3), uint64(1)).Return(nil)
+		blockState.EXPECT().SetJustification(hash, justification).Return(nil)
+
+		finalityGadget := NewMockFinalityGadget(ctrl)
+		finalityGadget.EXPECT().VerifyBlockJustification(hash, uint(150), justification).
+			Return(uint64(3), uint64(1), nil)
+
+		justifications := newJustificationRequests(blockState, nil, finalityGadget)
+		justifications.pending[hash] = &pendingJustification{number: 150}
+
+		blockResult := newResult("alice", blockRequest, nil)
+		results := []*SyncTaskResult{
+			newResult("alice", newJustificationRequest(), nil),
+			newResult("bob", newJustificationRequest(), justification),
+			blockResult,
+			// the justification was received from bob already
+			newResult("charlie", newJustificationRequest(), justification),
+		}
+
+		remaining, repChanges, err := justifications.handleResults(results)
+		require.NoError(t, err)
+		require.Equal(t, []*SyncTaskResult{blockResult}, remaining)
+		require.Empty(t, repChanges)
+		require.Empty(t, justifications.pending)
+	})
+
+	t.Run("invalid_justification", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		finalityGadget := NewMockFinalityGadget(ctrl)
+		finalityGadget.EXPECT().VerifyBlockJustification(hash, uint(150), justification).
+			Return(uint64(0), uint64(0), errors.New("test error"))
+
+		justifications := newJustificationRequests(nil, nil, finalityGadget)
+		justifications.pending[hash] = &pendingJustification{number: 150}
+
+		results := []*SyncTaskResult{newResult("alice", newJustificationRequest(), justification)}
+		remaining, repChanges, err := justifications.handleResults(results)
+		require.NoError(t, err)
+		require.Empty(t, remaining)
+		require.Equal(t, []Change{{
+			who: "alice",
+			rep: peerset.ReputationChange{
+				Value:  peerset.BadJustificationValue,
+				Reason: peerset.BadJustificationReason,
+			},
+		}}, repChanges)
+		require.Len(t, justifications.pending, 1)
+	})
+}
@@ -8,7 +8,9 @@
 	"errors"
 	"fmt"
 	"slices"
+	"time"
 
+	"github.com/ChainSafe/gossamer/dot/network"
 	"github.com/ChainSafe/gossamer/dot/network/messages"
 	"github.com/ChainSafe/gossamer/dot/peerset"
 	"github.com/ChainSafe/gossamer/dot/types"
@@ -16,11 +18,22 @@
 	"github.com/libp2p/go-libp2p/core/peer"
 )
 
-const maxNumberOfSameRequestPerPeer uint = 2
+const (
+	maxNumberOfSameRequestPerPeer uint = 2
+
+	// maxBlockResponseBytes is the budget of the encoded block data of a response. The block exceeding
+	// the budget is still sent, so the budget is half the maximum response size accepted by the peers.
+	maxBlockResponseBytes = network.MaxBlockResponseSize / 2
+
+	// maxBlockRequestsPerPeer is the number of block requests served to a peer during blockRequestsWindow
+	maxBlockRequestsPerPeer = 100
+	blockRequestsWindow     = 10 * time.Second
+)
 
 var (
 	ErrInvalidBlockRequest     = errors.New("invalid block request")
 	errMaxNumberOfSameRequest  = errors.New("max number of same request reached")
+	errBlockRequestRateLimited = errors.New("block request rate limit exceeded")
 	errInvalidRequestDirection = errors.New("invalid request direction")
 	errRequestStartTooHigh     = errors.New("request start number is higher than our best block")
 	errStartAndEndNotOnChain   = errors.New("request start and end hash are not on the same chain")
@@ -32,6 +45,22 @@ func (s *SyncService) CreateBlockResponse(from peer.ID, req *messages.BlockReque
 	*messages.BlockResponseMessage, error) {
 	logger.Debugf("sync request from %s: %s", from, req.String())
 
+	peerHash, err := common.Blake2bHash([]byte(from))
+	if err != nil {
+		return nil, fmt.Errorf("hashing peer id: %w", err)
+	}
+
+	if s.blockRequestLimiter.IsLimitExceeded(peerHash) {
+		s.network.ReportPeer(peerset.ReputationChange{
+			Value:  peerset.ExcessiveBlockRequestsValue,
+			Reason: peerset.ExcessiveBlockRequestsReason,
+		}, from)
+
+		logger.Debugf("block request rate limit exceeded by: %s", from)
+		return nil, fmt.Errorf("%w: %s", errBlockRequestRateLimited, from)
+	}
+	s.blockRequestLimiter.AddRequest(peerHash)
+
 	if req.RequestedData == 0 {
 		return nil, fmt.Errorf("%w: invalid requested data %v", ErrInvalidBlockRequest, req.RequestedData)
 	}
@@ -302,15 +331,17 @@ func (s *SyncService) checkOrGetDescendantHash(ancestor common.Hash,
 
 func (s *SyncService) handleAscendingByNumber(start, end uint,
 	requestedData byte) (*messages.BlockResponseMessage, error) {
-	var err error
-	data := make([]*types.BlockData, (end-start)+1)
+	var size uint64
+	data := make([]*types.BlockData, 0, (end-start)+1)
 
-	for i := uint(0); start+i <= end; i++ {
-		blockNumber := start + i
-		data[i], err = s.getBlockDataByNumber(blockNumber, requestedData)
+	for blockNumber := start; blockNumber <= end && size < maxBlockResponseBytes; blockNumber++ {
+		blockData, blockSize, err := s.getResponseBlockDataByNumber(blockNumber, requestedData)
 		if err != nil {
 			return nil, err
 		}
+
+		data = append(data, blockData)
+		size += blockSize
 	}
 
 	return &messages.BlockResponseMessage{
@@ -320,18 +351,21 @@ func (s *SyncService) handleAscendingByNumber(start, end uint,
 
 func (s *SyncService) handleDescendingByNumber(start, end uint,
 	requestedData byte) (*messages.BlockResponseMessage, error) {
-	var err error
+	var size uint64
 
 	response := &messages.BlockResponseMessage{
-		BlockData: make([]*types.BlockData, (start-end)+1),
+		BlockData: make([]*types.BlockData, 0, (start-end)+1),
 	}
 
-	for i := uint(0); start-i >= end; i++ {
+	for i := uint(0); start-i >= end && size < maxBlockResponseBytes; i++ {
 		blockNumber := start - i
-		response.BlockData[i], err = s.getBlockDataByNumber(blockNumber, requestedData)
+		blockData, blockSize, err := s.getResponseBlockDataByNumber(blockNumber, requestedData)
 		if err != nil {
 			return nil, err
 		}
+
+		response.BlockData = append(response.BlockData, blockData)
+		size += blockSize
 	}
 
 	return response, nil
@@ -355,32 +389,68 @@ func (s *SyncService) handleChainByHash(ancestor, descendant common.Hash,
 		}
 	}
 
+	// the blocks closest to the requested start block are kept once the
+	// response size budget is reached, so descending requests start from the end
+	if direction == messages.Descending {
+		slices.Reverse(subchain)
+	}
+
+	var size uint64
 	response := &messages.BlockResponseMessage{
-		BlockData: make([]*types.BlockData, len(subchain)),
+		BlockData: make([]*types.BlockData, 0, len(subchain)),
 	}
 
-	for i, hash := range subchain {
-		response.BlockData[i], err = s.getBlockData(hash, requestedData)
+	for _, hash := range subchain {
+		if size >= maxBlockResponseBytes {
+			break
+		}
+
+		blockData, blockSize, err := s.getResponseBlockData(hash, requestedData)
 		if err != nil {
 			return nil, err
 		}
-	}
 
-	// reverse BlockData, if descending request
-	if direction == messages.Descending {
-		slices.Reverse(response.BlockData)
+		response.BlockData = append(response.BlockData, blockData)
+		size += blockSize
 	}
 
 	return response, nil
 }
 
-func (s *SyncService) getBlockDataByNumber(num uint, requestedData byte) (*types.BlockData, error) {
+func (s *SyncService) getResponseBlockDataByNumber(num uint, requestedData byte) (
+	*types.BlockData, uint64, error) {
 	hash, err := s.blockState.GetHashByNumber(num)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	return s.getResponseBlockData(hash, requestedData)
+}
+
+// getResponseBlockData returns the block data to send in a block response, and the size of its encoding.
+// The block data is served from the cache if it was recently requested.
+func (s *SyncService) getResponseBlockData(hash common.Hash, requestedData byte) (
+	*types.BlockData, uint64, error) {
+	now := time.Now()
+	blockData, size, ok := s.blockDataCache.get(hash, requestedData, now)
+	if ok {
+		return blockData, size, nil
+	}
+
+	blockData, err := s.getBlockData(hash, requestedData)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	response := &messages.BlockResponseMessage{BlockData: []*types.BlockData{blockData}}
+	encoded, err := response.Encode()
+	if err != nil {
+		return nil, 0, fmt.Errorf("encoding block data: %w", err)
 	}
+	size = uint64(len(encoded))
 
-	return s.getBlockData(hash, requestedData)
+	s.blockDataCache.put(hash, requestedData, blockData, size, now)
+	return blockData, size, nil
 }
 
 func (s *SyncService) getBlockData(hash common.Hash, requestedData byte) (*types.BlockData, error) {
@@ -126,6 +126,8 @@ func(block *types.Block, ts *rtstorage.TrieState, _ bool) error {
 
 	mockBabeVerifier := NewMockBabeVerifier(ctrl)
 	mockBabeVerifier.EXPECT().VerifyBlock(gomock.AssignableToTypeOf(&types.Header{})).AnyTimes()
+	mockBabeVerifier.EXPECT().VerifyBlockAhead(gomock.AssignableToTypeOf(&types.Header{}),
+		gomock.AssignableToTypeOf(&types.Header{}), gomock.AssignableToTypeOf(&types.Header{})).AnyTimes()
 
 	mockFinalityGadget := NewMockFinalityGadget(ctrl)
 	mockFinalityGadget.EXPECT().
@@ -7,8 +7,11 @@
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/ChainSafe/gossamer/dot/network/messages"
+	"github.com/ChainSafe/gossamer/dot/network/ratelimiters"
+	"github.com/ChainSafe/gossamer/dot/peerset"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 	lrucache "github.com/ChainSafe/gossamer/lib/utils/lru-cache"
@@ -207,6 +210,9 @@ type args struct {
 			s := &SyncService{
 				blockState:            tt.blockStateBuilder(ctrl),
 				seenBlockSyncRequests: lrucache.NewLRUCache[common.Hash, uint](100),
+				blockRequestLimiter: ratelimiters.NewSlidingWindowRateLimiter(
+					maxBlockRequestsPerPeer, blockRequestsWindow),
+				blockDataCache: newBlockDataCache(blockDataCacheSize, blockDataCacheTTL),
 			}
 			got, err := s.CreateBlockResponse(peer.ID("alice"), tt.args.req)
 			if tt.err != nil {
@@ -435,3 +441,90 @@ type args struct {
 		})
 	}
 }
+
+func TestService_CreateBlockResponse_rateLimit(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockBlockState := NewMockBlockState(ctrl)
+	mockBlockState.EXPECT().BestBlockNumber().Return(uint(1), nil).Times(2)
+
+	mockNetwork := NewMockNetwork(ctrl)
+	mockNetwork.EXPECT().ReportPeer(peerset.ReputationChange{
+		Value:  peerset.ExcessiveBlockRequestsValue,
+		Reason: peerset.ExcessiveBlockRequestsReason,
+	}, peer.ID("alice"))
+
+	s := &SyncService{
+		network:               mockNetwork,
+		blockState:            mockBlockState,
+		seenBlockSyncRequests: lrucache.NewLRUCache[common.Hash, uint](100),
+		blockRequestLimiter:   ratelimiters.NewSlidingWindowRateLimiter(1, time.Minute),
+		blockDataCache:        newBlockDataCache(blockDataCacheSize, blockDataCacheTTL),
+	}
+
+	for number := uint(2); number < 4; number++ {
+		req := messages.NewBlockRequest(*messages.NewFromBlock(number), 1,
+			messages.BootstrapRequestData, messages.Ascending)
+		_, err := s.CreateBlockResponse(peer.ID("alice"), req)
+		assert.ErrorIs(t, err, errRequestStartTooHigh)
+	}
+
+	req := messages.NewBlockRequest(*messages.NewFromBlock(uint(4)), 1,
+		messages.BootstrapRequestData, messages.Ascending)
+	_, err := s.CreateBlockResponse(peer.ID("alice"), req)
+	assert.ErrorIs(t, err, errBlockRequestRateLimited)
+}
+
+func TestService_handleAscendingByNumber_sizeBudget(t *testing.T) {
+	t.Parallel()
+
+	// with the encoding overhead, three blocks exceed the budget
+	body := types.NewBody([]types.Extrinsic{make([]byte, maxBlockResponseBytes/3)})
+
+	ctrl := gomock.NewController(t)
+	mockBlockState := NewMockBlockState(ctrl)
+	mockBlockState.EXPECT().GetHashByNumber(gomock.Any()).DoAndReturn(func(number uint) (common.Hash, error) {
+		return common.Hash{byte(number)}, nil
+	}).Times(3)
+	mockBlockState.EXPECT().GetBlockBody(gomock.Any()).Return(body, nil).Times(3)
+
+	s := &SyncService{
+		blockState:     mockBlockState,
+		blockDataCache: newBlockDataCache(blockDataCacheSize, blockDataCacheTTL),
+	}
+
+	response, err := s.handleAscendingByNumber(1, 10, messages.RequestedDataBody)
+	assert.NoError(t, err)
+	assert.Len(t, response.BlockData, 3)
+}
+
+func TestService_getResponseBlockData_cache(t *testing.T) {
+	t.Parallel()
+
+	hash := common.Hash{1}
+	header := &types.Header{Number: 1, Digest: types.NewDigest()}
+
+	ctrl := gomock.NewController(t)
+	mockBlockState := NewMockBlockState(ctrl)
+	mockBlockState.EXPECT().GetHeader(hash).Return(header, nil)
+
+	s := &SyncService{
+		blockState:     mockBlockState,
+		blockDataCache: newBlockDataCache(blockDataCacheSize, blockDataCacheTTL),
+	}
+
+	expected := &types.BlockData{Hash: hash, Header: header}
+	for i := 0; i < 2; i++ {
+		blockData, size, err := s.getResponseBlockData(hash, messages.RequestedDataHeader)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, blockData)
+		assert.NotZero(t, size)
+	}
+
+	// the cached block data expires
+	expiry := time.Now().Add(blockDataCacheTTL + time.Second)
+	blockData, _, ok := s.blockDataCache.get(hash, messages.RequestedDataHeader, expiry)
+	assert.False(t, ok)
+	assert.Nil(t, blockData)
+}
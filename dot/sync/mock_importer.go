@@ -53,3 +53,18 @@ func (mr *MockimporterMockRecorder) importBlock(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "importBlock", reflect.TypeOf((*Mockimporter)(nil).importBlock), arg0, arg1)
 }
+
+// importBlocks mocks base method.
+func (m *Mockimporter) importBlocks(arg0 []*types.BlockData) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "importBlocks", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// importBlocks indicates an expected call of importBlocks.
+func (mr *MockimporterMockRecorder) importBlocks(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "importBlocks", reflect.TypeOf((*Mockimporter)(nil).importBlocks), arg0)
+}
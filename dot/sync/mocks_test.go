@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/ChainSafe/gossamer/dot/sync (interfaces: Telemetry,BlockState,StorageState,TransactionState,BabeVerifier,FinalityGadget,BlockImportHandler,Network)
+// Source: github.com/ChainSafe/gossamer/dot/sync (interfaces: Telemetry,BlockState,StorageState,TransactionState,BabeVerifier,FinalityGadget,GrandpaState,BlockImportHandler,Network)
 //
 // Generated by this command:
 //
-//	mockgen -destination=mocks_test.go -package=sync . Telemetry,BlockState,StorageState,TransactionState,BabeVerifier,FinalityGadget,BlockImportHandler,Network
+//	mockgen -destination=mocks_test.go -package=sync . Telemetry,BlockState,StorageState,TransactionState,BabeVerifier,FinalityGadget,GrandpaState,BlockImportHandler,Network
 //
 
 // Package sync is a generated GoMock package.
@@ -567,6 +567,20 @@ func (mr *MockBabeVerifierMockRecorder) VerifyBlock(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyBlock", reflect.TypeOf((*MockBabeVerifier)(nil).VerifyBlock), arg0)
 }
 
+// VerifyBlockAhead mocks base method.
+func (m *MockBabeVerifier) VerifyBlockAhead(arg0, arg1, arg2 *types.Header) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyBlockAhead", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyBlockAhead indicates an expected call of VerifyBlockAhead.
+func (mr *MockBabeVerifierMockRecorder) VerifyBlockAhead(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyBlockAhead", reflect.TypeOf((*MockBabeVerifier)(nil).VerifyBlockAhead), arg0, arg1, arg2)
+}
+
 // MockFinalityGadget is a mock of FinalityGadget interface.
 type MockFinalityGadget struct {
 	ctrl     *gomock.Controller
@@ -606,6 +620,44 @@ func (mr *MockFinalityGadgetMockRecorder) VerifyBlockJustification(arg0, arg1, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyBlockJustification", reflect.TypeOf((*MockFinalityGadget)(nil).VerifyBlockJustification), arg0, arg1, arg2)
 }
 
+// MockGrandpaState is a mock of GrandpaState interface.
+type MockGrandpaState struct {
+	ctrl     *gomock.Controller
+	recorder *MockGrandpaStateMockRecorder
+}
+
+// MockGrandpaStateMockRecorder is the mock recorder for MockGrandpaState.
+type MockGrandpaStateMockRecorder struct {
+	mock *MockGrandpaState
+}
+
+// NewMockGrandpaState creates a new mock instance.
+func NewMockGrandpaState(ctrl *gomock.Controller) *MockGrandpaState {
+	mock := &MockGrandpaState{ctrl: ctrl}
+	mock.recorder = &MockGrandpaStateMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGrandpaState) EXPECT() *MockGrandpaStateMockRecorder {
+	return m.recorder
+}
+
+// NextGrandpaAuthorityChange mocks base method.
+func (m *MockGrandpaState) NextGrandpaAuthorityChange(arg0 common.Hash, arg1 uint) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextGrandpaAuthorityChange", arg0, arg1)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextGrandpaAuthorityChange indicates an expected call of NextGrandpaAuthorityChange.
+func (mr *MockGrandpaStateMockRecorder) NextGrandpaAuthorityChange(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextGrandpaAuthorityChange", reflect.TypeOf((*MockGrandpaState)(nil).NextGrandpaAuthorityChange), arg0, arg1)
+}
+
 // MockBlockImportHandler is a mock of BlockImportHandler interface.
 type MockBlockImportHandler struct {
 	ctrl     *gomock.Controller
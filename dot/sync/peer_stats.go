@@ -0,0 +1,226 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/network/messages"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// statsSmoothing is the weight of the last observation in the moving averages
+	statsSmoothing = 0.2
+
+	// targetResponseTime is the time we want a block response to take, used to
+	// size the block requests sent to slow peers
+	targetResponseTime = 5 * time.Second
+	// minBlocksPerRequest is the minimum number of blocks requested to a peer
+	minBlocksPerRequest = 16
+
+	// latencyTimeoutFactor is the number of times the average latency of a peer
+	// we wait for a response before giving up
+	latencyTimeoutFactor = 4
+	minRequestTimeout    = 5 * time.Second
+	maxRequestTimeout    = 20 * time.Second
+)
+
+// peerStats are the statistics of the requests made to a peer
+type peerStats struct {
+	requests        uint64
+	failures        uint64
+	blocksServed    uint64
+	latency         time.Duration // moving average of the response time
+	bytesPerSecond  float64       // moving average of the response throughput
+	blocksPerSecond float64       // moving average of the blocks served per second
+
+	// requested is true if the peer was requested since the last stall
+	requested bool
+	// stalled is true if the peer was requested while the sync stalled, it is
+	// then only requested once no other peer is left
+	stalled bool
+}
+
+func ewma(average, value float64, first bool) float64 {
+	if first {
+		return value
+	}
+	return statsSmoothing*value + (1-statsSmoothing)*average
+}
+
+// score ranks the peers, the higher the better. Peers that were not requested yet
+// are tried first so we learn about them.
+func (ps *peerStats) score() float64 {
+	if ps == nil || ps.requests == 0 {
+		return math.Inf(1)
+	}
+
+	successRate := 1 - float64(ps.failures)/float64(ps.requests)
+	return ps.bytesPerSecond * successRate
+}
+
+// peerStatsTracker keeps track of the statistics of the peers we request blocks from
+type peerStatsTracker struct {
+	sync.Mutex
+	stats map[peer.ID]*peerStats
+}
+
+func newPeerStatsTracker() *peerStatsTracker {
+	return &peerStatsTracker{
+		stats: make(map[peer.ID]*peerStats),
+	}
+}
+
+// get returns the statistics of the peer, creating them if needed. The caller must hold the lock.
+func (t *peerStatsTracker) get(who peer.ID) *peerStats {
+	stats, ok := t.stats[who]
+	if !ok {
+		stats = &peerStats{}
+		t.stats[who] = stats
+	}
+	return stats
+}
+
+// succeeded records a response of the given size received after the elapsed time
+func (t *peerStatsTracker) succeeded(who peer.ID, elapsed time.Duration, bytes, blocks int) {
+	t.Lock()
+	defer t.Unlock()
+
+	stats := t.get(who)
+	first := stats.requests == stats.failures
+	stats.requests++
+	stats.requested = true
+	stats.blocksServed += uint64(blocks)
+
+	seconds := math.Max(elapsed.Seconds(), time.Millisecond.Seconds())
+	stats.latency = time.Duration(ewma(float64(stats.latency), float64(elapsed), first))
+	stats.bytesPerSecond = ewma(stats.bytesPerSecond, float64(bytes)/seconds, first)
+	stats.blocksPerSecond = ewma(stats.blocksPerSecond, float64(blocks)/seconds, first)
+}
+
+// failed records a failed request
+func (t *peerStatsTracker) failed(who peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	stats := t.get(who)
+	stats.requests++
+	stats.failures++
+	stats.requested = true
+}
+
+// remove forgets the statistics of the peer
+func (t *peerStatsTracker) remove(who peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.stats, who)
+}
+
+// stall deprioritises the peers requested since the last stall, as they did not
+// let the sync progress, and forgets the statistics of the other peers so they are
+// all tried again. Once all the workers stalled, they are all given another chance.
+func (t *peerStatsTracker) stall(workers []peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	for who, stats := range t.stats {
+		if stats.requested {
+			stats.stalled = true
+			stats.requested = false
+		}
+		if !stats.stalled {
+			delete(t.stats, who)
+		}
+	}
+
+	for _, who := range workers {
+		if stats, ok := t.stats[who]; !ok || !stats.stalled {
+			return
+		}
+	}
+	clear(t.stats)
+}
+
+// best returns the index of the peer with the highest score, the stalled peers
+// are only returned if all the peers stalled
+func (t *peerStatsTracker) best(peers []peer.ID) int {
+	t.Lock()
+	defer t.Unlock()
+
+	best := 0
+	bestStalled := true
+	bestScore := math.Inf(-1)
+	for i, who := range peers {
+		stats := t.stats[who]
+		stalled := stats != nil && stats.stalled
+		if stalled && !bestStalled {
+			continue
+		}
+
+		score := stats.score()
+		if (bestStalled && !stalled) || score > bestScore {
+			best, bestStalled, bestScore = i, stalled, score
+		}
+	}
+	return best
+}
+
+// requestSize returns the maximum number of blocks to request to the peer, so that
+// the response is expected to arrive within the target response time.
+func (t *peerStatsTracker) requestSize(who peer.ID) uint32 {
+	t.Lock()
+	defer t.Unlock()
+
+	stats, ok := t.stats[who]
+	if !ok || stats.blocksPerSecond == 0 {
+		return messages.MaxBlocksInResponse
+	}
+
+	size := stats.blocksPerSecond * targetResponseTime.Seconds()
+	return uint32(math.Max(minBlocksPerRequest, math.Min(size, messages.MaxBlocksInResponse)))
+}
+
+// requestTimeout returns the timeout of a request to the peer derived from its
+// average latency, or zero if the latency of the peer is unknown.
+func (t *peerStatsTracker) requestTimeout(who peer.ID) time.Duration {
+	t.Lock()
+	defer t.Unlock()
+
+	stats, ok := t.stats[who]
+	if !ok || stats.latency == 0 {
+		return 0
+	}
+
+	timeout := latencyTimeoutFactor * stats.latency
+	return min(max(timeout, minRequestTimeout), maxRequestTimeout)
+}
+
+// snapshot returns the statistics of all the peers, sorted by peer ID
+func (t *peerStatsTracker) snapshot() []common.SyncPeerStats {
+	t.Lock()
+	defer t.Unlock()
+
+	snapshot := make([]common.SyncPeerStats, 0, len(t.stats))
+	for who, stats := range t.stats {
+		snapshot = append(snapshot, common.SyncPeerStats{
+			PeerID:         who.String(),
+			Requests:       stats.requests,
+			Failures:       stats.failures,
+			BlocksServed:   stats.blocksServed,
+			Latency:        stats.latency,
+			BytesPerSecond: stats.bytesPerSecond,
+		})
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].PeerID < snapshot[j].PeerID
+	})
+	return snapshot
+}
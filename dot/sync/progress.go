@@ -0,0 +1,158 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// defaultStallTimeout is the time without any block imported, while behind the
+// sync target, after which the sync is considered stalled
+const defaultStallTimeout = time.Minute
+
+var (
+	importRateGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "gossamer_network_syncer",
+		Name:      "import_rate",
+		Help:      "moving average of the number of blocks imported per second",
+	})
+	targetBlockGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "gossamer_network_syncer",
+		Name:      "target_block",
+		Help:      "highest block number announced by the peers",
+	})
+	etaGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "gossamer_network_syncer",
+		Name:      "eta_seconds",
+		Help:      "estimated number of seconds to reach the target block at the current import rate",
+	})
+	isStalledGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "gossamer_network_syncer",
+		Name:      "is_stalled",
+		Help:      "bool representing whether the node is behind the target block without importing blocks",
+	})
+	stallsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gossamer_network_syncer",
+		Name:      "stalls_total",
+		Help:      "number of times the sync stalled and the requests were sent to other peers",
+	})
+)
+
+// syncProgress tracks the import rate, the estimated time to reach the sync target
+// and whether the sync stalled.
+type syncProgress struct {
+	mtx          sync.Mutex
+	stallTimeout time.Duration
+
+	started        bool
+	startingBlock  uint
+	bestBlock      uint
+	target         uint
+	importRate     float64
+	rateSampled    bool
+	sampledAt      time.Time
+	lastProgressAt time.Time
+	lastRecoveryAt time.Time
+}
+
+func newSyncProgress(stallTimeout time.Duration) *syncProgress {
+	return &syncProgress{
+		stallTimeout: stallTimeout,
+	}
+}
+
+// update records the best block and the sync target, updates the import rate and the metrics.
+// It returns true if the stall must be recovered from: the best block is behind the target
+// and did not change during the stall timeout, since the start of the stall or the last recovery.
+func (p *syncProgress) update(best, target uint, now time.Time) (recoverStall bool) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if target < best {
+		target = best
+	}
+
+	if !p.started {
+		p.started = true
+		p.startingBlock = best
+		p.bestBlock = best
+		p.sampledAt = now
+		p.lastProgressAt = now
+	}
+
+	elapsed := now.Sub(p.sampledAt).Seconds()
+	if elapsed > 0 {
+		var imported uint
+		if best > p.bestBlock {
+			imported = best - p.bestBlock
+		}
+		p.importRate = ewma(p.importRate, float64(imported)/elapsed, !p.rateSampled)
+		p.rateSampled = true
+		p.sampledAt = now
+	}
+
+	if best != p.bestBlock || best >= target {
+		p.lastProgressAt = now
+	}
+	p.bestBlock = best
+	p.target = target
+
+	stalled := p.isStalled(now)
+	if stalled && now.Sub(p.lastRecoveryAt) >= p.stallTimeout {
+		p.lastRecoveryAt = now
+		recoverStall = true
+	}
+
+	importRateGauge.Set(p.importRate)
+	targetBlockGauge.Set(float64(target))
+	etaGauge.Set(p.eta().Seconds())
+	if stalled {
+		isStalledGauge.Set(1)
+	} else {
+		isStalledGauge.Set(0)
+	}
+	if recoverStall {
+		stallsCounter.Inc()
+	}
+
+	return recoverStall
+}
+
+// isStalled returns true if the best block is behind the target and did not change
+// during the stall timeout. The caller must hold the lock.
+func (p *syncProgress) isStalled(now time.Time) bool {
+	return p.bestBlock < p.target && now.Sub(p.lastProgressAt) >= p.stallTimeout
+}
+
+// eta returns the estimated time to reach the target at the current import rate,
+// or zero if the target is reached or no block is being imported. The caller must hold the lock.
+func (p *syncProgress) eta() time.Duration {
+	if p.bestBlock >= p.target || p.importRate == 0 {
+		return 0
+	}
+
+	remaining := float64(p.target - p.bestBlock)
+	return time.Duration(remaining / p.importRate * float64(time.Second))
+}
+
+// status returns the sync progress
+func (p *syncProgress) status(now time.Time) common.SyncProgress {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	return common.SyncProgress{
+		StartingBlock:  p.startingBlock,
+		CurrentBlock:   p.bestBlock,
+		HighestBlock:   p.target,
+		ImportRate:     p.importRate,
+		ETA:            p.eta(),
+		Stalled:        p.isStalled(now),
+		LastProgressAt: p.lastProgressAt,
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncProgress(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1000, 0)
+	progress := newSyncProgress(time.Minute)
+
+	require.False(t, progress.update(100, 1100, start))
+
+	// 100 blocks imported in 10 seconds
+	now := start.Add(10 * time.Second)
+	require.False(t, progress.update(200, 1100, now))
+
+	status := progress.status(now)
+	require.Equal(t, common.SyncProgress{
+		StartingBlock:  100,
+		CurrentBlock:   200,
+		HighestBlock:   1100,
+		ImportRate:     10,
+		ETA:            90 * time.Second,
+		LastProgressAt: now,
+	}, status)
+
+	// no block imported during the stall timeout
+	lastProgress := now
+	now = now.Add(30 * time.Second)
+	require.False(t, progress.update(200, 1100, now))
+	now = now.Add(30 * time.Second)
+	require.True(t, progress.update(200, 1100, now))
+
+	status = progress.status(now)
+	require.True(t, status.Stalled)
+	require.Equal(t, lastProgress, status.LastProgressAt)
+
+	// the stall is recovered from once per stall timeout
+	now = now.Add(30 * time.Second)
+	require.False(t, progress.update(200, 1100, now))
+	now = now.Add(30 * time.Second)
+	require.True(t, progress.update(200, 1100, now))
+
+	// progress is made again
+	now = now.Add(10 * time.Second)
+	require.False(t, progress.update(201, 1100, now))
+	require.False(t, progress.status(now).Stalled)
+}
+
+func TestSyncProgress_targetReached(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1000, 0)
+	progress := newSyncProgress(time.Minute)
+
+	require.False(t, progress.update(100, 90, start))
+	require.False(t, progress.update(100, 100, start.Add(2*time.Minute)))
+
+	status := progress.status(start.Add(2 * time.Minute))
+	require.False(t, status.Stalled)
+	require.Equal(t, uint(100), status.HighestBlock)
+	require.Zero(t, status.ETA)
+}
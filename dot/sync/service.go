@@ -10,6 +10,7 @@
 	"time"
 
 	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/network/ratelimiters"
 	"github.com/ChainSafe/gossamer/dot/peerset"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/log"
@@ -92,6 +93,8 @@ type Strategy interface {
 	Process(results []*SyncTaskResult) (done bool, repChanges []Change, blocks []peer.ID, err error)
 	ShowMetrics()
 	IsSynced() bool
+	SyncTarget() uint
+	OnStall()
 }
 
 type SyncService struct {
@@ -107,8 +110,12 @@ type SyncService struct {
 	waitPeersDuration time.Duration
 	minPeers          int
 	slotDuration      time.Duration
+	progress          *syncProgress
 
 	seenBlockSyncRequests *lrucache.LRUCache[common.Hash, uint]
+	blockRequestLimiter   network.RateLimiter
+	blockDataCache        *blockDataCache
+	blockRules            *BlockRules
 
 	stopCh chan struct{}
 }
@@ -117,14 +124,22 @@ func NewSyncService(cfgs ...ServiceConfig) *SyncService {
 	svc := &SyncService{
 		minPeers:              minPeersDefault,
 		waitPeersDuration:     waitPeersDefaultTimeout,
+		progress:              newSyncProgress(defaultStallTimeout),
 		stopCh:                make(chan struct{}),
 		seenBlockSyncRequests: lrucache.NewLRUCache[common.Hash, uint](100),
+		blockRequestLimiter: ratelimiters.NewSlidingWindowRateLimiter(
+			maxBlockRequestsPerPeer, blockRequestsWindow),
+		blockDataCache: newBlockDataCache(blockDataCacheSize, blockDataCacheTTL),
 	}
 
 	for _, cfg := range cfgs {
 		cfg(svc)
 	}
 
+	if svc.blockRules == nil {
+		svc.blockRules = NewBlockRules(nil, nil)
+	}
+
 	return svc
 }
 
@@ -222,6 +237,30 @@ func (s *SyncService) HighestBlock() uint {
 	return highestBlock
 }
 
+// PeerStats returns the statistics of the block requests made to each sync peer
+func (s *SyncService) PeerStats() []common.SyncPeerStats {
+	return s.workerPool.peerStats()
+}
+
+// SyncProgress returns the import rate, the estimated time to reach the
+// highest block announced by the peers and whether the sync stalled
+func (s *SyncService) SyncProgress() common.SyncProgress {
+	return s.progress.status(time.Now())
+}
+
+// AddBadBlock rejects the block with the given hash and its descendants, the blocks
+// already imported are not reverted.
+func (s *SyncService) AddBadBlock(hash common.Hash) {
+	logger.Infof("adding bad block %s", hash)
+	s.blockRules.AddBadBlock(hash)
+}
+
+// AddForkBlock pins the block with the given hash at the given number, rejecting its siblings.
+func (s *SyncService) AddForkBlock(number uint, hash common.Hash) {
+	logger.Infof("adding fork block #%d (%s)", number, hash)
+	s.blockRules.AddForkBlock(number, hash)
+}
+
 func (s *SyncService) runSyncEngine() {
 	defer s.wg.Done()
 	s.waitWorkers()
@@ -236,6 +275,7 @@ func (s *SyncService) runSyncEngine() {
 		}
 
 		s.runStrategy()
+		s.updateProgress()
 
 		if s.IsSynced() {
 			isSyncedGauge.Set(1)
@@ -245,6 +285,29 @@ func (s *SyncService) runSyncEngine() {
 	}
 }
 
+// updateProgress updates the sync progress and, if the sync stalled, rotates
+// the workers and lets the strategy request the missing blocks again.
+func (s *SyncService) updateProgress() {
+	best, err := s.blockState.BestBlockNumber()
+	if err != nil {
+		logger.Warnf("getting best block number: %s", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target := s.currentStrategy.SyncTarget()
+	if !s.progress.update(best, target, time.Now()) {
+		return
+	}
+
+	logger.Warnf("sync stalled at block #%d for %s, target block #%d, requesting blocks from other peers",
+		best, s.progress.stallTimeout, target)
+	s.workerPool.rotateWorkers()
+	s.currentStrategy.OnStall()
+}
+
 func (s *SyncService) runStrategy() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -10,6 +10,7 @@
 
 	"github.com/ChainSafe/gossamer/dot/network"
 	"github.com/ChainSafe/gossamer/dot/network/messages"
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"golang.org/x/exp/maps"
 )
@@ -37,12 +38,18 @@ type SyncTaskResult struct {
 	response  messages.P2PMessage
 }
 
+// timeoutRequestMaker is implemented by the request makers supporting a timeout per request
+type timeoutRequestMaker interface {
+	DoWithTimeout(to peer.ID, req, res messages.P2PMessage, timeout time.Duration) error
+}
+
 type syncWorkerPool struct {
 	mtx sync.RWMutex
 
 	network     Network
 	workers     map[peer.ID]struct{}
 	ignorePeers map[peer.ID]struct{}
+	stats       *peerStatsTracker
 }
 
 func newSyncWorkerPool(net Network) *syncWorkerPool {
@@ -50,6 +57,7 @@ func newSyncWorkerPool(net Network) *syncWorkerPool {
 		network:     net,
 		workers:     make(map[peer.ID]struct{}),
 		ignorePeers: make(map[peer.ID]struct{}),
+		stats:       newPeerStatsTracker(),
 	}
 
 	return swp
@@ -75,8 +83,67 @@ func (s *syncWorkerPool) fromBlockAnnounceHandshake(who peer.ID) error {
 	return nil
 }
 
+// idleWorkers hands the idle worker with the best statistics to the tasks
+type idleWorkers struct {
+	mtx   sync.Mutex
+	cond  *sync.Cond
+	idle  []peer.ID
+	busy  int
+	stats *peerStatsTracker
+}
+
+func newIdleWorkers(workers []peer.ID, stats *peerStatsTracker) *idleWorkers {
+	w := &idleWorkers{
+		idle:  workers,
+		stats: stats,
+	}
+	w.cond = sync.NewCond(&w.mtx)
+	return w
+}
+
+// take blocks until a worker is idle and returns the best one, it returns false
+// if there is no worker left.
+func (w *idleWorkers) take() (peer.ID, bool) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	for len(w.idle) == 0 && w.busy > 0 {
+		w.cond.Wait()
+	}
+
+	if len(w.idle) == 0 {
+		return "", false
+	}
+
+	i := w.stats.best(w.idle)
+	worker := w.idle[i]
+	w.idle = append(w.idle[:i], w.idle[i+1:]...)
+	w.busy++
+	return worker, true
+}
+
+// release makes the worker idle again after it completed a task
+func (w *idleWorkers) release(worker peer.ID) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	w.idle = append(w.idle, worker)
+	w.busy--
+	w.cond.Signal()
+}
+
+// drop removes the worker after it failed a task
+func (w *idleWorkers) drop() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	w.busy--
+	w.cond.Broadcast()
+}
+
 // submitRequests blocks until all tasks have been completed or there are no workers
-// left in the pool to retry failed tasks
+// left in the pool to retry failed tasks. Tasks are handed to the idle worker with
+// the best statistics.
 func (s *syncWorkerPool) submitRequests(tasks []*SyncTask) []*SyncTaskResult {
 	if len(tasks) == 0 {
 		return nil
@@ -85,88 +152,167 @@ func (s *syncWorkerPool) submitRequests(tasks []*SyncTask) []*SyncTaskResult {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
 
-	pids := maps.Keys(s.workers)
-	workerPool := make(chan peer.ID, len(pids))
-	for _, worker := range pids {
-		workerPool <- worker
-	}
+	workers := newIdleWorkers(maps.Keys(s.workers), s.stats)
 
-	failedTasks := make(chan *SyncTask, len(tasks))
-	results := make(chan *SyncTaskResult, len(tasks))
+	var (
+		wg         sync.WaitGroup
+		resultsMtx sync.Mutex
+		results    = make([]*SyncTaskResult, 0, len(tasks))
+	)
 
-	var wg sync.WaitGroup
-	for _, task := range tasks {
+	var submit func(task *SyncTask)
+	submit = func(task *SyncTask) {
 		wg.Add(1)
-		go func(t *SyncTask) {
+		go func() {
 			defer wg.Done()
-			executeTask(t, workerPool, failedTasks, results)
-		}(task)
-	}
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for task := range failedTasks {
-			if len(workerPool) > 0 {
-				wg.Add(1)
-				go func(t *SyncTask) {
-					defer wg.Done()
-					executeTask(t, workerPool, failedTasks, results)
-				}(task)
-			} else {
-				results <- &SyncTaskResult{
-					completed: false,
-					request:   task.request,
-					response:  nil,
-				}
-			}
-		}
-	}()
-
-	allResults := make(chan []*SyncTaskResult, 1)
-	wg.Add(1)
-	go func(expectedResults int) {
-		defer wg.Done()
-		var taskResults []*SyncTaskResult
-
-		for result := range results {
-			taskResults = append(taskResults, result)
-			if len(taskResults) == expectedResults {
-				close(failedTasks)
-				break
-			}
-		}
+			result := s.executeTask(task, workers, submit)
 
-		allResults <- taskResults
-	}(len(tasks))
+			resultsMtx.Lock()
+			defer resultsMtx.Unlock()
+			results = append(results, result)
+		}()
+	}
 
-	wg.Wait()
-	close(workerPool)
-	close(results)
+	for _, task := range tasks {
+		submit(task)
+	}
 
-	return <-allResults
+	wg.Wait()
+	return results
 }
 
-func executeTask(task *SyncTask, workerPool chan peer.ID, failedTasks chan *SyncTask, results chan *SyncTaskResult) {
-	worker := <-workerPool
-	logger.Infof("[EXECUTING] worker %s", worker)
+// executeTask runs the task on the best idle worker, retrying on another worker
+// if it fails, until there is no worker left. If the request is shrunk for the
+// worker, the blocks left out are requested by a new task given to requeue.
+func (s *syncWorkerPool) executeTask(task *SyncTask, workers *idleWorkers,
+	requeue func(task *SyncTask)) *SyncTaskResult {
+	for {
+		worker, ok := workers.take()
+		if !ok {
+			return &SyncTaskResult{
+				completed: false,
+				request:   task.request,
+				response:  nil,
+			}
+		}
 
-	err := task.requestMaker.Do(worker, task.request, task.response)
-	if err != nil {
-		logger.Infof("[ERR] worker %s, request: %s, err: %s", worker, task.request.String(), err.Error())
-		failedTasks <- task
-	} else {
-		logger.Infof("[FINISHED] worker %s, request: %s", worker, task.request.String())
-		workerPool <- worker
-		results <- &SyncTaskResult{
+		logger.Infof("[EXECUTING] worker %s", worker)
+
+		request, remainder := s.sizeRequest(worker, task.request)
+		if remainder != nil {
+			requeue(&SyncTask{
+				requestMaker: task.requestMaker,
+				request:      remainder,
+				response:     &messages.BlockResponseMessage{},
+			})
+		}
+
+		start := time.Now()
+		err := s.doRequest(worker, task, request)
+		if err != nil {
+			logger.Infof("[ERR] worker %s, request: %s, err: %s", worker, request.String(), err.Error())
+			s.stats.failed(worker)
+			workers.drop()
+			continue
+		}
+
+		s.stats.succeeded(worker, time.Since(start), responseSize(task.response), blocksInResponse(task.response))
+		logger.Infof("[FINISHED] worker %s, request: %s", worker, request.String())
+		workers.release(worker)
+		return &SyncTaskResult{
 			who:       worker,
 			completed: true,
-			request:   task.request,
+			request:   request,
 			response:  task.response,
 		}
 	}
 }
 
+// doRequest sends the request, sized for the worker, to the worker with a timeout
+// derived from the latency of the worker if the request maker of the task supports it.
+func (s *syncWorkerPool) doRequest(worker peer.ID, task *SyncTask, request messages.P2PMessage) error {
+	requestMaker, ok := task.requestMaker.(timeoutRequestMaker)
+	if ok {
+		if timeout := s.stats.requestTimeout(worker); timeout > 0 {
+			return requestMaker.DoWithTimeout(worker, request, task.response, timeout)
+		}
+	}
+
+	return task.requestMaker.Do(worker, request, task.response)
+}
+
+// sizeRequest returns the request to send to the worker, lowering the number of blocks
+// requested to slow workers, along with a request for the blocks left out of the range.
+// The request of the task is left untouched, so it can be sized again for another worker
+// if this one fails. Requests starting at a block hash are not lowered, since the start
+// of the remaining blocks is unknown.
+func (s *syncWorkerPool) sizeRequest(worker peer.ID, request messages.P2PMessage) (
+	sized messages.P2PMessage, remainder *messages.BlockRequestMessage) {
+	blockRequest, ok := request.(*messages.BlockRequestMessage)
+	if !ok || blockRequest.Max == nil {
+		return request, nil
+	}
+
+	size := s.stats.requestSize(worker)
+	if size >= *blockRequest.Max {
+		return request, nil
+	}
+
+	start, ok := blockRequest.StartingBlock.RawValue().(uint)
+	if !ok {
+		return request, nil
+	}
+
+	logger.Debugf("requesting %d blocks instead of %d to worker %s", size, *blockRequest.Max, worker)
+	sizedRequest := messages.NewBlockRequest(blockRequest.StartingBlock, size,
+		blockRequest.RequestedData, blockRequest.Direction)
+	remaining := *blockRequest.Max - size
+
+	switch blockRequest.Direction {
+	case messages.Ascending:
+		start += uint(size)
+	case messages.Descending:
+		if start < uint(size) {
+			// the range ends at the genesis block
+			return sizedRequest, nil
+		}
+		start -= uint(size)
+	}
+
+	return sizedRequest, messages.NewBlockRequest(*messages.NewFromBlock(start), remaining,
+		blockRequest.RequestedData, blockRequest.Direction)
+}
+
+func responseSize(response messages.P2PMessage) int {
+	enc, err := response.Encode()
+	if err != nil {
+		return 0
+	}
+	return len(enc)
+}
+
+func blocksInResponse(response messages.P2PMessage) int {
+	blockResponse, ok := response.(*messages.BlockResponseMessage)
+	if !ok {
+		return 0
+	}
+	return len(blockResponse.BlockData)
+}
+
+// peerStats returns the statistics of the requests made to the workers
+func (s *syncWorkerPool) peerStats() []common.SyncPeerStats {
+	return s.stats.snapshot()
+}
+
+// rotateWorkers is called when the sync stalled, the workers requested since the
+// previous stall are only handed tasks again once no other worker is left
+func (s *syncWorkerPool) rotateWorkers() {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	s.stats.stall(maps.Keys(s.workers))
+}
+
 func (s *syncWorkerPool) ignorePeerAsWorker(who peer.ID) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
@@ -180,6 +326,7 @@ func (s *syncWorkerPool) removeWorker(who peer.ID) {
 	defer s.mtx.Unlock()
 
 	delete(s.workers, who)
+	s.stats.remove(who)
 }
 
 // totalWorkers only returns available or busy workers
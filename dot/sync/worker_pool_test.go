@@ -0,0 +1,284 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/network/messages"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSyncWorkerPool_submitRequests(t *testing.T) {
+	t.Parallel()
+
+	const fastPeer, slowPeer, failingPeer = peer.ID("fast"), peer.ID("slow"), peer.ID("failing")
+
+	pool := newSyncWorkerPool(nil)
+	for _, who := range []peer.ID{fastPeer, slowPeer, failingPeer} {
+		require.NoError(t, pool.fromBlockAnnounceHandshake(who))
+	}
+	pool.stats.succeeded(fastPeer, time.Second, 1<<20, 128)
+	pool.stats.succeeded(slowPeer, 10*time.Second, 1<<10, 10)
+	// never requested peers are tried first
+	require.Equal(t, 2, pool.stats.best([]peer.ID{fastPeer, slowPeer, failingPeer}))
+
+	ctrl := gomock.NewController(t)
+	requestMaker := NewMockRequestMaker(ctrl)
+	requestMaker.EXPECT().
+		Do(failingPeer, gomock.Any(), gomock.Any()).
+		Return(errors.New("timeout"))
+	requestMaker.EXPECT().
+		Do(fastPeer, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ peer.ID, _, res messages.P2PMessage) error {
+			res.(*messages.BlockResponseMessage).BlockData = []*types.BlockData{{}}
+			return nil
+		})
+
+	request := messages.NewBlockRequest(*messages.NewFromBlock(uint(1)),
+		messages.MaxBlocksInResponse, messages.BootstrapRequestData, messages.Ascending)
+	results := pool.submitRequests([]*SyncTask{{
+		requestMaker: requestMaker,
+		request:      request,
+		response:     &messages.BlockResponseMessage{},
+	}})
+
+	// the failed task is retried on the fastest peer
+	require.Len(t, results, 1)
+	require.True(t, results[0].completed)
+	require.Equal(t, fastPeer, results[0].who)
+
+	stats := make(map[string]common.SyncPeerStats)
+	for _, s := range pool.peerStats() {
+		stats[s.PeerID] = s
+	}
+	require.Len(t, stats, 3)
+	require.Equal(t, uint64(1), stats[failingPeer.String()].Failures)
+	require.Equal(t, uint64(2), stats[fastPeer.String()].Requests)
+	require.Equal(t, uint64(129), stats[fastPeer.String()].BlocksServed)
+
+	// the failing peer is now the worst one
+	require.Equal(t, 0, pool.stats.best([]peer.ID{fastPeer, slowPeer, failingPeer}))
+}
+
+func TestSyncWorkerPool_rotateWorkers(t *testing.T) {
+	t.Parallel()
+
+	const stalledPeer, otherPeer = peer.ID("stalled"), peer.ID("other")
+
+	pool := newSyncWorkerPool(nil)
+	for _, who := range []peer.ID{stalledPeer, otherPeer} {
+		require.NoError(t, pool.fromBlockAnnounceHandshake(who))
+	}
+	// the stalled peer is the fastest one but the sync did not progress
+	pool.stats.succeeded(stalledPeer, time.Second, 1<<20, 128)
+	pool.rotateWorkers()
+
+	ctrl := gomock.NewController(t)
+	requestMaker := NewMockRequestMaker(ctrl)
+	requestMaker.EXPECT().
+		Do(otherPeer, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ peer.ID, _, res messages.P2PMessage) error {
+			// full responses keep the requests to the other peer unsplit
+			blockData := make([]*types.BlockData, messages.MaxBlocksInResponse)
+			for i := range blockData {
+				blockData[i] = &types.BlockData{}
+			}
+			res.(*messages.BlockResponseMessage).BlockData = blockData
+			return nil
+		}).
+		Times(3)
+
+	// the stalled peer gets no task even once the statistics of the other peer are known
+	for i := range 3 {
+		request := messages.NewBlockRequest(*messages.NewFromBlock(uint(i)),
+			messages.MaxBlocksInResponse, messages.BootstrapRequestData, messages.Ascending)
+		results := pool.submitRequests([]*SyncTask{{
+			requestMaker: requestMaker,
+			request:      request,
+			response:     &messages.BlockResponseMessage{},
+		}})
+		require.Len(t, results, 1)
+		require.Equal(t, otherPeer, results[0].who)
+	}
+
+	// once all the workers stalled, they are all requested again
+	pool.rotateWorkers()
+	require.Equal(t, 0, pool.stats.best([]peer.ID{stalledPeer, otherPeer}))
+	require.Empty(t, pool.peerStats())
+}
+
+func TestSyncWorkerPool_submitRequests_noWorkerLeft(t *testing.T) {
+	t.Parallel()
+
+	const who = peer.ID("failing")
+	pool := newSyncWorkerPool(nil)
+	require.NoError(t, pool.fromBlockAnnounceHandshake(who))
+
+	ctrl := gomock.NewController(t)
+	requestMaker := NewMockRequestMaker(ctrl)
+	requestMaker.EXPECT().
+		Do(who, gomock.Any(), gomock.Any()).
+		Return(errors.New("timeout"))
+
+	tasks := make([]*SyncTask, 3)
+	for i := range tasks {
+		tasks[i] = &SyncTask{
+			requestMaker: requestMaker,
+			request: messages.NewBlockRequest(*messages.NewFromBlock(uint(i)),
+				messages.MaxBlocksInResponse, messages.BootstrapRequestData, messages.Ascending),
+			response: &messages.BlockResponseMessage{},
+		}
+	}
+
+	results := pool.submitRequests(tasks)
+	require.Len(t, results, 3)
+	for _, result := range results {
+		require.False(t, result.completed)
+	}
+}
+
+func TestSyncWorkerPool_submitRequests_requeuesRemainder(t *testing.T) {
+	t.Parallel()
+
+	const who = peer.ID("slow")
+	pool := newSyncWorkerPool(nil)
+	require.NoError(t, pool.fromBlockAnnounceHandshake(who))
+	pool.stats.succeeded(who, 2*time.Second, 1000, 20)
+
+	ctrl := gomock.NewController(t)
+	requestMaker := NewMockRequestMaker(ctrl)
+	var mtx sync.Mutex
+	requested := make(map[uint]uint32)
+	requestMaker.EXPECT().
+		Do(who, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ peer.ID, req, res messages.P2PMessage) error {
+			request := req.(*messages.BlockRequestMessage)
+			mtx.Lock()
+			requested[request.StartingBlock.RawValue().(uint)] = *request.Max
+			mtx.Unlock()
+
+			blockData := make([]*types.BlockData, *request.Max)
+			for i := range blockData {
+				blockData[i] = &types.BlockData{}
+			}
+			res.(*messages.BlockResponseMessage).BlockData = blockData
+			return nil
+		}).
+		MinTimes(2)
+
+	results := pool.submitRequests([]*SyncTask{{
+		requestMaker: requestMaker,
+		request: messages.NewBlockRequest(*messages.NewFromBlock(uint(1)),
+			messages.MaxBlocksInResponse, messages.BootstrapRequestData, messages.Ascending),
+		response: &messages.BlockResponseMessage{},
+	}})
+
+	// the blocks left out of the shrunk request are requested by other tasks
+	require.Len(t, results, len(requested))
+	next := uint(1)
+	for next <= messages.MaxBlocksInResponse {
+		size, ok := requested[next]
+		require.True(t, ok, "no request starting at block #%d", next)
+		next += uint(size)
+	}
+	require.Equal(t, uint(messages.MaxBlocksInResponse+1), next)
+	for _, result := range results {
+		require.True(t, result.completed)
+	}
+}
+
+func TestSyncWorkerPool_sizeRequest(t *testing.T) {
+	t.Parallel()
+
+	const who = peer.ID("slow")
+	hash := common.Hash{1}
+
+	testCases := map[string]struct {
+		request           *messages.BlockRequestMessage
+		expectedMax       uint32
+		expectedRemainder *messages.BlockRequestMessage
+	}{
+		"ascending_from_number": {
+			request: messages.NewBlockRequest(*messages.NewFromBlock(uint(10)), 128,
+				messages.BootstrapRequestData, messages.Ascending),
+			expectedMax: 50,
+			expectedRemainder: messages.NewBlockRequest(*messages.NewFromBlock(uint(60)), 78,
+				messages.BootstrapRequestData, messages.Ascending),
+		},
+		"descending_from_number": {
+			request: messages.NewBlockRequest(*messages.NewFromBlock(uint(100)), 128,
+				messages.BootstrapRequestData, messages.Descending),
+			expectedMax: 50,
+			expectedRemainder: messages.NewBlockRequest(*messages.NewFromBlock(uint(50)), 78,
+				messages.BootstrapRequestData, messages.Descending),
+		},
+		"descending_to_genesis": {
+			request: messages.NewBlockRequest(*messages.NewFromBlock(uint(40)), 128,
+				messages.BootstrapRequestData, messages.Descending),
+			expectedMax: 50,
+		},
+		"from_hash": {
+			request: messages.NewBlockRequest(*messages.NewFromBlock(hash), 128,
+				messages.BootstrapRequestData, messages.Ascending),
+			expectedMax: 128,
+		},
+		"not_shrunk": {
+			request: messages.NewBlockRequest(*messages.NewFromBlock(uint(10)), 20,
+				messages.BootstrapRequestData, messages.Ascending),
+			expectedMax: 20,
+		},
+	}
+
+	for name, tt := range testCases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pool := newSyncWorkerPool(nil)
+			pool.stats.succeeded(who, 2*time.Second, 1000, 20)
+
+			initialMax := *tt.request.Max
+			sized, remainder := pool.sizeRequest(who, tt.request)
+			require.Equal(t, tt.expectedMax, *sized.(*messages.BlockRequestMessage).Max)
+			require.Equal(t, tt.request.StartingBlock, sized.(*messages.BlockRequestMessage).StartingBlock)
+			require.Equal(t, tt.expectedRemainder, remainder)
+			// the request of the task is not modified
+			require.Equal(t, initialMax, *tt.request.Max)
+		})
+	}
+}
+
+func TestPeerStatsTracker(t *testing.T) {
+	t.Parallel()
+
+	const who = peer.ID("jimbo")
+	tracker := newPeerStatsTracker()
+
+	// unknown peers get full requests and the default timeout
+	require.Equal(t, uint32(messages.MaxBlocksInResponse), tracker.requestSize(who))
+	require.Zero(t, tracker.requestTimeout(who))
+
+	tracker.succeeded(who, 2*time.Second, 1000, 20)
+	require.Equal(t, uint32(50), tracker.requestSize(who))
+	require.Equal(t, 8*time.Second, tracker.requestTimeout(who))
+
+	// slower responses lower the moving averages
+	tracker.succeeded(who, 10*time.Second, 1000, 10)
+	require.Equal(t, uint32(41), tracker.requestSize(who))
+	require.Equal(t, 14400*time.Millisecond, tracker.requestTimeout(who))
+
+	tracker.succeeded(who, 10*time.Millisecond, 1000, 128)
+	require.Equal(t, uint32(messages.MaxBlocksInResponse), tracker.requestSize(who))
+
+	tracker.remove(who)
+	require.Empty(t, tracker.snapshot())
+}
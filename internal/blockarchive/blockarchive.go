@@ -0,0 +1,213 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package blockarchive implements the portable block archive format used to export
+// blocks from a node database and to import them into another node.
+//
+// An archive starts with a header made of the magic bytes "GSSMRBLK", the format
+// version, a flags byte and the genesis hash of the chain. It is followed by records
+// made of the length of the SCALE encoded types.BlockData, as an uint32 little endian,
+// and of the encoded block data. If the compression flag is set, the records are
+// compressed as a single zstd stream. The records are written and read with WriteRecord
+// and ReadRecord, which other formats reuse for their own records.
+package blockarchive
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// Version is the version of the archive format
+	Version byte = 1
+
+	// MaxRecordSize is the maximum size of a record, such as an encoded block data
+	MaxRecordSize = 64 * 1024 * 1024
+
+	flagCompressed byte = 1 << 0
+)
+
+var magic = [8]byte{'G', 'S', 'S', 'M', 'R', 'B', 'L', 'K'}
+
+var (
+	ErrInvalidMagic       = errors.New("not a block archive")
+	ErrUnsupportedVersion = errors.New("unsupported block archive version")
+	ErrRecordTooLarge     = errors.New("block archive record too large")
+)
+
+// Writer writes blocks to an archive.
+type Writer struct {
+	w       io.Writer
+	encoder *zstd.Encoder
+}
+
+// NewWriter writes the archive header to w and returns a Writer for the blocks of the chain
+// with the given genesis hash. Close must be called once all the blocks are written.
+func NewWriter(w io.Writer, genesisHash common.Hash, compress bool) (*Writer, error) {
+	header := make([]byte, 0, len(magic)+2+len(genesisHash))
+	header = append(header, magic[:]...)
+	header = append(header, Version)
+	var flags byte
+	if compress {
+		flags |= flagCompressed
+	}
+	header = append(header, flags)
+	header = append(header, genesisHash[:]...)
+
+	_, err := w.Write(header)
+	if err != nil {
+		return nil, fmt.Errorf("writing archive header: %w", err)
+	}
+
+	writer := &Writer{w: w}
+	if compress {
+		writer.encoder, err = zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("creating zstd encoder: %w", err)
+		}
+		writer.w = writer.encoder
+	}
+
+	return writer, nil
+}
+
+// Write appends the block data to the archive.
+func (w *Writer) Write(bd *types.BlockData) error {
+	encoded, err := scale.Marshal(*bd)
+	if err != nil {
+		return fmt.Errorf("encoding block data: %w", err)
+	}
+
+	err = WriteRecord(w.w, encoded)
+	if err != nil {
+		return fmt.Errorf("writing block %s: %w", bd.Hash, err)
+	}
+	return nil
+}
+
+// WriteRecord writes the record to w, prefixed by its length as an uint32 little endian.
+func WriteRecord(w io.Writer, record []byte) error {
+	if len(record) > MaxRecordSize {
+		return fmt.Errorf("%w: %d bytes", ErrRecordTooLarge, len(record))
+	}
+
+	framed := make([]byte, 4, 4+len(record))
+	binary.LittleEndian.PutUint32(framed, uint32(len(record))) //nolint:gosec
+	framed = append(framed, record...)
+
+	_, err := w.Write(framed)
+	return err
+}
+
+// Close flushes the compressed stream, it does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.encoder == nil {
+		return nil
+	}
+	return w.encoder.Close()
+}
+
+// Reader reads blocks from an archive.
+type Reader struct {
+	r       io.Reader
+	decoder *zstd.Decoder
+
+	// GenesisHash is the genesis hash of the chain the blocks belong to
+	GenesisHash common.Hash
+	// Compressed is true if the records of the archive are compressed
+	Compressed bool
+}
+
+// NewReader reads the archive header from r and returns a Reader for its blocks.
+// Close must be called once the reader is not used anymore.
+func NewReader(r io.Reader) (*Reader, error) {
+	header := make([]byte, len(magic)+2+len(common.Hash{}))
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, fmt.Errorf("reading archive header: %w", err)
+	}
+
+	if [8]byte(header[:len(magic)]) != magic {
+		return nil, ErrInvalidMagic
+	}
+
+	version := header[len(magic)]
+	if version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	flags := header[len(magic)+1]
+	reader := &Reader{
+		r:           r,
+		GenesisHash: common.NewHash(header[len(magic)+2:]),
+		Compressed:  flags&flagCompressed != 0,
+	}
+
+	if reader.Compressed {
+		reader.decoder, err = zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("creating zstd decoder: %w", err)
+		}
+		reader.r = reader.decoder
+	}
+
+	return reader, nil
+}
+
+// Read returns the next block data of the archive, or io.EOF once all the blocks were read.
+func (r *Reader) Read() (*types.BlockData, error) {
+	encoded, err := ReadRecord(r.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("reading block data: %w", err)
+	}
+
+	bd := types.NewEmptyBlockData()
+	err = scale.Unmarshal(encoded, bd)
+	if err != nil {
+		return nil, fmt.Errorf("decoding block data: %w", err)
+	}
+	return bd, nil
+}
+
+// ReadRecord reads a record written with WriteRecord from r. It returns io.EOF if r
+// ends before the record, and io.ErrUnexpectedEOF if it ends within the record.
+func ReadRecord(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	_, err := io.ReadFull(r, length[:])
+	if err != nil {
+		// io.ReadFull only returns io.EOF if no byte was read
+		return nil, err
+	}
+
+	size := binary.LittleEndian.Uint32(length[:])
+	if size > MaxRecordSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrRecordTooLarge, size)
+	}
+
+	record := make([]byte, size)
+	_, err = io.ReadFull(r, record)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+// Close releases the resources of the decompressor, it does not close the underlying reader.
+func (r *Reader) Close() {
+	if r.decoder != nil {
+		r.decoder.Close()
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package blockarchive
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBlocks(t *testing.T, count uint) []*types.BlockData {
+	t.Helper()
+
+	blocks := make([]*types.BlockData, 0, count)
+	parentHash := common.Hash{1}
+	for number := uint(1); number <= count; number++ {
+		digest := types.NewDigest()
+		prd, err := types.NewBabeSecondaryPlainPreDigest(0, uint64(number)).ToPreRuntimeDigest()
+		require.NoError(t, err)
+		require.NoError(t, digest.Add(*prd))
+		require.NoError(t, digest.Add(types.SealDigest{
+			ConsensusEngineID: types.BabeEngineID,
+			Data:              []byte{byte(number)},
+		}))
+
+		header := types.NewHeader(parentHash, common.Hash{2}, common.Hash{3}, number, digest)
+		body := types.NewBody([]types.Extrinsic{{1, 2, 3}, {byte(number)}})
+		bd := &types.BlockData{
+			Hash:   header.Hash(),
+			Header: header,
+			Body:   body,
+		}
+		if number%2 == 0 {
+			justification := []byte{4, 5, byte(number)}
+			bd.Justification = &justification
+		}
+
+		blocks = append(blocks, bd)
+		parentHash = header.Hash()
+	}
+	return blocks
+}
+
+func TestArchive(t *testing.T) {
+	genesisHash := common.Hash{9}
+	blocks := newTestBlocks(t, 10)
+
+	for _, compress := range []bool{false, true} {
+		buf := new(bytes.Buffer)
+		writer, err := NewWriter(buf, genesisHash, compress)
+		require.NoError(t, err)
+		for _, bd := range blocks {
+			require.NoError(t, writer.Write(bd))
+		}
+		require.NoError(t, writer.Close())
+
+		reader, err := NewReader(buf)
+		require.NoError(t, err)
+		require.Equal(t, genesisHash, reader.GenesisHash)
+		require.Equal(t, compress, reader.Compressed)
+
+		for _, expected := range blocks {
+			bd, err := reader.Read()
+			require.NoError(t, err)
+			require.Equal(t, expected.Hash, bd.Hash)
+			require.Equal(t, expected.Hash, bd.Header.Hash())
+			require.Equal(t, expected.Body, bd.Body)
+			require.Equal(t, expected.Justification, bd.Justification)
+		}
+
+		_, err = reader.Read()
+		require.ErrorIs(t, err, io.EOF)
+		reader.Close()
+	}
+}
+
+func TestArchive_Errors(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("GSSMRBLX")))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	header := append([]byte("GSSMRBLX"), make([]byte, 34)...)
+	_, err = NewReader(bytes.NewReader(header))
+	require.ErrorIs(t, err, ErrInvalidMagic)
+
+	header = append([]byte("GSSMRBLK"), make([]byte, 34)...)
+	header[8] = 2
+	_, err = NewReader(bytes.NewReader(header))
+	require.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	buf := new(bytes.Buffer)
+	writer, err := NewWriter(buf, common.Hash{}, false)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(newTestBlocks(t, 1)[0]))
+
+	truncated := buf.Bytes()[:buf.Len()-1]
+	reader, err := NewReader(bytes.NewReader(truncated))
+	require.NoError(t, err)
+	_, err = reader.Read()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	tooLarge := append(buf.Bytes()[:42:42], 0xff, 0xff, 0xff, 0xff)
+	reader, err = NewReader(bytes.NewReader(tooLarge))
+	require.NoError(t, err)
+	_, err = reader.Read()
+	require.ErrorIs(t, err, ErrRecordTooLarge)
+}
@@ -9,6 +9,15 @@
 
 var _ Batch = (*tableBatch)(nil)
 
+// NewTableBatch returns a batch writing the keys of the table with the given prefix
+// to the given batch, so the changes of several tables can be flushed at once.
+func NewTableBatch(batch Batch, prefix string) Batch {
+	return &tableBatch{
+		batch:  batch,
+		prefix: []byte(prefix),
+	}
+}
+
 type tableBatch struct {
 	batch  Batch
 	prefix []byte
@@ -0,0 +1,364 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package snapshot implements the state snapshot format used to export the state of a
+// finalised block from a node database and to bootstrap another node from it.
+//
+// A snapshot starts with a header made of the magic bytes "GSSMRSNP", the format version,
+// the state trie version and the genesis hash of the chain. It is followed by a zstd stream
+// of records framed as the records of a block archive, each made of a kind byte, the payload
+// and the CRC-32 (Castagnoli) checksum of the kind and payload as an uint32 little endian.
+// The records are, in order:
+//   - the SCALE encoded header of the block;
+//   - the SCALE encoded Consensus data at the block;
+//   - batches of the state entries, the entries of the state trie first, sorted by key,
+//     followed by the entries of each child trie, sorted by key;
+//   - an end record holding the number of entries, as an uint64 little endian, so that a
+//     truncated snapshot is detected.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/blockarchive"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// Version is the version of the snapshot format
+	Version byte = 1
+
+	// recordOverhead is the size of the kind and checksum of a record
+	recordOverhead = 1 + 4
+
+	// batchSize is the size of the keys and values above which a batch of entries is written
+	batchSize = 1024 * 1024
+)
+
+const (
+	kindHeader byte = iota + 1
+	kindConsensus
+	kindEntries
+	kindEnd
+)
+
+var magic = [8]byte{'G', 'S', 'S', 'M', 'R', 'S', 'N', 'P'}
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	ErrInvalidMagic       = errors.New("not a state snapshot")
+	ErrUnsupportedVersion = errors.New("unsupported state snapshot version")
+	ErrChecksumMismatch   = errors.New("state snapshot checksum mismatch")
+	ErrUnexpectedRecord   = errors.New("unexpected state snapshot record")
+	ErrEntryCountMismatch = errors.New("state snapshot entry count mismatch")
+)
+
+// Consensus is the BABE epoch and GRANDPA authority set data at the block of a snapshot,
+// needed to verify and finalise the blocks following it.
+type Consensus struct {
+	// FirstSlot is the slot of the first block of the chain following the genesis block
+	FirstSlot uint64
+	// Epoch is the epoch of the block
+	Epoch          uint64
+	EpochData      types.EpochDataRaw
+	ConfigData     types.ConfigData
+	NextEpochData  types.EpochDataRaw
+	NextConfigData types.ConfigData
+	// Round is the GRANDPA round the block was finalised in, or 0 if it is not known
+	Round uint64
+	// SetID is the GRANDPA authority set finalising the blocks following the block
+	SetID uint64
+	// SetIDChange is the number of the block the authority set changed at
+	SetIDChange uint
+	Authorities []types.GrandpaAuthoritiesRaw
+}
+
+// Entry is a key-value pair of the state trie, or of the child trie with the child
+// storage key ChildKey if it is not empty.
+type Entry struct {
+	ChildKey []byte
+	Key      []byte
+	Value    []byte
+}
+
+type entry struct {
+	Key   []byte
+	Value []byte
+}
+
+type entries struct {
+	ChildKey []byte
+	Entries  []entry
+}
+
+// Writer writes a state snapshot.
+type Writer struct {
+	encoder *zstd.Encoder
+	batch   entries
+	size    int
+	count   uint64
+}
+
+// NewWriter writes the snapshot header, the block header and the consensus data to w and returns
+// a Writer for the state entries of the block. Close must be called once all the entries are written.
+func NewWriter(w io.Writer, genesisHash common.Hash, stateVersion trie.TrieLayout,
+	header *types.Header, consensus *Consensus) (*Writer, error) {
+	fileHeader := make([]byte, 0, len(magic)+2+len(genesisHash))
+	fileHeader = append(fileHeader, magic[:]...)
+	fileHeader = append(fileHeader, Version, byte(stateVersion))
+	fileHeader = append(fileHeader, genesisHash[:]...)
+
+	_, err := w.Write(fileHeader)
+	if err != nil {
+		return nil, fmt.Errorf("writing snapshot header: %w", err)
+	}
+
+	encoder, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, fmt.Errorf("creating zstd encoder: %w", err)
+	}
+	writer := &Writer{encoder: encoder}
+
+	encodedHeader, err := scale.Marshal(*header)
+	if err != nil {
+		return nil, fmt.Errorf("encoding block header: %w", err)
+	}
+	err = writer.writeRecord(kindHeader, encodedHeader)
+	if err != nil {
+		return nil, fmt.Errorf("writing block header: %w", err)
+	}
+
+	encodedConsensus, err := scale.Marshal(*consensus)
+	if err != nil {
+		return nil, fmt.Errorf("encoding consensus data: %w", err)
+	}
+	err = writer.writeRecord(kindConsensus, encodedConsensus)
+	if err != nil {
+		return nil, fmt.Errorf("writing consensus data: %w", err)
+	}
+
+	return writer, nil
+}
+
+// Write appends the entry with the given key and value of the state trie, or of the child trie
+// with the given child storage key if it is not empty. The entries of the state trie must be
+// written first, and the entries of each trie in the order of their keys.
+func (w *Writer) Write(childKey, key, value []byte) error {
+	if !bytes.Equal(childKey, w.batch.ChildKey) {
+		err := w.flush()
+		if err != nil {
+			return err
+		}
+		w.batch.ChildKey = childKey
+	}
+
+	w.batch.Entries = append(w.batch.Entries, entry{Key: key, Value: value})
+	w.size += len(key) + len(value)
+	w.count++
+
+	if w.size < batchSize {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *Writer) flush() error {
+	if len(w.batch.Entries) == 0 {
+		return nil
+	}
+
+	encoded, err := scale.Marshal(w.batch)
+	if err != nil {
+		return fmt.Errorf("encoding entries: %w", err)
+	}
+	err = w.writeRecord(kindEntries, encoded)
+	if err != nil {
+		return fmt.Errorf("writing entries: %w", err)
+	}
+
+	w.batch.Entries = w.batch.Entries[:0]
+	w.size = 0
+	return nil
+}
+
+func (w *Writer) writeRecord(kind byte, payload []byte) error {
+	record := make([]byte, 1, len(payload)+recordOverhead)
+	record[0] = kind
+	record = append(record, payload...)
+	record = binary.LittleEndian.AppendUint32(record, crc32.Checksum(record, crcTable))
+
+	return blockarchive.WriteRecord(w.encoder, record)
+}
+
+// Close writes the remaining entries and the end record, and flushes the compressed stream.
+// It does not close the underlying writer.
+func (w *Writer) Close() error {
+	err := w.flush()
+	if err != nil {
+		return err
+	}
+
+	err = w.writeRecord(kindEnd, binary.LittleEndian.AppendUint64(nil, w.count))
+	if err != nil {
+		return fmt.Errorf("writing end record: %w", err)
+	}
+
+	return w.encoder.Close()
+}
+
+// Reader reads a state snapshot.
+type Reader struct {
+	decoder *zstd.Decoder
+	batch   entries
+	next    int
+	count   uint64
+
+	// GenesisHash is the genesis hash of the chain of the block
+	GenesisHash common.Hash
+	// StateVersion is the version of the state trie of the block
+	StateVersion trie.TrieLayout
+	// Header is the header of the block
+	Header *types.Header
+	// Consensus is the BABE and GRANDPA data at the block
+	Consensus *Consensus
+}
+
+// NewReader reads the snapshot header, the block header and the consensus data from r and returns
+// a Reader for the state entries of the block. Close must be called once the reader is not used anymore.
+func NewReader(r io.Reader) (*Reader, error) {
+	fileHeader := make([]byte, len(magic)+2+len(common.Hash{}))
+	_, err := io.ReadFull(r, fileHeader)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot header: %w", err)
+	}
+
+	if [8]byte(fileHeader[:len(magic)]) != magic {
+		return nil, ErrInvalidMagic
+	}
+
+	version := fileHeader[len(magic)]
+	if version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	stateVersion, err := trie.ParseVersion(fileHeader[len(magic)+1])
+	if err != nil {
+		return nil, fmt.Errorf("parsing state version: %w", err)
+	}
+
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("creating zstd decoder: %w", err)
+	}
+
+	reader := &Reader{
+		decoder:      decoder,
+		GenesisHash:  common.NewHash(fileHeader[len(magic)+2:]),
+		StateVersion: stateVersion,
+		Header:       types.NewEmptyHeader(),
+		Consensus:    new(Consensus),
+	}
+
+	err = reader.readRecordInto(kindHeader, reader.Header)
+	if err != nil {
+		decoder.Close()
+		return nil, fmt.Errorf("reading block header: %w", err)
+	}
+
+	err = reader.readRecordInto(kindConsensus, reader.Consensus)
+	if err != nil {
+		decoder.Close()
+		return nil, fmt.Errorf("reading consensus data: %w", err)
+	}
+
+	return reader, nil
+}
+
+// Read returns the next state entry of the snapshot, or io.EOF once all the entries were read
+// and their number checked against the end record.
+func (r *Reader) Read() (*Entry, error) {
+	for r.next == len(r.batch.Entries) {
+		kind, payload, err := r.readRecord()
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case kindEntries:
+			r.batch = entries{}
+			err = scale.Unmarshal(payload, &r.batch)
+			if err != nil {
+				return nil, fmt.Errorf("decoding entries: %w", err)
+			}
+			if len(r.batch.ChildKey) == 0 {
+				r.batch.ChildKey = nil
+			}
+			r.next = 0
+		case kindEnd:
+			if len(payload) != 8 {
+				return nil, fmt.Errorf("%w: end record of %d bytes", ErrUnexpectedRecord, len(payload))
+			}
+			count := binary.LittleEndian.Uint64(payload)
+			if count != r.count {
+				return nil, fmt.Errorf("%w: %d entries read, %d expected", ErrEntryCountMismatch, r.count, count)
+			}
+			return nil, io.EOF
+		default:
+			return nil, fmt.Errorf("%w: kind %d", ErrUnexpectedRecord, kind)
+		}
+	}
+
+	e := r.batch.Entries[r.next]
+	r.next++
+	r.count++
+	return &Entry{ChildKey: r.batch.ChildKey, Key: e.Key, Value: e.Value}, nil
+}
+
+func (r *Reader) readRecordInto(kind byte, dst any) error {
+	recordKind, payload, err := r.readRecord()
+	if err != nil {
+		return err
+	}
+	if recordKind != kind {
+		return fmt.Errorf("%w: kind %d instead of %d", ErrUnexpectedRecord, recordKind, kind)
+	}
+	return scale.Unmarshal(payload, dst)
+}
+
+func (r *Reader) readRecord() (kind byte, payload []byte, err error) {
+	record, err := blockarchive.ReadRecord(r.decoder)
+	if err != nil {
+		// the snapshot ends with the end record, any end of file before it is a truncation
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, fmt.Errorf("reading record: %w", err)
+	}
+
+	if len(record) < recordOverhead {
+		return 0, nil, fmt.Errorf("%w: record of %d bytes", ErrUnexpectedRecord, len(record))
+	}
+
+	checksumOffset := len(record) - 4
+	checksum := binary.LittleEndian.Uint32(record[checksumOffset:])
+	if crc32.Checksum(record[:checksumOffset], crcTable) != checksum {
+		return 0, nil, fmt.Errorf("%w: record of kind %d", ErrChecksumMismatch, record[0])
+	}
+
+	return record[0], record[1:checksumOffset], nil
+}
+
+// Close releases the resources of the decompressor, it does not close the underlying reader.
+func (r *Reader) Close() {
+	r.decoder.Close()
+}
@@ -0,0 +1,187 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/blockarchive"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHeader(t *testing.T) *types.Header {
+	t.Helper()
+
+	digest := types.NewDigest()
+	prd, err := types.NewBabeSecondaryPlainPreDigest(0, 10).ToPreRuntimeDigest()
+	require.NoError(t, err)
+	require.NoError(t, digest.Add(*prd))
+	return types.NewHeader(common.Hash{1}, common.Hash{2}, common.Hash{3}, 10, digest)
+}
+
+func newTestConsensus() *Consensus {
+	return &Consensus{
+		FirstSlot: 1,
+		Epoch:     2,
+		EpochData: types.EpochDataRaw{
+			Authorities: []types.AuthorityRaw{{Key: [32]byte{1}, Weight: 1}},
+			Randomness:  [32]byte{2},
+		},
+		ConfigData: types.ConfigData{C1: 1, C2: 4, SecondarySlots: 1},
+		NextEpochData: types.EpochDataRaw{
+			Authorities: []types.AuthorityRaw{{Key: [32]byte{3}, Weight: 1}},
+			Randomness:  [32]byte{4},
+		},
+		NextConfigData: types.ConfigData{C1: 1, C2: 4, SecondarySlots: 2},
+		Round:          5,
+		SetID:          1,
+		SetIDChange:    8,
+		Authorities:    []types.GrandpaAuthoritiesRaw{{Key: [32]byte{5}, ID: 1}},
+	}
+}
+
+func newTestEntries(count int) []Entry {
+	entries := make([]Entry, 0, count)
+	for i := 0; i < count; i++ {
+		var childKey []byte
+		if i >= count/2 {
+			childKey = []byte("child")
+		}
+		entries = append(entries, Entry{
+			ChildKey: childKey,
+			Key:      []byte(fmt.Sprintf("key%06d", i)),
+			// the values are large enough for the entries to be written in several batches
+			Value: bytes.Repeat([]byte{byte(i)}, 1000),
+		})
+	}
+	return entries
+}
+
+func TestSnapshot(t *testing.T) {
+	genesisHash := common.Hash{9}
+	header := newTestHeader(t)
+	consensus := newTestConsensus()
+	entries := newTestEntries(3000)
+
+	buf := new(bytes.Buffer)
+	writer, err := NewWriter(buf, genesisHash, trie.V1, header, consensus)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.NoError(t, writer.Write(entry.ChildKey, entry.Key, entry.Value))
+	}
+	require.NoError(t, writer.Close())
+
+	reader, err := NewReader(buf)
+	require.NoError(t, err)
+	defer reader.Close()
+	require.Equal(t, genesisHash, reader.GenesisHash)
+	require.Equal(t, trie.V1, reader.StateVersion)
+	require.Equal(t, header.Hash(), reader.Header.Hash())
+	require.Equal(t, consensus, reader.Consensus)
+
+	for _, expected := range entries {
+		entry, err := reader.Read()
+		require.NoError(t, err)
+		require.Equal(t, expected, *entry)
+	}
+
+	_, err = reader.Read()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestSnapshot_Errors(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("GSSMRSNX")))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	fileHeader := append([]byte("GSSMRSNX"), make([]byte, 34)...)
+	_, err = NewReader(bytes.NewReader(fileHeader))
+	require.ErrorIs(t, err, ErrInvalidMagic)
+
+	fileHeader = append([]byte("GSSMRSNP"), make([]byte, 34)...)
+	fileHeader[8] = 2
+	_, err = NewReader(bytes.NewReader(fileHeader))
+	require.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	fileHeader[8] = Version
+	fileHeader[9] = 2
+	_, err = NewReader(bytes.NewReader(fileHeader))
+	require.ErrorIs(t, err, trie.ErrParseVersion)
+
+	header := newTestHeader(t)
+	consensus := newTestConsensus()
+
+	testCases := map[string]struct {
+		write       func(t *testing.T, writer *Writer)
+		expectedErr error
+	}{
+		"truncated": {
+			write: func(t *testing.T, writer *Writer) {
+				require.NoError(t, writer.Write(nil, []byte("key"), []byte("value")))
+				require.NoError(t, writer.flush())
+			},
+			expectedErr: io.ErrUnexpectedEOF,
+		},
+		"entry_count_mismatch": {
+			write: func(t *testing.T, writer *Writer) {
+				require.NoError(t, writer.Write(nil, []byte("key"), []byte("value")))
+				writer.count++
+				require.NoError(t, writer.flush())
+				require.NoError(t, writer.writeRecord(kindEnd, binary.LittleEndian.AppendUint64(nil, writer.count)))
+			},
+			expectedErr: ErrEntryCountMismatch,
+		},
+		"checksum_mismatch": {
+			write: func(t *testing.T, writer *Writer) {
+				record := []byte{kindEnd, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+				require.NoError(t, blockarchive.WriteRecord(writer.encoder, record))
+			},
+			expectedErr: ErrChecksumMismatch,
+		},
+		"unexpected_record": {
+			write: func(t *testing.T, writer *Writer) {
+				require.NoError(t, writer.writeRecord(kindHeader, nil))
+			},
+			expectedErr: ErrUnexpectedRecord,
+		},
+		"record_too_short": {
+			write: func(t *testing.T, writer *Writer) {
+				require.NoError(t, blockarchive.WriteRecord(writer.encoder, []byte{kindEnd}))
+			},
+			expectedErr: ErrUnexpectedRecord,
+		},
+		"record_too_large": {
+			write: func(t *testing.T, writer *Writer) {
+				_, err := writer.encoder.Write([]byte{0xff, 0xff, 0xff, 0xff})
+				require.NoError(t, err)
+			},
+			expectedErr: blockarchive.ErrRecordTooLarge,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			writer, err := NewWriter(buf, common.Hash{}, trie.V0, header, consensus)
+			require.NoError(t, err)
+			testCase.write(t, writer)
+			require.NoError(t, writer.encoder.Close())
+
+			reader, err := NewReader(buf)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			for err == nil {
+				_, err = reader.Read()
+			}
+			require.ErrorIs(t, err, testCase.expectedErr)
+		})
+	}
+}
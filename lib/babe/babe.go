@@ -15,6 +15,7 @@
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
+	"github.com/benbjohnson/clock"
 
 	ethmetrics "github.com/ethereum/go-ethereum/metrics"
 )
@@ -62,6 +63,8 @@ type ServiceConfig struct {
 	IsDev              bool
 	Authority          bool
 	Telemetry          Telemetry
+	// Clock is the time source of the slots, the system clock if nil
+	Clock clock.Clock
 }
 
 // Validate returns error if config does not contain required attributes
@@ -89,6 +92,11 @@ func (Builder) NewServiceIFace(cfg *ServiceConfig) (service *Service, err error)
 		return nil, fmt.Errorf("cannot get slot duration: %w", err)
 	}
 
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.New()
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	babeService := &Service{
@@ -106,6 +114,7 @@ func (Builder) NewServiceIFace(cfg *ServiceConfig) (service *Service, err error)
 		constants: constants{
 			slotDuration: slotDuration,
 			epochLength:  cfg.EpochState.GetEpochLength(),
+			clock:        clk,
 		},
 		telemetry: cfg.Telemetry,
 	}
@@ -131,6 +140,11 @@ func NewService(cfg *ServiceConfig) (*Service, error) {
 		return nil, fmt.Errorf("cannot get slot duration: %w", err)
 	}
 
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.New()
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	babeService := &Service{
@@ -148,6 +162,7 @@ func NewService(cfg *ServiceConfig) (*Service, error) {
 		constants: constants{
 			slotDuration: slotDuration,
 			epochLength:  cfg.EpochState.GetEpochLength(),
+			clock:        clk,
 		},
 		telemetry: cfg.Telemetry,
 	}
@@ -337,7 +352,7 @@ func (b *Service) handleEpoch(epoch uint64) (next uint64, err error) {
 
 	nextEpochStarts := b.epochHandler.descriptor.endSlot
 	nextEpochStartTime := getSlotStartTime(nextEpochStarts, b.constants.slotDuration)
-	epochTimer := time.NewTimer(time.Until(nextEpochStartTime))
+	epochTimer := b.constants.clock.Timer(b.constants.clock.Until(nextEpochStartTime))
 
 	errCh := make(chan error, 1)
 	wg.Add(1)
@@ -472,8 +487,9 @@ func (b *Service) handleSlot(epoch uint64, slot Slot,
 	return nil
 }
 
-func getCurrentSlot(slotDuration time.Duration) uint64 {
-	return uint64(time.Now().UnixNano()) / uint64(slotDuration.Nanoseconds()) //nolint:gosec
+// slotAt returns the number of the slot at the given time
+func slotAt(t time.Time, slotDuration time.Duration) uint64 {
+	return uint64(t.UnixNano()) / uint64(slotDuration.Nanoseconds()) //nolint:gosec
 }
 
 func getSlotStartTime(slot uint64, slotDuration time.Duration) time.Time {
@@ -13,6 +13,7 @@
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 
+	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -42,6 +43,7 @@ func TestService_SlotDuration(t *testing.T) {
 	bs := &Service{
 		constants: constants{
 			slotDuration: duration,
+			clock:        clock.New(),
 		},
 	}
 
@@ -15,6 +15,7 @@
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 	"github.com/ChainSafe/gossamer/lib/transaction"
 	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/benbjohnson/clock"
 	ethmetrics "github.com/ethereum/go-ethereum/metrics"
 )
 
@@ -33,6 +34,7 @@ func (b *Service) buildBlock(parent *types.Header, slot Slot, rt Runtime,
 		authorityIndex,
 		preRuntimeDigest,
 	)
+	builder.clock = b.constants.clock
 
 	// is necessary to enable ethmetrics to be possible register values
 	ethmetrics.Enabled = true
@@ -57,6 +59,7 @@ type BlockBuilder struct {
 	blockState            BlockState
 	currentAuthorityIndex uint32
 	preRuntimeDigest      *types.PreRuntimeDigest
+	clock                 clock.Clock
 }
 
 // NewBlockBuilder creates a new block builder.
@@ -73,6 +76,7 @@ func NewBlockBuilder(
 		blockState:            bs,
 		currentAuthorityIndex: authidx,
 		preRuntimeDigest:      preRuntimeDigest,
+		clock:                 clock.New(),
 	}
 }
 
@@ -176,7 +180,7 @@ func (b *BlockBuilder) buildBlockExtrinsics(slot Slot, rt ExtrinsicHandler) []*t
 
 	slotEnd := slot.start.Add(slot.duration * 2 / 3) // reserve last 1/3 of slot for block finalisation
 	timeout := slotEnd.Sub(slot.start)               // timeout relative to the slot start
-	slotTimer := time.NewTimer(timeout)
+	slotTimer := b.clock.Timer(timeout)
 
 	for {
 		txn := b.transactionState.PopWithTimer(slotTimer.C)
@@ -172,7 +172,7 @@ func (b *Service) buildEpochData(currEpochData *types.EpochDataRaw,
 }
 
 func (b *Service) getFirstAuthoringSlot(epoch uint64, epochData *epochData) (uint64, error) {
-	startSlot := getCurrentSlot(b.constants.slotDuration)
+	startSlot := slotAt(b.constants.clock.Now(), b.constants.slotDuration)
 	for i := startSlot; i < startSlot+b.constants.epochLength; i++ {
 		_, err := claimSlot(epoch, i, epochData, b.keypair)
 		if errors.Is(err, errOverPrimarySlotThreshold) || errors.Is(err, errNotOurTurnToPropose) {
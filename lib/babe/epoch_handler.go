@@ -47,7 +47,7 @@ func newEpochHandler(epochDescriptor *epochDescriptor, constants constants,
 	}
 
 	return &epochHandler{
-		slotHandler:            newSlotHandler(constants.slotDuration),
+		slotHandler:            newSlotHandler(constants.slotDuration, constants.clock),
 		descriptor:             epochDescriptor,
 		constants:              constants,
 		handleSlot:             handleSlot,
@@ -59,7 +59,7 @@ func newEpochHandler(epochDescriptor *epochDescriptor, constants constants,
 // it is important to note that any error will be transmitted through errCh
 func (h *epochHandler) run(ctx context.Context, errCh chan<- error) {
 	defer close(errCh)
-	currSlot := getCurrentSlot(h.constants.slotDuration)
+	currSlot := slotAt(h.constants.clock.Now(), h.constants.slotDuration)
 
 	// if currSlot < h.firstSlot, it means we're at genesis and waiting for the first slot to arrive.
 	// we have to check it here to prevent int overflow.
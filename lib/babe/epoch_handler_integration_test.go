@@ -13,6 +13,7 @@
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -38,10 +39,11 @@ func TestEpochHandler_run_shouldReturnAfterContextCancel(t *testing.T) {
 	testConstants := constants{
 		slotDuration: slotDuration,
 		epochLength:  epochLength,
+		clock:        clock.New(),
 	}
 
 	const expectedEpoch = 1
-	startSlot := getCurrentSlot(slotDuration)
+	startSlot := slotAt(time.Now(), slotDuration)
 	handler := testHandleSlotFunc(t, authorityIndex, expectedEpoch, startSlot)
 
 	epochDescriptor := &epochDescriptor{
@@ -90,10 +92,11 @@ func TestEpochHandler_run(t *testing.T) {
 	testConstants := constants{
 		slotDuration: slotDuration,
 		epochLength:  epochLength,
+		clock:        clock.New(),
 	}
 
 	const expectedEpoch = 1
-	startSlot := getCurrentSlot(slotDuration)
+	startSlot := slotAt(time.Now(), slotDuration)
 	handler := testHandleSlotFunc(t, authorityIndex, expectedEpoch, startSlot)
 
 	epochDescriptor := &epochDescriptor{
@@ -11,6 +11,7 @@
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 
+	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,6 +32,7 @@ func TestNewEpochHandler(t *testing.T) {
 	testConstants := constants{
 		slotDuration: sd,
 		epochLength:  200,
+		clock:        clock.New(),
 	}
 
 	keypair := keyring.Alice().(*sr25519.Keypair)
@@ -7,12 +7,14 @@
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/benbjohnson/clock"
 )
 
-// timeUntilNextSlot calculates, based on the current system time, the remainng
+// timeUntilNextSlot calculates, based on the given current time, the remainng
 // time to the next slot
-func timeUntilNextSlot(slotDuration time.Duration) time.Duration {
-	now := time.Now().UnixNano()
+func timeUntilNextSlot(currentTime time.Time, slotDuration time.Duration) time.Duration {
+	now := currentTime.UnixNano()
 	slotDurationInNano := slotDuration.Nanoseconds()
 
 	nextSlot := (now + slotDurationInNano) / slotDurationInNano
@@ -23,31 +25,33 @@ func timeUntilNextSlot(slotDuration time.Duration) time.Duration {
 
 type slotHandler struct {
 	slotDuration time.Duration
+	clock        clock.Clock
 	lastSlot     *Slot
 }
 
-func newSlotHandler(slotDuration time.Duration) slotHandler {
+func newSlotHandler(slotDuration time.Duration, clk clock.Clock) slotHandler {
 	return slotHandler{
 		slotDuration: slotDuration,
+		clock:        clk,
 	}
 }
 
 // waitForNextSlot returns a new Slot greater than the last one when a new slot starts
-// based on the current system time similar to:
+// based on the time of the clock similar to:
 // https://github.com/paritytech/substrate/blob/fbddfbd76c60c6fda0024e8a44e82ad776033e4b/client/consensus/slots/src/slots.rs#L125
 func (s *slotHandler) waitForNextSlot(ctx context.Context) (Slot, error) {
 	for {
 		// check if there is enough time to collaborate
-		untilNextSlot := timeUntilNextSlot(s.slotDuration)
+		untilNextSlot := timeUntilNextSlot(s.clock.Now(), s.slotDuration)
 		oneThirdSlotDuration := s.slotDuration / 3
 		if untilNextSlot <= oneThirdSlotDuration {
-			err := waitUntilNextSlot(ctx, untilNextSlot)
+			err := waitUntilNextSlot(ctx, s.clock, untilNextSlot)
 			if err != nil {
 				return Slot{}, fmt.Errorf("waiting next slot: %w", err)
 			}
 		}
 
-		currentSystemTime := time.Now()
+		currentSystemTime := s.clock.Now()
 		currentSlotNumber := uint64(currentSystemTime.UnixNano()) / uint64(s.slotDuration.Nanoseconds()) //nolint:gosec
 		currentSlot := Slot{
 			start:    currentSystemTime,
@@ -61,18 +65,18 @@ func (s *slotHandler) waitForNextSlot(ctx context.Context) (Slot, error) {
 			return currentSlot, nil
 		}
 
-		err := waitUntilNextSlot(ctx, untilNextSlot)
+		err := waitUntilNextSlot(ctx, s.clock, untilNextSlot)
 		if err != nil {
 			return Slot{}, fmt.Errorf("waiting next slot: %w", err)
 		}
 	}
 }
 
-// waitUntilNextSlot is a blocking function that uses context.WithTimeout
+// waitUntilNextSlot is a blocking function that uses the clock WithTimeout
 // to "sleep", however if the parent context is canceled it releases with
 // context.Canceled error
-func waitUntilNextSlot(ctx context.Context, untilNextSlot time.Duration) error {
-	withTimeout, cancelWithTimeout := context.WithTimeout(ctx, untilNextSlot)
+func waitUntilNextSlot(ctx context.Context, clk clock.Clock, untilNextSlot time.Duration) error {
+	withTimeout, cancelWithTimeout := clk.WithTimeout(ctx, untilNextSlot)
 	defer cancelWithTimeout()
 
 	<-withTimeout.Done()
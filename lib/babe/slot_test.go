@@ -8,17 +8,20 @@
 	"testing"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/require"
 )
 
 func TestSlotHandlerConstructor(t *testing.T) {
 	t.Parallel()
 
+	clk := clock.New()
 	expected := slotHandler{
 		slotDuration: time.Duration(6000),
+		clock:        clk,
 	}
 
-	handler := newSlotHandler(time.Duration(6000))
+	handler := newSlotHandler(time.Duration(6000), clk)
 	require.Equal(t, expected, handler)
 }
 
@@ -26,7 +29,7 @@ func TestSlotHandlerNextSlot(t *testing.T) {
 	t.Parallel()
 
 	const slotDuration = 2 * time.Second
-	handler := newSlotHandler(slotDuration)
+	handler := newSlotHandler(slotDuration, clock.New())
 
 	firstIteration, err := handler.waitForNextSlot(context.Background())
 	require.NoError(t, err)
@@ -41,7 +44,7 @@ func TestSlotHandlerNextSlot_ContextCanceled(t *testing.T) {
 	t.Parallel()
 
 	const slotDuration = 2 * time.Second
-	handler := newSlotHandler(slotDuration)
+	handler := newSlotHandler(slotDuration, clock.New())
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -56,3 +59,28 @@ func TestSlotHandlerNextSlot_ContextCanceled(t *testing.T) {
 	require.ErrorIs(t, err, context.Canceled)
 	require.EqualError(t, err, "waiting next slot: context canceled")
 }
+
+func TestSlotHandlerNextSlot_MockClock(t *testing.T) {
+	t.Parallel()
+
+	const slotDuration = 6 * time.Second
+	clk := clock.NewMock()
+	clk.Set(time.Unix(600, 0))
+	handler := newSlotHandler(slotDuration, clk)
+
+	firstIteration, err := handler.waitForNextSlot(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), firstIteration.number)
+	require.Equal(t, time.Unix(600, 0), firstIteration.start)
+
+	secondIteration := make(chan Slot)
+	go func() {
+		slot, err := handler.waitForNextSlot(context.Background())
+		require.NoError(t, err)
+		secondIteration <- slot
+	}()
+
+	// the next slot is only yielded once the clock reaches it
+	clk.Add(slotDuration)
+	require.Equal(t, uint64(101), (<-secondIteration).number)
+}
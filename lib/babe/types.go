@@ -10,6 +10,7 @@
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/benbjohnson/clock"
 )
 
 // Randomness is an alias for a byte array with length types.RandomnessLength
@@ -75,4 +76,6 @@ func (ed *epochData) String() string {
 type constants struct {
 	slotDuration time.Duration
 	epochLength  uint64
+	// clock is the time source the slots are derived from
+	clock clock.Clock
 }
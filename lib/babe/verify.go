@@ -13,6 +13,7 @@
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/benbjohnson/clock"
 )
 
 var errEmptyKeyOwnershipProof = errors.New("key ownership proof is nil")
@@ -46,6 +47,8 @@ type VerificationManager struct {
 	// branches of the chain, so we need to keep track of all of them.
 	// map of epoch number -> block producer index -> block number and hash
 	onDisabled map[uint64]map[uint32][]*onDisabledInfo
+	// clock is the time source of the current slot, the system clock by default
+	clock clock.Clock
 }
 
 // NewVerificationManager returns a new NewVerificationManager
@@ -56,9 +59,15 @@ func NewVerificationManager(blockState BlockState, slotState SlotState, epochSta
 		blockState: blockState,
 		epochInfo:  make(map[uint64]*verifierInfo),
 		onDisabled: make(map[uint64]map[uint32][]*onDisabledInfo),
+		clock:      clock.New(),
 	}
 }
 
+// SetClock sets the time source of the current slot, which must be the one of the BABE slots.
+func (v *VerificationManager) SetClock(clk clock.Clock) {
+	v.clock = clk
+}
+
 // SetOnDisabled sets the BABE authority with the given index as disabled for the rest of the epoch
 func (v *VerificationManager) SetOnDisabled(index uint32, header *types.Header) error {
 	epoch, err := v.epochState.GetEpochForBlock(header)
@@ -133,6 +142,20 @@ func (v *VerificationManager) VerifyBlock(header *types.Header) error {
 		return fmt.Errorf("getting header: %w", err)
 	}
 
+	return v.verifyBlock(header, parentHeader, header)
+}
+
+// VerifyBlockAhead verifies that the block producer for the given block was authorized to produce it,
+// before its parent is imported. The parent header is given instead of being read from the block state,
+// and the epoch data kept in memory are looked up from the imported ancestor, so no block between the
+// ancestor and the header should contain a BABE consensus digest.
+func (v *VerificationManager) VerifyBlockAhead(header, parentHeader, importedAncestor *types.Header) error {
+	return v.verifyBlock(header, parentHeader, importedAncestor)
+}
+
+// verifyBlock verifies the block, epochDataHeader being the header used to find
+// the epoch data of the fork the block belongs to.
+func (v *VerificationManager) verifyBlock(header, parentHeader, epochDataHeader *types.Header) error {
 	currentBlockEpoch, err := v.epochState.GetEpochForBlock(header)
 	if err != nil {
 		return fmt.Errorf("getting epoch for block header: %w", err)
@@ -160,12 +183,13 @@ func (v *VerificationManager) VerifyBlock(header *types.Header) error {
 		return fmt.Errorf("getting current slot duration: %w", err)
 	}
 
-	info, err := v.getVerifierInfo(epochWhereDataDescriptorIs, header)
+	info, err := v.getVerifierInfo(epochWhereDataDescriptorIs, epochDataHeader)
 	if err != nil {
 		return fmt.Errorf("getting verifier info: %w", err)
 	}
 
 	verifier := newVerifier(v.blockState, v.slotState, currentBlockEpoch, info, slotDuration)
+	verifier.clock = v.clock
 	return verifier.verifyAuthorshipRight(header)
 }
 
@@ -203,6 +227,7 @@ type verifier struct {
 	threshold      *scale.Uint128
 	secondarySlots bool
 	slotDuration   time.Duration
+	clock          clock.Clock
 }
 
 // newVerifier returns a Verifier for the epoch described by the given descriptor
@@ -217,6 +242,7 @@ func newVerifier(blockState BlockState, slotState SlotState,
 		threshold:      info.threshold,
 		secondarySlots: info.secondarySlots,
 		slotDuration:   slotDuration,
+		clock:          clock.New(),
 	}
 }
 
@@ -374,7 +400,7 @@ func (b *verifier) verifyBlockEquivocation(header *types.Header) (bool, error) {
 		return false, nil
 	}
 
-	slotNow := getCurrentSlot(b.slotDuration)
+	slotNow := slotAt(b.clock.Now(), b.slotDuration)
 	signer := b.authorities[authorityIndex].Key
 	equivocationProof, err := b.slotState.CheckEquivocation(slotNow, slotNumber,
 		header, signer)
@@ -234,6 +234,35 @@ func TestVerificationManager_VerifyBlock_CurrentEpoch(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestVerificationManager_VerifyBlockAhead(t *testing.T) {
+	t.Parallel()
+	genesis, genesisTrie, genesisHeader := newWestendDevGenesisWithTrieAndHeader(t)
+	babeService := createTestService(t, ServiceConfig{}, genesis, genesisTrie, genesisHeader, AuthorOnEverySlotBABEConfig)
+
+	db, err := database.NewPebble(t.TempDir(), true)
+	require.NoError(t, err)
+	slotState := state.NewSlotState(db)
+
+	vm := NewVerificationManager(babeService.blockState, slotState, babeService.epochState)
+
+	epochDescriptor, err := babeService.initiateEpoch(0)
+	require.NoError(t, err)
+
+	slot := Slot{
+		start:    getSlotStartTime(epochDescriptor.startSlot, babeService.constants.slotDuration),
+		duration: babeService.constants.slotDuration,
+		number:   epochDescriptor.startSlot,
+	}
+	block := createTestBlockWithSlot(t, babeService, &genesisHeader, [][]byte{}, epochDescriptor, slot)
+
+	err = vm.VerifyBlockAhead(&block.Header, &genesisHeader, &genesisHeader)
+	require.NoError(t, err)
+
+	block.Header.Digest = block.Header.Digest[:1]
+	err = vm.VerifyBlockAhead(&block.Header, &genesisHeader, &genesisHeader)
+	require.ErrorIs(t, err, errMissingDigestItems)
+}
+
 func TestVerificationManager_VerifyBlock_FutureEpoch(t *testing.T) {
 	t.Skip("TODO: move this test under TestVerificationManager_VerifyBlock_MultipleEpochs")
 
@@ -566,7 +595,7 @@ func TestVerifyAuthorshipRight_Equivocation(t *testing.T) {
 	// https://github.com/paritytech/substrate/blob/09de7b41599add51cf27eca8f1bc4c50ed8e9453/frame/timestamp/src/lib.rs#L206
 
 	const slotDuration = 6 * time.Second
-	slotNumber := getCurrentSlot(slotDuration)
+	slotNumber := slotAt(time.Now(), slotDuration)
 	startTime := getSlotStartTime(slotNumber, slotDuration)
 	slot := NewSlot(startTime, slotDuration, slotNumber)
 
@@ -15,6 +15,7 @@
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -772,6 +773,7 @@ func Test_verifyBlockEquivocation(t *testing.T) {
 					blockState:   mockBlockState,
 					slotState:    mockSlotState,
 					slotDuration: 6 * time.Second,
+					clock:        clock.NewMock(),
 				}
 			},
 		},
@@ -787,10 +789,14 @@ func Test_verifyBlockEquivocation(t *testing.T) {
 				mockSlotState := NewMockSlotState(ctrl)
 				mockSlotState.
 					EXPECT().
-					CheckEquivocation(gomock.Any(), uint64(1),
+					CheckEquivocation(uint64(10), uint64(1),
 						defaultHeader, expectedAuthorityId).
 					Return(nil, nil)
 
+				// the current slot is the slot of the verifier clock
+				mockClock := clock.NewMock()
+				mockClock.Set(time.Unix(60, 0))
+
 				return &verifier{
 					authorities: []types.AuthorityRaw{
 						{
@@ -801,6 +807,7 @@ func Test_verifyBlockEquivocation(t *testing.T) {
 					blockState:   mockBlockState,
 					slotState:    mockSlotState,
 					slotDuration: 6 * time.Second,
+					clock:        mockClock,
 				}
 			},
 		},
@@ -857,6 +864,7 @@ func Test_verifyBlockEquivocation(t *testing.T) {
 					blockState:   mockBlockState,
 					slotState:    mockSlotState,
 					slotDuration: 6 * time.Second,
+					clock:        clock.NewMock(),
 				}
 			},
 		},
@@ -902,6 +910,7 @@ func Test_verifyBlockEquivocation(t *testing.T) {
 					blockState:   mockBlockState,
 					slotState:    mockSlotState,
 					slotDuration: 6 * time.Second,
+					clock:        clock.NewMock(),
 				}
 			},
 		},
@@ -306,6 +306,23 @@ func (bt *BlockTree) Prune(finalised Hash) (pruned []Hash) {
 	return pruned
 }
 
+// Revert removes the blocks with a number greater than the given number, along with their
+// runtime instances, and returns the hashes of the removed blocks.
+// The number must not be lower than the number of the root.
+func (bt *BlockTree) Revert(number uint) (reverted []Hash) {
+	bt.Lock()
+	defer bt.Unlock()
+
+	reverted = bt.root.revert(number, nil)
+	for _, hash := range reverted {
+		bt.runtimes.revert(hash)
+	}
+
+	bt.leaves = newLeafMap(bt.root)
+	leavesGauge.Set(float64(len(bt.leaves.nodes())))
+	return reverted
+}
+
 // String utilises github.com/disiqueira/gotree to create a printable tree
 func (bt *BlockTree) String() string {
 	bt.RLock()
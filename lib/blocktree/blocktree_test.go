@@ -778,6 +778,49 @@ func Test_BlockTree_Prune(t *testing.T) {
 	})
 }
 
+func Test_BlockTree_Revert(t *testing.T) {
+	t.Parallel()
+
+	// {0x00} -> {0x01} -> {0x02} -> {0x03}
+	//                  -> {0x04} (#100) -> {0x05} (#101)
+	blockTree := buildLinearBlockTree(t, 4)
+	appendForksAt(t, blockTree, common.MustHexToHash("0x01"),
+		common.MustHexToHash("0x04"),
+		common.MustHexToHash("0x05"))
+
+	ctrl := gomock.NewController(t)
+	rootRuntime := NewMockInstance(ctrl)
+	sharedRuntime := NewMockInstance(ctrl)
+	forkedRuntime := NewMockInstance(ctrl)
+	forkedRuntime.EXPECT().Stop()
+	blockTree.runtimes.set(common.MustHexToHash("0x00"), rootRuntime)
+	blockTree.runtimes.set(common.MustHexToHash("0x02"), sharedRuntime)
+	blockTree.runtimes.set(common.MustHexToHash("0x03"), sharedRuntime)
+	blockTree.runtimes.set(common.MustHexToHash("0x05"), forkedRuntime)
+
+	reverted := blockTree.Revert(2)
+	assert.ElementsMatch(t, []common.Hash{
+		common.MustHexToHash("0x03"),
+		common.MustHexToHash("0x04"),
+		common.MustHexToHash("0x05"),
+	}, reverted)
+
+	assert.Equal(t, common.MustHexToHash("0x02"), blockTree.BestBlockHash())
+	assert.Equal(t, []common.Hash{common.MustHexToHash("0x02")}, blockTree.Leaves())
+	assert.Nil(t, blockTree.getNode(common.MustHexToHash("0x04")))
+
+	expectedHashToRuntime := &hashToRuntime{
+		mapping: map[common.Hash]runtime.Instance{
+			common.MustHexToHash("0x00"): rootRuntime,
+			common.MustHexToHash("0x02"): sharedRuntime,
+		},
+	}
+	assert.Equal(t, expectedHashToRuntime, blockTree.runtimes)
+
+	reverted = blockTree.Revert(2)
+	assert.Empty(t, reverted)
+}
+
 func Test_BlockTree_GetHashByNumber(t *testing.T) {
 	bt, _ := createTestBlockTree(t, testHeader, 8)
 	best := bt.BestBlockHash()
@@ -42,6 +42,27 @@ func (h *hashToRuntime) delete(hash Hash) {
 	inMemoryRuntimesGauge.Dec()
 }
 
+// revert removes the runtime instance of the reverted block, stopping it
+// unless it is also the instance of another block.
+func (h *hashToRuntime) revert(hash Hash) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	instance, ok := h.mapping[hash]
+	if !ok {
+		return
+	}
+	delete(h.mapping, hash)
+	inMemoryRuntimesGauge.Dec()
+
+	for _, other := range h.mapping {
+		if other == instance {
+			return
+		}
+	}
+	instance.Stop()
+}
+
 func (h *hashToRuntime) hashes() (hashes []common.Hash) {
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
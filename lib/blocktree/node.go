@@ -191,6 +191,22 @@ func (n *node) prune(finalised *node, pruned []Hash) []Hash {
 	return pruned
 }
 
+// revert removes the descendants of the node with a number greater than the given number
+// and appends their hashes to reverted.
+func (n *node) revert(number uint, reverted []Hash) []Hash {
+	kept := make([]*node, 0, len(n.children))
+	for _, child := range n.children {
+		if child.number > number {
+			reverted = child.getAllDescendants(reverted)
+			continue
+		}
+		reverted = child.revert(number, reverted)
+		kept = append(kept, child)
+	}
+	n.children = kept
+	return reverted
+}
+
 func (n *node) deleteChild(toDelete *node) {
 	for i, child := range n.children {
 		if child.hash == toDelete.hash {
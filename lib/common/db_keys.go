@@ -24,4 +24,9 @@
 	PruningKey = []byte("prune")
 	// CodeSubstitutedBlock is the storage key to store block hash of substituted (if there is currently code substituted)
 	CodeSubstitutedBlock = []byte("code_substituted_block")
+	// SchemaVersionKey is the storage key to store the version of the database schema.
+	SchemaVersionKey = []byte("schema_version")
+	// MigrationProgressKey is the storage key to store the last key processed by the running migration
+	// of the database schema, so it resumes from this key when the node is restarted.
+	MigrationProgressKey = []byte("migration_progress")
 )
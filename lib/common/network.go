@@ -4,6 +4,8 @@
 package common
 
 import (
+	"time"
+
 	ma "github.com/multiformats/go-multiaddr"
 )
 
@@ -28,6 +30,70 @@ type PeerInfo struct {
 	BestNumber uint64
 }
 
+// KnownPeerInfo is information about the peers kept in the peer book needed for the rpc server
+type KnownPeerInfo struct {
+	PeerID      string
+	Addrs       []string
+	LastSeen    time.Time
+	Successes   uint32
+	Failures    uint32
+	Reputation  int32
+	BannedUntil time.Time
+}
+
+// SyncPeerStats is the statistics of the requests made to a sync peer needed for the rpc server
+type SyncPeerStats struct {
+	PeerID         string
+	Requests       uint64
+	Failures       uint64
+	BlocksServed   uint64
+	Latency        time.Duration
+	BytesPerSecond float64
+}
+
+// SyncProgress is the progress of the sync needed for the rpc server
+type SyncProgress struct {
+	StartingBlock  uint
+	CurrentBlock   uint
+	HighestBlock   uint
+	ImportRate     float64
+	ETA            time.Duration
+	Stalled        bool
+	LastProgressAt time.Time
+}
+
+// NetworkBandwidth is the traffic of the node in total, per protocol and per peer needed for the rpc server
+type NetworkBandwidth struct {
+	TotalIn   uint64
+	TotalOut  uint64
+	RateIn    float64
+	RateOut   float64
+	Protocols []ProtocolBandwidth
+	Peers     []PeerBandwidth
+}
+
+// ProtocolBandwidth is the traffic, the messages and the open streams of a protocol
+type ProtocolBandwidth struct {
+	Protocol        string
+	TotalIn         uint64
+	TotalOut        uint64
+	RateIn          float64
+	RateOut         float64
+	MessagesIn      uint64
+	MessagesOut     uint64
+	InboundStreams  uint64
+	OutboundStreams uint64
+}
+
+// PeerBandwidth is the traffic exchanged with a peer
+type PeerBandwidth struct {
+	PeerID   string
+	TotalIn  uint64
+	TotalOut uint64
+	RateIn   float64
+	RateOut  float64
+}
+
 // NetworkRole is the type of node.
 type NetworkRole byte
 
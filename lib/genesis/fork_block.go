@@ -0,0 +1,84 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package genesis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// ForkBlock is a block pinned by the chain spec, only the block with the given hash
+// can be imported at the given number.
+type ForkBlock struct {
+	Number uint
+	Hash   common.Hash
+}
+
+// MarshalJSON encodes the fork block as the chain spec `[number, hash]` pair.
+func (f ForkBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{f.Number, f.Hash})
+}
+
+// UnmarshalJSON decodes the fork block from the chain spec `[number, hash]` pair.
+func (f *ForkBlock) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	err := json.Unmarshal(data, &pair)
+	if err != nil {
+		return fmt.Errorf("decoding fork block: %w", err)
+	}
+
+	if len(pair) != 2 {
+		return fmt.Errorf("fork block must be a [number, hash] pair, got %d elements", len(pair))
+	}
+
+	err = json.Unmarshal(pair[0], &f.Number)
+	if err != nil {
+		return fmt.Errorf("decoding fork block number: %w", err)
+	}
+
+	err = json.Unmarshal(pair[1], &f.Hash)
+	if err != nil {
+		return fmt.Errorf("decoding fork block hash: %w", err)
+	}
+
+	return nil
+}
+
+// ParseForkBlock parses a fork block given as `number:hash`.
+func ParseForkBlock(s string) (ForkBlock, error) {
+	number, hash, ok := strings.Cut(s, ":")
+	if !ok {
+		return ForkBlock{}, fmt.Errorf("fork block %q must be formatted as number:hash", s)
+	}
+
+	n, err := strconv.ParseUint(strings.TrimSpace(number), 10, 64)
+	if err != nil {
+		return ForkBlock{}, fmt.Errorf("parsing fork block number: %w", err)
+	}
+
+	h, err := ParseBlockHash(hash)
+	if err != nil {
+		return ForkBlock{}, err
+	}
+
+	return ForkBlock{Number: uint(n), Hash: h}, nil
+}
+
+// ParseBlockHash parses a 0x prefixed block hash, as listed in the bad blocks of the chain spec.
+func ParseBlockHash(s string) (common.Hash, error) {
+	s = strings.TrimSpace(s)
+	if len(s) != 2+2*common.HashLength || !strings.HasPrefix(s, "0x") {
+		return common.Hash{}, fmt.Errorf("block hash %q must be a 0x prefixed 32 bytes hex string", s)
+	}
+
+	hash, err := common.HexToHash(s)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("parsing block hash: %w", err)
+	}
+	return hash, nil
+}
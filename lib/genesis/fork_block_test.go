@@ -0,0 +1,68 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package genesis
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkBlock_JSON(t *testing.T) {
+	hash := common.Hash{1, 2, 3}
+	data := `[[10,"` + hash.String() + `"]]`
+
+	var forkBlocks []ForkBlock
+	err := json.Unmarshal([]byte(data), &forkBlocks)
+	require.NoError(t, err)
+	require.Equal(t, []ForkBlock{{Number: 10, Hash: hash}}, forkBlocks)
+
+	encoded, err := json.Marshal(forkBlocks)
+	require.NoError(t, err)
+	require.JSONEq(t, data, string(encoded))
+
+	err = json.Unmarshal([]byte(`[[10]]`), &forkBlocks)
+	require.EqualError(t, err, "fork block must be a [number, hash] pair, got 1 elements")
+}
+
+func TestParseForkBlock(t *testing.T) {
+	hash := common.Hash{1, 2, 3}
+
+	cases := map[string]struct {
+		s         string
+		expected  ForkBlock
+		expErrMsg string
+	}{
+		"valid": {
+			s:        "10:" + hash.String(),
+			expected: ForkBlock{Number: 10, Hash: hash},
+		},
+		"missing_separator": {
+			s:         hash.String(),
+			expErrMsg: `fork block "` + hash.String() + `" must be formatted as number:hash`,
+		},
+		"invalid_number": {
+			s:         "ten:" + hash.String(),
+			expErrMsg: `parsing fork block number: strconv.ParseUint: parsing "ten": invalid syntax`,
+		},
+		"short_hash": {
+			s:         "10:0x0102",
+			expErrMsg: `block hash "0x0102" must be a 0x prefixed 32 bytes hex string`,
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			forkBlock, err := ParseForkBlock(tt.s)
+			if tt.expErrMsg != "" {
+				require.EqualError(t, err, tt.expErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, forkBlock)
+		})
+	}
+}
@@ -20,7 +20,7 @@ type Genesis struct {
 	ProtocolID         string                 `json:"protocolId"`
 	Genesis            Fields                 `json:"genesis"`
 	Properties         map[string]interface{} `json:"properties"`
-	ForkBlocks         []string               `json:"forkBlocks"`
+	ForkBlocks         []ForkBlock            `json:"forkBlocks"`
 	BadBlocks          []string               `json:"badBlocks"`
 	ConsensusEngine    string                 `json:"consensusEngine"`
 	CodeSubstitutes    map[string]string      `json:"codeSubstitutes"`
@@ -35,7 +35,7 @@ type Data struct {
 	TelemetryEndpoints []*TelemetryEndpoint
 	ProtocolID         string
 	Properties         map[string]interface{}
-	ForkBlocks         []string
+	ForkBlocks         []ForkBlock
 	BadBlocks          []string
 	ConsensusEngine    string
 	CodeSubstitutes    map[string]string
@@ -31,7 +31,7 @@ func TestNewGenesisRawFromJSON(t *testing.T) {
 			"tokenDecimals": float64(10),
 			"tokenSymbol":   "DOT",
 		},
-		ForkBlocks: []string{"fork1", "forkBlock2"},
+		ForkBlocks: []ForkBlock{{Number: 1, Hash: common.Hash{1}}, {Number: 2, Hash: common.Hash{2}}},
 		BadBlocks:  []string{"badBlock1", "badBlock2"},
 		Genesis: Fields{
 			Raw: map[string]map[string]string{
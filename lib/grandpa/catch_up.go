@@ -0,0 +1,121 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package grandpa
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/benbjohnson/clock"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// catchUpThreshold is the number of rounds a neighbour in our voter set must be
+	// ahead of us before we ask it for a catch up.
+	catchUpThreshold = 2
+
+	// catchUpRequestTimeout is the time after which a catch up request that was not
+	// answered is dropped, so that another one can be sent.
+	catchUpRequestTimeout = 45 * time.Second
+)
+
+// catchUpRequest is a catch up request waiting for its response
+type catchUpRequest struct {
+	to     peer.ID
+	round  uint64
+	setID  uint64
+	sentAt time.Time
+}
+
+// catchUpTracker keeps track of the catch up request in flight, only a single
+// request is sent at a time.
+type catchUpTracker struct {
+	sync.Mutex
+	pending *catchUpRequest
+	clock   clock.Clock // time source of the request timeout, the one of the voting rounds
+}
+
+// start registers a new catch up request to the given peer, it returns false if
+// there is already a request in flight.
+func (c *catchUpTracker) start(to peer.ID, round, setID uint64) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.pending != nil && c.clock.Since(c.pending.sentAt) < catchUpRequestTimeout {
+		return false
+	}
+
+	c.pending = &catchUpRequest{
+		to:     to,
+		round:  round,
+		setID:  setID,
+		sentAt: c.clock.Now(),
+	}
+	return true
+}
+
+// finish removes the request in flight if the response comes from the peer it was sent to.
+// It returns false if we were not expecting a catch up response from the peer.
+func (c *catchUpTracker) finish(from peer.ID) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.pending == nil || c.pending.to != from || c.clock.Since(c.pending.sentAt) >= catchUpRequestTimeout {
+		return false
+	}
+
+	c.pending = nil
+	return true
+}
+
+// cancel drops the request in flight, if it was sent to the given peer.
+func (c *catchUpTracker) cancel(to peer.ID) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.pending != nil && c.pending.to == to {
+		c.pending = nil
+	}
+}
+
+// sendCatchUpRequest asks the peer for the votes of its last completed round, so we
+// can skip the rounds we missed.
+func (s *Service) sendCatchUpRequest(to peer.ID, round, setID uint64) error {
+	if !s.catchUp.start(to, round, setID) {
+		logger.Tracef("catch up request already in flight, not requesting catch up to peer %s", to)
+		return nil
+	}
+
+	req := newCatchUpRequest(round, setID)
+	msg, err := req.ToConsensusMessage()
+	if err != nil {
+		s.catchUp.cancel(to)
+		return fmt.Errorf("encoding catch up request: %w", err)
+	}
+
+	logger.Debugf("sending catch up request for round %d and set id %d to peer %s", round, setID, to)
+	err = s.network.SendMessage(to, msg)
+	if err != nil {
+		s.catchUp.cancel(to)
+		return fmt.Errorf("sending catch up request: %w", err)
+	}
+
+	return nil
+}
+
+// reportPeer reports the reputation change of the peer to the network, messages
+// re-processed from the tracker have no peer and are not reported.
+func (s *Service) reportPeer(from peer.ID, value peerset.Reputation, reason string) {
+	if from == "" {
+		return
+	}
+
+	s.network.ReportPeer(peerset.ReputationChange{
+		Value:  value,
+		Reason: reason,
+	}, from)
+}
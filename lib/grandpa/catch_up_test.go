@@ -0,0 +1,37 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package grandpa
+
+import (
+	"testing"
+
+	"github.com/benbjohnson/clock"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_catchUpTracker(t *testing.T) {
+	t.Parallel()
+
+	mockClock := clock.NewMock()
+	tracker := &catchUpTracker{clock: mockClock}
+	const alice, bob = peer.ID("alice"), peer.ID("bob")
+
+	require.True(t, tracker.start(alice, 1, 0))
+	require.False(t, tracker.start(bob, 1, 0))
+	require.False(t, tracker.finish(bob))
+	require.True(t, tracker.finish(alice))
+	require.False(t, tracker.finish(alice))
+
+	// the request times out on the clock of the voting rounds
+	require.True(t, tracker.start(alice, 2, 0))
+	mockClock.Add(catchUpRequestTimeout - 1)
+	require.False(t, tracker.start(bob, 2, 0))
+	mockClock.Add(1)
+	require.False(t, tracker.finish(alice))
+	require.True(t, tracker.start(bob, 2, 0))
+
+	tracker.cancel(alice)
+	require.True(t, tracker.finish(bob))
+}
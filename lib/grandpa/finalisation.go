@@ -366,8 +366,8 @@ func (f *finalisationEngine) Run() (err error) {
 
 func (f *finalisationEngine) defineRoundVotes() (err error) {
 	gossipInterval := f.grandpaService.interval
-	determinePrevoteTimer := time.NewTimer(2 * gossipInterval)
-	determinePrecommitTimer := time.NewTimer(4 * gossipInterval)
+	determinePrevoteTimer := f.grandpaService.clock.Timer(2 * gossipInterval)
+	determinePrecommitTimer := f.grandpaService.clock.Timer(4 * gossipInterval)
 
 	precommited := false
 
@@ -440,7 +440,7 @@ func (f *finalisationEngine) defineRoundVotes() (err error) {
 
 func (f *finalisationEngine) finalizeRound() error {
 	gossipInterval := f.grandpaService.interval
-	attemptfinalisationTicker := time.NewTicker(gossipInterval / 2)
+	attemptfinalisationTicker := f.grandpaService.clock.Ticker(gossipInterval / 2)
 	defer attemptfinalisationTicker.Stop()
 
 	for {
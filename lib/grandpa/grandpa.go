@@ -10,7 +10,6 @@
 	"errors"
 	"fmt"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/ChainSafe/gossamer/dot/state"
@@ -22,6 +21,7 @@
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
 	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/benbjohnson/clock"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -54,12 +54,12 @@ type Service struct {
 	mapLock        sync.Mutex
 	chanLock       sync.Mutex
 	roundLock      sync.Mutex
-	authority      bool          // run the service as an authority (ie participate in voting)
-	paused         atomic.Value  // the service will be paused if it is waiting for catch up responses
-	resumed        chan struct{} // this channel will be closed when the service resumes
+	authority      bool           // run the service as an authority (ie participate in voting)
+	catchUp        catchUpTracker // the catch up request in flight, if any
 	messageHandler *MessageHandler
 	network        Network
 	interval       time.Duration
+	clock          clock.Clock // time source of the voting rounds
 
 	// current state information
 	state *State // current state
@@ -93,6 +93,8 @@ type Config struct {
 	Authority    bool
 	Interval     time.Duration
 	Telemetry    Telemetry
+	// Clock is the time source of the voting rounds, the system clock if nil
+	Clock clock.Clock
 }
 
 // NewService returns a new GRANDPA Service instance.
@@ -130,6 +132,10 @@ func NewService(cfg *Config) (*Service, error) {
 		cfg.Interval = defaultGrandpaInterval
 	}
 
+	if cfg.Clock == nil {
+		cfg.Clock = clock.New()
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	s := &Service{
 		ctx:                ctx,
@@ -146,10 +152,10 @@ func NewService(cfg *Config) (*Service, error) {
 		preVotedBlock:      make(map[uint64]*Vote),
 		bestFinalCandidate: make(map[uint64]*Vote),
 		head:               head,
-		resumed:            make(chan struct{}),
 		network:            cfg.Network,
 		finalisedCh:        finalisedCh,
 		interval:           cfg.Interval,
+		clock:              cfg.Clock,
 		telemetry:          cfg.Telemetry,
 	}
 
@@ -159,7 +165,8 @@ func NewService(cfg *Config) (*Service, error) {
 
 	s.messageHandler = NewMessageHandler(s, s.blockState, cfg.Telemetry)
 	s.tracker = newTracker(s.blockState, s.messageHandler)
-	s.paused.Store(false)
+	s.tracker.clock = s.clock
+	s.catchUp.clock = s.clock
 	return s, nil
 }
 
@@ -233,11 +240,13 @@ func (s *Service) updateAuthorities() error {
 	}
 
 	s.state.voters = nextAuthorities
+	s.roundLock.Lock()
 	s.state.setID = currSetID
 	// round resets to 1 after a set ID change,
 	// setting to 0 before incrementing indicates
 	// the setID has been increased
 	s.state.round = 0
+	s.roundLock.Unlock()
 	roundGauge.Set(float64(s.state.round))
 
 	s.sendTelemetryAuthoritySet()
@@ -287,7 +296,9 @@ func (s *Service) initiateRound() error {
 		logger.Debugf(
 			"found block finalised in higher round, updating our round to be %d...",
 			round)
+		s.roundLock.Lock()
 		s.state.round = round
+		s.roundLock.Unlock()
 		roundGauge.Set(float64(s.state.round))
 		err = s.grandpaState.SetLatestRound(round)
 		if err != nil {
@@ -297,8 +308,10 @@ func (s *Service) initiateRound() error {
 
 	if setID > s.state.setID {
 		logger.Debugf("found block finalised in higher setID, updating our setID to be %d...", setID)
+		s.roundLock.Lock()
 		s.state.setID = setID
 		s.state.round = round
+		s.roundLock.Unlock()
 	}
 
 	s.head, err = s.blockState.GetFinalisedHeader(round, setID)
@@ -1051,6 +1064,8 @@ func (s *Service) getVotes(stage Subround) []Vote {
 
 // GetSetID returns the current setID
 func (s *Service) GetSetID() uint64 {
+	s.roundLock.Lock()
+	defer s.roundLock.Unlock()
 	return s.state.setID
 }
 
@@ -1063,6 +1078,14 @@ func (s *Service) GetRound() uint64 {
 	return s.state.round
 }
 
+// roundAndSetID returns the current round and setID, read together so they are
+// consistent with each other.
+func (s *Service) roundAndSetID() (round, setID uint64) {
+	s.roundLock.Lock()
+	defer s.roundLock.Unlock()
+	return s.state.round, s.state.setID
+}
+
 // GetVoters returns the list of current grandpa.Voters
 func (s *Service) GetVoters() Voters {
 	return s.state.voters
@@ -1147,6 +1170,7 @@ func (s *Service) handleCommitMessage(commitMessage *CommitMessage) error {
 	if err != nil {
 		if errors.Is(err, database.ErrNotFound) {
 			s.tracker.addCommit(commitMessage)
+			return fmt.Errorf("verifying block hash against block number: %w: %w", ErrBlockDoesNotExist, err)
 		}
 
 		return fmt.Errorf("verifying block hash against block number: %w", err)
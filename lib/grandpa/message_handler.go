@@ -9,6 +9,7 @@
 	"fmt"
 
 	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
 	"github.com/ChainSafe/gossamer/internal/database"
 	"github.com/ChainSafe/gossamer/internal/primitives/core/hash"
 	"github.com/ChainSafe/gossamer/internal/primitives/runtime"
@@ -64,11 +65,11 @@ func (h *MessageHandler) handleMessage(from peer.ID, m GrandpaMessage) (network.
 		return nil, nil //nolint:nilnil
 	case *NeighbourPacketV1:
 		// we can afford to not retry handling neighbour message, if it errors.
-		return nil, h.handleNeighbourMessage(msg)
+		return nil, h.handleNeighbourMessage(from, msg)
 	case *CatchUpRequest:
 		return h.handleCatchUpRequest(msg)
 	case *CatchUpResponse:
-		err := h.handleCatchUpResponse(msg)
+		err := h.handleCatchUpResponse(from, msg)
 		if errors.Is(err, blocktree.ErrNodeNotFound) {
 			// TODO: we are adding these messages to reprocess them again, but we
 			// haven't added code to reprocess them. Do that.
@@ -82,11 +83,26 @@ func (h *MessageHandler) handleMessage(from peer.ID, m GrandpaMessage) (network.
 	}
 }
 
-func (*MessageHandler) handleNeighbourMessage(_ *NeighbourPacketV1) error {
-	// TODO(#2931)
-	return nil
+// handleNeighbourMessage requests a catch up to the neighbour if it is a few
+// rounds ahead of us in the same voter set.
+func (h *MessageHandler) handleNeighbourMessage(from peer.ID, msg *NeighbourPacketV1) error {
+	// TODO(#2931): keep track of the neighbours view to filter the gossiped messages
+	if !h.grandpa.authority || from == "" {
+		return nil
+	}
+
+	round, setID := h.grandpa.roundAndSetID()
+	if msg.SetID != setID || msg.Round <= round+catchUpThreshold {
+		return nil
+	}
+
+	logger.Debugf("neighbour %s is at round %d while we are at round %d, requesting catch up",
+		from, msg.Round, round)
+	return h.grandpa.sendCatchUpRequest(from, round, setID)
 }
 
+// handleCatchUpRequest responds with the votes of our last completed round, if the
+// requested round is not ahead of it.
 func (h *MessageHandler) handleCatchUpRequest(msg *CatchUpRequest) (*ConsensusMessage, error) {
 	if !h.grandpa.authority {
 		return nil, nil
@@ -95,26 +111,31 @@ func (h *MessageHandler) handleCatchUpRequest(msg *CatchUpRequest) (*ConsensusMe
 	logger.Debugf("received catch up request for round %d and set id %d",
 		msg.Round, msg.SetID)
 
-	if msg.SetID != h.grandpa.state.setID {
+	round, setID := h.grandpa.roundAndSetID()
+	if msg.SetID != setID {
 		return nil, ErrSetIDMismatch
 	}
 
-	if msg.Round >= h.grandpa.state.round {
+	if msg.Round >= round {
 		return nil, ErrInvalidCatchUpRound
 	}
 
-	resp, err := h.grandpa.newCatchUpResponse(msg.Round, msg.SetID)
+	lastCompletedRound := round - 1
+	resp, err := h.grandpa.newCatchUpResponse(lastCompletedRound, msg.SetID)
 	if err != nil {
 		return nil, err
 	}
 
 	logger.Debugf(
 		"sending catch up response with hash %s for round %d and set id %d",
-		resp.Hash, msg.Round, msg.SetID)
+		resp.Hash, lastCompletedRound, msg.SetID)
 	return resp.ToConsensusMessage()
 }
 
-func (h *MessageHandler) handleCatchUpResponse(msg *CatchUpResponse) error {
+// handleCatchUpResponse verifies the votes of the catch up response against our voter
+// set and fast-forwards to the round following the one of the response, by finalising
+// its block. The peer reputation is adjusted according to the response validity.
+func (h *MessageHandler) handleCatchUpResponse(from peer.ID, msg *CatchUpResponse) error {
 	if !h.grandpa.authority {
 		return nil
 	}
@@ -123,84 +144,99 @@ func (h *MessageHandler) handleCatchUpResponse(msg *CatchUpResponse) error {
 		"received catch up response with hash %s for round %d and set id %d",
 		msg.Hash, msg.Round, msg.SetID)
 
-	err := verifyBlockHashAgainstBlockNumber(h.blockState, msg.Hash, uint(msg.Number))
-	if err != nil {
-		if errors.Is(err, database.ErrNotFound) {
-			h.grandpa.tracker.addCatchUpResponse(msg)
-			logger.Infof("we might not have synced to the given block %s yet: %s", msg.Hash, err)
-			return nil
-		}
-		return err
+	// responses re-processed by the tracker were already expected when received
+	if from != "" && !h.grandpa.catchUp.finish(from) {
+		h.grandpa.reportPeer(from, peerset.UnexpectedCatchUpResponseValue, peerset.UnexpectedCatchUpResponseReason)
+		return fmt.Errorf("%w: from peer %s", errUnexpectedCatchUpResponse, from)
 	}
 
-	// TODO: re-add catch-up logic (#1531)
-	if true {
-		return nil
+	round, setID := h.grandpa.roundAndSetID()
+	if msg.SetID != setID {
+		h.grandpa.reportPeer(from, peerset.BadCatchUpResponseValue, peerset.BadCatchUpResponseReason)
+		return ErrSetIDMismatch
 	}
 
-	// if we aren't currently expecting a catch up response, return
-	if !h.grandpa.paused.Load().(bool) {
-		logger.Debug("not currently paused, ignoring catch up response")
+	// we completed the round in the meantime, the response is not useful anymore
+	if msg.Round < round {
+		logger.Debugf("ignoring catch up response for round %d, we are already at round %d",
+			msg.Round, round)
 		return nil
 	}
 
-	if msg.SetID != h.grandpa.state.setID {
-		return ErrSetIDMismatch
+	if msg.Hash.IsEmpty() || msg.Number == 0 {
+		h.grandpa.reportPeer(from, peerset.BadCatchUpResponseValue, peerset.BadCatchUpResponseReason)
+		return ErrGHOSTlessCatchUp
 	}
 
-	if msg.Round != h.grandpa.state.round-1 {
-		return ErrInvalidCatchUpResponseRound
+	err := verifyBlockHashAgainstBlockNumber(h.blockState, msg.Hash, uint(msg.Number))
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			h.grandpa.tracker.addCatchUpResponse(msg)
+			logger.Infof("we might not have synced to the given block %s yet: %s", msg.Hash, err)
+			return nil
+		}
+		h.grandpa.reportPeer(from, peerset.BadCatchUpResponseValue, peerset.BadCatchUpResponseReason)
+		return err
 	}
 
-	prevote, err := h.verifyPreVoteJustification(msg)
+	err = h.verifyCatchUpResponse(msg)
 	if err != nil {
-		return err
+		h.grandpa.reportPeer(from, peerset.BadCatchUpResponseValue, peerset.BadCatchUpResponseReason)
+		return fmt.Errorf("verifying catch up response: %w", err)
 	}
 
-	if err = h.verifyPreCommitJustification(msg); err != nil {
+	// set prevotes and precommits in db
+	if err = h.grandpa.grandpaState.SetPrevotes(msg.Round, msg.SetID, msg.PreVoteJustification); err != nil {
 		return err
 	}
 
-	if msg.Hash.IsEmpty() || msg.Number == 0 {
-		return ErrGHOSTlessCatchUp
+	if err = h.grandpa.grandpaState.SetPrecommits(msg.Round, msg.SetID, msg.PreCommitJustification); err != nil {
+		return err
 	}
 
-	if err = h.verifyCatchUpResponseCompletability(prevote, msg.Hash); err != nil {
-		return err
+	// finalising the block of the response completes our current round, the next
+	// round is then initiated from the round of the response.
+	has, err := h.blockState.HasFinalisedBlock(msg.Round, msg.SetID)
+	if err != nil {
+		return fmt.Errorf("checking for a finalised block in the block state: %w", err)
 	}
 
-	// set prevotes and precommits in db
-	if err = h.grandpa.grandpaState.SetPrevotes(msg.Round, msg.SetID, msg.PreVoteJustification); err != nil {
-		return err
+	if !has {
+		err = h.blockState.SetFinalisedHash(msg.Hash, msg.Round, msg.SetID)
+		if err != nil {
+			return fmt.Errorf("setting finalised hash: %w", err)
+		}
 	}
 
-	if err = h.grandpa.grandpaState.SetPrecommits(msg.Round, msg.SetID, msg.PreCommitJustification); err != nil {
+	h.grandpa.reportPeer(from, peerset.GoodCatchUpResponseValue, peerset.GoodCatchUpResponseReason)
+	logger.Debugf("caught up to round %d and set id %d", msg.Round, msg.SetID)
+	return nil
+}
+
+// verifyCatchUpResponse verifies the prevotes and precommits justifications of the
+// catch up response, and that its round is completable.
+func (h *MessageHandler) verifyCatchUpResponse(msg *CatchUpResponse) error {
+	prevote, err := h.verifyPreVoteJustification(msg)
+	if err != nil {
 		return err
 	}
 
-	// update state and signal to grandpa we are ready to initiate
-	head, err := h.grandpa.blockState.GetHeader(msg.Hash)
-	if err != nil {
+	if err = h.verifyPreCommitJustification(msg); err != nil {
 		return err
 	}
 
-	h.grandpa.head = head
-	h.grandpa.state.round = msg.Round
-	close(h.grandpa.resumed)
-	h.grandpa.resumed = make(chan struct{})
-	h.grandpa.paused.Store(false)
-	logger.Debugf("caught up to round; unpaused service and grandpa state round is %d", h.grandpa.state.round)
-	return nil
+	return h.verifyCatchUpResponseCompletability(prevote, msg.Hash)
 }
 
-// verifyCatchUpResponseCompletability verifies that the pre-commit block is a descendant of, or is, the pre-voted block
+// verifyCatchUpResponseCompletability verifies that the pre-voted block is a descendant of, or is, the pre-committed
+// block, since nothing beyond the pre-vote GHOST can be finalised in the round.
 func (h *MessageHandler) verifyCatchUpResponseCompletability(prevote, precommit common.Hash) error {
 	if prevote == precommit {
 		return nil
 	}
 
-	// check if the current block is a descendant of prevoted block
-	isDescendant, err := h.grandpa.blockState.IsDescendantOf(prevote, precommit)
+	// check if the prevoted block is a descendant of the precommitted block
+	isDescendant, err := h.grandpa.blockState.IsDescendantOf(precommit, prevote)
 	if err != nil {
 		return err
 	}
@@ -366,12 +402,34 @@ func (h *MessageHandler) verifyPreCommitJustification(msg *CatchUpResponse) erro
 func (s *Service) VerifyBlockJustification(finalizedHash common.Hash, finalizedNumber uint, encoded []byte) (
 	round uint64, setID uint64, err error,
 ) {
-	setID, err = s.grandpaState.GetSetIDByBlockNumber(finalizedNumber)
+	return verifyBlockJustification(s.grandpaState, finalizedHash, finalizedNumber, encoded)
+}
+
+// JustificationVerifier verifies block justifications without running the GRANDPA service,
+// it is used to import blocks while the node is offline.
+type JustificationVerifier struct {
+	grandpaState GrandpaState
+}
+
+// NewJustificationVerifier returns a new JustificationVerifier
+func NewJustificationVerifier(grandpaState GrandpaState) *JustificationVerifier {
+	return &JustificationVerifier{grandpaState: grandpaState}
+}
+
+// VerifyBlockJustification verifies the finality justification for a block
+func (v *JustificationVerifier) VerifyBlockJustification(finalizedHash common.Hash, finalizedNumber uint,
+	encoded []byte) (round uint64, setID uint64, err error) {
+	return verifyBlockJustification(v.grandpaState, finalizedHash, finalizedNumber, encoded)
+}
+
+func verifyBlockJustification(grandpaState GrandpaState, finalizedHash common.Hash, finalizedNumber uint,
+	encoded []byte) (round uint64, setID uint64, err error) {
+	setID, err = grandpaState.GetSetIDByBlockNumber(finalizedNumber)
 	if err != nil {
 		return 0, 0, fmt.Errorf("cannot get set ID from block number: %w", err)
 	}
 
-	auths, err := s.grandpaState.GetAuthorities(setID)
+	auths, err := grandpaState.GetAuthorities(setID)
 	if err != nil {
 		return 0, 0, fmt.Errorf("cannot get authorities for set ID: %w", err)
 	}
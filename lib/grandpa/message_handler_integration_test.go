@@ -10,12 +10,14 @@
 	"testing"
 	"time"
 
+	"github.com/ChainSafe/gossamer/dot/peerset"
 	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -683,32 +685,124 @@ func TestMessageHandler_HandleCatchUpResponse(t *testing.T) {
 
 	gs, st := newTestService(t, aliceKeyPair)
 
-	err = st.Block.SetHeader(testHeader)
+	body, err := types.NewBodyFromBytes([]byte{0})
+	require.NoError(t, err)
+
+	block := &types.Block{
+		Header: *testHeader,
+		Body:   *body,
+	}
+
+	err = st.Block.AddBlock(block)
 	require.NoError(t, err)
 
 	ctrl := gomock.NewController(t)
 	telemetryMock := NewMockTelemetry(ctrl)
+	networkMock := NewMockNetwork(ctrl)
+	gs.network = networkMock
 
 	h := NewMessageHandler(gs, st.Block, telemetryMock)
 
 	round := uint64(1)
-	gs.state.round = round + 1
+	gs.state.round = round
+	setID := gs.state.setID
 
-	pvJust := buildTestJustification(t, int(gs.state.threshold()), round, gs.state.setID, kr, prevote)
-	pcJust := buildTestJustification(t, int(gs.state.threshold()), round, gs.state.setID, kr, precommit)
+	pvJust := buildTestJustification(t, int(gs.state.threshold()), round, setID, kr, prevote)
+	pcJust := buildTestJustification(t, int(gs.state.threshold()), round, setID, kr, precommit)
 	msg := &CatchUpResponse{
 		Round:                  round,
-		SetID:                  gs.state.setID,
+		SetID:                  setID,
 		PreVoteJustification:   pvJust,
 		PreCommitJustification: pcJust,
 		Hash:                   testHash,
 		Number:                 uint32(round),
 	}
 
-	out, err := h.handleMessage("", msg)
+	const peerID = peer.ID("noot")
+
+	// responses we did not ask for are refused
+	networkMock.EXPECT().ReportPeer(peerset.ReputationChange{
+		Value:  peerset.UnexpectedCatchUpResponseValue,
+		Reason: peerset.UnexpectedCatchUpResponseReason,
+	}, peerID)
+	_, err = h.handleMessage(peerID, msg)
+	require.ErrorIs(t, err, errUnexpectedCatchUpResponse)
+
+	// invalid responses are refused
+	networkMock.EXPECT().SendMessage(peerID, gomock.AssignableToTypeOf(&ConsensusMessage{})).Return(nil)
+	err = gs.sendCatchUpRequest(peerID, round, setID)
+	require.NoError(t, err)
+
+	invalidMsg := *msg
+	invalidMsg.PreCommitJustification = buildTestJustification(t, int(gs.state.threshold()), round, setID+1, kr, precommit)
+	networkMock.EXPECT().ReportPeer(peerset.ReputationChange{
+		Value:  peerset.BadCatchUpResponseValue,
+		Reason: peerset.BadCatchUpResponseReason,
+	}, peerID)
+	_, err = h.handleMessage(peerID, &invalidMsg)
+	require.ErrorIs(t, err, ErrMinVotesNotMet)
+
+	// valid responses fast-forward to the response round
+	networkMock.EXPECT().SendMessage(peerID, gomock.AssignableToTypeOf(&ConsensusMessage{})).Return(nil)
+	err = gs.sendCatchUpRequest(peerID, round, setID)
+	require.NoError(t, err)
+
+	networkMock.EXPECT().ReportPeer(peerset.ReputationChange{
+		Value:  peerset.GoodCatchUpResponseValue,
+		Reason: peerset.GoodCatchUpResponseReason,
+	}, peerID)
+	out, err := h.handleMessage(peerID, msg)
 	require.NoError(t, err)
 	require.Nil(t, out)
-	require.Equal(t, round+1, gs.state.round)
+
+	finalisedHash, err := st.Block.GetFinalisedHash(round, setID)
+	require.NoError(t, err)
+	require.Equal(t, testHash, finalisedHash)
+
+	completable, err := gs.checkRoundCompletable()
+	require.NoError(t, err)
+	require.True(t, completable)
+
+	prevotes, err := st.Grandpa.GetPrevotes(round, setID)
+	require.NoError(t, err)
+	require.Equal(t, pvJust, prevotes)
+}
+
+func TestMessageHandler_NeighbourMessage_CatchUpRequest(t *testing.T) {
+	t.Parallel()
+
+	kr, err := keystore.NewEd25519Keyring()
+	require.NoError(t, err)
+	aliceKeyPair := kr.Alice().(*ed25519.Keypair)
+
+	gs, st := newTestService(t, aliceKeyPair)
+
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockTelemetry(ctrl)
+	networkMock := NewMockNetwork(ctrl)
+	gs.network = networkMock
+
+	h := NewMessageHandler(gs, st.Block, telemetryMock)
+
+	gs.state.round = 5
+	const peerID = peer.ID("noot")
+
+	// neighbours within the catch up threshold or in another set are ignored
+	_, err = h.handleMessage(peerID, &NeighbourPacketV1{Round: 5 + catchUpThreshold, SetID: gs.state.setID})
+	require.NoError(t, err)
+	_, err = h.handleMessage(peerID, &NeighbourPacketV1{Round: 10, SetID: gs.state.setID + 1})
+	require.NoError(t, err)
+
+	expectedRequest, err := newCatchUpRequest(5, gs.state.setID).ToConsensusMessage()
+	require.NoError(t, err)
+	networkMock.EXPECT().SendMessage(peerID, expectedRequest).Return(nil)
+
+	_, err = h.handleMessage(peerID, &NeighbourPacketV1{Round: 10, SetID: gs.state.setID})
+	require.NoError(t, err)
+
+	// a single catch up request is in flight at a time
+	_, err = h.handleMessage(peer.ID("other"), &NeighbourPacketV1{Round: 10, SetID: gs.state.setID})
+	require.NoError(t, err)
 }
 
 func Test_getEquivocatoryVoters(t *testing.T) {
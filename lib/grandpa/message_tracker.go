@@ -8,6 +8,7 @@
 	"time"
 
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/benbjohnson/clock"
 	"github.com/libp2p/go-libp2p/core/peer"
 )
 
@@ -21,6 +22,7 @@ type tracker struct {
 	commits    commitsTracker
 	in         chan *types.Block // receive imported block from BlockState
 	stopped    chan struct{}
+	clock      clock.Clock // time source of the retries of the tracked messages
 
 	catchUpResponseMessageMutex sync.Mutex
 	// round(uint64) is used as key and *CatchUpResponse as value
@@ -39,6 +41,7 @@ func newTracker(bs BlockState, handler *MessageHandler) *tracker {
 		commits:                 newCommitsTracker(commitsCapacity),
 		in:                      bs.GetImportedBlockNotifierChannel(),
 		stopped:                 make(chan struct{}),
+		clock:                   clock.New(),
 		catchUpResponseMessages: make(map[uint64]*CatchUpResponse),
 	}
 }
@@ -73,7 +76,7 @@ func (t *tracker) addCatchUpResponse(_ *CatchUpResponse) {
 
 func (t *tracker) handleBlocks() {
 	const timeout = time.Second
-	ticker := time.NewTicker(timeout)
+	ticker := t.clock.Ticker(timeout)
 	defer ticker.Stop()
 
 	for {
@@ -127,7 +130,8 @@ func (t *tracker) handleTick() {
 			logger.Debugf("failed to handle vote message %v from peer id %s: %s", message, peerID, err)
 		}
 
-		if message.Round < t.handler.grandpa.state.round && message.SetID == t.handler.grandpa.state.setID {
+		round, setID := t.handler.grandpa.roundAndSetID()
+		if message.Round < round && message.SetID == setID {
 			t.votes.delete(message.Message.BlockHash)
 		}
 	}
@@ -13,6 +13,7 @@
 	reflect "reflect"
 
 	network "github.com/ChainSafe/gossamer/dot/network"
+	peerset "github.com/ChainSafe/gossamer/dot/peerset"
 	types "github.com/ChainSafe/gossamer/dot/types"
 	common "github.com/ChainSafe/gossamer/lib/common"
 	runtime "github.com/ChainSafe/gossamer/lib/runtime"
@@ -612,6 +613,18 @@ func (mr *MockNetworkMockRecorder) RegisterNotificationsProtocol(arg0, arg1, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterNotificationsProtocol", reflect.TypeOf((*MockNetwork)(nil).RegisterNotificationsProtocol), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
 }
 
+// ReportPeer mocks base method.
+func (m *MockNetwork) ReportPeer(arg0 peerset.ReputationChange, arg1 peer.ID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportPeer", arg0, arg1)
+}
+
+// ReportPeer indicates an expected call of ReportPeer.
+func (mr *MockNetworkMockRecorder) ReportPeer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportPeer", reflect.TypeOf((*MockNetwork)(nil).ReportPeer), arg0, arg1)
+}
+
 // SendMessage mocks base method.
 func (m *MockNetwork) SendMessage(arg0 peer.ID, arg1 network.NotificationsMessage) error {
 	m.ctrl.T.Helper()
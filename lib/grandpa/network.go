@@ -4,10 +4,12 @@
 package grandpa
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/lib/blocktree"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 
@@ -121,15 +123,31 @@ func (s *Service) handleNetworkMessage(from peer.ID, msg NotificationsMessage) (
 	}
 
 	resp, err := s.messageHandler.handleMessage(from, m)
-	if err != nil {
+	if isPendingMessageError(err) {
+		// the message is for a block not imported yet or another round, it is kept by the
+		// tracker and must not close the stream the next messages of the peer are read from
+		logger.Debugf("message from peer %s not handled yet: %s", from, err)
+		return false, nil
+	} else if err != nil {
 		return false, err
 	}
 
 	switch r := resp.(type) {
 	case *ConsensusMessage:
-		if r != nil {
-			s.network.GossipMessage(resp)
+		if r == nil {
+			break
+		}
+
+		// catch up responses are only meant for the requesting peer
+		if _, isCatchUpRequest := m.(*CatchUpRequest); isCatchUpRequest {
+			err = s.network.SendMessage(from, r)
+			if err != nil {
+				logger.Debugf("failed to send catch up response to peer %s: %s", from, err)
+			}
+			break
 		}
+
+		s.network.GossipMessage(resp)
 	case nil:
 	default:
 		logger.Warnf(
@@ -138,13 +156,27 @@ func (s *Service) handleNetworkMessage(from peer.ID, msg NotificationsMessage) (
 	}
 
 	switch m.(type) {
-	case *NeighbourPacketV1, *CatchUpResponse:
+	case *NeighbourPacketV1, *CatchUpRequest, *CatchUpResponse:
 		return false, nil
 	}
 
 	return true, nil
 }
 
+// isPendingMessageError returns true if the error is caused by the message being for a block
+// not imported yet or for another round than the current one. The votes and commits for blocks
+// not imported yet, and the votes for the next round, are kept by the tracker to be handled
+// later. These messages are expected from honest peers while syncing, so they must not reset the
+// stream the messages of the peer are read from, as any error returned to the network does.
+// The other errors, including the other database errors, are not caused by pending messages.
+func isPendingMessageError(err error) bool {
+	return errors.Is(err, ErrBlockDoesNotExist) ||
+		errors.Is(err, blocktree.ErrDescendantNotFound) ||
+		errors.Is(err, blocktree.ErrEndNodeNotFound) ||
+		errors.Is(err, blocktree.ErrStartNodeNotFound) ||
+		errors.Is(err, errRoundsMismatch)
+}
+
 // decodeMessage decodes a network-level consensus message into a GRANDPA VoteMessage or CommitMessage
 func decodeMessage(cm *network.ConsensusMessage) (m GrandpaMessage, err error) {
 	msg := newGrandpaMessage()
@@ -8,6 +8,8 @@
 import (
 	"testing"
 
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"go.uber.org/mock/gomock"
@@ -77,3 +79,32 @@ func TestHandleNetworkMessage(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, propagate)
 }
+
+func TestHandleNetworkMessage_PendingVote(t *testing.T) {
+	kr, err := keystore.NewEd25519Keyring()
+	require.NoError(t, err)
+
+	gs := setupGrandpa(t, kr.Bob().(*ed25519.Keypair))
+	state.AddBlocksToState(t, gs.blockState.(*state.BlockState), 3, false)
+
+	// the vote is for a block not imported yet
+	fake := &types.Header{
+		Number: 77,
+	}
+	gs.keypair = kr.Alice().(*ed25519.Keypair)
+	_, msg, err := gs.createSignedVoteAndVoteMessage(NewVoteFromHeader(fake), prevote)
+	require.NoError(t, err)
+	gs.keypair = kr.Bob().(*ed25519.Keypair)
+
+	cm, err := msg.ToConsensusMessage()
+	require.NoError(t, err)
+
+	// the vote is kept by the tracker, the error must not reset the stream of the peer
+	propagate, err := gs.handleNetworkMessage(peer.ID("alice"), cm)
+	require.NoError(t, err)
+	require.False(t, propagate)
+
+	authorityID := kr.Alice().Public().(*ed25519.PublicKey).AsBytes()
+	voteMessage := getMessageFromVotesMapping(gs.tracker.votes.mapping, fake.Hash(), authorityID)
+	require.Equal(t, msg, voteMessage)
+}
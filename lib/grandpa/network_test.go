@@ -0,0 +1,60 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package grandpa
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/lib/blocktree"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isPendingMessageError(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		err     error
+		pending bool
+	}{
+		"nil_error": {},
+		"vote_for_unknown_block": {
+			err:     fmt.Errorf("handling vote message: validating vote: %w", ErrBlockDoesNotExist),
+			pending: true,
+		},
+		"vote_for_block_not_in_blocktree": {
+			err:     fmt.Errorf("handling vote message: validating vote: %w", blocktree.ErrEndNodeNotFound),
+			pending: true,
+		},
+		"commit_for_unknown_block": {
+			err: fmt.Errorf("handling commit message: verifying block hash against block number: %w: %w",
+				ErrBlockDoesNotExist, database.ErrNotFound),
+			pending: true,
+		},
+		"other_database_error": {
+			err: fmt.Errorf("handling commit message: getting authorities: %w", database.ErrNotFound),
+		},
+		"vote_for_other_round": {
+			err:     fmt.Errorf("handling vote message: %w: received round 3 but state round is 2", errRoundsMismatch),
+			pending: true,
+		},
+		"invalid_message": {
+			err: fmt.Errorf("handling vote message: %w", ErrInvalidSignature),
+		},
+		"other_error": {
+			err: errors.New("test error"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.pending, isPendingMessageError(testCase.err))
+		})
+	}
+}
@@ -10,7 +10,6 @@
 	"fmt"
 	"math/rand"
 	"sync"
-	"sync/atomic"
 	"testing"
 	"time"
 
@@ -21,6 +20,7 @@
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
 	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/benbjohnson/clock"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -188,10 +188,10 @@ func TestPlayGrandpaRound(t *testing.T) {
 				grandpaServices[idx] = &Service{
 					ctx:          ctx,
 					cancel:       cancel,
-					paused:       atomic.Value{},
 					blockState:   st.Block,
 					grandpaState: st.Grandpa,
 					interval:     subroundInterval,
+					clock:        clock.New(),
 					state: &State{
 						round:  1,
 						setID:  0,
@@ -207,7 +207,6 @@ func TestPlayGrandpaRound(t *testing.T) {
 					pvEquivocations:    make(map[ed25519.PublicKeyBytes][]*SignedVote),
 					pcEquivocations:    make(map[ed25519.PublicKeyBytes][]*SignedVote),
 				}
-				grandpaServices[idx].paused.Store(false)
 			}
 
 			neighbourServices := make([][]*Service, len(grandpaServices))
@@ -361,10 +360,10 @@ func TestPlayGrandpaRoundMultipleRounds(t *testing.T) {
 		grandpaServices[idx] = &Service{
 			ctx:          ctx,
 			cancel:       cancel,
-			paused:       atomic.Value{},
 			blockState:   st.Block,
 			grandpaState: st.Grandpa,
 			interval:     subroundInterval,
+			clock:        clock.New(),
 			state: &State{
 				round:  1,
 				setID:  0,
@@ -376,7 +375,6 @@ func TestPlayGrandpaRoundMultipleRounds(t *testing.T) {
 			preVotedBlock:      make(map[uint64]*Vote),
 			bestFinalCandidate: make(map[uint64]*Vote),
 		}
-		grandpaServices[idx].paused.Store(false)
 
 		const withBranches = false
 		const baseLength = 4
@@ -667,11 +665,11 @@ func TestSendingVotesInRightStage(t *testing.T) {
 	grandpa := &Service{
 		ctx:          ctx,
 		cancel:       cancel,
-		paused:       atomic.Value{},
 		network:      mockedNet,
 		blockState:   mockedState,
 		grandpaState: mockedGrandpaState,
 		interval:     subroundInterval,
+		clock:        clock.New(),
 		state: &State{
 			round:  1,
 			setID:  0,
@@ -686,7 +684,6 @@ func TestSendingVotesInRightStage(t *testing.T) {
 		bestFinalCandidate: make(map[uint64]*Vote),
 		telemetry:          mockedTelemetry,
 	}
-	grandpa.paused.Store(false)
 
 	expectedVote := NewVote(testGenesisHeader.Hash(), uint32(testGenesisHeader.Number))
 	_, expectedPrimaryProposal, err := grandpa.createSignedVoteAndVoteMessage(expectedVote, primaryProposal)
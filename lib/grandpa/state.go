@@ -8,6 +8,7 @@
 	"github.com/libp2p/go-libp2p/core/protocol"
 
 	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/runtime"
@@ -59,6 +60,7 @@ type GrandpaState interface {
 type Network interface {
 	GossipMessage(msg network.NotificationsMessage)
 	SendMessage(to peer.ID, msg NotificationsMessage) error
+	ReportPeer(change peerset.ReputationChange, p peer.ID)
 	RegisterNotificationsProtocol(sub protocol.ID,
 		messageID network.MessageType,
 		handshakeGetter network.HandshakeGetter,
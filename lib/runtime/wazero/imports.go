@@ -23,7 +23,6 @@
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 	"github.com/ChainSafe/gossamer/pkg/trie"
-	inmemory_trie "github.com/ChainSafe/gossamer/pkg/trie/inmemory"
 	"github.com/ChainSafe/gossamer/pkg/trie/inmemory/proof"
 	"github.com/tetratelabs/wazero/api"
 )
@@ -813,7 +812,7 @@ func ext_trie_blake2_256_root_version_2(ctx context.Context, m api.Module, dataS
 		return 0
 	}
 
-	hash, err := stateVersion.Root(inmemory_trie.NewEmptyTrie(), entries)
+	hash, err := stateVersion.Root(entries)
 	if err != nil {
 		logger.Errorf("failed computing trie Merkle root hash: %s", err)
 		return 0
@@ -857,18 +856,6 @@ func ext_trie_blake2_256_ordered_root_version_2(
 		return 0
 	}
 
-	var entries trie.Entries
-
-	for i, value := range values {
-		key, err := scale.Marshal(big.NewInt(int64(i)))
-		if err != nil {
-			logger.Errorf("failed scale encoding value index %d: %s", i, err)
-			return 0
-		}
-
-		entries = append(entries, trie.Entry{Key: key, Value: value})
-	}
-
 	// allocate memory for value and copy value to memory
 	ptr, err := rtCtx.Allocator.Allocate(m.Memory(), 32)
 	if err != nil {
@@ -876,7 +863,7 @@ func ext_trie_blake2_256_ordered_root_version_2(
 		return 0
 	}
 
-	hash, err := stateVersion.Root(inmemory_trie.NewEmptyTrie(), entries)
+	hash, err := stateVersion.OrderedRoot(values)
 	if err != nil {
 		logger.Errorf("failed computing trie Merkle root hash: %s", err)
 		return 0
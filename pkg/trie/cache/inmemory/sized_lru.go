@@ -0,0 +1,108 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package inmemory
+
+import "container/list"
+
+// entryOverheadSize is the estimated size in bytes of the bookkeeping of a cache entry:
+// its list element, map bucket slot and key slice header.
+const entryOverheadSize = 96
+
+type sizedLRUEntry[K comparable] struct {
+	key   K
+	value []byte
+	size  uint64
+}
+
+// sizedLRU is a least recently used cache bounded by the size in bytes of its entries.
+// It is not safe for concurrent use.
+type sizedLRU[K comparable] struct {
+	maxSize uint64
+	size    uint64
+	entries map[K]*list.Element
+	order   *list.List
+}
+
+func newSizedLRU[K comparable](maxSize uint64) *sizedLRU[K] {
+	return &sizedLRU[K]{
+		maxSize: maxSize,
+		entries: make(map[K]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the value of the key and marks it as the most recently used.
+func (c *sizedLRU[K]) get(key K) (value []byte, ok bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*sizedLRUEntry[K]).value, true
+}
+
+// peek returns the value of the key without changing its recency.
+func (c *sizedLRU[K]) peek(key K) (value []byte, ok bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return element.Value.(*sizedLRUEntry[K]).value, true
+}
+
+// add inserts or replaces the value of the key as the most recently used and evicts
+// the least recently used entries until the cache fits its maximum size.
+// The size of the key in bytes is given by the caller since the key type is generic.
+func (c *sizedLRU[K]) add(key K, keySize int, value []byte) {
+	size := uint64(keySize+len(value)) + entryOverheadSize
+	if size > c.maxSize {
+		c.remove(key)
+		return
+	}
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*sizedLRUEntry[K])
+		c.size = c.size - entry.size + size
+		entry.value = value
+		entry.size = size
+		c.order.MoveToFront(element)
+	} else {
+		c.entries[key] = c.order.PushFront(&sizedLRUEntry[K]{key: key, value: value, size: size})
+		c.size += size
+	}
+
+	for c.size > c.maxSize {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *sizedLRU[K]) remove(key K) {
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+func (c *sizedLRU[K]) removeElement(element *list.Element) {
+	entry := c.order.Remove(element).(*sizedLRUEntry[K])
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
+
+// each calls f for each entry from the least to the most recently used.
+func (c *sizedLRU[K]) each(f func(key K, value []byte)) {
+	for element := c.order.Back(); element != nil; element = element.Prev() {
+		entry := element.Value.(*sizedLRUEntry[K])
+		f(entry.key, entry.value)
+	}
+}
+
+func (c *sizedLRU[K]) len() int {
+	return len(c.entries)
+}
+
+func (c *sizedLRU[K]) clear() {
+	c.entries = make(map[K]*list.Element)
+	c.order.Init()
+	c.size = 0
+}
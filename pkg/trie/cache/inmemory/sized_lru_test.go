@@ -0,0 +1,78 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sizedLRU(t *testing.T) {
+	const entrySize = 2 + 10 + entryOverheadSize
+
+	t.Run("set_and_get_value_successful", func(t *testing.T) {
+		cache := newSizedLRU[string](entrySize)
+		cache.add("k1", 2, make([]byte, 10))
+
+		value, ok := cache.get("k1")
+		assert.True(t, ok)
+		assert.Equal(t, make([]byte, 10), value)
+		assert.Equal(t, uint64(entrySize), cache.size)
+	})
+
+	t.Run("get_value_not_found", func(t *testing.T) {
+		cache := newSizedLRU[string](entrySize)
+		value, ok := cache.get("missing")
+		assert.False(t, ok)
+		assert.Nil(t, value)
+	})
+
+	t.Run("nil_value_is_cached", func(t *testing.T) {
+		cache := newSizedLRU[string](entrySize)
+		cache.add("k1", 2, nil)
+
+		value, ok := cache.get("k1")
+		assert.True(t, ok)
+		assert.Nil(t, value)
+	})
+
+	t.Run("evict_least_recently_used_when_size_exceeded", func(t *testing.T) {
+		cache := newSizedLRU[string](2 * entrySize)
+		cache.add("k1", 2, make([]byte, 10))
+		cache.add("k2", 2, make([]byte, 10))
+
+		// k1 becomes the most recently used
+		_, ok := cache.get("k1")
+		assert.True(t, ok)
+
+		cache.add("k3", 2, make([]byte, 10))
+		_, ok = cache.peek("k2")
+		assert.False(t, ok)
+		_, ok = cache.peek("k1")
+		assert.True(t, ok)
+		_, ok = cache.peek("k3")
+		assert.True(t, ok)
+		assert.Equal(t, uint64(2*entrySize), cache.size)
+	})
+
+	t.Run("replace_value_updates_size", func(t *testing.T) {
+		cache := newSizedLRU[string](2 * entrySize)
+		cache.add("k1", 2, make([]byte, 10))
+		cache.add("k1", 2, make([]byte, 5))
+
+		assert.Equal(t, uint64(entrySize-5), cache.size)
+		assert.Equal(t, 1, cache.len())
+	})
+
+	t.Run("value_larger_than_cache_is_not_cached", func(t *testing.T) {
+		cache := newSizedLRU[string](entrySize)
+		cache.add("k1", 2, make([]byte, 10))
+		cache.add("k1", 2, make([]byte, 11))
+
+		_, ok := cache.peek("k1")
+		assert.False(t, ok)
+		assert.Zero(t, cache.size)
+	})
+}
@@ -4,46 +4,284 @@
 package inmemory
 
 import (
-	lrucache "github.com/ChainSafe/gossamer/lib/utils/lru-cache"
+	"sync"
+
 	"github.com/ChainSafe/gossamer/pkg/trie/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 // https://github.com/paritytech/polkadot-sdk/blob/a8f4f4f00f8fc0da512a09e1450bf4cda954d70d/substrate/primitives/trie/src/cache/mod.rs#L98
-const defaultNodeCacheMaxElements = 10000
-const defaultValueCacheMaxSize = 2 * 1024 * 1024 // 2MB
+const (
+	// DefaultSharedCacheSize is the default maximum size in bytes of the shared trie cache
+	DefaultSharedCacheSize = 64 * 1024 * 1024 // 64MiB
+	// localNodeCacheMaxSize is the maximum size in bytes of the node cache of a local cache
+	localNodeCacheMaxSize = 8 * 1024 * 1024 // 8MiB
+	// localValueCacheMaxSize is the maximum size in bytes of the value cache of a local cache
+	localValueCacheMaxSize = 2 * 1024 * 1024 // 2MiB
+)
+
+const (
+	nodeCacheName  = "node"
+	valueCacheName = "value"
+	localLevel     = "local"
+	sharedLevel    = "shared"
+)
+
+var (
+	cacheHitsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gossamer_trie_cache",
+		Name:      "hits_total",
+		Help:      "total number of trie cache hits per cache and level",
+	}, []string{"cache", "level"})
+	cacheMissesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gossamer_trie_cache",
+		Name:      "misses_total",
+		Help:      "total number of trie cache misses per cache",
+	}, []string{"cache"})
+	cacheSizeGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gossamer_trie_cache",
+		Name:      "size_bytes",
+		Help:      "size in bytes of the shared trie cache per cache",
+	}, []string{"cache"})
+	cacheEntriesGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gossamer_trie_cache",
+		Name:      "entries",
+		Help:      "number of entries of the shared trie cache per cache",
+	}, []string{"cache"})
+)
+
+// valueCacheKey is the key of a value in the value cache, the full key of the
+// value in the trie with the given root.
+type valueCacheKey struct {
+	root string
+	key  string
+}
+
+func newValueCacheKey(root, key []byte) valueCacheKey {
+	return valueCacheKey{root: string(root), key: string(key)}
+}
+
+func (k valueCacheKey) size() int {
+	return len(k.root) + len(k.key)
+}
+
+// SharedTrieCache is the trie cache shared by the tries of all the blocks. It has a
+// node cache keyed by hash, which also holds the hashed values, and a value cache keyed
+// by the storage root and the full key of the values. Both caches are bounded by their
+// size in bytes and evict their least recently used entries.
+// The tries of a block use a LocalTrieCache which is merged into the shared cache.
+type SharedTrieCache struct {
+	mtx    sync.RWMutex
+	nodes  *sizedLRU[string]
+	values *sizedLRU[valueCacheKey]
+}
+
+var _ cache.TrieCache = (*SharedTrieCache)(nil)
+
+// NewSharedTrieCache creates a new shared trie cache bounded by the given size in bytes,
+// split between 70% for the node cache and 30% for the value cache.
+func NewSharedTrieCache(maxSize uint64) *SharedTrieCache {
+	nodeCacheMaxSize := maxSize * 70 / 100
+	return &SharedTrieCache{
+		nodes:  newSizedLRU[string](nodeCacheMaxSize),
+		values: newSizedLRU[valueCacheKey](maxSize - nodeCacheMaxSize),
+	}
+}
+
+// LocalCache returns a new local cache reading through the shared cache.
+func (c *SharedTrieCache) LocalCache() *LocalTrieCache {
+	return &LocalTrieCache{
+		shared: c,
+		nodes:  newSizedLRU[string](localNodeCacheMaxSize),
+		values: newSizedLRU[valueCacheKey](localValueCacheMaxSize),
+	}
+}
+
+// GetNode returns the encoded node with the given hash
+func (c *SharedTrieCache) GetNode(hash []byte) []byte {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	node, ok := c.nodes.get(string(hash))
+	countLookup(nodeCacheName, sharedLevel, ok)
+	return node
+}
+
+// SetNode caches the encoded node with the given hash
+func (c *SharedTrieCache) SetNode(hash, encodedNode []byte) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.nodes.add(string(hash), len(hash), encodedNode)
+	c.updateGauges()
+}
+
+// GetValue returns the hashed value with the given hash
+func (c *SharedTrieCache) GetValue(hash []byte) []byte {
+	return c.GetNode(hash)
+}
+
+// SetValue caches the hashed value with the given hash
+func (c *SharedTrieCache) SetValue(hash, value []byte) {
+	c.SetNode(hash, value)
+}
+
+// GetValueForKey returns the value at the full key of the trie with the given root
+func (c *SharedTrieCache) GetValueForKey(root, key []byte) (value []byte, cached bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	value, cached = c.values.get(newValueCacheKey(root, key))
+	countLookup(valueCacheName, sharedLevel, cached)
+	return value, cached
+}
+
+// SetValueForKey caches the value at the full key of the trie with the given root
+func (c *SharedTrieCache) SetValueForKey(root, key, value []byte) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	cacheKey := newValueCacheKey(root, key)
+	c.values.add(cacheKey, cacheKey.size(), value)
+	c.updateGauges()
+}
+
+func (c *SharedTrieCache) peekNode(hash string) ([]byte, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.nodes.peek(hash)
+}
+
+func (c *SharedTrieCache) peekValue(cacheKey valueCacheKey) ([]byte, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.values.peek(cacheKey)
+}
+
+// merge inserts the entries of the local cache, or marks them as most recently used.
+func (c *SharedTrieCache) merge(local *LocalTrieCache) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
 
-// TrieInMemoryCache is an in-memory cache for trie nodes
-type TrieInMemoryCache struct {
-	nodeCache  *lrucache.LRUCache[string, []byte]
-	valueCache *maxBytesLRUCache
+	local.nodes.each(func(hash string, node []byte) {
+		c.nodes.add(hash, len(hash), node)
+	})
+	local.values.each(func(cacheKey valueCacheKey, value []byte) {
+		c.values.add(cacheKey, cacheKey.size(), value)
+	})
+	c.updateGauges()
 }
 
-// NewTrieInMemoryCache creates a new TrieInMemoryCache
-func NewTrieInMemoryCache() *TrieInMemoryCache {
-	return &TrieInMemoryCache{
-		nodeCache:  lrucache.NewLRUCache[string, []byte](defaultNodeCacheMaxElements),
-		valueCache: newLruCache(defaultValueCacheMaxSize),
+// Size returns the size in bytes of the node and value caches.
+func (c *SharedTrieCache) Size() (nodes, values uint64) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.nodes.size, c.values.size
+}
+
+func (c *SharedTrieCache) updateGauges() {
+	cacheSizeGauge.WithLabelValues(nodeCacheName).Set(float64(c.nodes.size))
+	cacheSizeGauge.WithLabelValues(valueCacheName).Set(float64(c.values.size))
+	cacheEntriesGauge.WithLabelValues(nodeCacheName).Set(float64(c.nodes.len()))
+	cacheEntriesGauge.WithLabelValues(valueCacheName).Set(float64(c.values.len()))
+}
+
+func countLookup(cacheName, level string, hit bool) {
+	if hit {
+		cacheHitsCounter.WithLabelValues(cacheName, level).Inc()
+		return
 	}
+	cacheMissesCounter.WithLabelValues(cacheName).Inc()
 }
 
-// GetValue returns the value for the given key
-func (tc *TrieInMemoryCache) GetValue(key []byte) []byte {
-	return tc.valueCache.get(string(key))
+// LocalTrieCache is the trie cache of the tries of a single block. It reads through the
+// shared cache and keeps the nodes and values accessed or written until it is merged
+// into the shared cache, so the shared cache is only locked for writing once per block.
+type LocalTrieCache struct {
+	shared *SharedTrieCache
+
+	mtx    sync.Mutex
+	nodes  *sizedLRU[string]
+	values *sizedLRU[valueCacheKey]
 }
 
-// SetValue sets the value for the given key
-func (tc *TrieInMemoryCache) SetValue(key []byte, value []byte) {
-	tc.valueCache.set(string(key), value)
+var _ cache.TrieCache = (*LocalTrieCache)(nil)
+
+// GetNode returns the encoded node with the given hash from the local cache,
+// or from the shared cache and caches it locally.
+func (c *LocalTrieCache) GetNode(hash []byte) []byte {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	node, ok := c.nodes.get(string(hash))
+	if ok {
+		countLookup(nodeCacheName, localLevel, true)
+		return node
+	}
+
+	node, ok = c.shared.peekNode(string(hash))
+	countLookup(nodeCacheName, sharedLevel, ok)
+	if ok {
+		c.nodes.add(string(hash), len(hash), node)
+	}
+	return node
+}
+
+// SetNode caches the encoded node with the given hash locally
+func (c *LocalTrieCache) SetNode(hash, encodedNode []byte) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.nodes.add(string(hash), len(hash), encodedNode)
+}
+
+// GetValue returns the hashed value with the given hash
+func (c *LocalTrieCache) GetValue(hash []byte) []byte {
+	return c.GetNode(hash)
+}
+
+// SetValue caches the hashed value with the given hash locally
+func (c *LocalTrieCache) SetValue(hash, value []byte) {
+	c.SetNode(hash, value)
 }
 
-// GetNode returns the node for the given key
-func (tc *TrieInMemoryCache) GetNode(key []byte) []byte {
-	return tc.nodeCache.Get(string(key))
+// GetValueForKey returns the value at the full key of the trie with the given root
+// from the local cache, or from the shared cache and caches it locally.
+func (c *LocalTrieCache) GetValueForKey(root, key []byte) (value []byte, cached bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	cacheKey := newValueCacheKey(root, key)
+	value, cached = c.values.get(cacheKey)
+	if cached {
+		countLookup(valueCacheName, localLevel, true)
+		return value, true
+	}
+
+	value, cached = c.shared.peekValue(cacheKey)
+	countLookup(valueCacheName, sharedLevel, cached)
+	if cached {
+		c.values.add(cacheKey, cacheKey.size(), value)
+	}
+	return value, cached
 }
 
-// SetNode sets the node for the given key
-func (tc *TrieInMemoryCache) SetNode(key, value []byte) {
-	tc.nodeCache.Put(string(key), value)
+// SetValueForKey caches the value at the full key of the trie with the given root locally
+func (c *LocalTrieCache) SetValueForKey(root, key, value []byte) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	cacheKey := newValueCacheKey(root, key)
+	c.values.add(cacheKey, cacheKey.size(), value)
 }
 
-var _ cache.TrieCache = (*TrieInMemoryCache)(nil)
+// Merge merges the local cache into the shared cache and clears it.
+func (c *LocalTrieCache) Merge() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.shared.merge(c)
+	c.nodes.clear()
+	c.values.clear()
+}
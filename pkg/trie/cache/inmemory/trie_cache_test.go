@@ -9,21 +9,86 @@
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_TrieCache_SetAndGet(t *testing.T) {
-	t.Run("set_and_get_value_successful", func(t *testing.T) {
-		cache := NewTrieInMemoryCache()
-		key := []byte("key")
-		value := []byte("value")
+func Test_SharedTrieCache_SetAndGet(t *testing.T) {
+	t.Run("set_and_get_node_successful", func(t *testing.T) {
+		cache := NewSharedTrieCache(DefaultSharedCacheSize)
+		hash := []byte("hash")
+		node := []byte("node")
 
-		cache.SetValue(key, value)
-		valueFromCache := cache.GetValue(key)
+		cache.SetNode(hash, node)
+		assert.Equal(t, node, cache.GetNode(hash))
+		assert.Equal(t, node, cache.GetValue(hash))
+	})
+
+	t.Run("get_node_not_found", func(t *testing.T) {
+		cache := NewSharedTrieCache(DefaultSharedCacheSize)
+		assert.Nil(t, cache.GetNode([]byte("missing")))
+	})
+
+	t.Run("values_are_keyed_by_root_and_key", func(t *testing.T) {
+		cache := NewSharedTrieCache(DefaultSharedCacheSize)
+		cache.SetValueForKey([]byte("root1"), []byte("key"), []byte("value"))
+		cache.SetValueForKey([]byte("root2"), []byte("key"), nil)
+
+		value, cached := cache.GetValueForKey([]byte("root1"), []byte("key"))
+		assert.True(t, cached)
+		assert.Equal(t, []byte("value"), value)
+
+		value, cached = cache.GetValueForKey([]byte("root2"), []byte("key"))
+		assert.True(t, cached)
+		assert.Nil(t, value)
+
+		_, cached = cache.GetValueForKey([]byte("root3"), []byte("key"))
+		assert.False(t, cached)
+	})
 
-		assert.Equal(t, value, valueFromCache)
+	t.Run("size_is_bounded", func(t *testing.T) {
+		const maxSize = 10 * 1024
+		cache := NewSharedTrieCache(maxSize)
+		for i := 0; i < 100; i++ {
+			cache.SetNode([]byte{byte(i)}, make([]byte, 100))
+			cache.SetValueForKey([]byte("root"), []byte{byte(i)}, make([]byte, 100))
+		}
+
+		nodes, values := cache.Size()
+		assert.LessOrEqual(t, nodes, uint64(maxSize*70/100))
+		assert.LessOrEqual(t, values, uint64(maxSize*30/100))
+		assert.NotNil(t, cache.GetNode([]byte{99}))
+		assert.Nil(t, cache.GetNode([]byte{0}))
+	})
+}
+
+func Test_LocalTrieCache(t *testing.T) {
+	t.Run("reads_through_shared_cache", func(t *testing.T) {
+		shared := NewSharedTrieCache(DefaultSharedCacheSize)
+		shared.SetNode([]byte("hash"), []byte("node"))
+		shared.SetValueForKey([]byte("root"), []byte("key"), []byte("value"))
+
+		local := shared.LocalCache()
+		assert.Equal(t, []byte("node"), local.GetNode([]byte("hash")))
+		value, cached := local.GetValueForKey([]byte("root"), []byte("key"))
+		assert.True(t, cached)
+		assert.Equal(t, []byte("value"), value)
 	})
 
-	t.Run("get_value_not_found", func(t *testing.T) {
-		cache := NewTrieInMemoryCache()
-		valueFromCache := cache.GetValue([]byte("missing"))
-		assert.Nil(t, valueFromCache)
+	t.Run("local_entries_are_shared_once_merged", func(t *testing.T) {
+		shared := NewSharedTrieCache(DefaultSharedCacheSize)
+		local := shared.LocalCache()
+		local.SetNode([]byte("hash"), []byte("node"))
+		local.SetValueForKey([]byte("root"), []byte("key"), nil)
+
+		assert.Equal(t, []byte("node"), local.GetNode([]byte("hash")))
+		assert.Nil(t, shared.GetNode([]byte("hash")))
+		_, cached := shared.GetValueForKey([]byte("root"), []byte("key"))
+		assert.False(t, cached)
+
+		local.Merge()
+
+		assert.Equal(t, []byte("node"), shared.GetNode([]byte("hash")))
+		value, cached := shared.GetValueForKey([]byte("root"), []byte("key"))
+		assert.True(t, cached)
+		assert.Nil(t, value)
+		assert.Zero(t, local.nodes.len())
+		assert.Zero(t, local.values.len())
 	})
 }
@@ -3,9 +3,20 @@
 
 package cache
 
+// TrieCache caches the nodes and values of the tries of a database.
 type TrieCache interface {
-	GetValue(key []byte) []byte
-	SetValue(key, value []byte)
-	GetNode(key []byte) []byte
-	SetNode(key, value []byte)
+	// GetNode returns the encoded node with the given hash, or nil if it is not cached.
+	GetNode(hash []byte) []byte
+	// SetNode caches the encoded node with the given hash.
+	SetNode(hash, encodedNode []byte)
+	// GetValue returns the hashed value with the given hash, or nil if it is not cached.
+	GetValue(hash []byte) []byte
+	// SetValue caches the hashed value with the given hash.
+	SetValue(hash, value []byte)
+	// GetValueForKey returns the value at the full key of the trie with the given root,
+	// and whether it is cached. A cached nil value means the key is not in the trie.
+	GetValueForKey(root, key []byte) (value []byte, cached bool)
+	// SetValueForKey caches the value at the full key of the trie with the given root,
+	// a nil value caches that the key is not in the trie.
+	SetValueForKey(root, key, value []byte)
 }
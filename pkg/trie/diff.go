@@ -0,0 +1,71 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+// ChildStorageKeyPrefix is the prefix of the keys of the top trie holding the roots
+// of the default child tries.
+var ChildStorageKeyPrefix = []byte(":child_storage:default:")
+
+// DiffKind is the kind of difference of an entry between two tries.
+type DiffKind uint8
+
+const (
+	// Added is an entry only in the new trie.
+	Added DiffKind = iota
+	// Removed is an entry only in the old trie.
+	Removed
+	// Modified is an entry in both tries with different values.
+	Modified
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// Diff is an entry which differs between two tries.
+type Diff struct {
+	Kind DiffKind
+	// ChildKey is the key of the child trie of the entry, without the child storage
+	// key prefix, or nil for the entries of the top trie.
+	ChildKey []byte
+	Key      []byte
+	// OldValue is the value in the old trie, nil for an added entry.
+	OldValue []byte
+	// NewValue is the value in the new trie, nil for a removed entry.
+	NewValue []byte
+}
+
+// DiffIterator iterates over the entries which differ between two tries, in
+// lexicographic order of their keys. The entries of a child trie follow the entry
+// of its root in the top trie.
+type DiffIterator interface {
+	// Next returns the next difference, or nil once all the differences were iterated over.
+	Next() (*Diff, error)
+}
+
+// NewDiff returns the difference of the entry between its old and new values, or nil
+// if the values are equal.
+func NewDiff(key, oldValue, newValue []byte) *Diff {
+	switch {
+	case oldValue == nil && newValue == nil:
+		return nil
+	case oldValue == nil:
+		return &Diff{Kind: Added, Key: key, NewValue: newValue}
+	case newValue == nil:
+		return &Diff{Kind: Removed, Key: key, OldValue: oldValue}
+	case string(oldValue) == string(newValue):
+		return nil
+	default:
+		return &Diff{Kind: Modified, Key: key, OldValue: oldValue, NewValue: newValue}
+	}
+}
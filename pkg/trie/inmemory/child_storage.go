@@ -12,7 +12,7 @@
 )
 
 // ChildStorageKeyPrefix is the prefix for all child storage keys
-var ChildStorageKeyPrefix = []byte(":child_storage:default:")
+var ChildStorageKeyPrefix = trie.ChildStorageKeyPrefix
 
 // setChild inserts a child trie into the main trie at key :child_storage:[keyToChild]
 // A child trie is added as a node (K, V) in the main trie. K is the child storage key
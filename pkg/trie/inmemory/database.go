@@ -135,6 +135,13 @@ func loadStorageValue(db db.DBGetter, node *node.Node) error {
 
 	prefixedKey := bytes.Join([][]byte{node.PartialKey, node.StorageValue[:]}, nil)
 	rawStorageValue, err := db.Get(prefixedKey)
+	if err != nil || rawStorageValue == nil {
+		// the values written by the trie database are keyed by their hash only
+		value, hashErr := db.Get(node.StorageValue)
+		if hashErr == nil && value != nil {
+			rawStorageValue, err = value, nil
+		}
+	}
 	if err != nil {
 		return err
 	}
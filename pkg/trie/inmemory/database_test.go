@@ -4,6 +4,7 @@
 package inmemory
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/ChainSafe/gossamer/internal/database"
@@ -12,6 +13,7 @@
 	"github.com/ChainSafe/gossamer/pkg/trie/node"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
 )
 
 func newTestDB(t *testing.T) database.Table {
@@ -38,6 +40,35 @@ func Test_Trie_Store_Load(t *testing.T) {
 	assert.Equal(t, tr.String(), trieFromDB.String())
 }
 
+func Test_RootBuilder_Writer_Load(t *testing.T) {
+	t.Parallel()
+
+	generator := newGenerator()
+	keyValues := generateKeyValues(t, generator, 1000)
+	keys := maps.Keys(keyValues)
+	slices.Sort(keys)
+
+	for _, version := range []trie.TrieLayout{trie.V0, trie.V1} {
+		tr := NewEmptyTrie()
+		tr.SetVersion(version)
+		db := newTestDB(t)
+		builder := trie.NewRootBuilderWithWriter(version, db)
+		for _, key := range keys {
+			require.NoError(t, tr.Put([]byte(key), keyValues[key]))
+			require.NoError(t, builder.Add([]byte(key), keyValues[key]))
+		}
+		rootHash, err := builder.Root()
+		require.NoError(t, err)
+		require.Equal(t, tr.MustHash(), rootHash)
+
+		// the trie written by the builder is loaded as if the trie wrote it
+		trieFromDB := NewEmptyTrie()
+		err = trieFromDB.Load(db, rootHash)
+		require.NoError(t, err)
+		assert.Equal(t, tr.Entries(), trieFromDB.Entries(), version.String())
+	}
+}
+
 func Test_Trie_Load_EmptyHash(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,237 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package inmemory
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/ChainSafe/gossamer/pkg/trie/codec"
+	"github.com/ChainSafe/gossamer/pkg/trie/node"
+)
+
+// diffCursorItem is a node to visit with its nibble path, the nibble path of its
+// parent followed by its partial key.
+type diffCursorItem struct {
+	prefix []byte
+	node   *node.Node
+	path   []byte
+}
+
+// diffCursor visits the nodes of a trie in depth first pre-order, which is the
+// lexicographic order of their nibble paths.
+type diffCursor struct {
+	trie  *InMemoryTrie
+	stack []*diffCursorItem
+}
+
+func newDiffCursor(t *InMemoryTrie) *diffCursor {
+	cursor := &diffCursor{trie: t}
+	if t.root != nil {
+		cursor.stack = []*diffCursorItem{newDiffCursorItem(nil, t.root)}
+	}
+	return cursor
+}
+
+func newDiffCursorItem(prefix []byte, n *node.Node) *diffCursorItem {
+	return &diffCursorItem{
+		prefix: prefix,
+		node:   n,
+		path:   concatenateSlices(prefix, n.PartialKey),
+	}
+}
+
+func (c *diffCursor) front() *diffCursorItem {
+	if len(c.stack) == 0 {
+		return nil
+	}
+	return c.stack[len(c.stack)-1]
+}
+
+// skip moves to the next node which is not a descendant of the front node.
+func (c *diffCursor) skip() {
+	c.stack = c.stack[:len(c.stack)-1]
+}
+
+// next moves to the next node, the first child of the front node if any.
+func (c *diffCursor) next() {
+	item := c.front()
+	c.skip()
+	for i := len(item.node.Children) - 1; i >= 0; i-- {
+		child := item.node.Children[i]
+		if child == nil {
+			continue
+		}
+		prefix := concatenateSlices(item.path, []byte{byte(i)})
+		c.stack = append(c.stack, newDiffCursorItem(prefix, child))
+	}
+}
+
+// value returns the value of the node, or nil if it has none.
+func (c *diffCursor) value(item *diffCursorItem) ([]byte, error) {
+	n := item.node
+	if n.Kind() == node.Branch && n.StorageValue == nil {
+		return nil, nil
+	}
+	if !n.IsHashedValue {
+		if n.StorageValue == nil {
+			return []byte{}, nil
+		}
+		return n.StorageValue, nil
+	}
+	value, err := c.trie.db.Get(n.StorageValue)
+	if err != nil {
+		return nil, fmt.Errorf("getting hashed value 0x%x: %w", n.StorageValue, err)
+	}
+	return value, nil
+}
+
+// InMemoryTrieDiffIterator iterates over the entries which differ between two tries,
+// walking both tries in parallel and skipping the subtrees they share, which are the
+// same nodes or nodes with the same Merkle value.
+type InMemoryTrieDiffIterator struct {
+	old *diffCursor
+	new *diffCursor
+	// isChild is set for the iterators of child tries, which have no child tries
+	isChild bool
+	// child iterates over the child trie of childKey once its root differs
+	child    *InMemoryTrieDiffIterator
+	childKey []byte
+}
+
+var _ trie.DiffIterator = (*InMemoryTrieDiffIterator)(nil)
+
+// NewInMemoryTrieDiffIterator creates an iterator over the entries which differ between
+// the old and new tries.
+func NewInMemoryTrieDiffIterator(oldTrie, newTrie *InMemoryTrie) *InMemoryTrieDiffIterator {
+	return &InMemoryTrieDiffIterator{
+		old: newDiffCursor(oldTrie),
+		new: newDiffCursor(newTrie),
+	}
+}
+
+// Next returns the next difference between the tries, or nil once all the differences
+// were iterated over.
+func (i *InMemoryTrieDiffIterator) Next() (*trie.Diff, error) {
+	if i.child != nil {
+		diff, err := i.child.Next()
+		if err != nil {
+			return nil, fmt.Errorf("child trie 0x%x: %w", i.childKey, err)
+		}
+		if diff != nil {
+			diff.ChildKey = i.childKey
+			return diff, nil
+		}
+		i.child = nil
+	}
+
+	diff, err := i.nextTopDiff()
+	if err != nil || diff == nil {
+		return nil, err
+	}
+
+	if !i.isChild && bytes.HasPrefix(diff.Key, ChildStorageKeyPrefix) {
+		i.child, err = i.childIterator(diff.OldValue, diff.NewValue)
+		if err != nil {
+			return nil, fmt.Errorf("child trie 0x%x: %w", diff.Key, err)
+		}
+		i.childKey = diff.Key[len(ChildStorageKeyPrefix):]
+	}
+	return diff, nil
+}
+
+func (i *InMemoryTrieDiffIterator) nextTopDiff() (*trie.Diff, error) {
+	for {
+		oldItem, newItem := i.old.front(), i.new.front()
+		if oldItem == nil && newItem == nil {
+			return nil, nil
+		}
+
+		if oldItem != nil && newItem != nil && sameNode(oldItem, newItem) {
+			i.old.skip()
+			i.new.skip()
+			continue
+		}
+
+		var cmp int
+		switch {
+		case oldItem == nil:
+			cmp = 1
+		case newItem == nil:
+			cmp = -1
+		default:
+			cmp = bytes.Compare(oldItem.path, newItem.path)
+		}
+
+		var key, oldValue, newValue []byte
+		if cmp <= 0 {
+			if len(oldItem.path)%2 == 0 {
+				key = codec.NibblesToKeyLE(oldItem.path)
+				value, err := i.old.value(oldItem)
+				if err != nil {
+					return nil, err
+				}
+				oldValue = value
+			}
+			i.old.next()
+		}
+		if cmp >= 0 {
+			if len(newItem.path)%2 == 0 {
+				key = codec.NibblesToKeyLE(newItem.path)
+				value, err := i.new.value(newItem)
+				if err != nil {
+					return nil, err
+				}
+				newValue = value
+			}
+			i.new.next()
+		}
+
+		if diff := trie.NewDiff(key, oldValue, newValue); diff != nil {
+			return diff, nil
+		}
+	}
+}
+
+// childIterator creates the iterator over the child tries with the given old and new roots,
+// a nil root being an empty child trie.
+func (i *InMemoryTrieDiffIterator) childIterator(oldRoot, newRoot []byte) (*InMemoryTrieDiffIterator, error) {
+	oldTrie, err := i.old.childTrie(oldRoot)
+	if err != nil {
+		return nil, err
+	}
+	newTrie, err := i.new.childTrie(newRoot)
+	if err != nil {
+		return nil, err
+	}
+	child := NewInMemoryTrieDiffIterator(oldTrie, newTrie)
+	child.isChild = true
+	return child, nil
+}
+
+func (c *diffCursor) childTrie(root []byte) (*InMemoryTrie, error) {
+	if root == nil {
+		return NewEmptyTrie(), nil
+	}
+	child, ok := c.trie.childTries[common.BytesToHash(root)]
+	if !ok {
+		return nil, fmt.Errorf("%w: root 0x%x", trie.ErrChildTrieDoesNotExist, root)
+	}
+	return child, nil
+}
+
+// sameNode returns true if both items are the same node at the same position, so
+// the subtrees they are the root of are identical.
+func sameNode(a, b *diffCursorItem) bool {
+	if !bytes.Equal(a.prefix, b.prefix) {
+		return false
+	}
+	if a.node == b.node {
+		return true
+	}
+	// the Merkle value is cleared when the node is modified
+	return len(a.node.MerkleValue) > 0 && bytes.Equal(a.node.MerkleValue, b.node.MerkleValue)
+}
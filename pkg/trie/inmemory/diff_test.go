@@ -0,0 +1,131 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package inmemory
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectDiffs(t *testing.T, iter trie.DiffIterator) []trie.Diff {
+	t.Helper()
+
+	var diffs []trie.Diff
+	for {
+		diff, err := iter.Next()
+		require.NoError(t, err)
+		if diff == nil {
+			return diffs
+		}
+		diffs = append(diffs, *diff)
+	}
+}
+
+func Test_InMemoryTrieDiffIterator(t *testing.T) {
+	t.Parallel()
+
+	newTrie := func(t *testing.T, version trie.TrieLayout, entries map[string][]byte) *InMemoryTrie {
+		t.Helper()
+
+		tr := NewEmptyTrie()
+		tr.SetVersion(version)
+		for key, value := range entries {
+			require.NoError(t, tr.Put([]byte(key), value))
+		}
+		return tr
+	}
+
+	oldEntries := map[string][]byte{
+		"no":           {1},
+		"noot":         {2},
+		"not":          bytes.Repeat([]byte{3}, 40),
+		"notable":      {4},
+		"notification": bytes.Repeat([]byte{5}, 40),
+		"test":         {6},
+	}
+	expected := []trie.Diff{
+		{Kind: trie.Modified, Key: []byte("noot"), OldValue: []byte{2}, NewValue: bytes.Repeat([]byte{2}, 40)},
+		{Kind: trie.Added, Key: []byte("nothing"), NewValue: []byte{8}},
+		{
+			Kind:     trie.Modified,
+			Key:      []byte("notification"),
+			OldValue: bytes.Repeat([]byte{5}, 40),
+			NewValue: bytes.Repeat([]byte{7}, 40),
+		},
+		{Kind: trie.Removed, Key: []byte("test"), OldValue: []byte{6}},
+	}
+
+	for _, version := range []trie.TrieLayout{trie.V0, trie.V1} {
+		t.Run(version.String(), func(t *testing.T) {
+			t.Parallel()
+
+			oldTrie := newTrie(t, version, oldEntries)
+			oldTrie.MustHash()
+
+			newTrie := oldTrie.Snapshot()
+			require.NoError(t, newTrie.Put([]byte("noot"), bytes.Repeat([]byte{2}, 40)))
+			require.NoError(t, newTrie.Put([]byte("notification"), bytes.Repeat([]byte{7}, 40)))
+			require.NoError(t, newTrie.Put([]byte("nothing"), []byte{8}))
+			require.NoError(t, newTrie.Delete([]byte("test")))
+
+			assert.Empty(t, collectDiffs(t, NewInMemoryTrieDiffIterator(oldTrie, oldTrie.Snapshot())))
+			assert.Equal(t, expected, collectDiffs(t, NewInMemoryTrieDiffIterator(oldTrie, newTrie)))
+
+			// tries built separately share no nodes but the ones with the same Merkle value
+			newTrie.MustHash()
+			builtTrie := newTrie.DeepCopy()
+			assert.Empty(t, collectDiffs(t, NewInMemoryTrieDiffIterator(newTrie, builtTrie)))
+			assert.Equal(t, expected, collectDiffs(t, NewInMemoryTrieDiffIterator(oldTrie, builtTrie)))
+		})
+	}
+
+	t.Run("empty_tries", func(t *testing.T) {
+		t.Parallel()
+
+		oldTrie := newTrie(t, trie.V1, oldEntries)
+		assert.Empty(t, collectDiffs(t, NewInMemoryTrieDiffIterator(NewEmptyTrie(), NewEmptyTrie())))
+
+		diffs := collectDiffs(t, NewInMemoryTrieDiffIterator(oldTrie, NewEmptyTrie()))
+		require.Len(t, diffs, len(oldEntries))
+		for _, diff := range diffs {
+			assert.Equal(t, trie.Removed, diff.Kind)
+			assert.Equal(t, oldEntries[string(diff.Key)], diff.OldValue)
+		}
+	})
+
+	t.Run("child_tries", func(t *testing.T) {
+		t.Parallel()
+
+		oldTrie := newTrie(t, trie.V1, map[string][]byte{"key": {1}})
+		require.NoError(t, oldTrie.PutIntoChild([]byte("child"), []byte("a"), []byte{1}))
+		require.NoError(t, oldTrie.PutIntoChild([]byte("child"), []byte("b"), []byte{2}))
+		oldChildRoot := oldTrie.Get(append(bytes.Clone(ChildStorageKeyPrefix), []byte("child")...))
+
+		newTrie := oldTrie.Snapshot()
+		require.NoError(t, newTrie.PutIntoChild([]byte("child"), []byte("c"), []byte{3}))
+		require.NoError(t, newTrie.ClearFromChild([]byte("child"), []byte("b")))
+		childKey := append(bytes.Clone(ChildStorageKeyPrefix), []byte("child")...)
+		newChildRoot := newTrie.Get(childKey)
+
+		expected := []trie.Diff{
+			{Kind: trie.Modified, Key: childKey, OldValue: oldChildRoot, NewValue: newChildRoot},
+			{Kind: trie.Removed, ChildKey: []byte("child"), Key: []byte("b"), OldValue: []byte{2}},
+			{Kind: trie.Added, ChildKey: []byte("child"), Key: []byte("c"), NewValue: []byte{3}},
+		}
+		assert.Equal(t, expected, collectDiffs(t, NewInMemoryTrieDiffIterator(oldTrie, newTrie)))
+
+		withoutChild := oldTrie.Snapshot()
+		require.NoError(t, withoutChild.DeleteChild([]byte("child")))
+		expected = []trie.Diff{
+			{Kind: trie.Removed, Key: childKey, OldValue: oldChildRoot},
+			{Kind: trie.Removed, ChildKey: []byte("child"), Key: []byte("a"), OldValue: []byte{1}},
+			{Kind: trie.Removed, ChildKey: []byte("child"), Key: []byte("b"), OldValue: []byte{2}},
+		}
+		assert.Equal(t, expected, collectDiffs(t, NewInMemoryTrieDiffIterator(oldTrie, withoutChild)))
+	})
+}
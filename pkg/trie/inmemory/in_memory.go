@@ -691,9 +691,19 @@ func makeChildPrefix(branchPrefix, branchKey []byte,
 // Note the key argument is given in little Endian format.
 func (t *InMemoryTrie) Get(keyLE []byte) (value []byte) {
 	keyNibbles := codec.KeyLEToNibbles(keyLE)
+	if emptyKeyMissesNode(t.root, keyNibbles) {
+		return nil
+	}
 	return retrieve(t.db, t.root, keyNibbles)
 }
 
+// emptyKeyMissesNode returns true if the key is empty and the node has a partial key.
+// The lookup and deletion functions of a node treat an empty key as the key of the node
+// itself, so they must not be called with an empty key for a node with a partial key.
+func emptyKeyMissesNode(n *node.Node, key []byte) bool {
+	return n != nil && len(key) == 0 && len(n.PartialKey) > 0
+}
+
 func retrieve(db db.DBGetter, parent *node.Node, key []byte) (value []byte) {
 	if parent == nil {
 		return nil
@@ -730,9 +740,17 @@ func retrieveFromBranch(db db.DBGetter, branch *node.Node, key []byte) (value []
 	}
 
 	commonPrefixLength := lenCommonPrefix(branch.PartialKey, key)
+	if commonPrefixLength < len(branch.PartialKey) {
+		// the key diverges from the branch partial key
+		return nil
+	}
+
 	childIndex := key[commonPrefixLength]
 	childKey := key[commonPrefixLength+1:]
 	child := branch.Children[childIndex]
+	if emptyKeyMissesNode(child, childKey) {
+		return nil
+	}
 	return retrieve(db, child, childKey)
 }
 
@@ -1140,6 +1158,9 @@ func (t *InMemoryTrie) Delete(keyLE []byte) (err error) {
 	}()
 
 	key := codec.KeyLEToNibbles(keyLE)
+	if emptyKeyMissesNode(t.root, key) {
+		return nil
+	}
 	root, _, _, err := t.deleteAtNode(t.root, key, pendingDeltas)
 	if err != nil {
 		return fmt.Errorf("deleting key %x: %w", keyLE, err)
@@ -1223,13 +1244,17 @@ func (t *InMemoryTrie) deleteBranch(branch *node.Node, key []byte,
 	}
 
 	commonPrefixLength := lenCommonPrefix(branch.PartialKey, key)
-	keyDoesNotExist := commonPrefixLength == len(key)
+	keyDoesNotExist := commonPrefixLength == len(key) ||
+		commonPrefixLength < len(branch.PartialKey)
 	if keyDoesNotExist {
 		return branch, false, 0, nil
 	}
 	childIndex := key[commonPrefixLength]
 	childKey := key[commonPrefixLength+1:]
 	child := branch.Children[childIndex]
+	if emptyKeyMissesNode(child, childKey) {
+		return branch, false, 0, nil
+	}
 
 	newChild, deleted, nodesRemoved, err := t.deleteAtNode(child, childKey, pendingDeltas)
 	if err != nil {
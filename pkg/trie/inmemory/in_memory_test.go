@@ -2189,6 +2189,18 @@ func Test_retrieve(t *testing.T) {
 			value: []byte{2},
 			db:    defaultDBGetterMock,
 		},
+		"branch_key_diverges_within_partial_key": {
+			parent: &node.Node{
+				PartialKey:   []byte{1, 2},
+				StorageValue: []byte{2},
+				Descendants:  1,
+				Children: padRightChildren([]*node.Node{
+					{PartialKey: []byte{1}, StorageValue: []byte{1}},
+				}),
+			},
+			key: []byte{1, 0, 1},
+			db:  defaultDBGetterMock,
+		},
 		"branch_key_mismatch_with_shorter_search_key": {
 			parent: &node.Node{
 				PartialKey:   []byte{1, 2},
@@ -3502,6 +3514,39 @@ func Test_Trie_clearPrefixAtNode(t *testing.T) {
 	}
 }
 
+func Test_Trie_missingKeysWithinPartialKeys(t *testing.T) {
+	t.Parallel()
+
+	// the root branch has the partial key 0x1, its child at index 1 is a branch with
+	// the partial key 0x11 holding the value of 0x1111 and its child at index 2 is a
+	// leaf, so keys ending or diverging within these partial keys are not in the trie.
+	entries := map[string][]byte{
+		"\x11\x11":         {1},
+		"\x12\x22":         {2},
+		"\x11\x11\x22\x33": {3},
+	}
+	missingKeys := [][]byte{nil, {0x11}, {0x13}, {0x11, 0x10}, {0x11, 0x11, 0x20}}
+
+	trie := NewEmptyTrie()
+	for key, value := range entries {
+		require.NoError(t, trie.Put([]byte(key), value))
+	}
+	root := trie.MustHash()
+
+	for _, key := range missingKeys {
+		assert.Nil(t, trie.Get(key), "key 0x%x", key)
+	}
+
+	for _, key := range missingKeys {
+		require.NoError(t, trie.Delete(key))
+		assert.Equal(t, root, trie.MustHash(), "key 0x%x", key)
+	}
+
+	for key, value := range entries {
+		assert.Equal(t, value, trie.Get([]byte(key)))
+	}
+}
+
 func Test_Trie_Delete(t *testing.T) {
 	t.Parallel()
 
@@ -3722,6 +3767,31 @@ func Test_Trie_deleteAtNode(t *testing.T) {
 				generation: 1,
 			},
 		},
+		"branch_parent_key_diverges_within_partial_key": {
+			trie: InMemoryTrie{
+				generation: 1,
+			},
+			parent: &node.Node{
+				PartialKey:   []byte{1, 2},
+				StorageValue: []byte{1},
+				Descendants:  1,
+				Children: padRightChildren([]*node.Node{
+					{PartialKey: []byte{2}, StorageValue: []byte{1}},
+				}),
+			},
+			key: []byte{1, 0, 2},
+			newParent: &node.Node{
+				PartialKey:   []byte{1, 2},
+				StorageValue: []byte{1},
+				Descendants:  1,
+				Children: padRightChildren([]*node.Node{
+					{PartialKey: []byte{2}, StorageValue: []byte{1}},
+				}),
+			},
+			expectedTrie: InMemoryTrie{
+				generation: 1,
+			},
+		},
 		"branch_parent_matches_key": {
 			trie: InMemoryTrie{
 				generation: 1,
@@ -4,11 +4,14 @@
 package inmemory
 
 import (
+	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/pkg/trie"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_Version_Root(t *testing.T) {
@@ -16,7 +19,6 @@ func Test_Version_Root(t *testing.T) {
 
 	testCases := map[string]struct {
 		version  trie.TrieLayout
-		trie     trie.Trie
 		entries  trie.Entries
 		expected common.Hash
 	}{
@@ -51,9 +53,91 @@ func Test_Version_Root(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			maxInline, err := testCase.version.Root(NewEmptyTrie(), testCase.entries)
+			maxInline, err := testCase.version.Root(testCase.entries)
 			assert.NoError(t, err)
 			assert.Equal(t, testCase.expected, maxInline)
 		})
 	}
 }
+
+func Test_Version_Root_MatchesTrie(t *testing.T) {
+	t.Parallel()
+
+	generator := rand.New(rand.NewSource(0)) //nolint:gosec
+	randomBytes := func(maxLength int) []byte {
+		b := make([]byte, generator.Intn(maxLength+1))
+		_, _ = generator.Read(b)
+		return b
+	}
+
+	testCases := map[string]trie.Entries{
+		"empty":           nil,
+		"single_entry":    {{Key: []byte("key"), Value: []byte("value")}},
+		"empty_key":       {{Key: []byte{}, Value: []byte{1}}, {Key: []byte{1}, Value: []byte{2}}},
+		"nil_value":       {{Key: []byte{1}, Value: nil}, {Key: []byte{1, 2}, Value: []byte{2}}},
+		"prefixed_keys":   {{Key: []byte{1}}, {Key: []byte{1, 2}}, {Key: []byte{1, 2, 3}}, {Key: []byte{1, 3}}},
+		"duplicated_keys": {{Key: []byte{1}, Value: []byte{1}}, {Key: []byte{2}}, {Key: []byte{1}, Value: []byte{3}}},
+		"long_partial_key": {
+			{Key: make([]byte, 100), Value: []byte{1}},
+			{Key: append(make([]byte, 100), 1), Value: make([]byte, 40)},
+		},
+	}
+	for i := 0; i < 10; i++ {
+		var entries trie.Entries
+		for j := 0; j < 1+generator.Intn(500); j++ {
+			entries = append(entries, trie.Entry{Key: randomBytes(6), Value: randomBytes(64)})
+		}
+		testCases[fmt.Sprintf("random_%d", i)] = entries
+	}
+
+	for name, entries := range testCases {
+		entries := entries
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, version := range []trie.TrieLayout{trie.V0, trie.V1} {
+				tr := NewEmptyTrie()
+				tr.SetVersion(version)
+				for _, entry := range entries {
+					require.NoError(t, tr.Put(entry.Key, entry.Value))
+				}
+
+				root, err := version.Root(entries)
+				require.NoError(t, err)
+				assert.Equal(t, tr.MustHash(), root, version.String())
+			}
+		})
+	}
+}
+
+func Benchmark_Version_Root(b *testing.B) {
+	for _, size := range []int{10, 1000, 100000} {
+		generator := rand.New(rand.NewSource(0)) //nolint:gosec
+		entries := make(trie.Entries, size)
+		for i := range entries {
+			entries[i].Key = make([]byte, 32)
+			_, _ = generator.Read(entries[i].Key)
+			entries[i].Value = make([]byte, generator.Intn(64))
+			_, _ = generator.Read(entries[i].Value)
+		}
+
+		b.Run(fmt.Sprintf("trie_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				tr := NewEmptyTrie()
+				tr.SetVersion(trie.V1)
+				for _, entry := range entries {
+					_ = tr.Put(entry.Key, entry.Value)
+				}
+				_ = tr.MustHash()
+			}
+		})
+
+		b.Run(fmt.Sprintf("root_builder_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = trie.V1.Root(entries)
+			}
+		})
+	}
+}
@@ -4,12 +4,16 @@
 package trie
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
+	"slices"
 	"strings"
 
 	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
 )
 
 const (
@@ -81,20 +85,41 @@ func (v TrieLayout) MaxInlineValue() int {
 	}
 }
 
-// Root returns the root hash of the trie built using the given entries
-func (v TrieLayout) Root(t Trie, entries Entries) (common.Hash, error) {
-	// TODO: is there any way to calculate the hash without building a trie?
-	// Eg: https://github.com/paritytech/trie/blob/542829a8195c12b67eef05e9020ec7a6d9313c3f/trie-root/src/lib.rs#L273-L388
-	t.SetVersion(v)
+// Root returns the root hash of the trie with the given entries, computed with a RootBuilder
+// without building the trie. The entries are sorted by key if they are not already, and the
+// last value of a key given more than once is used, as if the entries were inserted in order.
+func (v TrieLayout) Root(entries Entries) (common.Hash, error) {
+	compare := func(a, b Entry) int { return bytes.Compare(a.Key, b.Key) }
+	if !slices.IsSortedFunc(entries, compare) {
+		entries = slices.Clone(entries)
+		slices.SortStableFunc(entries, compare)
+	}
 
-	for _, kv := range entries {
-		err := t.Put(kv.Key, kv.Value)
+	builder := NewRootBuilder(v)
+	for i, entry := range entries {
+		if i+1 < len(entries) && bytes.Equal(entry.Key, entries[i+1].Key) {
+			continue
+		}
+		err := builder.Add(entry.Key, entry.Value)
 		if err != nil {
 			return common.EmptyHash, err
 		}
 	}
+	return builder.Root()
+}
 
-	return t.Hash()
+// OrderedRoot returns the root hash of the trie with the given values keyed by the
+// SCALE compact encoding of their index, such as the extrinsics root of a block.
+func (v TrieLayout) OrderedRoot(values [][]byte) (common.Hash, error) {
+	entries := make(Entries, len(values))
+	for i, value := range values {
+		key, err := scale.Marshal(big.NewInt(int64(i)))
+		if err != nil {
+			return common.EmptyHash, fmt.Errorf("encoding index %d: %w", i, err)
+		}
+		entries[i] = Entry{Key: key, Value: value}
+	}
+	return v.Root(entries)
 }
 
 // Hash returns the root hash of the trie built using the given entries
@@ -0,0 +1,268 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/ChainSafe/gossamer/pkg/trie/codec"
+	"github.com/ChainSafe/gossamer/pkg/trie/db"
+	"github.com/ChainSafe/gossamer/pkg/trie/pools"
+	triedbcodec "github.com/ChainSafe/gossamer/pkg/trie/triedb/codec"
+)
+
+// ErrKeysNotSorted is returned when the keys added to a RootBuilder are not in strictly
+// ascending order.
+var ErrKeysNotSorted = errors.New("keys are not sorted")
+
+// rootBuilderBranch is a branch on the path of the pending entry, holding the Merkle values
+// of its children already built.
+type rootBuilderBranch struct {
+	// depth is the number of nibbles of the full key of the branch
+	depth int
+	// value is the value of the branch, or nil if it has none
+	value    []byte
+	children [16][]byte
+}
+
+// RootBuilder computes the Merkle root of a trie from its entries added in ascending order of
+// keys, without building the trie. It only keeps the branches on the path of the last entry
+// added, so its memory usage is bounded by the depth of the trie.
+// See https://github.com/paritytech/trie/blob/542829a8195c12b67eef05e9020ec7a6d9313c3f/trie-root/src/lib.rs
+type RootBuilder struct {
+	version  TrieLayout
+	writer   db.DBPutter
+	branches []*rootBuilderBranch
+	// key, in nibbles, and value are the last entry added, pending until the next key
+	// tells if it is a leaf or the value of a branch.
+	key      []byte
+	value    []byte
+	hasEntry bool
+	root     common.Hash
+	encoding bytes.Buffer
+}
+
+// NewRootBuilder creates a root builder for the given state trie version.
+func NewRootBuilder(version TrieLayout) *RootBuilder {
+	return &RootBuilder{version: version}
+}
+
+// NewRootBuilderWithWriter creates a root builder for the given state trie version which also
+// writes the trie to the given writer as it is built, the way the in-memory trie writes its
+// nodes to the database: the root and the nodes not inlined in their parent by hash, and the
+// hashed values by the partial key of their node followed by their hash.
+func NewRootBuilderWithWriter(version TrieLayout, writer db.DBPutter) *RootBuilder {
+	return &RootBuilder{version: version, writer: writer}
+}
+
+// Add adds an entry to the trie. The key must be greater than the key of the previous entry.
+func (b *RootBuilder) Add(key, value []byte) error {
+	nibbles := codec.KeyLEToNibbles(key)
+	if value == nil {
+		// nil means there is no value, as for a trie insertion
+		value = []byte{}
+	}
+
+	if b.hasEntry {
+		if bytes.Compare(nibbles, b.key) <= 0 {
+			return fmt.Errorf("%w: key 0x%x after key 0x%x", ErrKeysNotSorted, key, codec.NibblesToKeyLE(b.key))
+		}
+		err := b.flush(codec.CommonPrefix(b.key, nibbles))
+		if err != nil {
+			return err
+		}
+	}
+
+	b.key, b.value, b.hasEntry = nibbles, value, true
+	return nil
+}
+
+// Root returns the Merkle root of the trie with the entries added, and resets the
+// builder so it can be used for another trie.
+func (b *RootBuilder) Root() (root common.Hash, err error) {
+	defer func() {
+		b.branches, b.key, b.value, b.hasEntry = nil, nil, nil, false
+	}()
+
+	if !b.hasEntry {
+		return EmptyHash, nil
+	}
+
+	err = b.flush(-1)
+	if err != nil {
+		return common.EmptyHash, err
+	}
+	return b.root, nil
+}
+
+// flush adds the pending entry to the branches on its path and builds the branches deeper
+// than depth, the length of the common prefix of the pending entry and the next key, or -1
+// to build the whole trie up to its root.
+func (b *RootBuilder) flush(depth int) error {
+	if len(b.key) == depth {
+		// the pending entry is on the path of the next key, so it is the value of a branch
+		b.branches = append(b.branches, &rootBuilderBranch{depth: depth, value: b.value})
+		return nil
+	}
+
+	err := b.addNode(depth, len(b.key), b.value, nil)
+	if err != nil {
+		return fmt.Errorf("adding leaf: %w", err)
+	}
+
+	for len(b.branches) > 0 && b.branches[len(b.branches)-1].depth > depth {
+		branch := b.branches[len(b.branches)-1]
+		b.branches = b.branches[:len(b.branches)-1]
+		err = b.addNode(depth, branch.depth, branch.value, &branch.children)
+		if err != nil {
+			return fmt.Errorf("adding branch: %w", err)
+		}
+	}
+	return nil
+}
+
+// addNode encodes the node at the given depth of the pending entry path and sets its
+// Merkle value as child of its parent branch, creating the parent branch at the common
+// depth if there is none deeper. Without parent, the node is the root of the trie.
+func (b *RootBuilder) addNode(commonDepth, depth int, value []byte, children *[16][]byte) error {
+	if commonDepth >= 0 && (len(b.branches) == 0 || b.branches[len(b.branches)-1].depth < commonDepth) {
+		b.branches = append(b.branches, &rootBuilderBranch{depth: commonDepth})
+	}
+
+	var parent *rootBuilderBranch
+	partialKeyStart := 0
+	if len(b.branches) > 0 {
+		parent = b.branches[len(b.branches)-1]
+		partialKeyStart = parent.depth + 1
+	}
+
+	b.encoding.Reset()
+	err := b.encodeNode(b.key[partialKeyStart:depth], value, children)
+	if err != nil {
+		return err
+	}
+
+	encoding := b.encoding.Bytes()
+	if parent == nil {
+		b.root, err = blake2b(encoding)
+		if err != nil {
+			return err
+		}
+		return b.write(b.root[:], encoding)
+	}
+
+	merkleValue := bytes.Clone(encoding)
+	if len(encoding) >= common.HashLength {
+		digest, err := blake2b(encoding)
+		if err != nil {
+			return err
+		}
+		merkleValue = digest.ToBytes()
+		err = b.write(merkleValue, encoding)
+		if err != nil {
+			return err
+		}
+	}
+	parent.children[b.key[parent.depth]] = merkleValue
+	return nil
+}
+
+// write writes the node or value to the writer of the builder, if any.
+func (b *RootBuilder) write(key, value []byte) error {
+	if b.writer == nil {
+		return nil
+	}
+	err := b.writer.Put(key, bytes.Clone(value))
+	if err != nil {
+		return fmt.Errorf("writing node: %w", err)
+	}
+	return nil
+}
+
+// encodeNode writes the encoding of the leaf, or of the branch if children is not nil,
+// as specified in https://spec.polkadot.network/#sect-state-storage
+func (b *RootBuilder) encodeNode(partialKey, value []byte, children *[16][]byte) (err error) {
+	hashedValue := value != nil && len(value) > b.version.MaxInlineValue()
+
+	var kind triedbcodec.NodeKind
+	switch {
+	case children == nil && hashedValue:
+		kind = triedbcodec.LeafWithHashedValue
+	case children == nil:
+		kind = triedbcodec.LeafNode
+	case value == nil:
+		kind = triedbcodec.BranchWithoutValue
+	case hashedValue:
+		kind = triedbcodec.BranchWithHashedValue
+	default:
+		kind = triedbcodec.BranchWithValue
+	}
+
+	err = triedbcodec.EncodeHeader(codec.NibblesToKeyLE(partialKey), uint(len(partialKey)), kind, &b.encoding)
+	if err != nil {
+		return fmt.Errorf("encoding header: %w", err)
+	}
+
+	if children != nil {
+		var bitmap uint16
+		for i, child := range children {
+			if child != nil {
+				bitmap |= 1 << i
+			}
+		}
+		b.encoding.Write(common.Uint16ToBytes(bitmap))
+	}
+
+	switch {
+	case value == nil:
+	case hashedValue:
+		digest, err := blake2b(value)
+		if err != nil {
+			return err
+		}
+		err = b.write(bytes.Join([][]byte{partialKey, digest[:]}, nil), value)
+		if err != nil {
+			return err
+		}
+		b.encoding.Write(digest[:])
+	default:
+		err = scale.NewEncoder(&b.encoding).Encode(value)
+		if err != nil {
+			return fmt.Errorf("scale encoding value: %w", err)
+		}
+	}
+
+	if children != nil {
+		encoder := scale.NewEncoder(&b.encoding)
+		for _, child := range children {
+			if child == nil {
+				continue
+			}
+			err = encoder.Encode(child)
+			if err != nil {
+				return fmt.Errorf("scale encoding child Merkle value: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// blake2b returns the Blake2b hash digest of the data, using the pooled hashers.
+func blake2b(data []byte) (digest common.Hash, err error) {
+	hasher := pools.Hashers.Get().(hash.Hash)
+	hasher.Reset()
+	defer pools.Hashers.Put(hasher)
+
+	_, err = hasher.Write(data)
+	if err != nil {
+		return digest, fmt.Errorf("hashing: %w", err)
+	}
+	hasher.Sum(digest[:0])
+	return digest, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RootBuilder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_trie", func(t *testing.T) {
+		t.Parallel()
+
+		root, err := NewRootBuilder(V1).Root()
+		require.NoError(t, err)
+		assert.Equal(t, EmptyHash, root)
+	})
+
+	t.Run("single_leaf", func(t *testing.T) {
+		t.Parallel()
+
+		builder := NewRootBuilder(V0)
+		require.NoError(t, builder.Add([]byte{0x12}, []byte{1}))
+		root, err := builder.Root()
+		require.NoError(t, err)
+		// leaf header with a partial key of 2 nibbles, the key and the SCALE encoded value
+		assert.Equal(t, common.MustBlake2bHash([]byte{0x42, 0x12, 0x04, 0x01}), root)
+
+		// the builder is reset once the root is computed
+		root, err = builder.Root()
+		require.NoError(t, err)
+		assert.Equal(t, EmptyHash, root)
+	})
+
+	t.Run("hashed_value", func(t *testing.T) {
+		t.Parallel()
+
+		value := make([]byte, 33)
+		builder := NewRootBuilder(V1)
+		require.NoError(t, builder.Add([]byte{0x12}, value))
+		root, err := builder.Root()
+		require.NoError(t, err)
+
+		valueHash := common.MustBlake2bHash(value)
+		// leaf with hashed value header with a partial key of 2 nibbles
+		expectedEncoding := append([]byte{0x22, 0x12}, valueHash[:]...)
+		assert.Equal(t, common.MustBlake2bHash(expectedEncoding), root)
+	})
+
+	t.Run("branch", func(t *testing.T) {
+		t.Parallel()
+
+		builder := NewRootBuilder(V0)
+		require.NoError(t, builder.Add([]byte{0x10}, []byte{1}))
+		require.NoError(t, builder.Add([]byte{0x12}, []byte{2}))
+		root, err := builder.Root()
+		require.NoError(t, err)
+
+		expectedEncoding := []byte{
+			0x81, 0x01, // branch header with a partial key of 1 nibble, 1
+			0x05, 0x00, // children bitmap of children 0 and 2
+			0x0c, 0x40, 0x04, 0x01, // inlined leaf with empty partial key 0 and value 1
+			0x0c, 0x40, 0x04, 0x02, // inlined leaf with empty partial key 2 and value 2
+		}
+		assert.Equal(t, common.MustBlake2bHash(expectedEncoding), root)
+	})
+
+	t.Run("keys_not_sorted", func(t *testing.T) {
+		t.Parallel()
+
+		builder := NewRootBuilder(V0)
+		require.NoError(t, builder.Add([]byte{2}, []byte{1}))
+		err := builder.Add([]byte{1}, []byte{1})
+		assert.ErrorIs(t, err, ErrKeysNotSorted)
+		assert.EqualError(t, err, "keys are not sorted: key 0x01 after key 0x02")
+		err = builder.Add([]byte{2}, []byte{1})
+		assert.ErrorIs(t, err, ErrKeysNotSorted)
+	})
+}
+
+func Test_Version_OrderedRoot(t *testing.T) {
+	t.Parallel()
+
+	root, err := V0.OrderedRoot(nil)
+	require.NoError(t, err)
+	assert.Equal(t, EmptyHash, root)
+
+	values := make([][]byte, 100)
+	entries := make(Entries, len(values))
+	for i := range values {
+		values[i] = []byte{byte(i)}
+		// SCALE compact encoding of the index
+		key := []byte{byte(i << 2)}
+		if i >= 1<<6 {
+			key = []byte{byte(i<<2) | 1, byte(i >> 6)}
+		}
+		entries[i] = Entry{Key: key, Value: values[i]}
+	}
+
+	root, err = V1.OrderedRoot(values)
+	require.NoError(t, err)
+	expected, err := V1.Root(entries)
+	require.NoError(t, err)
+	assert.Equal(t, expected, root)
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package triedb
+
+import (
+	"github.com/ChainSafe/gossamer/pkg/trie/db"
+	"github.com/ChainSafe/gossamer/pkg/trie/triedb/hash"
+)
+
+// getEncodedNode returns the encoded node with the given hash from the cache,
+// or from the database at the prefixed key and caches it.
+func getEncodedNode[H hash.Hash](db db.DBGetter, cache Cache, prefixedKey []byte, nodeHash H) ([]byte, error) {
+	if cache != nil {
+		if encodedNode := cache.GetNode(nodeHash.Bytes()); encodedNode != nil {
+			return encodedNode, nil
+		}
+	}
+
+	encodedNode, err := db.Get(prefixedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil && len(encodedNode) > 0 {
+		cache.SetNode(nodeHash.Bytes(), encodedNode)
+	}
+	return encodedNode, nil
+}
+
+// getHashedValue returns the value with the given hash from the cache,
+// or from the database at the prefixed key and caches it.
+func getHashedValue[H hash.Hash](db db.DBGetter, cache Cache, prefixedKey []byte, valueHash H) ([]byte, error) {
+	if cache != nil {
+		if value := cache.GetValue(valueHash.Bytes()); value != nil {
+			return value, nil
+		}
+	}
+
+	value, err := db.Get(prefixedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil && value != nil {
+		cache.SetValue(valueHash.Bytes(), value)
+	}
+	return value, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package triedb
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/internal/primitives/core/hash"
+	"github.com/ChainSafe/gossamer/internal/primitives/runtime"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	inmemory_cache "github.com/ChainSafe/gossamer/pkg/trie/cache/inmemory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrieDB_Cache(t *testing.T) {
+	t.Parallel()
+
+	newTrie := func(t *testing.T) (*TrieDB[hash.H256, runtime.BlakeTwo256], *inmemory_cache.SharedTrieCache) {
+		t.Helper()
+
+		cache := inmemory_cache.NewSharedTrieCache(inmemory_cache.DefaultSharedCacheSize)
+		db := NewMemoryDB[hash.H256, runtime.BlakeTwo256](EmptyNode)
+		tr := NewEmptyTrieDB[hash.H256, runtime.BlakeTwo256](db, WithCache[hash.H256, runtime.BlakeTwo256](cache))
+		tr.SetVersion(trie.V1)
+
+		require.NoError(t, tr.Put([]byte("branch"), []byte("branchvalue")))
+		require.NoError(t, tr.Put([]byte("branchleaf"), make([]byte, 40)))
+		require.NoError(t, tr.commit())
+		return tr, cache
+	}
+
+	t.Run("commit_caches_nodes_and_values", func(t *testing.T) {
+		t.Parallel()
+
+		tr, cache := newTrie(t)
+		assert.NotNil(t, cache.GetNode(tr.rootHash.Bytes()))
+		valueHash := runtime.BlakeTwo256{}.Hash(make([]byte, 40))
+		assert.Equal(t, make([]byte, 40), cache.GetValue(valueHash.Bytes()))
+	})
+
+	t.Run("get_caches_values_by_root_and_key", func(t *testing.T) {
+		t.Parallel()
+
+		tr, cache := newTrie(t)
+		assert.Equal(t, make([]byte, 40), tr.Get([]byte("branchleaf")))
+		assert.Nil(t, tr.Get([]byte("missing")))
+
+		value, cached := cache.GetValueForKey(tr.rootHash.Bytes(), []byte("branchleaf"))
+		assert.True(t, cached)
+		assert.Equal(t, make([]byte, 40), value)
+		value, cached = cache.GetValueForKey(tr.rootHash.Bytes(), []byte("missing"))
+		assert.True(t, cached)
+		assert.Nil(t, value)
+
+		// the cached value is returned without looking up the trie
+		cache.SetValueForKey(tr.rootHash.Bytes(), []byte("branch"), []byte("cached"))
+		assert.Equal(t, []byte("cached"), tr.Get([]byte("branch")))
+	})
+
+	t.Run("uncommitted_changes_bypass_value_cache", func(t *testing.T) {
+		t.Parallel()
+
+		tr, cache := newTrie(t)
+		root := tr.rootHash
+		cache.SetValueForKey(root.Bytes(), []byte("branch"), []byte("cached"))
+
+		require.NoError(t, tr.Put([]byte("other"), []byte("value")))
+		assert.Equal(t, []byte("branchvalue"), tr.Get([]byte("branch")))
+		_, cached := cache.GetValueForKey(root.Bytes(), []byte("other"))
+		assert.False(t, cached)
+
+		require.NoError(t, tr.commit())
+		assert.NotEqual(t, root, tr.rootHash)
+		assert.Equal(t, []byte("branchvalue"), tr.Get([]byte("branch")))
+	})
+}
@@ -0,0 +1,318 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package triedb
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/ChainSafe/gossamer/pkg/trie/triedb/codec"
+	"github.com/ChainSafe/gossamer/pkg/trie/triedb/hash"
+	"github.com/ChainSafe/gossamer/pkg/trie/triedb/nibbles"
+)
+
+// diffCursorItem is a node to visit, at the given nibble path of its parent
+type diffCursorItem[H hash.Hash] struct {
+	prefix []uint8
+	handle codec.MerkleValue
+	// node and path, the prefix followed by the partial key of the node, are set
+	// once the node is loaded
+	node codec.EncodedNode
+	path []uint8
+}
+
+// diffCursor visits the nodes of a trie in depth first pre-order, which is the
+// lexicographic order of their nibble paths.
+type diffCursor[H hash.Hash, Hasher hash.Hasher[H]] struct {
+	trie  *TrieDB[H, Hasher]
+	stack []*diffCursorItem[H]
+}
+
+func newDiffCursor[H hash.Hash, Hasher hash.Hasher[H]](t *TrieDB[H, Hasher]) *diffCursor[H, Hasher] {
+	cursor := &diffCursor[H, Hasher]{trie: t}
+	if t.rootHash != (*new(Hasher)).Hash(EmptyNode) {
+		cursor.stack = []*diffCursorItem[H]{{handle: codec.HashedNode[H]{Hash: t.rootHash}}}
+	}
+	return cursor
+}
+
+func (c *diffCursor[H, Hasher]) front() *diffCursorItem[H] {
+	if len(c.stack) == 0 {
+		return nil
+	}
+	return c.stack[len(c.stack)-1]
+}
+
+func (c *diffCursor[H, Hasher]) load(item *diffCursorItem[H]) error {
+	if item.node != nil {
+		return nil
+	}
+	node, _, err := c.trie.getNodeOrLookup(item.handle, nibblesToPrefix(item.prefix), false)
+	if err != nil {
+		return err
+	}
+	item.node = node
+	item.path = item.prefix
+	if partialKey := node.GetPartialKey(); partialKey != nil {
+		item.path = make([]uint8, len(item.prefix), len(item.prefix)+int(partialKey.Len()))
+		copy(item.path, item.prefix)
+		for i := uint(0); i < partialKey.Len(); i++ {
+			item.path = append(item.path, partialKey.At(i))
+		}
+	}
+	return nil
+}
+
+// skip moves to the next node which is not a descendant of the front node.
+func (c *diffCursor[H, Hasher]) skip() {
+	c.stack = c.stack[:len(c.stack)-1]
+}
+
+// next moves to the next node, the first child of the front node if any.
+func (c *diffCursor[H, Hasher]) next() {
+	item := c.front()
+	c.skip()
+	branch, ok := item.node.(codec.Branch)
+	if !ok {
+		return
+	}
+	for i := codec.ChildrenCapacity - 1; i >= 0; i-- {
+		if branch.Children[i] == nil {
+			continue
+		}
+		prefix := make([]uint8, len(item.path)+1)
+		copy(prefix, item.path)
+		prefix[len(item.path)] = uint8(i) //nolint:gosec
+		c.stack = append(c.stack, &diffCursorItem[H]{prefix: prefix, handle: branch.Children[i]})
+	}
+}
+
+// value returns the value of the loaded node, or nil if it has none.
+func (c *diffCursor[H, Hasher]) value(item *diffCursorItem[H]) ([]byte, error) {
+	switch value := item.node.GetValue().(type) {
+	case nil:
+		return nil, nil
+	case codec.InlineValue:
+		if value == nil {
+			return []byte{}, nil
+		}
+		return value, nil
+	case codec.HashedValue[H]:
+		return c.trie.fetchValue(value.Hash, nibblesToPrefix(item.path))
+	default:
+		panic(fmt.Sprintf("unreachable: %T", value))
+	}
+}
+
+// TrieDBDiffIterator iterates over the entries which differ between two tries, walking
+// both tries in parallel and skipping the subtrees they share.
+// It reads the nodes committed to the database, the changes of the tries must be committed
+// by hashing them before creating the iterator.
+type TrieDBDiffIterator[H hash.Hash, Hasher hash.Hasher[H]] struct {
+	old *diffCursor[H, Hasher]
+	new *diffCursor[H, Hasher]
+	// isChild is set for the iterators of child tries, which have no child tries
+	isChild bool
+	// child iterates over the child trie of childKey once its root differs
+	child    *TrieDBDiffIterator[H, Hasher]
+	childKey []byte
+}
+
+// NewTrieDBDiffIterator creates an iterator over the entries which differ between the old
+// and new tries.
+func NewTrieDBDiffIterator[H hash.Hash, Hasher hash.Hasher[H]](
+	oldTrie, newTrie *TrieDB[H, Hasher]) *TrieDBDiffIterator[H, Hasher] {
+	return &TrieDBDiffIterator[H, Hasher]{
+		old: newDiffCursor(oldTrie),
+		new: newDiffCursor(newTrie),
+	}
+}
+
+// Next returns the next difference between the tries, or nil once all the differences
+// were iterated over.
+func (i *TrieDBDiffIterator[H, Hasher]) Next() (*trie.Diff, error) {
+	if i.child != nil {
+		diff, err := i.child.Next()
+		if err != nil {
+			return nil, fmt.Errorf("child trie 0x%x: %w", i.childKey, err)
+		}
+		if diff != nil {
+			diff.ChildKey = i.childKey
+			return diff, nil
+		}
+		i.child = nil
+	}
+
+	diff, err := i.nextTopDiff()
+	if err != nil || diff == nil {
+		return nil, err
+	}
+
+	if !i.isChild && bytes.HasPrefix(diff.Key, trie.ChildStorageKeyPrefix) {
+		i.child, err = i.childIterator(diff.OldValue, diff.NewValue)
+		if err != nil {
+			return nil, fmt.Errorf("child trie 0x%x: %w", diff.Key, err)
+		}
+		i.childKey = diff.Key[len(trie.ChildStorageKeyPrefix):]
+	}
+	return diff, nil
+}
+
+func (i *TrieDBDiffIterator[H, Hasher]) nextTopDiff() (*trie.Diff, error) {
+	for {
+		oldItem, newItem := i.old.front(), i.new.front()
+		if oldItem == nil && newItem == nil {
+			return nil, nil
+		}
+
+		if oldItem != nil && newItem != nil && sameNode[H](oldItem, newItem) {
+			i.old.skip()
+			i.new.skip()
+			continue
+		}
+
+		var cmp int
+		switch {
+		case oldItem == nil:
+			cmp = 1
+		case newItem == nil:
+			cmp = -1
+		default:
+			if err := i.old.load(oldItem); err != nil {
+				return nil, err
+			}
+			if err := i.new.load(newItem); err != nil {
+				return nil, err
+			}
+			cmp = bytes.Compare(oldItem.path, newItem.path)
+		}
+
+		var key, oldValue, newValue []byte
+		if cmp <= 0 {
+			if err := i.old.load(oldItem); err != nil {
+				return nil, err
+			}
+			if len(oldItem.path)%2 == 0 {
+				key = nibblesToKey(oldItem.path)
+				if cmp < 0 || !sameValue[H](oldItem.node.GetValue(), newItem.node.GetValue()) {
+					value, err := i.old.value(oldItem)
+					if err != nil {
+						return nil, err
+					}
+					oldValue = value
+				}
+			}
+			i.old.next()
+		}
+		if cmp >= 0 {
+			if err := i.new.load(newItem); err != nil {
+				return nil, err
+			}
+			if len(newItem.path)%2 == 0 {
+				key = nibblesToKey(newItem.path)
+				if cmp > 0 || !sameValue[H](oldItem.node.GetValue(), newItem.node.GetValue()) {
+					value, err := i.new.value(newItem)
+					if err != nil {
+						return nil, err
+					}
+					newValue = value
+				}
+			}
+			i.new.next()
+		}
+
+		if diff := trie.NewDiff(key, oldValue, newValue); diff != nil {
+			return diff, nil
+		}
+	}
+}
+
+// childIterator creates the iterator over the child tries with the given old and new roots,
+// a nil root being an empty child trie.
+func (i *TrieDBDiffIterator[H, Hasher]) childIterator(oldRoot, newRoot []byte) (
+	*TrieDBDiffIterator[H, Hasher], error) {
+	oldTrie, err := i.old.childTrie(oldRoot)
+	if err != nil {
+		return nil, err
+	}
+	newTrie, err := i.new.childTrie(newRoot)
+	if err != nil {
+		return nil, err
+	}
+	child := NewTrieDBDiffIterator(oldTrie, newTrie)
+	child.isChild = true
+	return child, nil
+}
+
+func (c *diffCursor[H, Hasher]) childTrie(root []byte) (*TrieDB[H, Hasher], error) {
+	rootHash := (*new(Hasher)).Hash(EmptyNode)
+	if root != nil {
+		if len(root) != rootHash.Length() {
+			return nil, fmt.Errorf("invalid child trie root 0x%x", root)
+		}
+		err := scale.Unmarshal(root, &rootHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	child := NewTrieDB[H, Hasher](rootHash, c.trie.db)
+	child.cache = c.trie.cache
+	return child, nil
+}
+
+// sameNode returns true if both items are the same node at the same position, so
+// the subtrees they are the root of are identical.
+func sameNode[H hash.Hash](a, b *diffCursorItem[H]) bool {
+	if !bytes.Equal(a.prefix, b.prefix) {
+		return false
+	}
+	switch aHandle := a.handle.(type) {
+	case codec.HashedNode[H]:
+		bHandle, ok := b.handle.(codec.HashedNode[H])
+		return ok && aHandle.Hash == bHandle.Hash
+	case codec.InlineNode:
+		bHandle, ok := b.handle.(codec.InlineNode)
+		return ok && bytes.Equal(aHandle, bHandle)
+	default:
+		return false
+	}
+}
+
+// sameValue returns true if the encoded values are known to be equal without
+// fetching hashed values.
+func sameValue[H hash.Hash](a, b codec.EncodedValue) bool {
+	switch aValue := a.(type) {
+	case nil:
+		return b == nil
+	case codec.InlineValue:
+		bValue, ok := b.(codec.InlineValue)
+		return ok && bytes.Equal(aValue, bValue)
+	case codec.HashedValue[H]:
+		bValue, ok := b.(codec.HashedValue[H])
+		return ok && aValue.Hash == bValue.Hash
+	default:
+		return false
+	}
+}
+
+func nibblesToPrefix(path []uint8) nibbles.Prefix {
+	prefix := nibbles.Prefix{Key: nibblesToKey(path)}
+	if len(path)%2 == 1 {
+		padded := path[len(path)-1] << 4
+		prefix.Padded = &padded
+	}
+	return prefix
+}
+
+// nibblesToKey returns the key of the nibbles, ignoring a last odd nibble.
+func nibblesToKey(path []uint8) []byte {
+	key := make([]byte, len(path)/2)
+	for i := range key {
+		key[i] = path[2*i]<<4 | path[2*i+1]
+	}
+	return key
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package triedb
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/internal/primitives/core/hash"
+	"github.com/ChainSafe/gossamer/internal/primitives/runtime"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+)
+
+type countingDB struct {
+	*MemoryDB
+	gets int
+}
+
+func (db *countingDB) Get(key []byte) ([]byte, error) {
+	db.gets++
+	return db.MemoryDB.Get(key)
+}
+
+func newDiffTestTrie(t *testing.T, db *countingDB, version trie.TrieLayout,
+	entries map[string][]byte) *TrieDB[hash.H256, runtime.BlakeTwo256] {
+	t.Helper()
+
+	trieDB := NewEmptyTrieDB[hash.H256, runtime.BlakeTwo256](db)
+	trieDB.SetVersion(version)
+	keys := maps.Keys(entries)
+	slices.Sort(keys)
+	for _, key := range keys {
+		require.NoError(t, trieDB.Put([]byte(key), entries[key]))
+	}
+	require.NoError(t, trieDB.commit())
+	return NewTrieDB[hash.H256, runtime.BlakeTwo256](trieDB.rootHash, db)
+}
+
+func collectDiffs(t *testing.T, iter trie.DiffIterator) []trie.Diff {
+	t.Helper()
+
+	var diffs []trie.Diff
+	for {
+		diff, err := iter.Next()
+		require.NoError(t, err)
+		if diff == nil {
+			return diffs
+		}
+		diffs = append(diffs, *diff)
+	}
+}
+
+func TestTrieDBDiffIterator(t *testing.T) {
+	t.Parallel()
+
+	oldEntries := map[string][]byte{
+		"no":           {1},
+		"noot":         {2},
+		"not":          bytes.Repeat([]byte{3}, 40),
+		"notable":      {4},
+		"notification": bytes.Repeat([]byte{5}, 40),
+		"test":         {6},
+	}
+	newEntries := map[string][]byte{
+		"no":           {1},
+		"noot":         bytes.Repeat([]byte{2}, 40),
+		"not":          bytes.Repeat([]byte{3}, 40),
+		"notable":      {4},
+		"notification": bytes.Repeat([]byte{7}, 40),
+		"nothing":      {8},
+	}
+
+	for _, version := range []trie.TrieLayout{trie.V0, trie.V1} {
+		t.Run(version.String(), func(t *testing.T) {
+			t.Parallel()
+
+			db := &countingDB{MemoryDB: NewMemoryDB[hash.H256, runtime.BlakeTwo256](EmptyNode)}
+			oldTrie := newDiffTestTrie(t, db, version, oldEntries)
+			newTrie := newDiffTestTrie(t, db, version, newEntries)
+
+			expected := []trie.Diff{
+				{Kind: trie.Modified, Key: []byte("noot"), OldValue: []byte{2}, NewValue: bytes.Repeat([]byte{2}, 40)},
+				{Kind: trie.Added, Key: []byte("nothing"), NewValue: []byte{8}},
+				{
+					Kind:     trie.Modified,
+					Key:      []byte("notification"),
+					OldValue: bytes.Repeat([]byte{5}, 40),
+					NewValue: bytes.Repeat([]byte{7}, 40),
+				},
+				{Kind: trie.Removed, Key: []byte("test"), OldValue: []byte{6}},
+			}
+			assert.Equal(t, expected, collectDiffs(t, NewTrieDBDiffIterator(oldTrie, newTrie)))
+
+			reversed := make([]trie.Diff, len(expected))
+			for i, diff := range expected {
+				reversed[i] = trie.Diff{Key: diff.Key, OldValue: diff.NewValue, NewValue: diff.OldValue}
+				switch diff.Kind {
+				case trie.Added:
+					reversed[i].Kind = trie.Removed
+				case trie.Removed:
+					reversed[i].Kind = trie.Added
+				default:
+					reversed[i].Kind = trie.Modified
+				}
+			}
+			assert.Equal(t, reversed, collectDiffs(t, NewTrieDBDiffIterator(newTrie, oldTrie)))
+		})
+	}
+
+	t.Run("empty_tries", func(t *testing.T) {
+		t.Parallel()
+
+		db := &countingDB{MemoryDB: NewMemoryDB[hash.H256, runtime.BlakeTwo256](EmptyNode)}
+		emptyTrie := NewEmptyTrieDB[hash.H256, runtime.BlakeTwo256](db)
+		oldTrie := newDiffTestTrie(t, db, trie.V1, oldEntries)
+
+		assert.Empty(t, collectDiffs(t, NewTrieDBDiffIterator(emptyTrie, emptyTrie)))
+
+		diffs := collectDiffs(t, NewTrieDBDiffIterator(emptyTrie, oldTrie))
+		require.Len(t, diffs, len(oldEntries))
+		for _, diff := range diffs {
+			assert.Equal(t, trie.Added, diff.Kind)
+			assert.Equal(t, oldEntries[string(diff.Key)], diff.NewValue)
+		}
+	})
+
+	t.Run("skips_identical_subtrees", func(t *testing.T) {
+		t.Parallel()
+
+		entries := make(map[string][]byte)
+		for i := 0; i < 1000; i++ {
+			entries[fmt.Sprintf("key_%d", i)] = []byte(fmt.Sprintf("value_%d", i))
+		}
+		db := &countingDB{MemoryDB: NewMemoryDB[hash.H256, runtime.BlakeTwo256](EmptyNode)}
+		oldTrie := newDiffTestTrie(t, db, trie.V1, entries)
+		entries["key_500"] = []byte("modified")
+		newTrie := newDiffTestTrie(t, db, trie.V1, entries)
+
+		db.gets = 0
+		assert.Empty(t, collectDiffs(t, NewTrieDBDiffIterator(oldTrie, oldTrie)))
+		assert.Zero(t, db.gets)
+
+		expected := []trie.Diff{
+			{Kind: trie.Modified, Key: []byte("key_500"), OldValue: []byte("value_500"), NewValue: []byte("modified")},
+		}
+		assert.Equal(t, expected, collectDiffs(t, NewTrieDBDiffIterator(oldTrie, newTrie)))
+		assert.Less(t, db.gets, 20)
+	})
+
+	t.Run("child_tries", func(t *testing.T) {
+		t.Parallel()
+
+		db := &countingDB{MemoryDB: NewMemoryDB[hash.H256, runtime.BlakeTwo256](EmptyNode)}
+		oldChild := newDiffTestTrie(t, db, trie.V1, map[string][]byte{"a": {1}, "b": {2}})
+		newChild := newDiffTestTrie(t, db, trie.V1, map[string][]byte{"a": {1}, "c": {3}})
+
+		childKey := append(bytes.Clone(trie.ChildStorageKeyPrefix), []byte("child")...)
+		oldTrie := newDiffTestTrie(t, db, trie.V1, map[string][]byte{
+			"key":            {1},
+			string(childKey): oldChild.rootHash.Bytes(),
+		})
+		newTrie := newDiffTestTrie(t, db, trie.V1, map[string][]byte{
+			"key":            {1},
+			string(childKey): newChild.rootHash.Bytes(),
+		})
+		withoutChild := newDiffTestTrie(t, db, trie.V1, map[string][]byte{"key": {1}})
+
+		expected := []trie.Diff{
+			{Kind: trie.Modified, Key: childKey, OldValue: oldChild.rootHash.Bytes(), NewValue: newChild.rootHash.Bytes()},
+			{Kind: trie.Removed, ChildKey: []byte("child"), Key: []byte("b"), OldValue: []byte{2}},
+			{Kind: trie.Added, ChildKey: []byte("child"), Key: []byte("c"), NewValue: []byte{3}},
+		}
+		assert.Equal(t, expected, collectDiffs(t, NewTrieDBDiffIterator(oldTrie, newTrie)))
+
+		expected = []trie.Diff{
+			{Kind: trie.Removed, Key: childKey, OldValue: oldChild.rootHash.Bytes()},
+			{Kind: trie.Removed, ChildKey: []byte("child"), Key: []byte("a"), OldValue: []byte{1}},
+			{Kind: trie.Removed, ChildKey: []byte("child"), Key: []byte("b"), OldValue: []byte{2}},
+		}
+		assert.Equal(t, expected, collectDiffs(t, NewTrieDBDiffIterator(oldTrie, withoutChild)))
+	})
+}
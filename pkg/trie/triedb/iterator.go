@@ -7,6 +7,7 @@
 	"bytes"
 	"fmt"
 
+	"github.com/ChainSafe/gossamer/pkg/trie"
 	"github.com/ChainSafe/gossamer/pkg/trie/triedb/codec"
 	"github.com/ChainSafe/gossamer/pkg/trie/triedb/hash"
 	"github.com/ChainSafe/gossamer/pkg/trie/triedb/nibbles"
@@ -471,3 +472,99 @@ type TrieItem struct {
 	Key   []byte
 	Value []byte
 }
+
+// TrieDBIteratorOpts is an option of the TrieDBIterator
+type TrieDBIteratorOpts[H hash.Hash, Hasher hash.Hasher[H]] func(*TrieDBIterator[H, Hasher])
+
+// WithCursorAt starts the iteration after the given key.
+func WithCursorAt[H hash.Hash, Hasher hash.Hasher[H]](cursor []byte) TrieDBIteratorOpts[H, Hasher] {
+	return func(i *TrieDBIterator[H, Hasher]) {
+		i.cursorAtKey = cursor
+	}
+}
+
+// TrieDBIterator iterates over the entries of the trie in lexicographic order of their keys.
+// It reads the nodes committed to the database, the changes of the trie must be committed
+// by hashing it before creating the iterator.
+type TrieDBIterator[H hash.Hash, Hasher hash.Hasher[H]] struct {
+	db          *TrieDB[H, Hasher]
+	iter        *rawIterator[H, Hasher]
+	cursorAtKey []byte
+}
+
+// NewTrieDBIterator creates an iterator over the entries of the trie.
+func NewTrieDBIterator[H hash.Hash, Hasher hash.Hasher[H]](
+	db *TrieDB[H, Hasher], opts ...TrieDBIteratorOpts[H, Hasher]) *TrieDBIterator[H, Hasher] {
+	iter := &TrieDBIterator[H, Hasher]{db: db}
+	for _, opt := range opts {
+		opt(iter)
+	}
+	return iter
+}
+
+// NextEntry returns the next entry of the trie, with its key in Little Endian format,
+// or nil once all the entries were iterated over.
+func (i *TrieDBIterator[H, Hasher]) NextEntry() *trie.Entry {
+	if i.iter == nil {
+		iter, err := newRawIterator(i.db)
+		if err != nil {
+			logger.Errorf("creating trie iterator: %s", err)
+			return nil
+		}
+		if i.cursorAtKey != nil {
+			_, err = iter.seek(i.cursorAtKey, true)
+			if err != nil {
+				logger.Errorf("seeking trie iterator to key 0x%x: %s", i.cursorAtKey, err)
+				return nil
+			}
+		}
+		i.iter = iter
+	}
+
+	for {
+		item, err := i.iter.NextItem()
+		if err != nil {
+			logger.Errorf("iterating over trie: %s", err)
+			return nil
+		}
+		if item == nil {
+			return nil
+		}
+		// the seek positions the iterator at the cursor key itself when it is in the trie
+		if i.cursorAtKey != nil && bytes.Compare(item.Key, i.cursorAtKey) <= 0 {
+			continue
+		}
+
+		// the raw iterator reuses its key buffer
+		key := bytes.Clone(item.Key)
+		i.cursorAtKey = key
+		return &trie.Entry{Key: key, Value: item.Value}
+	}
+}
+
+// NextKey returns the next key of the trie in Little Endian format,
+// or nil once all the keys were iterated over.
+func (i *TrieDBIterator[H, Hasher]) NextKey() []byte {
+	entry := i.NextEntry()
+	if entry != nil {
+		return entry.Key
+	}
+	return nil
+}
+
+// NextKeyFunc advances the iterator until the predicate condition meets
+func (i *TrieDBIterator[H, Hasher]) NextKeyFunc(predicate func(nextKey []byte) bool) (nextKey []byte) {
+	for entry := i.NextEntry(); entry != nil; entry = i.NextEntry() {
+		if predicate(entry.Key) {
+			return entry.Key
+		}
+	}
+	return nil
+}
+
+// Seek advances the iterator to the first key greater than or equal to the target key.
+func (i *TrieDBIterator[H, Hasher]) Seek(targetKey []byte) {
+	i.NextKeyFunc(func(nextKey []byte) bool {
+		return bytes.Compare(nextKey, targetKey) >= 0
+	})
+}
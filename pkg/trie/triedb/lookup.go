@@ -49,7 +49,7 @@ func (l *TrieLookup[H, Hasher]) lookupNode(
 	for {
 		// Get node from DB
 		prefixedKey := append(nibbleKey.Mid(keyNibbles).Left().JoinedBytes(), hash.Bytes()...)
-		nodeData, err := l.db.Get(prefixedKey)
+		nodeData, err := getEncodedNode(l.db, l.cache, prefixedKey, hash)
 		if err != nil {
 			return nil, ErrIncompleteDB
 		}
@@ -165,7 +165,7 @@ func (l *TrieLookup[H, Hasher]) fetchValue(
 	case codec.HashedValue[H]:
 		prefixedKey := bytes.Join([][]byte{prefix.JoinedBytes(), v.Hash.Bytes()}, nil)
 
-		nodeData, err := l.db.Get(prefixedKey)
+		nodeData, err := getHashedValue(l.db, l.cache, prefixedKey, v.Hash)
 		if err != nil {
 			return nil, ErrIncompleteDB
 		}
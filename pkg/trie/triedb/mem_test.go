@@ -50,7 +50,7 @@ func Benchmark_ValueCache(b *testing.B) {
 	})
 
 	b.Run("get_value_with_cache", func(b *testing.B) {
-		cache := inmemory_cache.NewTrieInMemoryCache()
+		cache := inmemory_cache.NewSharedTrieCache(inmemory_cache.DefaultSharedCacheSize)
 		trieDB := NewTrieDB[hash.H256, runtime.BlakeTwo256](
 			hash.H256(root.ToBytes()), db, WithCache[hash.H256, runtime.BlakeTwo256](cache))
 		b.ResetTimer()
@@ -102,7 +102,7 @@ func Benchmark_NodesCache(b *testing.B) {
 	// node data and we need to decode it every time we access it. We could
 	// cache the decoded node instead and avoid decoding it every time.
 	b.Run("iterate_all_entries_with_cache", func(b *testing.B) {
-		cache := inmemory_cache.NewTrieInMemoryCache()
+		cache := inmemory_cache.NewSharedTrieCache(inmemory_cache.DefaultSharedCacheSize)
 		trieDB := NewTrieDB[hash.H256, runtime.BlakeTwo256](
 			hash.H256(root.ToBytes()), db, WithCache[hash.H256, runtime.BlakeTwo256](cache))
 		b.ResetTimer()
@@ -87,11 +87,14 @@ type Prefix struct {
 	Padded *byte
 }
 
+// JoinedBytes returns the key followed by the padded byte if any. The returned slice
+// has no spare capacity so appending to it never writes to the key it was taken from.
 func (p Prefix) JoinedBytes() []byte {
+	key := slices.Clip(p.Key)
 	if p.Padded != nil {
-		return append(p.Key, *p.Padded)
+		return append(key, *p.Padded)
 	}
-	return p.Key
+	return key
 }
 
 // Return left portion of [Nibbles], if the slice
@@ -33,6 +33,15 @@ func TestNibbles_MidAndLeft(t *testing.T) {
 	assert.Equal(t, Prefix{Key: []byte{0x01}, Padded: &padded}, m.Left())
 }
 
+func TestPrefix_JoinedBytes(t *testing.T) {
+	key := append(make([]byte, 0, 8), 0x01, 0x23)
+	prefix := NewNibbles(key, 4).Left()
+
+	joined := append(prefix.JoinedBytes(), 0xff)
+	assert.Equal(t, []byte{0x01, 0x23, 0xff}, joined)
+	assert.Equal(t, []byte{0x01, 0x23, 0x00}, key[:3])
+}
+
 func TestNibbles_Right(t *testing.T) {
 	data := []uint8{1, 2, 3, 4, 5, 234, 78, 99}
 	nibbles := NewNibbles(data)
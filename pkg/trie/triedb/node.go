@@ -107,8 +107,9 @@ func (vr newValueRef[H]) equal(other nodeValue[H]) bool {
 	}
 }
 
-func NewValue[H hash.Hash](data []byte, threshold int) nodeValue[H] {
-	if len(data) >= threshold {
+// NewValue returns the node value of the data, hashed when it is longer than the max inline value size
+func NewValue[H hash.Hash](data []byte, maxInlineValue int) nodeValue[H] {
+	if len(data) > maxInlineValue {
 		return newValueRef[H]{
 			hash: *new(H),
 			data: data,
@@ -129,7 +130,8 @@ func NewValueFromEncoded[H hash.Hash](encodedValue codec.EncodedValue) nodeValue
 	return nil
 }
 
-func inMemoryFetchedValue[H hash.Hash](value nodeValue[H], prefix []byte, db db.DBGetter) ([]byte, error) {
+func inMemoryFetchedValue[H hash.Hash](value nodeValue[H], prefix []byte, db db.DBGetter, cache Cache) (
+	[]byte, error) {
 	switch v := value.(type) {
 	case inline[H]:
 		return v, nil
@@ -137,7 +139,7 @@ func inMemoryFetchedValue[H hash.Hash](value nodeValue[H], prefix []byte, db db.
 		return v.data, nil
 	case valueRef[H]:
 		prefixedKey := bytes.Join([][]byte{prefix, v.hash.Bytes()}, nil)
-		value, err := db.Get(prefixedKey)
+		value, err := getHashedValue(db, cache, prefixedKey, v.hash)
 		if err != nil {
 			return nil, err
 		}
@@ -179,7 +181,7 @@ func (n Branch[H]) getPartialKey() *nodeKey { return &n.partialKey }
 
 // Create a new node from the encoded data, decoding this data into a codec.Node
 // and mapping that with this node type
-func newNodeFromEncoded[H hash.Hash](nodeHash H, data []byte, storage nodeStorage[H]) (Node, error) {
+func newNodeFromEncoded[H hash.Hash](nodeHash H, data []byte, storage *nodeStorage[H]) (Node, error) {
 	reader := bytes.NewReader(data)
 	encodedNode, err := codec.Decode[H](reader)
 	if err != nil {
@@ -33,7 +33,7 @@ func (persisted[H]) isNodeHandle() {}
 func newFromEncodedMerkleValue[H hash.Hash](
 	parentHash H,
 	encodedNodeHandle codec.MerkleValue,
-	storage nodeStorage[H],
+	storage *nodeStorage[H],
 ) (NodeHandle, error) {
 	switch encoded := encodedNodeHandle.(type) {
 	case codec.HashedNode[H]:
@@ -0,0 +1,488 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package proof
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/ChainSafe/gossamer/pkg/trie/db"
+	"github.com/ChainSafe/gossamer/pkg/trie/triedb"
+	"github.com/ChainSafe/gossamer/pkg/trie/triedb/codec"
+	"github.com/ChainSafe/gossamer/pkg/trie/triedb/hash"
+	"github.com/ChainSafe/gossamer/pkg/trie/triedb/nibbles"
+)
+
+// escapeCompactHeader is the header byte prefixing the nodes of a compact proof whose
+// hashed value is omitted, the value being the next item of the proof.
+const escapeCompactHeader byte = 0b0000_0001
+
+var (
+	ErrRootMismatch         = errors.New("root mismatch")
+	ErrInvalidChildRoot     = errors.New("invalid child trie root")
+	ErrExtraneousChildProof = errors.New("extraneous child trie proof")
+	ErrExtraneousChildNode  = errors.New("extraneous child trie node")
+)
+
+// CompactProof is a storage proof in the compact encoding of Substrate.
+// The nodes of the top trie followed by the nodes of each child trie are encoded in
+// depth first pre-order, omitting the hashes of the child nodes in the proof and the
+// hashed values, which are instead the items following the node they belong to.
+// It SCALE encodes as sp-trie's CompactProof.
+// https://github.com/paritytech/polkadot-sdk/blob/a8f4f4f00f8fc0da512a09e1450bf4cda954d70d/substrate/primitives/trie/src/trie_codec.rs
+type CompactProof[H hash.Hash, Hasher hash.Hasher[H]] [][]byte
+
+// NewCompactProof encodes the nodes of the trie with the given root and of its child
+// tries found in the database, which is keyed by node hash. Nodes missing from the
+// database are kept as hash references, so the database can be a partial set of nodes
+// such as the ones recorded while reading the trie.
+func NewCompactProof[H hash.Hash, Hasher hash.Hasher[H]](database db.DBGetter, root H) (
+	CompactProof[H, Hasher], error) {
+	childRoots, err := childTrieRoots[H, Hasher](database, root)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := compactEncoder[H, Hasher]{db: database}
+	rootData, err := encoder.get(root)
+	if err != nil {
+		return nil, err
+	}
+	if rootData == nil {
+		return nil, fmt.Errorf("%w: root %x", triedb.ErrIncompleteDB, root.Bytes())
+	}
+	err = encoder.encode(rootData)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, childRoot := range childRoots {
+		childRootData, err := encoder.get(childRoot)
+		if err != nil {
+			return nil, err
+		}
+		// the root of a child trie may be in the proof without its nodes
+		if childRootData == nil {
+			continue
+		}
+		err = encoder.encode(childRootData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return encoder.output, nil
+}
+
+// NewCompactProofFromRecords encodes the nodes and values recorded while reading
+// the trie with the given root.
+func NewCompactProofFromRecords[H hash.Hash, Hasher hash.Hasher[H]](records []triedb.Record[H], root H) (
+	CompactProof[H, Hasher], error) {
+	database := db.NewEmptyMemoryDB()
+	for _, record := range records {
+		err := database.Put(record.Hash.Bytes(), record.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewCompactProof[H, Hasher](database, root)
+}
+
+// Decode decodes the proof into the database, keyed by node hash, and returns the root
+// of its top trie. It fails if a node is missing, or if the proof holds a child trie
+// which is not referenced by the top trie or nodes after its last trie.
+func (p CompactProof[H, Hasher]) Decode(database db.Database) (root H, err error) {
+	root, used, err := decodeCompactTrie[H, Hasher](database, p)
+	if err != nil {
+		return root, err
+	}
+
+	childRoots, err := childTrieRoots[H, Hasher](database, root)
+	if err != nil {
+		return root, err
+	}
+
+	remaining := p[used:]
+	var extractedChildRoot *H
+	for _, childRoot := range childRoots {
+		if extractedChildRoot == nil && len(remaining) > 0 {
+			childTrieRoot, used, err := decodeCompactTrie[H, Hasher](database, remaining)
+			if err != nil {
+				return root, err
+			}
+			remaining = remaining[used:]
+			extractedChildRoot = &childTrieRoot
+		}
+
+		// the proof may hold the root of a child trie without its nodes, in which
+		// case the decoded child trie is the one of a following root
+		if extractedChildRoot != nil && *extractedChildRoot == childRoot {
+			extractedChildRoot = nil
+		}
+	}
+
+	if extractedChildRoot != nil {
+		return root, fmt.Errorf("%w: %x", ErrExtraneousChildProof, (*extractedChildRoot).Bytes())
+	}
+	if len(remaining) > 0 {
+		return root, ErrExtraneousChildNode
+	}
+	return root, nil
+}
+
+// ToMemoryDB decodes the proof into a new memory database and verifies its root
+// is the expected root.
+func (p CompactProof[H, Hasher]) ToMemoryDB(expectedRoot H) (*db.MemoryDB, error) {
+	database := db.NewEmptyMemoryDB()
+	root, err := p.Decode(database)
+	if err != nil {
+		return nil, err
+	}
+	if root != expectedRoot {
+		return nil, fmt.Errorf("%w: expected %x got %x", ErrRootMismatch, expectedRoot.Bytes(), root.Bytes())
+	}
+	return database, nil
+}
+
+type compactEncoder[H hash.Hash, Hasher hash.Hasher[H]] struct {
+	db     db.DBGetter
+	output [][]byte
+}
+
+// get returns the node or value with the given hash, or nil if it is not in the database.
+func (e *compactEncoder[H, Hasher]) get(hash H) ([]byte, error) {
+	return getNode[H, Hasher](e.db, hash)
+}
+
+// encode appends the node, its omitted value and the nodes of its children found in
+// the database to the output, in depth first pre-order.
+func (e *compactEncoder[H, Hasher]) encode(nodeData []byte) error {
+	node, err := codec.Decode[H](bytes.NewReader(nodeData))
+	if err != nil {
+		return err
+	}
+
+	outputIndex := len(e.output)
+	// placeholder replaced once the children to omit are known
+	e.output = append(e.output, nil)
+
+	var omitValue bool
+	if hashedValue, ok := node.GetValue().(codec.HashedValue[H]); ok {
+		value, err := e.get(hashedValue.Hash)
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			omitValue = true
+			e.output = append(e.output, value)
+		}
+	}
+
+	var encoded []byte
+	switch n := node.(type) {
+	case codec.Empty:
+		encoded = nodeData
+	case codec.Leaf:
+		if !omitValue {
+			encoded = nodeData
+			break
+		}
+		buffer := bytes.NewBuffer([]byte{escapeCompactHeader})
+		err = triedb.NewEncodedLeaf(n.PartialKey.Right(), n.PartialKey.Len(), codec.InlineValue{}, buffer)
+		if err != nil {
+			return err
+		}
+		encoded = buffer.Bytes()
+	case codec.Branch:
+		var children [codec.ChildrenCapacity]triedb.ChildReference
+		for i, child := range n.Children {
+			switch c := child.(type) {
+			case codec.InlineNode:
+				children[i] = triedb.InlineChildReference(c)
+			case codec.HashedNode[H]:
+				childData, err := e.get(c.Hash)
+				if err != nil {
+					return err
+				}
+				if childData == nil {
+					children[i] = triedb.HashChildReference[H](c)
+					continue
+				}
+				children[i] = triedb.InlineChildReference(nil)
+				err = e.encode(childData)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		value := n.Value
+		buffer := bytes.NewBuffer(nil)
+		if omitValue {
+			value = codec.InlineValue{}
+			buffer.WriteByte(escapeCompactHeader)
+		}
+		err = triedb.NewEncodedBranch(n.PartialKey.Right(), n.PartialKey.Len(), children, value, buffer)
+		if err != nil {
+			return err
+		}
+		encoded = buffer.Bytes()
+	default:
+		panic("unreachable")
+	}
+
+	e.output[outputIndex] = encoded
+	return nil
+}
+
+// decodeStackEntry is a node of the compact proof whose omitted children are being decoded.
+type decodeStackEntry[H hash.Hash] struct {
+	node codec.EncodedNode
+	// childIndex is the index of the next child of a branch
+	childIndex int
+	// children are the child references of the decoded node
+	children [codec.ChildrenCapacity]triedb.ChildReference
+	// attachedValue is the omitted value of the node, if any
+	attachedValue []byte
+	hasValue      bool
+}
+
+// advanceChildIndex moves to the next omitted child of a branch, returning false if
+// one is found and true once all the children are known.
+func (e *decodeStackEntry[H]) advanceChildIndex() bool {
+	branch, ok := e.node.(codec.Branch)
+	if !ok {
+		return true
+	}
+
+	for ; e.childIndex < codec.ChildrenCapacity; e.childIndex++ {
+		switch c := branch.Children[e.childIndex].(type) {
+		case codec.InlineNode:
+			if len(c) == 0 {
+				return false
+			}
+			e.children[e.childIndex] = triedb.InlineChildReference(c)
+		case codec.HashedNode[H]:
+			e.children[e.childIndex] = triedb.HashChildReference[H](c)
+		}
+	}
+	return true
+}
+
+// encodeNode encodes the decoded node, using the hash of its attached value if any.
+func (e *decodeStackEntry[H]) encodeNode(valueHash *H) ([]byte, error) {
+	value := e.node.GetValue()
+	if valueHash != nil {
+		value = codec.HashedValue[H]{Hash: *valueHash}
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	switch n := e.node.(type) {
+	case codec.Empty:
+		return []byte{triedb.EmptyTrieBytes}, nil
+	case codec.Leaf:
+		err := triedb.NewEncodedLeaf(n.PartialKey.Right(), n.PartialKey.Len(), value, buffer)
+		if err != nil {
+			return nil, err
+		}
+	case codec.Branch:
+		err := triedb.NewEncodedBranch(n.PartialKey.Right(), n.PartialKey.Len(), e.children, value, buffer)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		panic("unreachable")
+	}
+	return buffer.Bytes(), nil
+}
+
+// decodeCompactTrie decodes the nodes of a single trie from the start of the encoded
+// nodes into the database, and returns its root and the number of items used.
+func decodeCompactTrie[H hash.Hash, Hasher hash.Hasher[H]](database db.DBPutter, encoded [][]byte) (
+	root H, used int, err error) {
+	var stack []*decodeStackEntry[H]
+
+	for used < len(encoded) {
+		encodedNode := encoded[used]
+		used++
+
+		attachedValue := len(encodedNode) > 0 && encodedNode[0] == escapeCompactHeader
+		if attachedValue {
+			encodedNode = encodedNode[1:]
+		}
+
+		node, err := codec.Decode[H](bytes.NewReader(encodedNode))
+		if err != nil {
+			return root, used, fmt.Errorf("decoding node %d: %w", used-1, err)
+		}
+
+		entry := &decodeStackEntry[H]{node: node}
+		if attachedValue {
+			if used == len(encoded) {
+				return root, used, fmt.Errorf("%w: missing value of node %d", ErrIncompleteProof, used-1)
+			}
+			entry.attachedValue = encoded[used]
+			entry.hasValue = true
+			used++
+		}
+
+		for {
+			if !entry.advanceChildIndex() {
+				stack = append(stack, entry)
+				break
+			}
+
+			var valueHash *H
+			if entry.hasValue {
+				hash := (*new(Hasher)).Hash(entry.attachedValue)
+				err = database.Put(hash.Bytes(), entry.attachedValue)
+				if err != nil {
+					return root, used, err
+				}
+				valueHash = &hash
+			}
+
+			nodeData, err := entry.encodeNode(valueHash)
+			if err != nil {
+				return root, used, err
+			}
+			nodeHash := (*new(Hasher)).Hash(nodeData)
+			err = database.Put(nodeHash.Bytes(), nodeData)
+			if err != nil {
+				return root, used, err
+			}
+
+			if len(stack) == 0 {
+				return nodeHash, used, nil
+			}
+
+			entry = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			entry.children[entry.childIndex] = triedb.HashChildReference[H]{Hash: nodeHash}
+			entry.childIndex++
+		}
+	}
+
+	return root, used, ErrIncompleteProof
+}
+
+// childTrieRoots returns the roots of the default child tries stored in the trie with
+// the given root, skipping the parts of the trie missing from the database.
+func childTrieRoots[H hash.Hash, Hasher hash.Hasher[H]](database db.DBGetter, root H) ([]H, error) {
+	rootData, err := getNode[H, Hasher](database, root)
+	if err != nil || rootData == nil {
+		return nil, err
+	}
+
+	var roots []H
+	prefix := nibbles.NewLeftNibbles(trie.ChildStorageKeyPrefix)
+	var walk func(nodeData []byte, path []uint8) error
+	walk = func(nodeData []byte, path []uint8) error {
+		node, err := codec.Decode[H](bytes.NewReader(nodeData))
+		if err != nil {
+			return err
+		}
+		partialKey := node.GetPartialKey()
+		if partialKey == nil {
+			return nil
+		}
+		for i := uint(0); i < partialKey.Len(); i++ {
+			path = append(path, partialKey.At(i))
+		}
+		if !nibblesCompatible(path, prefix) {
+			return nil
+		}
+
+		if value := node.GetValue(); value != nil && len(path) >= int(prefix.Len()) && len(path)%2 == 0 {
+			var valueData []byte
+			switch v := value.(type) {
+			case codec.InlineValue:
+				valueData = v
+			case codec.HashedValue[H]:
+				valueData, err = getNode[H, Hasher](database, v.Hash)
+				if err != nil {
+					return err
+				}
+			}
+			if valueData != nil {
+				key := nibblesToKey(path)
+				var childRoot H
+				if len(valueData) != childRoot.Length() {
+					return fmt.Errorf("%w: key %x value %x", ErrInvalidChildRoot, key, valueData)
+				}
+				err = scale.Unmarshal(valueData, &childRoot)
+				if err != nil {
+					return err
+				}
+				roots = append(roots, childRoot)
+			}
+		}
+
+		branch, ok := node.(codec.Branch)
+		if !ok {
+			return nil
+		}
+		for i, child := range branch.Children {
+			var childData []byte
+			switch c := child.(type) {
+			case codec.InlineNode:
+				childData = c
+			case codec.HashedNode[H]:
+				childData, err = getNode[H, Hasher](database, c.Hash)
+				if err != nil {
+					return err
+				}
+			}
+			if childData == nil {
+				continue
+			}
+			err = walk(childData, append(path, uint8(i))) //nolint:gosec
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = walk(rootData, nil)
+	if err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// getNode returns the node or value with the given hash from the database keyed by hash,
+// or nil if it is missing.
+func getNode[H hash.Hash, Hasher hash.Hasher[H]](database db.DBGetter, hash H) ([]byte, error) {
+	data, err := database.Get(hash.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if data == nil && hash == (*new(Hasher)).Hash(triedb.EmptyNode) {
+		return triedb.EmptyNode, nil
+	}
+	return data, nil
+}
+
+// nibblesCompatible returns true if one of the nibble path and the prefix is a prefix
+// of the other, so keys starting with the prefix may be found below the path.
+func nibblesCompatible(path []uint8, prefix nibbles.LeftNibbles) bool {
+	for i := 0; i < len(path) && uint(i) < prefix.Len(); i++ {
+		if path[i] != *prefix.At(uint(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+func nibblesToKey(path []uint8) []byte {
+	key := make([]byte, len(path)/2)
+	for i := range key {
+		key[i] = path[2*i]<<4 | path[2*i+1]
+	}
+	return key
+}
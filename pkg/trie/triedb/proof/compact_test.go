@@ -0,0 +1,400 @@
+// Copyright 2024 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package proof
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/internal/primitives/core/hash"
+	"github.com/ChainSafe/gossamer/internal/primitives/runtime"
+	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/ChainSafe/gossamer/pkg/trie/triedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type compactProof = CompactProof[hash.H256, runtime.BlakeTwo256]
+
+func buildTrie(t *testing.T, version trie.TrieLayout, entries []trie.Entry) (*MemoryDB, hash.H256) {
+	t.Helper()
+
+	inmemoryDB := NewMemoryDB(triedb.EmptyNode)
+	trieDB := triedb.NewEmptyTrieDB[hash.H256, runtime.BlakeTwo256](inmemoryDB)
+	trieDB.SetVersion(version)
+	for _, entry := range entries {
+		require.NoError(t, trieDB.Put(entry.Key, entry.Value))
+	}
+	return inmemoryDB, trieDB.MustHash()
+}
+
+func recordReads(t *testing.T, inmemoryDB *MemoryDB, version trie.TrieLayout, root hash.H256,
+	keys ...[]byte) []triedb.Record[hash.H256] {
+	t.Helper()
+
+	recorder := triedb.NewRecorder[hash.H256]()
+	trieDB := triedb.NewTrieDB[hash.H256, runtime.BlakeTwo256](
+		root, inmemoryDB, triedb.WithRecorder[hash.H256, runtime.BlakeTwo256](recorder))
+	trieDB.SetVersion(version)
+	for _, key := range keys {
+		trieDB.Get(key)
+	}
+	return recorder.Drain()
+}
+
+func Test_NewCompactProof(t *testing.T) {
+	t.Parallel()
+
+	valueX := bytes.Repeat([]byte{'x'}, 33)
+	valueY := bytes.Repeat([]byte{'y'}, 33)
+	leafX := append([]byte{0x41, 0x00, 0x84}, valueX...)
+	leafY := append([]byte{0x41, 0x00, 0x84}, valueY...)
+	leafYHash := runtime.BlakeTwo256{}.Hash(leafY)
+
+	testCases := map[string]struct {
+		entries       []trie.Entry
+		version       trie.TrieLayout
+		keys          [][]byte
+		expectedProof compactProof
+	}{
+		"leaf": {
+			entries:       []trie.Entry{{Key: []byte("a"), Value: []byte("a")}},
+			version:       trie.V0,
+			keys:          [][]byte{[]byte("a")},
+			expectedProof: compactProof{{66, 97, 4, 97}},
+		},
+		"leaf_with_hashed_value": {
+			entries: []trie.Entry{{Key: []byte("a"), Value: bytes.Repeat([]byte{1}, 40)}},
+			version: trie.V1,
+			keys:    [][]byte{[]byte("a")},
+			expectedProof: compactProof{
+				{escapeCompactHeader, 66, 97, 0},
+				bytes.Repeat([]byte{1}, 40),
+			},
+		},
+		"branch_with_hashed_children": {
+			entries: []trie.Entry{
+				{Key: []byte{0x10}, Value: valueX},
+				{Key: []byte{0x20}, Value: valueY},
+			},
+			version: trie.V0,
+			keys:    [][]byte{{0x10}, {0x20}},
+			expectedProof: compactProof{
+				{128, 6, 0, 0, 0},
+				leafX,
+				leafY,
+			},
+		},
+		"branch_with_hashed_children_and_values": {
+			entries: []trie.Entry{
+				{Key: []byte{0x10}, Value: valueX},
+				{Key: []byte{0x20}, Value: valueY},
+			},
+			version: trie.V1,
+			keys:    [][]byte{{0x10}, {0x20}},
+			expectedProof: compactProof{
+				{128, 6, 0, 0, 0},
+				{escapeCompactHeader, 0x41, 0x00, 0x00},
+				valueX,
+				{escapeCompactHeader, 0x41, 0x00, 0x00},
+				valueY,
+			},
+		},
+		"partial_branch": {
+			entries: []trie.Entry{
+				{Key: []byte{0x10}, Value: valueX},
+				{Key: []byte{0x20}, Value: valueY},
+			},
+			version: trie.V0,
+			keys:    [][]byte{{0x10}},
+			expectedProof: compactProof{
+				append([]byte{128, 6, 0, 0, 128}, leafYHash.Bytes()...),
+				leafX,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			inmemoryDB, root := buildTrie(t, testCase.version, testCase.entries)
+			records := recordReads(t, inmemoryDB, testCase.version, root, testCase.keys...)
+
+			proof, err := NewCompactProofFromRecords[hash.H256, runtime.BlakeTwo256](records, root)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedProof, proof)
+
+			_, err = proof.ToMemoryDB(root)
+			require.NoError(t, err)
+		})
+	}
+}
+
+// Test_CompactProof_referenceVectors checks the proofs against vectors built from the
+// sp-trie codec tests and trie constants, with the roots hashed from the full encoding
+// of the root nodes.
+// https://github.com/paritytech/polkadot-sdk/blob/v1.16.4-rc1/substrate/primitives/trie/src/lib.rs
+func Test_CompactProof_referenceVectors(t *testing.T) {
+	t.Parallel()
+
+	// leaves of the keys 0x1314 and 0x4819 below a branch with the slots 1 and 4 taken,
+	// as in sp-trie codec_trie_two_tuples_disjoint_keys
+	leafA := []byte{0x43, 0x03, 0x14}
+	leafB := []byte{0x43, 0x08, 0x19}
+	longValueA := bytes.Repeat([]byte{0xff}, 33)
+	longValueB := bytes.Repeat([]byte{0xfe}, 33)
+	hashOf := func(data []byte) []byte {
+		return runtime.BlakeTwo256{}.Hash(data).Bytes()
+	}
+	concat := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	// 33 bytes values are inlined in V0 leaves, compact(33) = 0x84
+	fullLeafA := concat(leafA, []byte{0x84}, longValueA)
+	fullLeafB := concat(leafB, []byte{0x84}, longValueB)
+	// 33 bytes values are hashed in V1 leaves, ALT_HASHING_LEAF_PREFIX_MASK | 3 nibbles
+	hashedValueLeafA := concat([]byte{0x23, 0x03, 0x14}, hashOf(longValueA))
+	hashedValueLeafB := concat([]byte{0x23, 0x08, 0x19}, hashOf(longValueB))
+
+	// branch without value nor partial key, slots 1 and 4 taken, children omitted
+	compactBranch := []byte{0x80, 0x12, 0x00, 0x00, 0x00}
+	// hashed children are prefixed by compact(32) = 0x80
+	branchWithHashes := func(childA, childB []byte) []byte {
+		return concat([]byte{0x80, 0x12, 0x00, 0x80}, hashOf(childA), []byte{0x80}, hashOf(childB))
+	}
+
+	testCases := map[string]struct {
+		entries       []trie.Entry
+		version       trie.TrieLayout
+		keys          [][]byte
+		expectedProof compactProof
+		// rootNode is the full encoding of the root node
+		rootNode []byte
+	}{
+		// sp-trie codec_trie_single_tuple
+		"single_tuple": {
+			entries:       []trie.Entry{{Key: []byte{0xaa}, Value: []byte{0xbb}}},
+			version:       trie.V1,
+			keys:          [][]byte{{0xaa}},
+			expectedProof: compactProof{{0x42, 0xaa, 0x04, 0xbb}},
+			rootNode:      []byte{0x42, 0xaa, 0x04, 0xbb},
+		},
+		// sp-trie codec_trie_two_tuples_disjoint_keys, inline children are kept
+		"two_tuples_disjoint_keys": {
+			entries: []trie.Entry{
+				{Key: []byte{0x48, 0x19}, Value: []byte{0xfe}},
+				{Key: []byte{0x13, 0x14}, Value: []byte{0xff}},
+			},
+			version: trie.V1,
+			keys:    [][]byte{{0x48, 0x19}},
+			expectedProof: compactProof{{
+				0x80, 0x12, 0x00,
+				0x14, 0x43, 0x03, 0x14, 0x04, 0xff,
+				0x14, 0x43, 0x08, 0x19, 0x04, 0xfe,
+			}},
+			rootNode: []byte{
+				0x80, 0x12, 0x00,
+				0x14, 0x43, 0x03, 0x14, 0x04, 0xff,
+				0x14, 0x43, 0x08, 0x19, 0x04, 0xfe,
+			},
+		},
+		"hashed_children": {
+			entries: []trie.Entry{
+				{Key: []byte{0x48, 0x19}, Value: longValueB},
+				{Key: []byte{0x13, 0x14}, Value: longValueA},
+			},
+			version:       trie.V0,
+			keys:          [][]byte{{0x48, 0x19}, {0x13, 0x14}},
+			expectedProof: compactProof{compactBranch, fullLeafA, fullLeafB},
+			rootNode:      branchWithHashes(fullLeafA, fullLeafB),
+		},
+		"hashed_children_partial": {
+			entries: []trie.Entry{
+				{Key: []byte{0x48, 0x19}, Value: longValueB},
+				{Key: []byte{0x13, 0x14}, Value: longValueA},
+			},
+			version: trie.V0,
+			keys:    [][]byte{{0x13, 0x14}},
+			expectedProof: compactProof{
+				concat([]byte{0x80, 0x12, 0x00, 0x00, 0x80}, hashOf(fullLeafB)),
+				fullLeafA,
+			},
+			rootNode: branchWithHashes(fullLeafA, fullLeafB),
+		},
+		// the hashed values are escaped with ESCAPE_COMPACT_HEADER and follow their
+		// leaf, encoded with an empty inline value
+		"hashed_values": {
+			entries: []trie.Entry{
+				{Key: []byte{0x48, 0x19}, Value: longValueB},
+				{Key: []byte{0x13, 0x14}, Value: longValueA},
+			},
+			version: trie.V1,
+			keys:    [][]byte{{0x48, 0x19}, {0x13, 0x14}},
+			expectedProof: compactProof{
+				compactBranch,
+				concat([]byte{0x01}, leafA, []byte{0x00}),
+				longValueA,
+				concat([]byte{0x01}, leafB, []byte{0x00}),
+				longValueB,
+			},
+			rootNode: branchWithHashes(hashedValueLeafA, hashedValueLeafB),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expectedRoot := runtime.BlakeTwo256{}.Hash(testCase.rootNode)
+			inmemoryDB, root := buildTrie(t, testCase.version, testCase.entries)
+			require.Equal(t, expectedRoot, root)
+
+			records := recordReads(t, inmemoryDB, testCase.version, root, testCase.keys...)
+			proof, err := NewCompactProofFromRecords[hash.H256, runtime.BlakeTwo256](records, root)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedProof, proof)
+
+			proofDB, err := testCase.expectedProof.ToMemoryDB(expectedRoot)
+			require.NoError(t, err)
+			rootNode, err := proofDB.Get(expectedRoot.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, testCase.rootNode, rootNode)
+		})
+	}
+}
+
+func Test_CompactProof_Decode(t *testing.T) {
+	t.Parallel()
+
+	entries := []trie.Entry{
+		{Key: []byte("pol"), Value: []byte("pol")},
+		{Key: []byte("polka"), Value: []byte("polka")},
+		{Key: []byte("polkadot"), Value: bytes.Repeat([]byte("polkadot"), 10)},
+		{Key: []byte("go"), Value: []byte("go")},
+		{Key: []byte("golang"), Value: bytes.Repeat([]byte("golang"), 10)},
+		{Key: []byte("gossamer"), Value: []byte("gossamer")},
+	}
+	provenKeys := [][]byte{[]byte("go"), []byte("polkadot"), []byte("unknown")}
+
+	for _, version := range []trie.TrieLayout{trie.V0, trie.V1} {
+		t.Run(version.String(), func(t *testing.T) {
+			t.Parallel()
+
+			inmemoryDB, root := buildTrie(t, version, entries)
+			records := recordReads(t, inmemoryDB, version, root, provenKeys...)
+			proof, err := NewCompactProofFromRecords[hash.H256, runtime.BlakeTwo256](records, root)
+			require.NoError(t, err)
+
+			proofDB := NewMemoryDB(triedb.EmptyNode)
+			decodedRoot, err := proof.Decode(proofDB)
+			require.NoError(t, err)
+			require.Equal(t, root, decodedRoot)
+
+			// every recorded node and value is decoded
+			for _, record := range records {
+				data, err := proofDB.Get(record.Hash.Bytes())
+				require.NoError(t, err)
+				assert.Equal(t, record.Data, data)
+			}
+
+			proofTrie := triedb.NewTrieDB[hash.H256, runtime.BlakeTwo256](root, hashKeyedDB{proofDB})
+			proofTrie.SetVersion(version)
+			for _, key := range provenKeys {
+				expected := triedb.NewTrieDB[hash.H256, runtime.BlakeTwo256](root, inmemoryDB).Get(key)
+				assert.Equal(t, expected, proofTrie.Get(key))
+			}
+		})
+	}
+
+	t.Run("root_mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		inmemoryDB, root := buildTrie(t, trie.V1, entries)
+		records := recordReads(t, inmemoryDB, trie.V1, root, provenKeys...)
+		proof, err := NewCompactProofFromRecords[hash.H256, runtime.BlakeTwo256](records, root)
+		require.NoError(t, err)
+
+		_, err = proof.ToMemoryDB(hash.H256(bytes.Repeat([]byte{1}, 32)))
+		assert.ErrorIs(t, err, ErrRootMismatch)
+	})
+
+	t.Run("incomplete_proof", func(t *testing.T) {
+		t.Parallel()
+
+		inmemoryDB, root := buildTrie(t, trie.V1, entries)
+		records := recordReads(t, inmemoryDB, trie.V1, root, provenKeys...)
+		proof, err := NewCompactProofFromRecords[hash.H256, runtime.BlakeTwo256](records, root)
+		require.NoError(t, err)
+
+		_, err = proof[:len(proof)-1].ToMemoryDB(root)
+		assert.ErrorIs(t, err, ErrIncompleteProof)
+	})
+
+	t.Run("extraneous_node", func(t *testing.T) {
+		t.Parallel()
+
+		inmemoryDB, root := buildTrie(t, trie.V1, entries)
+		records := recordReads(t, inmemoryDB, trie.V1, root, provenKeys...)
+		proof, err := NewCompactProofFromRecords[hash.H256, runtime.BlakeTwo256](records, root)
+		require.NoError(t, err)
+
+		proof = append(proof, []byte{66, 97, 4, 97})
+		_, err = proof.ToMemoryDB(root)
+		assert.ErrorIs(t, err, ErrExtraneousChildNode)
+	})
+}
+
+func Test_CompactProof_ChildTries(t *testing.T) {
+	t.Parallel()
+
+	for _, version := range []trie.TrieLayout{trie.V0, trie.V1} {
+		t.Run(version.String(), func(t *testing.T) {
+			t.Parallel()
+
+			childEntries := make([]trie.Entry, 20)
+			for i := range childEntries {
+				childEntries[i] = trie.Entry{
+					Key:   []byte(fmt.Sprintf("child_key_%d", i)),
+					Value: bytes.Repeat([]byte{byte(i)}, 40),
+				}
+			}
+			childDB, childRoot := buildTrie(t, version, childEntries)
+			childKey := append(bytes.Clone(trie.ChildStorageKeyPrefix), []byte("child")...)
+
+			inmemoryDB, root := buildTrie(t, version, []trie.Entry{
+				{Key: []byte("key"), Value: []byte("value")},
+				{Key: []byte("other"), Value: bytes.Repeat([]byte{1}, 40)},
+				{Key: childKey, Value: childRoot.Bytes()},
+			})
+
+			records := recordReads(t, inmemoryDB, version, root, []byte("key"), childKey)
+			records = append(records, recordReads(t, childDB, version, childRoot, childEntries[3].Key)...)
+
+			proof, err := NewCompactProofFromRecords[hash.H256, runtime.BlakeTwo256](records, root)
+			require.NoError(t, err)
+
+			proofDB := NewMemoryDB(triedb.EmptyNode)
+			decodedRoot, err := proof.Decode(proofDB)
+			require.NoError(t, err)
+			require.Equal(t, root, decodedRoot)
+
+			childTrie := triedb.NewTrieDB[hash.H256, runtime.BlakeTwo256](childRoot, hashKeyedDB{proofDB})
+			childTrie.SetVersion(version)
+			assert.Equal(t, childEntries[3].Value, childTrie.Get(childEntries[3].Key))
+
+			// the child trie nodes are not referenced without the child trie root in the top trie
+			topRecords := recordReads(t, inmemoryDB, version, root, []byte("key"))
+			topProof, err := NewCompactProofFromRecords[hash.H256, runtime.BlakeTwo256](topRecords, root)
+			require.NoError(t, err)
+			childProof, err := NewCompactProofFromRecords[hash.H256, runtime.BlakeTwo256](records, childRoot)
+			require.NoError(t, err)
+
+			_, err = append(topProof, childProof...).ToMemoryDB(root)
+			assert.ErrorIs(t, err, ErrExtraneousChildNode)
+		})
+	}
+}
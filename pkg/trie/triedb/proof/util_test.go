@@ -7,6 +7,7 @@
 	"bytes"
 
 	"github.com/ChainSafe/gossamer/internal/database"
+	"github.com/ChainSafe/gossamer/internal/primitives/core/hash"
 	"github.com/ChainSafe/gossamer/internal/primitives/runtime"
 	"github.com/ChainSafe/gossamer/pkg/trie/db"
 )
@@ -88,3 +89,12 @@ func (b *MemoryBatch) ValueSize() int {
 }
 
 var _ database.Batch = &MemoryBatch{}
+
+// hashKeyedDB reads the nodes of a database keyed by hash with the prefixed keys of TrieDB
+type hashKeyedDB struct {
+	*MemoryDB
+}
+
+func (db hashKeyedDB) Get(key []byte) ([]byte, error) {
+	return db.MemoryDB.Get(key[len(key)-hash.H256("").Length():])
+}
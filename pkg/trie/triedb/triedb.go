@@ -10,6 +10,7 @@
 	"slices"
 
 	"github.com/ChainSafe/gossamer/pkg/trie"
+	"github.com/ChainSafe/gossamer/pkg/trie/cache"
 	"github.com/ChainSafe/gossamer/pkg/trie/db"
 
 	"github.com/ChainSafe/gossamer/internal/database"
@@ -27,14 +28,23 @@
 
 type TrieDBOpts[H hash.Hash, Hasher hash.Hasher[H]] func(*TrieDB[H, Hasher])
 
-// Define cache interface for now to reduce size of changes
-type Cache interface{}
+// Cache caches the encoded nodes and hashed values by their hash, and the values of the
+// committed tries by their root and key. It can be shared by the tries of the same database.
+type Cache = cache.TrieCache
 
 func WithCache[H hash.Hash, Hasher hash.Hasher[H]](c Cache) TrieDBOpts[H, Hasher] {
 	return func(t *TrieDB[H, Hasher]) {
 		t.cache = c
 	}
 }
+
+// WithSharedValues keeps the replaced and removed hashed values in the database,
+// for databases storing the values by hash only where several keys can share a value.
+func WithSharedValues[H hash.Hash, Hasher hash.Hasher[H]]() TrieDBOpts[H, Hasher] {
+	return func(t *TrieDB[H, Hasher]) {
+		t.sharedValues = true
+	}
+}
 func WithRecorder[H hash.Hash, Hasher hash.Hasher[H]](r TrieRecorder) TrieDBOpts[H, Hasher] {
 	return func(t *TrieDB[H, Hasher]) {
 		t.recorder = r
@@ -60,13 +70,15 @@ type TrieDB[H hash.Hash, Hasher hash.Hasher[H]] struct {
 	cache Cache
 	// Optional recorder for recording trie accesses
 	recorder TrieRecorder
+	// sharedValues is set to not delete the hashed values from the db
+	sharedValues bool
 }
 
 func NewEmptyTrieDB[H hash.Hash, Hasher hash.Hasher[H]](
 	db db.RWDatabase, opts ...TrieDBOpts[H, Hasher]) *TrieDB[H, Hasher] {
 	hasher := *new(Hasher)
 	root := hasher.Hash([]byte{0})
-	return NewTrieDB[H, Hasher](root, db)
+	return NewTrieDB[H, Hasher](root, db, opts...)
 }
 
 // NewTrieDB creates a new TrieDB using the given root and db
@@ -125,14 +137,39 @@ func (t *TrieDB[H, Hasher]) MustHash() H {
 // which matches its key with the key given.
 // Note the key argument is given in little Endian format.
 func (t *TrieDB[H, Hasher]) Get(key []byte) []byte {
+	// the values are cached by root so only the values of the committed trie can be cached,
+	// and the accesses have to be recorded while recording
+	useValueCache := t.cache != nil && t.recorder == nil && t.isCommitted()
+	if useValueCache {
+		if val, cached := t.cache.GetValueForKey(t.rootHash.Bytes(), key); cached {
+			return val
+		}
+	}
+
 	val, err := t.lookup(key, nibbles.NewNibbles(slices.Clone(key)), t.rootHandle)
 	if err != nil {
 		return nil
 	}
 
+	if useValueCache {
+		t.cache.SetValueForKey(t.rootHash.Bytes(), key, val)
+	}
 	return val
 }
 
+// isCommitted returns true if the trie has no changes since it was last committed.
+func (t *TrieDB[H, Hasher]) isCommitted() bool {
+	switch h := t.rootHandle.(type) {
+	case persisted[H]:
+		return true
+	case inMemory:
+		_, cached := t.storage.nodes[h].(CachedStoredNode[H])
+		return cached
+	default:
+		return false
+	}
+}
+
 func (t *TrieDB[H, Hasher]) lookup(fullKey []byte, partialKey nibbles.Nibbles, handle NodeHandle) ([]byte, error) {
 	prefix := fullKey
 
@@ -152,14 +189,14 @@ func (t *TrieDB[H, Hasher]) lookup(fullKey []byte, partialKey nibbles.Nibbles, h
 				return nil, nil
 			case Leaf[H]:
 				if nibbles.NewNibblesFromNodeKey(n.partialKey).Equal(partialKey) {
-					return inMemoryFetchedValue(n.value, prefix, t.db)
+					return inMemoryFetchedValue(n.value, prefix, t.db, t.cache)
 				} else {
 					return nil, nil
 				}
 			case Branch[H]:
 				slice := nibbles.NewNibblesFromNodeKey(n.partialKey)
 				if slice.Equal(partialKey) {
-					return inMemoryFetchedValue(n.value, prefix, t.db)
+					return inMemoryFetchedValue(n.value, prefix, t.db, t.cache)
 				} else if partialKey.StartsWith(slice) {
 					idx := partialKey.At(slice.Len())
 					child := n.children[idx]
@@ -187,7 +224,7 @@ func (t *TrieDB[H, Hasher]) getNodeOrLookup(
 	case codec.HashedNode[H]:
 		prefixedKey := append(partialKey.JoinedBytes(), nodeHandle.Hash.Bytes()...)
 		var err error
-		nodeData, err = t.db.Get(prefixedKey)
+		nodeData, err = getEncodedNode(t.db, t.cache, prefixedKey, nodeHandle.Hash)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -217,7 +254,7 @@ func (t *TrieDB[H, Hasher]) getNodeOrLookup(
 
 func (t *TrieDB[H, Hasher]) fetchValue(hash H, prefix nibbles.Prefix) ([]byte, error) {
 	prefixedKey := append(prefix.JoinedBytes(), hash.Bytes()...)
-	value, err := t.db.Get(prefixedKey)
+	value, err := getHashedValue(t.db, t.cache, prefixedKey, hash)
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +287,7 @@ func (t *TrieDB[H, Hasher]) remove(keyNibbles nibbles.Nibbles) error {
 
 // Delete deletes the given key from the trie
 func (t *TrieDB[H, Hasher]) Delete(key []byte) error {
-	return t.remove(nibbles.NewNibbles(key))
+	return t.remove(nibbles.NewNibbles(slices.Clone(key)))
 }
 
 // insert inserts the node and update the rootHandle
@@ -268,7 +305,7 @@ func (t *TrieDB[H, Hasher]) insert(keyNibbles nibbles.Nibbles, value []byte) err
 
 // Put inserts the given key / value pair into the trie
 func (t *TrieDB[H, Hasher]) Put(key, value []byte) error {
-	return t.insert(nibbles.NewNibbles(key), value)
+	return t.insert(nibbles.NewNibbles(slices.Clone(key)), value)
 }
 
 // insertAt inserts the given key / value pair into the node referenced by the
@@ -442,7 +479,7 @@ func (t *TrieDB[H, Hasher]) fix(branch Branch[H], key *nibbles.Nibbles) (Node, e
 			pushed := nibbles.PushAtLeft(0, idx, 0)
 			prefixEnd = &pushed
 		default:
-			so := prefix.Key
+			so := slices.Clip(prefix.Key)
 			so = append(so, nibbles.PadLeft(*prefix.Padded)|idx)
 			start = prefix.Key
 			allocStart = so
@@ -508,6 +545,8 @@ func combineKey(start nodeKey, end nodeKey) nodeKey {
 	if !(end.Offset < nibbles.NibblesPerByte) {
 		panic("invalid end offset")
 	}
+	// the data of the start key may be shared with other nodes
+	start.Data = slices.Clone(start.Data)
 	finalOffset := (start.Offset + end.Offset) % nibbles.NibblesPerByte
 	_ = start.ShiftKey(finalOffset)
 	var st uint
@@ -545,20 +584,6 @@ func (t *TrieDB[H, Hasher]) removeInspector(
 		// Wrong partial, so we return the node as is
 		return restoreNode{n}, nil
 	case Branch[H]:
-		if partial.Len() == 0 {
-			if n.value == nil {
-				// Nothing to delete since the branch doesn't contains a value
-				return restoreNode{n}, nil
-			}
-			// The branch contains the value so we delete it
-			t.replaceOldValue(oldValue, n.value, keyNibbles.Left())
-			newNode, err := t.fix(Branch[H]{n.partialKey, n.children, nil}, keyNibbles)
-			if err != nil {
-				return nil, err
-			}
-			return replaceNode{newNode}, nil
-		}
-
 		existingKey := nibbles.NewNibblesFromNodeKey(n.partialKey)
 
 		common := existingKey.CommonPrefix(partial)
@@ -586,7 +611,7 @@ func (t *TrieDB[H, Hasher]) removeInspector(
 		if child == nil {
 			return restoreNode{n}, nil
 		}
-		prefix := keyNibbles
+		prefix := *keyNibbles
 		keyNibbles.Advance(common + 1)
 
 		removeAtResult, err := t.removeAt(child, keyNibbles, oldValue)
@@ -602,7 +627,7 @@ func (t *TrieDB[H, Hasher]) removeInspector(
 			return restoreNode{n}, nil
 		}
 
-		newNode, err := t.fix(n, prefix)
+		newNode, err := t.fix(n, &prefix)
 		if err != nil {
 			return nil, err
 		}
@@ -792,6 +817,11 @@ func (t *TrieDB[H, Hasher]) replaceOldValue(
 	storedValue nodeValue[H],
 	prefix nibbles.Prefix,
 ) {
+	if t.sharedValues {
+		*oldValue = storedValue
+		return
+	}
+
 	switch oldv := storedValue.(type) {
 	case valueRef[H]:
 		hash := oldv.getHash()
@@ -810,17 +840,16 @@ func (t *TrieDB[H, Hasher]) replaceOldValue(
 }
 
 // lookup node in DB and add it in storage, return storage handle
-// TODO: implement cache to improve performance
 func (t *TrieDB[H, Hasher]) lookupNode(hash H, key nibbles.Prefix) (storageHandle, error) {
 	prefixedKey := append(key.JoinedBytes(), hash.Bytes()...)
-	encodedNode, err := t.db.Get(prefixedKey)
+	encodedNode, err := getEncodedNode(t.db, t.cache, prefixedKey, hash)
 	if err != nil {
 		return -1, ErrIncompleteDB
 	}
 
 	t.recordAccess(EncodedNodeAccess[H]{Hash: t.rootHash, EncodedNode: encodedNode})
 
-	node, err := newNodeFromEncoded[H](hash, encodedNode, t.storage)
+	node, err := newNodeFromEncoded[H](hash, encodedNode, &t.storage)
 	if err != nil {
 		return -1, err
 	}
@@ -863,14 +892,6 @@ func (t *TrieDB[H, Hasher]) commit() error {
 
 	switch stored := t.storage.destroy(handle).(type) {
 	case NewStoredNode:
-		// Reconstructs the full key for root node
-		var fullKey *nibbles.NibbleSlice
-		if pk := stored.getNode().getPartialKey(); pk != nil {
-			fk := nibbles.NewNibblesFromNodeKey(*pk)
-			ns := nibbles.NewNibbleSliceFromNibbles(fk)
-			fullKey = &ns
-		}
-
 		var k nibbles.NibbleSlice
 
 		encodedNode, err := newEncodedNode[H](
@@ -885,6 +906,7 @@ func(node nodeToEncode, partialKey *nibbles.Nibbles, childIndex *byte) (ChildRef
 					if err != nil {
 						return nil, err
 					}
+					t.cacheValue(hash, n.value)
 					k.DropLasts(mov)
 					return HashChildReference[H]{hash}, nil
 				case trieNodeToEncode:
@@ -911,12 +933,10 @@ func(node nodeToEncode, partialKey *nibbles.Nibbles, childIndex *byte) (ChildRef
 			return err
 		}
 
+		t.cacheNode(hash, encodedNode)
 		t.rootHash = hash
 		t.rootHandle = persisted[H]{t.rootHash}
 
-		// TODO: use fullKey when caching these nodes
-		_ = fullKey
-
 		// Flush all db changes
 		return dbBatch.Flush()
 	case CachedStoredNode[H]:
@@ -946,16 +966,6 @@ func (t *TrieDB[H, Hasher]) commitChild(
 		case CachedStoredNode[H]:
 			return HashChildReference[H]{storedNode.hash}, nil
 		case NewStoredNode:
-			var fullKey *nibbles.NibbleSlice
-			prefix := prefixKey.Clone()
-			if partial := stored.getNode().getPartialKey(); partial != nil {
-				fk := nibbles.NewNibblesFromNodeKey(*partial)
-				prefix.AppendPartial(fk.RightPartial())
-			}
-			fullKey = &prefix
-			// TODO: caching uses fullKey
-			_ = fullKey
-
 			// We have to store the node in the DB
 			commitChildFunc := func(node nodeToEncode, partialKey *nibbles.Nibbles, childIndex *byte) (ChildReference, error) {
 				mov := prefixKey.AppendOptionalSliceAndNibble(partialKey, childIndex)
@@ -967,6 +977,7 @@ func (t *TrieDB[H, Hasher]) commitChild(
 					if err != nil {
 						panic("inserting in db")
 					}
+					t.cacheValue(hash, n.value)
 
 					prefixKey.DropLasts(mov)
 					return HashChildReference[H]{hash}, nil
@@ -996,6 +1007,7 @@ func (t *TrieDB[H, Hasher]) commitChild(
 				if err != nil {
 					return nil, err
 				}
+				t.cacheNode(hash, encoded)
 
 				return HashChildReference[H]{hash}, nil
 			} else {
@@ -1009,6 +1021,20 @@ func (t *TrieDB[H, Hasher]) commitChild(
 	}
 }
 
+// cacheNode caches the committed encoded node so it is not read back from the db
+func (t *TrieDB[H, Hasher]) cacheNode(hash H, encodedNode []byte) {
+	if t.cache != nil {
+		t.cache.SetNode(hash.Bytes(), encodedNode)
+	}
+}
+
+// cacheValue caches the committed hashed value so it is not read back from the db
+func (t *TrieDB[H, Hasher]) cacheValue(hash H, value []byte) {
+	if t.cache != nil {
+		t.cache.SetValue(hash.Bytes(), value)
+	}
+}
+
 func (t *TrieDB[H, Hasher]) recordAccess(access TrieAccess) {
 	if t.recorder != nil {
 		t.recorder.Record(access)
@@ -87,4 +87,35 @@ func TestIterator(t *testing.T) {
 		assert.NotNil(t, actual)
 		assert.Equal(t, expected, actual.Key)
 	})
+
+	t.Run("trie_iterator_over_all_entries", func(t *testing.T) {
+		iter := NewTrieDBIterator(trieDB)
+
+		expected := inMemoryTrie.NextKey([]byte{})
+		i := 0
+		for entry := iter.NextEntry(); entry != nil; entry = iter.NextEntry() {
+			assert.Equal(t, expected, entry.Key)
+			assert.Equal(t, entries[string(entry.Key)], entry.Value)
+			expected = inMemoryTrie.NextKey(expected)
+			i++
+		}
+		assert.Equal(t, len(entries), i)
+	})
+
+	t.Run("trie_iterator_with_cursor", func(t *testing.T) {
+		iter := NewTrieDBIterator(trieDB, WithCursorAt[hash.H256, runtime.BlakeTwo256]([]byte("not")))
+		assert.Equal(t, inMemoryTrie.NextKey([]byte("not")), iter.NextKey())
+
+		iter = NewTrieDBIterator(trieDB, WithCursorAt[hash.H256, runtime.BlakeTwo256]([]byte("nota")))
+		assert.Equal(t, []byte("notable"), iter.NextKey())
+	})
+
+	t.Run("trie_iterator_seek", func(t *testing.T) {
+		iter := NewTrieDBIterator(trieDB)
+		iter.Seek([]byte("not"))
+		assert.Equal(t, []byte("notable"), iter.NextKey())
+
+		iter.Seek([]byte("nou"))
+		assert.Nil(t, iter.NextKey())
+	})
 }
@@ -4,6 +4,7 @@
 package triedb
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/ChainSafe/gossamer/internal/primitives/core/hash"
@@ -642,6 +643,30 @@ func TestDBCommits(t *testing.T) {
 		assert.Equal(t, []byte("leafvalue"), value)
 	})
 
+	t.Run("insert_into_reloaded_branch_with_inlined_leaves", func(t *testing.T) {
+		t.Parallel()
+
+		inmemoryDB := NewMemoryDB[hash.H256, runtime.BlakeTwo256](EmptyNode)
+		tr := NewEmptyTrieDB[hash.H256, runtime.BlakeTwo256](inmemoryDB)
+
+		err := tr.Put([]byte("a"), []byte{1})
+		assert.NoError(t, err)
+		err = tr.Put([]byte("b"), []byte{2})
+		assert.NoError(t, err)
+
+		err = tr.commit()
+		assert.NoError(t, err)
+
+		reloaded := NewTrieDB[hash.H256, runtime.BlakeTwo256](tr.rootHash, inmemoryDB)
+		err = reloaded.Put([]byte("a"), []byte{3})
+		assert.NoError(t, err)
+
+		value := reloaded.Get([]byte("a"))
+		assert.Equal(t, []byte{3}, value)
+		value = reloaded.Get([]byte("b"))
+		assert.Equal(t, []byte{2}, value)
+	})
+
 	t.Run("commit_branch_and_hashed_leaf", func(t *testing.T) {
 		t.Parallel()
 
@@ -689,6 +714,23 @@ func TestDBCommits(t *testing.T) {
 		assert.Equal(t, make([]byte, 40), value)
 	})
 
+	t.Run("commit_leaf_with_max_inline_value", func(t *testing.T) {
+		t.Parallel()
+
+		inmemoryDB := NewMemoryDB[hash.H256, runtime.BlakeTwo256](EmptyNode)
+		tr := NewEmptyTrieDB[hash.H256, runtime.BlakeTwo256](inmemoryDB)
+		tr.SetVersion(trie.V1)
+
+		err := tr.Put([]byte("leaf"), make([]byte, trie.V1MaxInlineValueSize))
+		assert.NoError(t, err)
+
+		err = tr.commit()
+		assert.NoError(t, err)
+
+		// 1 leaf with its inlined value
+		assert.Len(t, inmemoryDB.data, 1)
+	})
+
 	t.Run("commit_leaf_with_hashed_value_then_remove_it", func(t *testing.T) {
 		t.Parallel()
 
@@ -800,3 +842,66 @@ func TestDBCommits(t *testing.T) {
 		assert.Nil(t, v)
 	})
 }
+
+func TestPutAndDeleteDoNotModifyKey(t *testing.T) {
+	t.Parallel()
+
+	inmemoryDB := NewMemoryDB[hash.H256, runtime.BlakeTwo256](EmptyNode)
+	tr := NewEmptyTrieDB[hash.H256, runtime.BlakeTwo256](inmemoryDB)
+
+	// keys with spare capacity, which the trie must not write into
+	keys := [][]byte{
+		append(make([]byte, 0, 64), ":child_storage:default:child"...),
+		append(make([]byte, 0, 64), ":child_storage:default:other"...),
+		append(make([]byte, 0, 64), "key"...),
+	}
+	originals := make([][]byte, len(keys))
+	for i, key := range keys {
+		originals[i] = bytes.Clone(key)
+	}
+
+	for _, key := range keys {
+		require.NoError(t, tr.Put(key, []byte{1}))
+	}
+	assert.Equal(t, originals, keys)
+	for _, key := range originals {
+		assert.Equal(t, []byte{1}, tr.Get(key))
+	}
+
+	for _, key := range keys {
+		require.NoError(t, tr.Delete(key))
+	}
+	assert.Equal(t, originals, keys)
+	for _, key := range originals {
+		assert.Nil(t, tr.Get(key))
+	}
+}
+
+func TestDeleteMissingKeysWithinPartialKeys(t *testing.T) {
+	t.Parallel()
+
+	// the root branch has the partial key 0x1, its child at index 1 is a branch with
+	// the partial key 0x11 holding the value of 0x1111, so keys ending or diverging
+	// within these partial keys are not in the trie.
+	entries := []trie.Entry{
+		{Key: []byte{0x11, 0x11}, Value: []byte{1}},
+		{Key: []byte{0x12, 0x22}, Value: []byte{2}},
+		{Key: []byte{0x11, 0x11, 0x22, 0x33}, Value: []byte{3}},
+	}
+
+	inmemoryDB := NewMemoryDB[hash.H256, runtime.BlakeTwo256](EmptyNode)
+	tr := NewEmptyTrieDB[hash.H256, runtime.BlakeTwo256](inmemoryDB)
+	for _, entry := range entries {
+		require.NoError(t, tr.Put(entry.Key, entry.Value))
+	}
+	root := tr.MustHash()
+
+	for _, key := range [][]byte{nil, {0x11}, {0x13}, {0x11, 0x10}, {0x11, 0x11, 0x20}} {
+		require.NoError(t, tr.Delete(key))
+		assert.Equal(t, root, tr.MustHash(), "key 0x%x", key)
+	}
+
+	for _, entry := range entries {
+		assert.Equal(t, entry.Value, tr.Get(entry.Key))
+	}
+}